@@ -1,40 +1,234 @@
 /**
  * MicroScript — The programming language
  * Copyright (c) 2025 Cyril John Magayaga
- * 
+ *
  * It was originally written in Java programming language.
  */
 package com.magayaga.microscript;
 
+import java.util.ArrayList;
 import java.util.List;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
 
 public class ArrowFunction extends Function {
+    private static final Pattern LINE_SEPARATOR_PATTERN = Pattern.compile("\\r?\\n");
+    private static final Pattern LAMBDA_PATTERN = Pattern.compile("^\\|([^|]*)\\|\\s*=>\\s*(.+)$", Pattern.DOTALL);
+    private static final Pattern TYPED_BLOCK_PATTERN = Pattern.compile("^(\\w+(?:<[^>{]*>)?\\??)\\s*(\\{.*\\})$", Pattern.DOTALL);
+    // What an inline lambda's untyped parameters and result are called
+    private static final String ANY = "T";
+
     private final String body;
     private final boolean isExpression;
+    // The body as lines, compiled once like a declared function's
+    private final Body lines;
+    private Environment closure;
 
     public ArrowFunction(String name, List<Parameter> parameters, String returnType, String body, boolean isExpression) {
+        // For expression bodied functions, wrap the expression in a return statement;
+        // block bodied functions are split into lines
+        this(name, parameters, returnType, body, isExpression,
+            new Body(isExpression ? List.of("return " + body + ";") : List.of(LINE_SEPARATOR_PATTERN.split(body))));
+    }
+
+    private ArrowFunction(String name, List<Parameter> parameters, String returnType, String body, boolean isExpression, Body lines) {
         super(name, parameters, returnType, null); // Pass null for body since we'll override it
         this.body = body;
         this.isExpression = isExpression;
+        this.lines = lines;
     }
 
     // Override to handle the body as a string instead of a list
     @Override
-    public List<String> getBody() {
-        // For expression bodied functions, wrap the expression in a return statement
-        if (isExpression) {
-            return List.of("return " + body + ";");
-        } else {
-            // For block bodied functions, split the body into lines
-            return List.of(body.split("\\r?\\n"));
-        }
+    public Body getBody() {
+        return lines;
     }
-    
+
+    /**
+     * The same lambda reading closure instead of the scope it was written in,
+     * sharing the compiled body
+     */
+    public ArrowFunction withClosure(Environment closure) {
+        ArrowFunction copy = new ArrowFunction(getName(), getParameters(), getReturnType(), body, isExpression, lines);
+        copy.setTypeParameters(getTypeParameters());
+        copy.closure = closure;
+        return copy;
+    }
+
     public String getRawBody() {
         return body;
     }
-    
+
     public boolean isExpression() {
         return isExpression;
     }
+
+    /**
+     * The environment an inline lambda was written in, which its body sees,
+     * or null for arrow functions declared with var
+     */
+    public Environment getClosure() {
+        return closure;
+    }
+
+    /**
+     * Builds an anonymous function written inline, such as the argument in
+     * each(xs, |Int32: x| => console.write("{x}")). The body is an expression,
+     * a single console statement, or statements in braces, optionally after a
+     * return type: |Int32: x| => Int32 { var y = x * 2; return y + 1; }.
+     * Parameters and results without a type take any value.
+     */
+    public static ArrowFunction lambda(String text, Environment closure) {
+        Matcher matcher = LAMBDA_PATTERN.matcher(text.trim());
+        if (!matcher.matches()) {
+            throw new RuntimeException("Invalid lambda syntax: " + text.trim());
+        }
+        String parameterList = matcher.group(1).trim();
+        String rest = matcher.group(2).trim();
+        boolean generic = false;
+
+        List<Parameter> parameters = new ArrayList<>();
+        if (!parameterList.isEmpty() && !parameterList.equals("&")) {
+            for (String parameter : Parser.splitParameters(parameterList)) {
+                String[] typeAndName = parameter.trim().split(":");
+                if (typeAndName.length == 1 && typeAndName[0].trim().matches("[A-Za-z_]\\w*")) {
+                    parameters.add(new Parameter(typeAndName[0].trim(), ANY));
+                    generic = true;
+                } else if (typeAndName.length == 2) {
+                    parameters.add(new Parameter(typeAndName[1].trim(), typeAndName[0].trim()));
+                } else {
+                    throw new RuntimeException("Invalid parameter format in lambda: " + parameter.trim());
+                }
+            }
+        }
+
+        String returnType = null;
+        Matcher typed = TYPED_BLOCK_PATTERN.matcher(rest);
+        if (typed.matches()) {
+            returnType = typed.group(1);
+            rest = typed.group(2);
+        }
+        if (returnType == null) {
+            returnType = ANY;
+            generic = true;
+        }
+
+        ArrowFunction function;
+        if (rest.startsWith("{") && rest.endsWith("}")) {
+            String inner = rest.substring(1, rest.length() - 1).trim();
+            List<String> statements = statements(inner);
+            function = statements.size() == 1 && !inner.endsWith(";") && !inner.startsWith("console.")
+                ? new ArrowFunction("lambda", parameters, returnType, inner, true)
+                : new ArrowFunction("lambda", parameters, returnType, String.join("\n", statements), false);
+        } else if (rest.startsWith("console.")) {
+            // console.write prints rather than giving a value, so it runs as a statement
+            function = new ArrowFunction("lambda", parameters, returnType, rest.endsWith(";") ? rest : rest + ";", false);
+        } else {
+            function = new ArrowFunction("lambda", parameters, returnType, rest, true);
+        }
+        if (generic) {
+            function.setTypeParameters(List.of(ANY));
+        }
+        if (closure != null) {
+            // The lambda may be returned or stored, and still read the scope once its function has returned
+            closure.capture();
+        }
+        function.closure = closure;
+        return function;
+    }
+
+    /**
+     * Where the lambda starting at start ends, or -1 when none starts there.
+     * An expression body ends at a comma or closing bracket outside brackets
+     * and strings, so a lambda can be one argument among others.
+     */
+    public static int lambdaEnd(String text, int start) {
+        if (start >= text.length() || text.charAt(start) != '|' || text.startsWith("||", start)) {
+            return -1;
+        }
+        int close = text.indexOf('|', start + 1);
+        if (close < 0) {
+            return -1;
+        }
+        int i = skipSpaces(text, close + 1);
+        if (!text.startsWith("=>", i)) {
+            return -1;
+        }
+        i = skipSpaces(text, i + 2);
+        int typeEnd = i;
+        while (typeEnd < text.length() && (Character.isLetterOrDigit(text.charAt(typeEnd)) || "_<>?".indexOf(text.charAt(typeEnd)) >= 0)) {
+            typeEnd++;
+        }
+        int brace = skipSpaces(text, typeEnd);
+        boolean block = brace < text.length() && text.charAt(brace) == '{';
+        if (block) {
+            i = brace;
+        }
+
+        int depth = 0;
+        char quote = 0;
+        for (; i < text.length(); i++) {
+            char c = text.charAt(i);
+            if (quote != 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == quote) {
+                    quote = 0;
+                }
+            } else if (c == '"' || c == '\'') {
+                quote = c;
+            } else if (c == '(' || c == '[' || c == '{') {
+                depth++;
+            } else if (c == ')' || c == ']' || c == '}') {
+                if (depth == 0) {
+                    return i;
+                }
+                depth--;
+                if (block && depth == 0) {
+                    return i + 1;
+                }
+            } else if (depth == 0 && (c == ',' || c == ';')) {
+                return i;
+            }
+        }
+        return block ? -1 : text.length();
+    }
+
+    private static int skipSpaces(String text, int i) {
+        while (i < text.length() && Character.isWhitespace(text.charAt(i))) {
+            i++;
+        }
+        return i;
+    }
+
+    // Splits a block body into statements at semicolons outside brackets and strings
+    private static List<String> statements(String block) {
+        List<String> statements = new ArrayList<>();
+        int depth = 0;
+        char quote = 0;
+        int start = 0;
+        for (int i = 0; i < block.length(); i++) {
+            char c = block.charAt(i);
+            if (quote != 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == quote) {
+                    quote = 0;
+                }
+            } else if (c == '"' || c == '\'') {
+                quote = c;
+            } else if (c == '(' || c == '[' || c == '{') {
+                depth++;
+            } else if (c == ')' || c == ']' || c == '}') {
+                depth--;
+            } else if (c == ';' && depth == 0) {
+                statements.add(block.substring(start, i + 1).trim());
+                start = i + 1;
+            }
+        }
+        if (!block.substring(start).trim().isEmpty()) {
+            statements.add(block.substring(start).trim());
+        }
+        return statements;
+    }
 }
@@ -0,0 +1,264 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.lang.management.ManagementFactory;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+
+/**
+ * Runs the bench_* functions of a script and reports time and allocations per call.
+ * Usage:
+ *   microscript bench <file> [--time <duration>] [--out <results>]
+ *   microscript bench --compare <old results> <new results>
+ */
+public class Benchmark {
+    private static final String PREFIX = "bench_";
+    private static final long DEFAULT_TARGET_NANOS = 1_000_000_000L;
+    private static final long MAX_ITERATIONS = 1_000_000_000L;
+
+    private static final com.sun.management.ThreadMXBean THREADS = threadBean();
+
+    /**
+     * The measurement of one benchmark function
+     */
+    public static class Result {
+        final String name;
+        final long iterations;
+        final double nsPerOp;
+        final long bytesPerOp; // -1 when allocations can't be measured
+
+        Result(String name, long iterations, double nsPerOp, long bytesPerOp) {
+            this.name = name;
+            this.iterations = iterations;
+            this.nsPerOp = nsPerOp;
+            this.bytesPerOp = bytesPerOp;
+        }
+
+        // Same layout as Go's benchmark output so existing tooling can read it
+        String format() {
+            String line = String.format("%-30s %10d %14.1f ns/op", name, iterations, nsPerOp);
+            if (bytesPerOp >= 0) {
+                line += String.format(" %10d B/op", bytesPerOp);
+            }
+            return line;
+        }
+    }
+
+    public static void main(String[] args) {
+        if (args.length >= 1 && args[0].equals("--compare")) {
+            if (args.length != 3) {
+                System.err.println("Usage: microscript bench --compare <old results> <new results>");
+                return;
+            }
+            try {
+                compare(readResults(Paths.get(args[1])), readResults(Paths.get(args[2])));
+            } catch (IOException e) {
+                System.err.println("Error reading benchmark results: " + e.getMessage());
+            }
+            return;
+        }
+
+        String filePath = null;
+        String outPath = null;
+        long targetNanos = DEFAULT_TARGET_NANOS;
+        for (int i = 0; i < args.length; i++) {
+            String arg = args[i];
+            if ((arg.equals("--time") || arg.equals("-t")) && i + 1 < args.length) {
+                try {
+                    targetNanos = parseDuration(args[++i]);
+                } catch (IllegalArgumentException e) {
+                    System.err.println(e.getMessage());
+                    return;
+                }
+            } else if ((arg.equals("--out") || arg.equals("-o")) && i + 1 < args.length) {
+                outPath = args[++i];
+            } else if (filePath == null) {
+                filePath = arg;
+            } else {
+                System.err.println("Unexpected argument: " + arg);
+                return;
+            }
+        }
+        if (filePath == null) {
+            System.err.println("Usage: microscript bench <file> [--time <duration>] [--out <results>]");
+            return;
+        }
+
+        try {
+            Interpreter interpreter = new Interpreter();
+            interpreter.runFile(filePath);
+
+            List<Result> results = run(interpreter.getEnvironment(), targetNanos);
+            if (results.isEmpty()) {
+                System.err.println("No " + PREFIX + "* functions found in '" + filePath + "'");
+                return;
+            }
+
+            List<String> output = new ArrayList<>();
+            for (Result result : results) {
+                output.add(result.format());
+            }
+            if (outPath != null) {
+                Files.write(Paths.get(outPath), output);
+            }
+        } catch (IOException e) {
+            System.err.println("Error reading file '" + filePath + "': " + e.getMessage());
+        } catch (Exception e) {
+            System.err.println("Error benchmarking script '" + filePath + "': " + e.getMessage());
+        }
+    }
+
+    /**
+     * Runs every parameterless bench_* function in name order, printing each result as it finishes
+     */
+    public static List<Result> run(Environment environment, long targetNanos) {
+        List<String> names = new ArrayList<>();
+        for (String name : environment.getFunctionNames()) {
+            if (name.startsWith(PREFIX) && environment.getFunction(name).getParameters().isEmpty()) {
+                names.add(name);
+            }
+        }
+        names.sort(null);
+
+        Executor executor = new Executor(environment);
+        List<Result> results = new ArrayList<>();
+        for (String name : names) {
+            Result result = measure(executor, name, targetNanos);
+            System.out.println(result.format());
+            results.add(result);
+        }
+        return results;
+    }
+
+    /**
+     * Grows the iteration count until one round takes at least the target duration,
+     * the same way Go's testing package sizes b.N
+     */
+    private static Result measure(Executor executor, String name, long targetNanos) {
+        String[] noArgs = new String[0];
+        long threadId = Thread.currentThread().getId();
+        long iterations = 1;
+
+        while (true) {
+            long startBytes = allocatedBytes(threadId);
+            long start = System.nanoTime();
+            for (long i = 0; i < iterations; i++) {
+                executor.executeFunction(name, noArgs);
+            }
+            long elapsed = System.nanoTime() - start;
+            long allocated = allocatedBytes(threadId) - startBytes;
+
+            if (elapsed >= targetNanos || iterations >= MAX_ITERATIONS) {
+                long bytesPerOp = startBytes < 0 ? -1 : allocated / iterations;
+                return new Result(name, iterations, (double) elapsed / iterations, bytesPerOp);
+            }
+
+            // Predict the count needed to reach the target, overshooting a little,
+            // but never grow more than 100x at once
+            long predicted = elapsed > 0 ? targetNanos * iterations / elapsed : iterations * 100;
+            predicted += predicted / 5;
+            iterations = Math.max(iterations + 1, Math.min(predicted, iterations * 100));
+            iterations = Math.min(iterations, MAX_ITERATIONS);
+        }
+    }
+
+    /**
+     * Prints old and new ns/op and B/op side by side for benchmarks present in both runs
+     */
+    public static void compare(Map<String, Result> before, Map<String, Result> after) {
+        System.out.printf("%-30s %14s %14s %9s %12s %12s %9s%n",
+            "name", "old ns/op", "new ns/op", "delta", "old B/op", "new B/op", "delta");
+        for (Result old : before.values()) {
+            Result current = after.get(old.name);
+            if (current == null) {
+                continue;
+            }
+            String bytesOld = old.bytesPerOp >= 0 ? String.valueOf(old.bytesPerOp) : "-";
+            String bytesNew = current.bytesPerOp >= 0 ? String.valueOf(current.bytesPerOp) : "-";
+            String bytesDelta = old.bytesPerOp >= 0 && current.bytesPerOp >= 0
+                ? delta(old.bytesPerOp, current.bytesPerOp) : "~";
+            System.out.printf("%-30s %14.1f %14.1f %9s %12s %12s %9s%n",
+                old.name, old.nsPerOp, current.nsPerOp, delta(old.nsPerOp, current.nsPerOp),
+                bytesOld, bytesNew, bytesDelta);
+        }
+    }
+
+    private static String delta(double before, double after) {
+        if (before == 0) {
+            return after == 0 ? "0.00%" : "~";
+        }
+        return String.format("%+.2f%%", (after - before) / before * 100);
+    }
+
+    /**
+     * Reads results written with --out; lines that aren't benchmark results are ignored
+     */
+    static Map<String, Result> readResults(Path path) throws IOException {
+        Map<String, Result> results = new LinkedHashMap<>();
+        for (String line : Files.readAllLines(path)) {
+            String[] fields = line.trim().split("\\s+");
+            if (fields.length < 4 || !fields[0].startsWith(PREFIX) || !fields[3].equals("ns/op")) {
+                continue;
+            }
+            try {
+                long bytesPerOp = fields.length >= 6 && fields[5].equals("B/op") ? Long.parseLong(fields[4]) : -1;
+                results.put(fields[0], new Result(fields[0], Long.parseLong(fields[1]),
+                    Double.parseDouble(fields[2]), bytesPerOp));
+            } catch (NumberFormatException e) {
+                // Not a result line
+            }
+        }
+        return results;
+    }
+
+    /**
+     * Parses durations like "500ms", "2s" or "1m"; a bare number is seconds
+     */
+    static long parseDuration(String text) {
+        String value = text.trim().toLowerCase();
+        double multiplier = 1e9;
+        if (value.endsWith("ms")) {
+            multiplier = 1e6;
+            value = value.substring(0, value.length() - 2);
+        } else if (value.endsWith("s")) {
+            value = value.substring(0, value.length() - 1);
+        } else if (value.endsWith("m")) {
+            multiplier = 60e9;
+            value = value.substring(0, value.length() - 1);
+        }
+        try {
+            return (long) (Double.parseDouble(value) * multiplier);
+        } catch (NumberFormatException e) {
+            throw new IllegalArgumentException("Invalid duration: " + text);
+        }
+    }
+
+    private static long allocatedBytes(long threadId) {
+        return THREADS != null ? THREADS.getThreadAllocatedBytes(threadId) : -1;
+    }
+
+    // Allocation counters are a HotSpot extension, so other VMs only get timings
+    private static com.sun.management.ThreadMXBean threadBean() {
+        try {
+            com.sun.management.ThreadMXBean bean =
+                (com.sun.management.ThreadMXBean) ManagementFactory.getThreadMXBean();
+            if (bean.isThreadAllocatedMemorySupported() && bean.isThreadAllocatedMemoryEnabled()) {
+                return bean;
+            }
+        } catch (ClassCastException | NoClassDefFoundError e) {
+            // Fall through
+        }
+        return null;
+    }
+}
@@ -0,0 +1,49 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.AbstractList;
+import java.util.ArrayList;
+import java.util.List;
+import java.util.RandomAccess;
+
+/**
+ * The lines of a function body or a script together with their statements,
+ * compiled once when the function or script is defined. Block handlers take
+ * the lines as a plain list and find the statements on it, so the if, for
+ * and while blocks inside a loop are read once rather than every iteration.
+ */
+public class Body extends AbstractList<String> implements RandomAccess {
+    private final List<String> lines;
+    private final List<Statement> statements;
+
+    public Body(List<String> lines) {
+        this.lines = new ArrayList<>(lines);
+        this.statements = Statement.compile(this.lines);
+    }
+
+    /**
+     * The lines as a Body, compiling them unless they already are one
+     */
+    public static Body of(List<String> lines) {
+        return lines instanceof Body ? (Body) lines : new Body(lines);
+    }
+
+    public List<Statement> getStatements() {
+        return statements;
+    }
+
+    @Override
+    public String get(int index) {
+        return lines.get(index);
+    }
+
+    @Override
+    public int size() {
+        return lines.size();
+    }
+}
@@ -0,0 +1,104 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayDeque;
+import java.util.Deque;
+import java.util.List;
+
+/**
+ * Brace matching across source lines. Braces inside string and character
+ * literals, after a // that starts a comment, and inside block comments
+ * don't count; a // used for floor division, as in if (n // 2 > 0) {, is
+ * read past like any other operator.
+ */
+public class Braces {
+    private Braces() {
+    }
+
+    /**
+     * The line holding the brace that closes the first '{' at or after the
+     * start line, or -1 when the lines end first
+     */
+    public static int findClosing(List<String> lines, int start) {
+        return scan(lines, start, new ArrayDeque<>());
+    }
+
+    /**
+     * The error for a block on the start line that findClosing couldn't
+     * close. It points at the innermost '{' still open when the lines ran
+     * out, which is where a '}' is most likely missing, and names the block
+     * it left open.
+     */
+    public static ScriptException unclosed(List<String> lines, int start) {
+        Deque<int[]> open = new ArrayDeque<>();
+        scan(lines, start, open);
+        if (open.isEmpty()) {
+            return new ScriptException("Missing opening brace for block at line " + (start + 1), start, null);
+        }
+        int[] innermost = open.peek();
+        int[] outermost = open.peekLast();
+        String message = "Missing closing brace: the '{' at line " + (innermost[0] + 1) + ", column " + (innermost[1] + 1)
+            + " is never closed";
+        if (innermost != outermost) {
+            message += ", so the block opened at line " + (outermost[0] + 1) + ", column " + (outermost[1] + 1)
+                + " runs on to the end of the file at line " + lines.size();
+        } else {
+            message += " before the end of the file at line " + lines.size();
+        }
+        return new ScriptException(message, innermost[0], innermost[1], null);
+    }
+
+    // Pushes each '{' as {line, column} and pops it at its '}'; what's left is unclosed
+    private static int scan(List<String> lines, int start, Deque<int[]> open) {
+        boolean inComment = false;
+        for (int i = start; i < lines.size(); i++) {
+            String line = lines.get(i);
+            boolean inString = false;
+            for (int j = 0; j < line.length(); j++) {
+                char c = line.charAt(j);
+                if (inComment) {
+                    if (c == '*' && j + 1 < line.length() && line.charAt(j + 1) == '/') {
+                        inComment = false;
+                        j++;
+                    }
+                    continue;
+                }
+                if (inString) {
+                    if (c == '\\') {
+                        j++;
+                    } else if (c == '"') {
+                        inString = false;
+                    }
+                    continue;
+                }
+                int literal = Define.charLiteralLength(line, j);
+                if (literal > 0) {
+                    j += literal - 1;
+                } else if (c == '"') {
+                    inString = true;
+                } else if (line.startsWith("//", j)) {
+                    if (Checker.startsComment(line, j)) {
+                        break;
+                    }
+                    j++; // a // b
+                } else if (c == '/' && j + 1 < line.length() && line.charAt(j + 1) == '*') {
+                    inComment = true;
+                    j++;
+                } else if (c == '{') {
+                    open.push(new int[] {i, j});
+                } else if (c == '}' && !open.isEmpty()) {
+                    open.pop();
+                    if (open.isEmpty()) {
+                        return i;
+                    }
+                }
+            }
+        }
+        return -1;
+    }
+}
@@ -0,0 +1,356 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.HashSet;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+
+/**
+ * Built-in functions that take evaluated values, so they work both as
+ * statements and inside larger expressions:
+ *
+ *   range([start,] end [, step])   the numbers from start up to end
+ *   toInt(value [, fallback])      a whole number, from a number, string or bool
+ *   toFloat(value [, fallback])    a Float64, from a number, string or bool
+ *   toString(value)                the value as printed by console.write
+ *   toBool(value [, fallback])     true/false, from a bool, "true"/"false" or a number
+ *   freeze(value)                  makes a list, map or struct, and everything in it, unchangeable
+ *   typeof(value)                  the type name: Int32, Float64, String, List, a struct name, ...
+ *   fields(value)                  the field names of a struct, or the keys of a map
+ *   methods(value)                 the method signatures of a struct
+ *   functions()                    the signatures of the script's top-level functions, by name
+ *   partial(fn, args...)           fn with its first arguments given, as fn.bind(args...) makes
+ *   divmod(a, b)                   the tuple (a // b, remainder), rounding the quotient down
+ *   setTimeout(fn, ms)             calls fn once after ms milliseconds; returns the timer's ID
+ *   setInterval(fn, ms)            calls fn every ms milliseconds; returns the timer's ID
+ *   clearTimeout(id)               stops a timer, as does clearInterval(id)
+ *
+ * Timer callbacks run on the event loop, after the script's top level or
+ * while it waits in http::wait or redis::wait.
+ *
+ * A conversion that can't be made is an error, unless a fallback is given,
+ * in which case the fallback (which may be null) is returned instead.
+ */
+public class Builtins {
+    private static final Set<String> NAMES = new HashSet<>(Arrays.asList(
+        "range", "toInt", "toFloat", "toString", "toBool", "freeze", "typeof", "fields", "methods", "functions", "partial",
+        "divmod", "setTimeout", "setInterval", "clearTimeout", "clearInterval"));
+
+    // Longer values are cut short in error messages
+    private static final int PREVIEW_LENGTH = 40;
+
+    private Builtins() {
+    }
+
+    public static boolean has(String name) {
+        return NAMES.contains(name);
+    }
+
+    public static Object call(String name, Object[] args, Environment environment) {
+        switch (name) {
+            case "range":
+                return range(args);
+            case "toInt":
+                expectArguments(name, args, 1, 2);
+                return convert(name, args, Builtins::toInt);
+            case "toFloat":
+                expectArguments(name, args, 1, 2);
+                return convert(name, args, Builtins::toFloat);
+            case "toString":
+                expectArguments(name, args, 1, 1);
+                return String.valueOf(args[0]);
+            case "toBool":
+                expectArguments(name, args, 1, 2);
+                return convert(name, args, Builtins::toBool);
+            case "freeze":
+                expectArguments(name, args, 1, 1);
+                if (!(args[0] instanceof ListVariable || args[0] instanceof MapVariable
+                        || (args[0] instanceof Struct && !((Struct) args[0]).isDefinition()))) {
+                    throw new RuntimeException("freeze expects a list, map or struct instance, got: " + args[0]);
+                }
+                return freeze(args[0]);
+            case "typeof":
+                expectArguments(name, args, 1, 1);
+                return typeOf(args[0]);
+            case "fields":
+                expectArguments(name, args, 1, 1);
+                return fields(args[0]);
+            case "methods":
+                expectArguments(name, args, 1, 1);
+                if (!(args[0] instanceof Struct)) {
+                    throw new RuntimeException("methods expects a struct, got " + typeOf(args[0]));
+                }
+                return signatures(((Struct) args[0]).getMethods().values());
+            case "functions": {
+                expectArguments(name, args, 0, 0);
+                Environment root = environment.getRoot();
+                List<Function> functions = new ArrayList<>();
+                for (String functionName : root.getFunctionNames()) {
+                    functions.add(root.getFunction(functionName));
+                }
+                functions.sort((a, b) -> a.getName().compareTo(b.getName()));
+                return signatures(functions);
+            }
+            case "partial":
+                if (args.length == 0) {
+                    throw new RuntimeException("partial expects a function and the arguments to bind");
+                }
+                return Partial.of(args[0], Arrays.asList(args).subList(1, args.length), environment);
+            case "divmod":
+                expectArguments(name, args, 2, 2);
+                return new Tuple(Operators.floorDivide(args[0], args[1]), Operators.floorModulo(args[0], args[1]));
+            case "setTimeout":
+            case "setInterval":
+                return setTimer(name, args, environment);
+            case "clearTimeout":
+            case "clearInterval":
+                expectArguments(name, args, 1, 1);
+                if (args[0] != null) {
+                    if (!(args[0] instanceof Number)) {
+                        throw new RuntimeException(name + " expects a timer ID, got " + describe(args[0]));
+                    }
+                    environment.getEventLoop().clearTimer(((Number) args[0]).intValue());
+                }
+                return null;
+            default:
+                throw new RuntimeException("Function not found: " + name);
+        }
+    }
+
+    private static ListVariable range(Object[] args) {
+        expectArguments("range", args, 1, 3);
+        double[] bounds = new double[args.length];
+        for (int i = 0; i < args.length; i++) {
+            if (!(args[i] instanceof Number)) {
+                throw new RuntimeException("range arguments must be numbers, got: " + args[i]);
+            }
+            bounds[i] = ((Number) args[i]).doubleValue();
+        }
+        double start = bounds.length == 1 ? 0 : bounds[0];
+        double end = bounds.length == 1 ? bounds[0] : bounds[1];
+        double step = bounds.length == 3 ? bounds[2] : 1;
+        if (step == 0) {
+            throw new RuntimeException("range step must not be zero");
+        }
+        ListVariable numbers = new ListVariable();
+        for (double n = start; step > 0 ? n < end : n > end; n += step) {
+            numbers.add(n);
+        }
+        return numbers;
+    }
+
+    private static int setTimer(String name, Object[] args, Environment environment) {
+        expectArguments(name, args, 2, 2);
+        Object callback = args[0];
+        if (!(callback instanceof Function || callback instanceof Import.FunctionInterface)) {
+            throw new RuntimeException(name + " expects a function, got " + describe(callback));
+        }
+        if (!(args[1] instanceof Number)) {
+            throw new RuntimeException(name + " expects a delay in milliseconds, got " + describe(args[1]));
+        }
+        Runnable run = () -> callBack(name + " callback", callback, environment);
+        return environment.getEventLoop().setTimer(run, ((Number) args[1]).longValue(), name.equals("setInterval"));
+    }
+
+    /**
+     * Calls a function value from the event loop, reporting an error it
+     * raises rather than letting it stop the loop
+     */
+    static void callBack(String what, Object callback, Environment environment, Object... values) {
+        String callbackName = callback instanceof Function ? ((Function) callback).getName() : callback.toString();
+        try {
+            if (callback instanceof Function) {
+                // The caller's scope may be gone by the time the callback runs
+                new Executor(environment.getRoot()).call((Function) callback, values);
+            } else {
+                ((Import.FunctionInterface) callback).call(values);
+            }
+        } catch (RuntimeException e) {
+            System.err.println("Error in " + what + " " + callbackName + ": " + e.getMessage());
+        }
+    }
+
+    // Freezes the value in place along with every list, map and struct it holds
+    private static Object freeze(Object value) {
+        if (value instanceof ListVariable && !((ListVariable) value).isFrozen()) {
+            ((ListVariable) value).freeze();
+            for (Object element : (ListVariable) value) {
+                freeze(element);
+            }
+        } else if (value instanceof MapVariable && !((MapVariable) value).isFrozen()) {
+            ((MapVariable) value).freeze();
+            for (Object element : ((MapVariable) value).values()) {
+                freeze(element);
+            }
+        } else if (value instanceof Struct && !((Struct) value).isDefinition() && !((Struct) value).isFrozen()) {
+            ((Struct) value).freeze();
+            for (Object element : ((Struct) value).getValues().values()) {
+                freeze(element);
+            }
+        }
+        return value;
+    }
+
+    /**
+     * The name a type annotation would use for the value, so that
+     * typeof(x) == "T" agrees with x is T
+     */
+    static String typeOf(Object value) {
+        if (value == null) return "null";
+        if (value instanceof String) return "String";
+        if (value instanceof Integer) return "Int32";
+        if (value instanceof Long) return "Int64";
+        if (value instanceof Float) return "Float32";
+        if (value instanceof Double) return "Float64";
+        if (value instanceof Character) return "Char";
+        if (value instanceof Boolean) return "Bool";
+        if (value instanceof Struct) return ((Struct) value).getName();
+        if (value instanceof Tuple) return "Tuple";
+        if (value instanceof Range) return "Range";
+        if (value instanceof ModuleNamespace) return "Module";
+        if (value instanceof CollectionVariable) {
+            String kind = ((CollectionVariable) value).getKind().name();
+            return kind.charAt(0) + kind.substring(1).toLowerCase();
+        }
+        if (value instanceof List) return "List";
+        if (value instanceof Map) return "Map";
+        if (value instanceof Task) return "Task";
+        if (value instanceof Mutex) return "Mutex";
+        if (value instanceof Function || value instanceof Import.FunctionInterface) return "Function";
+        return value.getClass().getSimpleName();
+    }
+
+    /**
+     * The value's type and a short preview of it, for error messages:
+     * Int32 (42), String ("ten")
+     */
+    static String describe(Object value) {
+        if (value == null) {
+            return "null";
+        }
+        String preview = value instanceof String ? "\"" + value + "\""
+            : value instanceof Character ? "'" + value + "'"
+            : String.valueOf(value);
+        if (preview.length() > PREVIEW_LENGTH) {
+            preview = preview.substring(0, PREVIEW_LENGTH - 3) + "...";
+        }
+        return typeOf(value) + " (" + preview + ")";
+    }
+
+    private static ListVariable fields(Object value) {
+        ListVariable names = new ListVariable();
+        if (value instanceof Struct) {
+            names.addAll(((Struct) value).getFields().keySet());
+        } else if (value instanceof Map) {
+            for (Object key : ((Map<?, ?>) value).keySet()) {
+                names.add(String.valueOf(key));
+            }
+        } else {
+            throw new RuntimeException("fields expects a struct or map, got " + typeOf(value));
+        }
+        return names;
+    }
+
+    private static ListVariable signatures(Iterable<Function> functions) {
+        ListVariable result = new ListVariable();
+        for (Function function : functions) {
+            result.add(function.getSignature());
+        }
+        return result;
+    }
+
+    private interface Conversion {
+        // Returns null when the value can't be converted
+        Object apply(Object value);
+    }
+
+    private static Object convert(String name, Object[] args, Conversion conversion) {
+        Object result = args[0] == null ? null : conversion.apply(args[0]);
+        if (result != null) {
+            return result;
+        }
+        if (args.length == 2) {
+            return args[1];
+        }
+        String shown = args[0] instanceof String ? "\"" + args[0] + "\"" : String.valueOf(args[0]);
+        throw new RuntimeException(name + ": cannot convert " + shown + "; pass a fallback as the second argument to avoid this error");
+    }
+
+    private static Object toInt(Object value) {
+        double number;
+        if (value instanceof Number) {
+            number = ((Number) value).doubleValue();
+            if (Double.isNaN(number) || Double.isInfinite(number)) {
+                return null;
+            }
+            number = number < 0 ? Math.ceil(number) : Math.floor(number); // Drops the fraction
+        } else if (value instanceof Boolean) {
+            number = (Boolean) value ? 1 : 0;
+        } else if (value instanceof String) {
+            try {
+                number = Long.parseLong(((String) value).trim());
+            } catch (NumberFormatException e) {
+                return null;
+            }
+        } else {
+            return null;
+        }
+        if (number < Integer.MIN_VALUE || number > Integer.MAX_VALUE) {
+            return null;
+        }
+        return (int) number;
+    }
+
+    private static Object toFloat(Object value) {
+        if (value instanceof Number) {
+            return ((Number) value).doubleValue();
+        }
+        if (value instanceof Boolean) {
+            return (Boolean) value ? 1.0 : 0.0;
+        }
+        if (value instanceof String) {
+            String text = ((String) value).trim();
+            // Double.parseDouble also reads "NaN", "Infinity" and hex floats, which scripts never mean
+            if (!text.matches("[-+]?(\\d+\\.?\\d*|\\.\\d+)([eE][-+]?\\d+)?")) {
+                return null;
+            }
+            return Double.parseDouble(text);
+        }
+        return null;
+    }
+
+    private static Object toBool(Object value) {
+        if (value instanceof Boolean) {
+            return value;
+        }
+        if (value instanceof Number) {
+            return ((Number) value).doubleValue() != 0;
+        }
+        if (value instanceof String) {
+            String text = ((String) value).trim();
+            if (text.equalsIgnoreCase("true")) {
+                return true;
+            }
+            if (text.equalsIgnoreCase("false")) {
+                return false;
+            }
+        }
+        return null;
+    }
+
+    private static void expectArguments(String name, Object[] args, int min, int max) {
+        if (args.length < min || args.length > max) {
+            String expected = min == max ? String.valueOf(min) : min + " to " + max;
+            throw new RuntimeException(name + " expects " + expected + " argument" + (max == 1 ? "" : "s")
+                + ", got " + args.length);
+        }
+    }
+}
@@ -0,0 +1,285 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.nio.file.StandardCopyOption;
+import java.util.ArrayList;
+import java.util.Comparator;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+import java.util.stream.Collectors;
+import java.util.stream.Stream;
+import java.util.zip.ZipEntry;
+import java.util.zip.ZipInputStream;
+import java.util.zip.ZipOutputStream;
+
+/**
+ * Packs a script and the files it needs into a single zip-based .musx
+ * archive, and unpacks one to run it.
+ * Usage:
+ *   microscript bundle <entry> [-o <file.musx>] [-I <dir>] [--max-depth <n>] [asset...]
+ *
+ * Script modules the entry imports, directly or through other modules, are
+ * stored at the top of the archive, where import finds them when the bundle
+ * runs. Assets are files or directories, stored under their path relative to
+ * the entry script's directory. The archive's bundle.json names the entry script,
+ * and records the limits given with --max-depth, --max-iterations and
+ * --max-macro-passes, which run applies unless its own options override them.
+ * When run, the archive is extracted to a temporary directory that is removed
+ * on exit; scripts find their assets with bundle::path("name").
+ */
+public class Bundle {
+    public static final String EXTENSION = ".musx";
+    private static final String MANIFEST = "bundle.json";
+    private static final Pattern IMPORT_PATTERN = Pattern.compile("^\\s*import\\s+(\\w+)(?:\\s+as\\s+\\w+)?\\s*;?\\s*$");
+
+    private final Path directory;
+    private final Path entry;
+    private final Map<?, ?> limits;
+
+    private Bundle(Path directory, Path entry, Map<?, ?> limits) {
+        this.directory = directory;
+        this.entry = entry;
+        this.limits = limits;
+    }
+
+    public static void main(String[] args) {
+        String entry = null;
+        String output = null;
+        List<String> assets = new ArrayList<>();
+        Map<String, Object> limits = new LinkedHashMap<>();
+        for (int i = 0; i < args.length; i++) {
+            if (args[i].equals("-o") && i + 1 < args.length) {
+                output = args[++i];
+            } else if (args[i].equals("-I") && i + 1 < args.length) {
+                Import.addSearchPath(args[++i]);
+            } else if (Limits.optionName(args[i]) != null && i + 1 < args.length) {
+                String option = args[i];
+                try {
+                    limits.put(Limits.optionName(option), Limits.parse(option, args[++i]));
+                } catch (IllegalArgumentException e) {
+                    System.err.println(e.getMessage());
+                    return;
+                }
+            } else if (entry == null) {
+                entry = args[i];
+            } else {
+                assets.add(args[i]);
+            }
+        }
+        if (entry == null) {
+            System.err.println("Usage: microscript bundle <entry> [-o <file.musx>] [-I <dir>] [--max-depth <n>] [asset...]");
+            return;
+        }
+        if (output == null) {
+            String name = Paths.get(entry).getFileName().toString();
+            int dot = name.lastIndexOf('.');
+            output = (dot > 0 ? name.substring(0, dot) : name) + EXTENSION;
+        }
+        try {
+            int count = create(Paths.get(entry), assets, Paths.get(output), limits);
+            System.out.println("Bundled " + count + " file" + (count == 1 ? "" : "s") + " into " + output);
+        } catch (IOException e) {
+            System.err.println("Bundle error: " + e.getMessage());
+            System.exit(1);
+        }
+    }
+
+    /**
+     * Writes the entry script and the given assets to a .musx archive and
+     * returns how many files it holds
+     */
+    public static int create(Path entry, List<String> assets, Path output) throws IOException {
+        return create(entry, assets, output, new LinkedHashMap<>());
+    }
+
+    /**
+     * Like create, also recording limits, by name, for run to apply
+     */
+    public static int create(Path entry, List<String> assets, Path output, Map<String, Object> limits) throws IOException {
+        if (!Files.isRegularFile(entry)) {
+            throw new IOException("No such script: " + entry);
+        }
+        Path base = entry.toAbsolutePath().normalize().getParent();
+        Map<String, Path> files = new LinkedHashMap<>();
+        files.put(entry.getFileName().toString(), entry);
+        addImports(entry, files);
+        for (String asset : assets) {
+            Path path = Paths.get(asset);
+            if (!Files.exists(path)) {
+                throw new IOException("No such asset: " + asset);
+            }
+            List<Path> found;
+            try (Stream<Path> walk = Files.walk(path)) {
+                found = walk.filter(Files::isRegularFile).sorted().collect(Collectors.toList());
+            }
+            for (Path file : found) {
+                files.putIfAbsent(entryName(base, file), file);
+            }
+        }
+
+        Map<String, Object> manifest = new LinkedHashMap<>();
+        manifest.put("entry", entry.getFileName().toString());
+        if (!limits.isEmpty()) {
+            manifest.put("limits", limits);
+        }
+        try (ZipOutputStream zip = new ZipOutputStream(Files.newOutputStream(output))) {
+            zip.putNextEntry(new ZipEntry(MANIFEST));
+            zip.write(Json.stringify(manifest).getBytes(StandardCharsets.UTF_8));
+            zip.closeEntry();
+            for (Map.Entry<String, Path> file : files.entrySet()) {
+                zip.putNextEntry(new ZipEntry(file.getKey()));
+                Files.copy(file.getValue(), zip);
+                zip.closeEntry();
+            }
+        }
+        return files.size();
+    }
+
+    // Adds the script modules a script imports, and theirs, by file name
+    private static void addImports(Path script, Map<String, Path> files) throws IOException {
+        Path directory = script.toAbsolutePath().getParent();
+        for (String line : new Scanner(script.toString()).readLines()) {
+            Matcher matcher = IMPORT_PATTERN.matcher(line);
+            if (!matcher.find()) {
+                continue;
+            }
+            List<Path> directories = new ArrayList<>();
+            directories.add(directory);
+            directories.addAll(Import.getSearchPaths());
+            Path module = Import.findScript(matcher.group(1), directories);
+            // Native modules such as math have no file
+            if (module != null && files.putIfAbsent(module.getFileName().toString(), module) == null) {
+                addImports(module, files);
+            }
+        }
+    }
+
+    // The archive name of a file: its path relative to the entry's directory
+    private static String entryName(Path base, Path file) throws IOException {
+        Path absolute = file.toAbsolutePath().normalize();
+        if (!absolute.startsWith(base)) {
+            throw new IOException("Asset " + file + " is outside the script's directory " + base);
+        }
+        return base.relativize(absolute).toString().replace('\\', '/');
+    }
+
+    /**
+     * Extracts a .musx archive to a temporary directory, removed when the JVM
+     * exits
+     */
+    public static Bundle open(String archive) throws IOException {
+        Path directory = Files.createTempDirectory("microscript-bundle");
+        Runtime.getRuntime().addShutdownHook(new Thread(() -> delete(directory)));
+        Map<?, ?> manifest = null;
+        try (ZipInputStream zip = new ZipInputStream(Files.newInputStream(Paths.get(archive)))) {
+            ZipEntry item;
+            while ((item = zip.getNextEntry()) != null) {
+                Path target = directory.resolve(item.getName()).normalize();
+                if (!target.startsWith(directory)) {
+                    throw new IOException("Bundle entry escapes the archive: " + item.getName());
+                }
+                if (item.isDirectory()) {
+                    Files.createDirectories(target);
+                } else if (item.getName().equals(MANIFEST)) {
+                    manifest = readManifest(zip);
+                } else {
+                    Files.createDirectories(target.getParent());
+                    Files.copy(zip, target, StandardCopyOption.REPLACE_EXISTING);
+                }
+            }
+        }
+        if (manifest == null) {
+            throw new IOException("'" + archive + "' has no " + MANIFEST + "; create it with microscript bundle");
+        }
+        String entry = (String) manifest.get("entry");
+        Path script = directory.resolve(entry).normalize();
+        if (!script.startsWith(directory) || !Files.isRegularFile(script)) {
+            throw new IOException("Entry script '" + entry + "' is missing from '" + archive + "'");
+        }
+        Object limits = manifest.get("limits");
+        if (limits != null && !(limits instanceof Map)) {
+            throw new IOException(MANIFEST + ": limits must be an object, such as {\"callDepth\": 500}");
+        }
+        return new Bundle(directory, script, limits != null ? (Map<?, ?>) limits : new LinkedHashMap<>());
+    }
+
+    private static Map<?, ?> readManifest(InputStream input) throws IOException {
+        ByteArrayOutputStream bytes = new ByteArrayOutputStream();
+        copy(input, bytes);
+        Object manifest = Json.parse(new String(bytes.toByteArray(), StandardCharsets.UTF_8));
+        Object entry = manifest instanceof Map ? ((Map<?, ?>) manifest).get("entry") : null;
+        if (!(entry instanceof String)) {
+            throw new IOException(MANIFEST + " must name the entry script");
+        }
+        return (Map<?, ?>) manifest;
+    }
+
+    private static void copy(InputStream input, OutputStream output) throws IOException {
+        byte[] buffer = new byte[8192];
+        int read;
+        while ((read = input.read(buffer)) > 0) {
+            output.write(buffer, 0, read);
+        }
+    }
+
+    private static void delete(Path directory) {
+        try (Stream<Path> walk = Files.walk(directory)) {
+            walk.sorted(Comparator.reverseOrder()).forEach(path -> path.toFile().delete());
+        } catch (IOException e) {
+            // Nothing left to clean up
+        }
+    }
+
+    /**
+     * The limits bundle.json sets, by name, such as callDepth
+     */
+    public Map<?, ?> getLimits() {
+        return limits;
+    }
+
+    /**
+     * The extracted entry script
+     */
+    public Path getEntry() {
+        return entry;
+    }
+
+    /**
+     * The extracted copy of a bundled file, by its name in the archive
+     */
+    public Path resolve(String name) {
+        Path path = directory.resolve(name).normalize();
+        if (!path.startsWith(directory)) {
+            throw new RuntimeException("Path is outside the bundle: " + name);
+        }
+        return path;
+    }
+
+    /**
+     * Makes bundle::path(name) available to the script
+     */
+    public void register(Environment env) {
+        env.setVariable("bundle::path", (Import.FunctionInterface) (args) -> {
+            if (args.length != 1 || args[0] == null) {
+                throw new RuntimeException("bundle::path expects 1 argument: a file name");
+            }
+            return resolve(args[0].toString()).toString();
+        });
+    }
+}
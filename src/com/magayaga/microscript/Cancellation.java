@@ -0,0 +1,63 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+/**
+ * Stops a running script from another thread or after a deadline. Every
+ * environment under the same root shares an instance; the interpreter checks
+ * it before each statement and loop iteration, so a runaway loop ends with an
+ * "Execution cancelled" error instead of running forever. Once cancelled it
+ * stays cancelled.
+ */
+public class Cancellation {
+    private volatile boolean cancelled;
+    private volatile String reason;
+    // System.nanoTime() deadline; only meaningful when hasDeadline is set
+    private volatile long deadline;
+    private volatile boolean hasDeadline;
+    private volatile long timeoutMillis;
+
+    public void cancel() {
+        cancel(null);
+    }
+
+    public void cancel(String reason) {
+        if (!cancelled) {
+            this.reason = reason;
+            cancelled = true;
+        }
+    }
+
+    /**
+     * Cancels once the given number of milliseconds has passed from now
+     */
+    public void cancelAfter(long millis) {
+        timeoutMillis = millis;
+        deadline = System.nanoTime() + millis * 1_000_000L;
+        hasDeadline = true;
+    }
+
+    public boolean isCancelled() {
+        if (!cancelled && hasDeadline && System.nanoTime() - deadline >= 0) {
+            cancel("time limit of " + timeoutMillis + "ms exceeded");
+        }
+        return cancelled;
+    }
+
+    /**
+     * Throws when the script has been cancelled
+     */
+    public void check() {
+        if (isCancelled()) {
+            throw new RuntimeException(getMessage());
+        }
+    }
+
+    public String getMessage() {
+        return reason != null ? "Execution cancelled: " + reason : "Execution cancelled";
+    }
+}
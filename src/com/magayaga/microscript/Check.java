@@ -0,0 +1,72 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.util.ArrayList;
+import java.util.List;
+
+/**
+ * Reports problems in a script without running it.
+ * Usage:
+ *   microscript check [--types] [--syntax] <file>
+ *
+ * Prints one diagnostic per line as file:line:column: severity: message [code]
+ * and exits with status 1 when any of them is an error.
+ */
+public class Check {
+    public static void main(String[] args) {
+        String filePath = null;
+        boolean types = false;
+        boolean syntax = false;
+        for (String arg : args) {
+            if (arg.equals("--types")) {
+                types = true;
+            } else if (arg.equals("--syntax")) {
+                syntax = true;
+            } else if (filePath == null && !arg.startsWith("--")) {
+                filePath = arg;
+            } else {
+                filePath = null;
+                break;
+            }
+        }
+        if (filePath == null) {
+            System.err.println("Usage: microscript check [--types] [--syntax] <file>");
+            return;
+        }
+
+        List<String> lines;
+        try {
+            lines = new Scanner(filePath).readLines();
+        } catch (IOException e) {
+            System.err.println("Error reading file '" + filePath + "': " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+
+        List<Checker.Diagnostic> diagnostics = new ArrayList<>(Checker.check(lines));
+        if (types) {
+            diagnostics.addAll(TypeChecker.check(lines));
+        }
+        if (syntax) {
+            diagnostics.addAll(SyntaxChecker.check(lines));
+        }
+        if (types || syntax) {
+            diagnostics.sort((a, b) -> a.getLine() != b.getLine()
+                ? a.getLine() - b.getLine() : a.getColumn() - b.getColumn());
+        }
+        boolean failed = false;
+        for (Checker.Diagnostic diagnostic : diagnostics) {
+            System.out.println(diagnostic.format(filePath) + " [" + diagnostic.getCode() + "]");
+            failed |= diagnostic.getSeverity() == Checker.Severity.ERROR;
+        }
+        if (failed) {
+            System.exit(1);
+        }
+    }
+}
@@ -0,0 +1,569 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayDeque;
+import java.util.ArrayList;
+import java.util.Deque;
+import java.util.HashMap;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Static checks over source lines that don't need the script to run.
+ * Errors: unbalanced brackets and unterminated string and character
+ * literals. Warnings: duplicate
+ * functions, unused and shadowing variables, assignment in a condition,
+ * macro redefinition and unreachable code after return.
+ */
+public class Checker {
+    private static final Pattern FUNCTION_PATTERN = Pattern.compile("^function\\s+([\\w:]+)\\s*(?:<[^>]*>)?\\(");
+    private static final Pattern C_STYLE_FUNCTION_PATTERN = Pattern.compile("^(?:String|Int32|Int64|Float32|Float64|fn)\\s+([\\w:]+)\\s*\\(");
+    private static final Pattern PARAMETERS_PATTERN = Pattern.compile("\\(([^)]*)\\)");
+    private static final Pattern VAR_PATTERN = Pattern.compile("^var\\s+([A-Za-z_]\\w*)");
+    private static final Pattern CONDITION_PATTERN = Pattern.compile("\\b(if|elif|while)\\s*\\((.*)\\)");
+    private static final Pattern ASSIGNMENT_PATTERN = Pattern.compile("(?<![=!<>:+\\-*/%])=(?![=>])");
+    private static final Pattern DEFINE_PATTERN = Pattern.compile("^#define\\s+([A-Z_][A-Z0-9_]*)");
+    private static final Pattern UNDEF_PATTERN = Pattern.compile("^#undef\\s+([A-Z_][A-Z0-9_]*)");
+    private static final Pattern RETURN_PATTERN = Pattern.compile("^return\\b");
+
+    public enum Severity {
+        ERROR, WARNING
+    }
+
+    /**
+     * A problem at a position in the source; line and column are 0-based
+     */
+    public static class Diagnostic {
+        private final int line;
+        private final int column;
+        private final int length;
+        private final Severity severity;
+        private final String code;
+        private final String message;
+
+        public Diagnostic(int line, int column, int length, Severity severity, String code, String message) {
+            this.line = line;
+            this.column = column;
+            this.length = length;
+            this.severity = severity;
+            this.code = code;
+            this.message = message;
+        }
+
+        public int getLine() {
+            return line;
+        }
+
+        public int getColumn() {
+            return column;
+        }
+
+        public int getLength() {
+            return length;
+        }
+
+        public Severity getSeverity() {
+            return severity;
+        }
+
+        /**
+         * A short, stable identifier for the kind of problem, e.g. unused-variable
+         */
+        public String getCode() {
+            return code;
+        }
+
+        public String getMessage() {
+            return message;
+        }
+
+        // file:line:column: severity: message, 1-based like compiler output
+        public String format(String file) {
+            return file + ":" + (line + 1) + ":" + (column + 1) + ": "
+                + severity.name().toLowerCase() + ": " + message;
+        }
+
+        /**
+         * The diagnostic in the same shape --error-format json uses for errors
+         */
+        public Map<String, Object> toMap(String file) {
+            Map<String, Object> map = new LinkedHashMap<>();
+            map.put("file", file);
+            map.put("line", line + 1);
+            map.put("column", column + 1);
+            map.put("code", code);
+            map.put("message", message);
+            map.put("severity", severity.name().toLowerCase());
+            return map;
+        }
+
+        /**
+         * The same diagnostic with another severity, used by --werror
+         */
+        public Diagnostic withSeverity(Severity severity) {
+            return new Diagnostic(line, column, length, severity, code, message);
+        }
+    }
+
+    // An opening bracket waiting for its match
+    private static class Open {
+        final char bracket;
+        final int line;
+        final int column;
+
+        Open(char bracket, int line, int column) {
+            this.bracket = bracket;
+            this.line = line;
+            this.column = column;
+        }
+    }
+
+    public static List<Diagnostic> check(List<String> lines) {
+        lines = Exports.strip(Define.stripBlockComments(lines));
+        List<Diagnostic> diagnostics = new ArrayList<>();
+        checkBrackets(lines, diagnostics);
+        checkFunctions(lines, diagnostics);
+        checkVariables(lines, diagnostics);
+        checkConditions(lines, diagnostics);
+        checkMacros(lines, diagnostics);
+        checkUnreachable(lines, diagnostics);
+        diagnostics.sort((a, b) -> a.line != b.line ? a.line - b.line : a.column - b.column);
+        return diagnostics;
+    }
+
+    private static void checkBrackets(List<String> lines, List<Diagnostic> diagnostics) {
+        Deque<Open> open = new ArrayDeque<>();
+        boolean inBlockComment = false;
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            if (inBlockComment) {
+                if (line.contains("*/")) {
+                    inBlockComment = false;
+                }
+                continue;
+            }
+            String trimmed = line.trim();
+            if (trimmed.startsWith("/*")) {
+                inBlockComment = !trimmed.contains("*/");
+                continue;
+            }
+
+            int stringStart = -1;
+            for (int j = 0; j < line.length(); j++) {
+                char c = line.charAt(j);
+                if (stringStart >= 0) {
+                    if (c == '\\') {
+                        j++;
+                    } else if (c == '"') {
+                        stringStart = -1;
+                    }
+                    continue;
+                }
+                if (c == '"') {
+                    stringStart = j;
+                } else if (c == '\'') {
+                    // A character literal may hold a quote or bracket: '"', '('
+                    int length = Define.charLiteralLength(line, j);
+                    if (length == 0) {
+                        diagnostics.add(new Diagnostic(i, j, 1, Severity.ERROR, "unterminated-char",
+                            "Unterminated character literal"));
+                    } else {
+                        j += length - 1;
+                    }
+                } else if (c == '/' && j + 1 < line.length() && line.charAt(j + 1) == '/') {
+                    break;
+                } else if (c == '(' || c == '[' || c == '{') {
+                    open.push(new Open(c, i, j));
+                } else if (c == ')' || c == ']' || c == '}') {
+                    char expected = c == ')' ? '(' : c == ']' ? '[' : '{';
+                    if (open.isEmpty()) {
+                        diagnostics.add(new Diagnostic(i, j, 1, Severity.ERROR, "brackets", "Unmatched '" + c + "'"));
+                    } else if (open.peek().bracket != expected) {
+                        Open unclosed = open.peek();
+                        diagnostics.add(new Diagnostic(i, j, 1, Severity.ERROR, "brackets",
+                            "Expected '" + closing(unclosed.bracket) + "' to close '" + unclosed.bracket
+                            + "' from line " + (unclosed.line + 1) + ", found '" + c + "'"));
+                        // Only drop the opener when the closer doesn't belong further out
+                        if (open.stream().noneMatch(o -> o.bracket == expected)) {
+                            continue;
+                        }
+                        while (open.peek().bracket != expected) {
+                            open.pop();
+                        }
+                        open.pop();
+                    } else {
+                        open.pop();
+                    }
+                }
+            }
+            if (stringStart >= 0) {
+                diagnostics.add(new Diagnostic(i, stringStart, line.length() - stringStart, Severity.ERROR,
+                    "unterminated-string", "Unterminated string literal"));
+            }
+        }
+        for (Open unclosed : open) {
+            diagnostics.add(new Diagnostic(unclosed.line, unclosed.column, 1, Severity.ERROR, "brackets",
+                "Unclosed '" + unclosed.bracket + "'"));
+        }
+    }
+
+    private static char closing(char bracket) {
+        return bracket == '(' ? ')' : bracket == '[' ? ']' : '}';
+    }
+
+    private static void checkFunctions(List<String> lines, List<Diagnostic> diagnostics) {
+        Map<String, Integer> defined = new HashMap<>();
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            String trimmed = line.trim();
+            Matcher matcher = FUNCTION_PATTERN.matcher(trimmed);
+            if (!matcher.find()) {
+                matcher = C_STYLE_FUNCTION_PATTERN.matcher(trimmed);
+                if (!matcher.find()) {
+                    continue;
+                }
+            }
+            // Methods inside classes and namespaces are indented; only top-level names clash
+            if (!line.startsWith(trimmed)) {
+                continue;
+            }
+            String name = matcher.group(1);
+            Integer previous = defined.putIfAbsent(name, i);
+            if (previous != null) {
+                diagnostics.add(new Diagnostic(i, matcher.start(1), name.length(), Severity.WARNING,
+                    "duplicate-function", "Function '" + name + "' is already defined on line " + (previous + 1)));
+            }
+        }
+    }
+
+    private static void checkVariables(List<String> lines, List<Diagnostic> diagnostics) {
+        // Globals are declarations outside every block
+        Map<String, Integer> globals = new HashMap<>();
+        int depth = 0;
+        for (int i = 0; i < lines.size(); i++) {
+            String code = codeOnly(lines.get(i));
+            Matcher matcher = VAR_PATTERN.matcher(code.trim());
+            if (depth == 0 && matcher.find()) {
+                globals.putIfAbsent(matcher.group(1), i);
+            }
+            depth += braceDelta(code);
+        }
+
+        for (int i = 0; i < lines.size(); i++) {
+            String trimmed = lines.get(i).trim();
+            if (!FUNCTION_PATTERN.matcher(trimmed).find() && !C_STYLE_FUNCTION_PATTERN.matcher(trimmed).find()) {
+                continue;
+            }
+            // Interface methods are signatures without a body
+            if (!codeOnly(trimmed).contains("{")) {
+                continue;
+            }
+            int end = blockEnd(lines, i);
+            if (end > i) {
+                checkFunctionVariables(lines, i, end, globals, diagnostics);
+            }
+        }
+    }
+
+    private static void checkFunctionVariables(List<String> lines, int header, int end,
+                                               Map<String, Integer> globals, List<Diagnostic> diagnostics) {
+        List<String> parameters = new ArrayList<>();
+        Matcher parameterList = PARAMETERS_PATTERN.matcher(lines.get(header));
+        if (parameterList.find()) {
+            for (String parameter : Parser.splitParameters(parameterList.group(1))) {
+                String name = parameter.split(":")[0].trim();
+                if (!name.isEmpty()) {
+                    parameters.add(name);
+                }
+            }
+        }
+
+        Map<String, Integer> declared = new LinkedHashMap<>();
+        Map<String, Integer> columns = new HashMap<>();
+        for (int j = header + 1; j < end; j++) {
+            String line = lines.get(j);
+            String trimmed = line.trim();
+            Matcher matcher = VAR_PATTERN.matcher(trimmed);
+            if (!matcher.find()) {
+                continue;
+            }
+            String name = matcher.group(1);
+            int column = line.indexOf(trimmed) + matcher.start(1);
+            if (parameters.contains(name)) {
+                diagnostics.add(new Diagnostic(j, column, name.length(), Severity.WARNING, "shadowed-variable",
+                    "Variable '" + name + "' shadows a parameter of the same name"));
+            } else if (declared.containsKey(name)) {
+                diagnostics.add(new Diagnostic(j, column, name.length(), Severity.WARNING, "shadowed-variable",
+                    "Variable '" + name + "' is already declared on line " + (declared.get(name) + 1)));
+            } else if (globals.containsKey(name)) {
+                diagnostics.add(new Diagnostic(j, column, name.length(), Severity.WARNING, "shadowed-variable",
+                    "Variable '" + name + "' shadows the global declared on line " + (globals.get(name) + 1)));
+            }
+            if (declared.putIfAbsent(name, j) == null) {
+                columns.put(name, column);
+            }
+        }
+
+        for (Map.Entry<String, Integer> entry : declared.entrySet()) {
+            String name = entry.getKey();
+            // Uses inside strings count, since templates like "{x}" read variables
+            Pattern use = Pattern.compile("\\b" + Pattern.quote(name) + "\\b");
+            int uses = 0;
+            for (int j = header + 1; j < end; j++) {
+                String trimmed = lines.get(j).trim();
+                if (trimmed.startsWith("//")) {
+                    continue;
+                }
+                Matcher matcher = use.matcher(trimmed);
+                while (matcher.find()) {
+                    uses++;
+                }
+            }
+            if (uses <= 1) {
+                diagnostics.add(new Diagnostic(entry.getValue(), columns.get(name), name.length(), Severity.WARNING,
+                    "unused-variable", "Variable '" + name + "' is declared but never used"));
+            }
+        }
+    }
+
+    private static void checkConditions(List<String> lines, List<Diagnostic> diagnostics) {
+        for (int i = 0; i < lines.size(); i++) {
+            String code = codeOnly(lines.get(i));
+            Matcher condition = CONDITION_PATTERN.matcher(code);
+            if (!condition.find()) {
+                continue;
+            }
+            Matcher assignment = ASSIGNMENT_PATTERN.matcher(condition.group(2));
+            if (assignment.find()) {
+                diagnostics.add(new Diagnostic(i, condition.start(2) + assignment.start(), 1, Severity.WARNING,
+                    "assignment-in-condition", "Assignment in " + condition.group(1) + " condition; did you mean '=='?"));
+            }
+        }
+    }
+
+    private static void checkMacros(List<String> lines, List<Diagnostic> diagnostics) {
+        Map<String, Integer> defined = new HashMap<>();
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            String trimmed = line.trim();
+            Matcher define = DEFINE_PATTERN.matcher(trimmed);
+            if (define.find()) {
+                String name = define.group(1);
+                Integer previous = defined.put(name, i);
+                if (previous != null) {
+                    diagnostics.add(new Diagnostic(i, line.indexOf(trimmed) + define.start(1), name.length(),
+                        Severity.WARNING, "macro-redefinition",
+                        "Macro '" + name + "' redefined; previous definition on line " + (previous + 1)));
+                }
+                continue;
+            }
+            Matcher undef = UNDEF_PATTERN.matcher(trimmed);
+            if (undef.find()) {
+                defined.remove(undef.group(1));
+            }
+        }
+    }
+
+    private static void checkUnreachable(List<String> lines, List<Diagnostic> diagnostics) {
+        for (int i = 0; i < lines.size(); i++) {
+            if (!RETURN_PATTERN.matcher(lines.get(i).trim()).find()) {
+                continue;
+            }
+            for (int j = i + 1; j < lines.size(); j++) {
+                String line = lines.get(j);
+                String trimmed = line.trim();
+                if (trimmed.isEmpty() || trimmed.startsWith("//")) {
+                    continue;
+                }
+                // A closing brace or switch label ends the returning block
+                if (!trimmed.startsWith("}") && !trimmed.startsWith("case") && !trimmed.startsWith("default")) {
+                    diagnostics.add(new Diagnostic(j, line.indexOf(trimmed), trimmed.length(), Severity.WARNING,
+                        "unreachable-code", "Unreachable code after return on line " + (i + 1)));
+                }
+                break;
+            }
+        }
+    }
+
+    /**
+     * Moves diagnostics on lines that macros rewrote to the macro invocation,
+     * since columns in the expanded text mean nothing to the reader
+     */
+    static List<Diagnostic> atInvocations(List<Diagnostic> diagnostics, Define define) {
+        List<Diagnostic> mapped = new ArrayList<>();
+        for (Diagnostic diagnostic : diagnostics) {
+            Define.Expansion expansion = define.getExpansion(diagnostic.line);
+            mapped.add(expansion == null ? diagnostic : new Diagnostic(diagnostic.line, expansion.getColumn(),
+                expansion.getInvocation().length(), diagnostic.severity, diagnostic.code,
+                diagnostic.message + " (" + expansion.getNote() + ")"));
+        }
+        return mapped;
+    }
+
+    /**
+     * The line closing the block opened on the given line, or -1
+     */
+    static int blockEnd(List<String> lines, int start) {
+        int depth = 0;
+        boolean opened = false;
+        for (int i = start; i < lines.size(); i++) {
+            String code = codeOnly(lines.get(i));
+            for (int j = 0; j < code.length(); j++) {
+                char c = code.charAt(j);
+                if (c == '{') {
+                    depth++;
+                    opened = true;
+                } else if (c == '}' && --depth == 0 && opened) {
+                    return i;
+                }
+            }
+        }
+        return -1;
+    }
+
+    static int braceDelta(String code) {
+        int delta = 0;
+        for (int i = 0; i < code.length(); i++) {
+            char c = code.charAt(i);
+            if (c == '{') {
+                delta++;
+            } else if (c == '}') {
+                delta--;
+            }
+        }
+        return delta;
+    }
+
+    /**
+     * The 0-based column where a string or character literal left open on
+     * the line starts, or -1 when every literal is closed
+     */
+    static int unterminatedLiteral(String line) {
+        int stringStart = -1;
+        for (int i = 0; i < line.length(); i++) {
+            char c = line.charAt(i);
+            if (stringStart >= 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == '"') {
+                    stringStart = -1;
+                }
+                continue;
+            }
+            if (c == '"') {
+                stringStart = i;
+            } else if (c == '\'') {
+                int length = Define.charLiteralLength(line, i);
+                if (length == 0) {
+                    return i;
+                }
+                i += length - 1;
+            } else if (line.startsWith("//", i)) {
+                if (startsComment(line, i)) {
+                    break;
+                }
+                i++; // a // b
+            }
+        }
+        return stringStart;
+    }
+
+    /**
+     * Whether the // at index i starts a comment. It is floor division only
+     * between two values, as in total // 2: after a number, a name or a
+     * closing bracket, with whitespace and then a number, a name or an
+     * opening bracket on its right. Text such as foo() // prints it reads as
+     * a comment, since a second word can't follow an operand, and so does
+     * anything after a string, which can't be divided. A single word is
+     * still ambiguous: foo() // note is foo() divided by note, so a comment
+     * that short goes after the statement's ; or brace.
+     */
+    static boolean startsComment(String line, int i) {
+        int before = i - 1;
+        while (before >= 0 && Character.isWhitespace(line.charAt(before))) {
+            before--;
+        }
+        if (before < 0) {
+            return true;
+        }
+        char c = line.charAt(before);
+        if (!(Character.isLetterOrDigit(c) || c == '_' || c == ')' || c == ']')) {
+            return true;
+        }
+        int after = i + 2;
+        if (after >= line.length() || !Character.isWhitespace(line.charAt(after))) {
+            return true;
+        }
+        while (after < line.length() && Character.isWhitespace(line.charAt(after))) {
+            after++;
+        }
+        if (after >= line.length()) {
+            return true;
+        }
+        char operand = line.charAt(after);
+        if (operand == '(' || operand == '[' || operand == '-') {
+            return false;
+        }
+        if (!(Character.isLetterOrDigit(operand) || operand == '_')) {
+            return true;
+        }
+        // An operand is followed by code, not by another word as in prose
+        int end = after;
+        while (end < line.length() && (Character.isLetterOrDigit(line.charAt(end)) || line.charAt(end) == '_' || line.charAt(end) == '.')) {
+            end++;
+        }
+        int next = end;
+        while (next < line.length() && Character.isWhitespace(line.charAt(next))) {
+            next++;
+        }
+        return next > end && next < line.length()
+            && (Character.isLetterOrDigit(line.charAt(next)) || line.charAt(next) == '_');
+    }
+
+    /**
+     * The line with string contents blanked and any line comment removed, so
+     * columns still line up with the source
+     */
+    static String codeOnly(String line) {
+        StringBuilder code = new StringBuilder(line.length());
+        boolean inString = false;
+        for (int i = 0; i < line.length(); i++) {
+            char c = line.charAt(i);
+            if (inString) {
+                if (c == '\\' && i + 1 < line.length()) {
+                    code.append("  ");
+                    i++;
+                    continue;
+                }
+                if (c == '"') {
+                    inString = false;
+                    code.append(c);
+                } else {
+                    code.append(' ');
+                }
+                continue;
+            }
+            if (line.startsWith("//", i)) {
+                if (startsComment(line, i)) {
+                    break;
+                }
+                code.append("//"); // a // b
+                i++;
+                continue;
+            }
+            if (c == '"') {
+                inString = true;
+            }
+            code.append(c);
+        }
+        return code.toString();
+    }
+}
@@ -12,7 +12,7 @@ public class Cli {
     private static final String GREEN = "\u001B[32;1m"; // Bold green
     private static final String BLUE = "\u001B[34;1m";  // Bold blue
 
-    private static final String VERSION = "MicroScript v0.1.0";
+    static final String VERSION = "MicroScript v0.1.0";
     private static final String AUTHOR = "Cyril John Magayaga";
 
     public static void printUsage() {
@@ -20,8 +20,29 @@ public class Cli {
         System.out.println(GREEN + "Options:" + RESET);
         System.out.println("  " + BLUE + "--help" + RESET + "        Show help information");
         System.out.println("  " + BLUE + "--version" + RESET + "     Show version information");
+        System.out.println("  " + BLUE + "--trace-json <file>" + RESET + " With run, write an execution trace as JSON Lines");
+        System.out.println("  " + BLUE + "--error-format json" + RESET + " With run, print errors as JSON objects on stderr");
+        System.out.println("  " + BLUE + "--werror" + RESET + "      With run, treat warnings as errors");
+        System.out.println("  " + BLUE + "--lenient" + RESET + "     With run, report failing statements and keep going");
+        System.out.println("  " + BLUE + "--max-time <duration>" + RESET + " With run, stop the script after e.g. 500ms, 10s or 2m");
+        System.out.println("  " + BLUE + "--max-depth <n>" + RESET + " With run, allow at most n nested function calls (0 for no limit)");
+        System.out.println("  " + BLUE + "--max-iterations <n>" + RESET + " With run, stop a for loop after n iterations (default 1000000)");
+        System.out.println("  " + BLUE + "--max-macro-passes <n>" + RESET + " With run, expand nested macros at most n levels deep (default 10)");
+        System.out.println("  " + BLUE + "-I <dir>" + RESET + "      With run, also search dir for imported scripts (see MICROSCRIPT_PATH)");
+        System.out.println("  " + BLUE + "--dry-run" + RESET + "     With run, check the whole file for syntax errors without running it");
         System.out.println(GREEN + "Commands:" + RESET);
         System.out.println("  " + BLUE + "run" + RESET + "           Run a MicroScript source file");
+        System.out.println("  " + BLUE + "repl" + RESET + "          Start an interactive prompt with history and tab completion");
+        System.out.println("  " + BLUE + "kernel" + RESET + "        Run as a Jupyter kernel (--connection-file <file>), started by Jupyter");
+        System.out.println("  " + BLUE + "fmt" + RESET + "           Format source files in place, or stdin to stdout with - (--check lists files that would change)");
+        System.out.println("  " + BLUE + "check" + RESET + "         Report problems in a source file without running it (--types adds type checks, --syntax syntax checks)");
+        System.out.println("  " + BLUE + "bench" + RESET + "         Run the bench_* functions of a source file");
+        System.out.println("  " + BLUE + "lsp" + RESET + "           Start the language server on stdin/stdout");
+        System.out.println("  " + BLUE + "debug" + RESET + "         Debug a source file over the Debug Adapter Protocol");
+        System.out.println("  " + BLUE + "bundle" + RESET + "        Pack a script and its assets into a .musx archive that run accepts");
+        System.out.println("  " + BLUE + "compile" + RESET + "       Precompile a source file into a .musc file that run loads without preprocessing");
+        System.out.println("  " + BLUE + "fuzz" + RESET + "          Fuzz the parser, macro preprocessor or expression evaluator");
+        System.out.println("  " + BLUE + "transpile" + RESET + "     Translate a source file into another language (--target go or js)");
         System.out.println("  " + BLUE + "about" + RESET + "         Show about information");
     }
 
@@ -75,6 +96,73 @@ public class Cli {
             }
         }
         
+        else if (args[0].equals("bench")) {
+            if (args.length < 2) {
+                System.out.println("Error: No file specified");
+                printUsage();
+                return;
+            }
+            String[] benchArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, benchArgs, 0, benchArgs.length);
+            Benchmark.main(benchArgs);
+        }
+        
+        else if (args[0].equals("check")) {
+            String[] checkArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, checkArgs, 0, checkArgs.length);
+            Check.main(checkArgs);
+        }
+        
+        else if (args[0].equals("lsp")) {
+            LanguageServer.main(new String[0]);
+        }
+        
+        else if (args[0].equals("debug")) {
+            String[] debugArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, debugArgs, 0, debugArgs.length);
+            DebugAdapter.main(debugArgs);
+        }
+        
+        else if (args[0].equals("bundle")) {
+            String[] bundleArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, bundleArgs, 0, bundleArgs.length);
+            Bundle.main(bundleArgs);
+        }
+        
+        else if (args[0].equals("repl")) {
+            Repl.main(new String[0]);
+        }
+        
+        else if (args[0].equals("kernel")) {
+            String[] kernelArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, kernelArgs, 0, kernelArgs.length);
+            Kernel.main(kernelArgs);
+        }
+        
+        else if (args[0].equals("fmt")) {
+            String[] fmtArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, fmtArgs, 0, fmtArgs.length);
+            Format.main(fmtArgs);
+        }
+        
+        else if (args[0].equals("compile")) {
+            String[] compileArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, compileArgs, 0, compileArgs.length);
+            Compile.main(compileArgs);
+        }
+        
+        else if (args[0].equals("fuzz")) {
+            String[] fuzzArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, fuzzArgs, 0, fuzzArgs.length);
+            Fuzz.main(fuzzArgs);
+        }
+        
+        else if (args[0].equals("transpile")) {
+            String[] transpileArgs = new String[args.length - 1];
+            System.arraycopy(args, 1, transpileArgs, 0, transpileArgs.length);
+            Transpiler.main(transpileArgs);
+        }
+        
         else {
             System.out.println("Unknown command: " + args[0]);
             printUsage();
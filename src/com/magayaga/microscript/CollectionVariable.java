@@ -0,0 +1,165 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.AbstractList;
+import java.util.List;
+
+/**
+ * A stack, queue or deque created with stack(), queue() or deque(). Elements
+ * live in a ring buffer, so adding and removing at either end takes constant
+ * time. It is a read-only List, so for loops, indexing and printing treat it
+ * like a list from front to back.
+ *
+ *   stack: push, pop, peek (the last pushed)
+ *   queue: push, shift, peek (the first pushed)
+ *   deque: push, pop, unshift, shift, peekFirst, peekLast
+ *
+ * All three also have size(), isEmpty(), clear() and toList().
+ */
+public class CollectionVariable extends AbstractList<Object> {
+    public enum Kind { STACK, QUEUE, DEQUE }
+
+    private final Kind kind;
+    private Object[] items = new Object[8];
+    private int head;
+    private int count;
+
+    public CollectionVariable(Kind kind) {
+        this.kind = kind;
+    }
+
+    public Kind getKind() {
+        return kind;
+    }
+
+    /**
+     * Calls a method by name, as written in a script
+     */
+    public Object call(String method, List<Object> args) {
+        switch (method) {
+            case "size":
+                expectArguments(method, args, 0);
+                return count;
+            case "isEmpty":
+                expectArguments(method, args, 0);
+                return count == 0;
+            case "clear":
+                expectArguments(method, args, 0);
+                items = new Object[8];
+                head = 0;
+                count = 0;
+                return null;
+            case "toList":
+                expectArguments(method, args, 0);
+                return new ListVariable(toArray());
+            case "push":
+                expectArguments(method, args, 1);
+                addLast(args.get(0));
+                return null;
+            default:
+                break;
+        }
+        if (kind != Kind.QUEUE && method.equals("pop")) {
+            expectArguments(method, args, 0);
+            return removeLast();
+        }
+        if (kind != Kind.STACK && method.equals("shift")) {
+            expectArguments(method, args, 0);
+            return removeFirst();
+        }
+        if (kind == Kind.DEQUE && method.equals("unshift")) {
+            expectArguments(method, args, 1);
+            addFirst(args.get(0));
+            return null;
+        }
+        if ((kind == Kind.STACK && method.equals("peek")) || (kind == Kind.DEQUE && method.equals("peekLast"))) {
+            expectArguments(method, args, 0);
+            return get(checkNotEmpty(method) - 1);
+        }
+        if ((kind == Kind.QUEUE && method.equals("peek")) || (kind == Kind.DEQUE && method.equals("peekFirst"))) {
+            expectArguments(method, args, 0);
+            checkNotEmpty(method);
+            return get(0);
+        }
+        throw new RuntimeException("Unknown " + name() + " method: " + method);
+    }
+
+    public void addLast(Object value) {
+        grow();
+        items[(head + count) % items.length] = value;
+        count++;
+    }
+
+    public void addFirst(Object value) {
+        grow();
+        head = (head - 1 + items.length) % items.length;
+        items[head] = value;
+        count++;
+    }
+
+    public Object removeLast() {
+        checkNotEmpty("pop");
+        int last = (head + count - 1) % items.length;
+        Object value = items[last];
+        items[last] = null;
+        count--;
+        return value;
+    }
+
+    public Object removeFirst() {
+        checkNotEmpty("shift");
+        Object value = items[head];
+        items[head] = null;
+        head = (head + 1) % items.length;
+        count--;
+        return value;
+    }
+
+    @Override
+    public Object get(int index) {
+        if (index < 0 || index >= count) {
+            throw new IndexOutOfBoundsException("Index " + index + " out of bounds for " + name() + " of size " + count);
+        }
+        return items[(head + index) % items.length];
+    }
+
+    @Override
+    public int size() {
+        return count;
+    }
+
+    private void grow() {
+        if (count < items.length) {
+            return;
+        }
+        Object[] larger = new Object[items.length * 2];
+        for (int i = 0; i < count; i++) {
+            larger[i] = items[(head + i) % items.length];
+        }
+        items = larger;
+        head = 0;
+    }
+
+    private int checkNotEmpty(String method) {
+        if (count == 0) {
+            throw new RuntimeException(method + " on an empty " + name());
+        }
+        return count;
+    }
+
+    private void expectArguments(String method, List<Object> args, int expected) {
+        if (args.size() != expected) {
+            throw new RuntimeException(name() + "." + method + " expects " + expected + " argument"
+                + (expected == 1 ? "" : "s") + ", got " + args.size());
+        }
+    }
+
+    private String name() {
+        return kind.name().toLowerCase();
+    }
+}
@@ -0,0 +1,155 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedInputStream;
+import java.io.BufferedOutputStream;
+import java.io.DataInputStream;
+import java.io.DataOutputStream;
+import java.io.EOFException;
+import java.io.IOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.List;
+
+/**
+ * Precompiles a script into a .musc file that run loads directly.
+ * Usage:
+ *   microscript compile <file> [-o <file.musc>]
+ *
+ * The script is checked, its macros expanded and its constants folded once,
+ * here, so running the .musc skips the checker, the macro preprocessor and
+ * the optimizer. MicroScript has no bytecode yet: the file holds the
+ * expanded source, one entry per source line, so errors still point at the
+ * original line numbers. Files written by another format version are
+ * refused by run and need compiling again.
+ */
+public class Compile {
+    public static final String EXTENSION = ".musc";
+    private static final int MAGIC = 0x4d555343; // "MUSC"
+    private static final int FORMAT_VERSION = 1;
+
+    private Compile() {
+    }
+
+    public static void main(String[] args) {
+        String filePath = null;
+        String outputPath = null;
+        for (int i = 0; i < args.length; i++) {
+            if (args[i].equals("-o") && i + 1 < args.length) {
+                outputPath = args[++i];
+            } else if (filePath == null && !args[i].startsWith("-")) {
+                filePath = args[i];
+            } else {
+                filePath = null;
+                break;
+            }
+        }
+        if (filePath == null) {
+            System.err.println("Usage: microscript compile <file> [-o <file.musc>]");
+            return;
+        }
+
+        List<String> lines;
+        try {
+            lines = new Scanner(filePath).readLines();
+        } catch (IOException e) {
+            System.err.println("Error reading file '" + filePath + "': " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+
+        // Run skips these checks for a .musc, so they are reported now
+        List<Checker.Diagnostic> diagnostics = new ArrayList<>(Checker.check(lines));
+        diagnostics.addAll(TypeChecker.check(lines));
+        boolean failed = false;
+        for (Checker.Diagnostic diagnostic : diagnostics) {
+            System.err.println(diagnostic.format(filePath) + " [" + diagnostic.getCode() + "]");
+            failed |= diagnostic.getSeverity() == Checker.Severity.ERROR;
+        }
+        if (failed) {
+            System.exit(1);
+            return;
+        }
+
+        List<String> compiled;
+        try {
+            compiled = new Optimizer().optimize(new Define().preprocess(lines));
+        } catch (RuntimeException | StackOverflowError e) {
+            ScriptException error = ScriptException.at(e, -1);
+            String where = error.getLine() >= 0 ? " at line " + (error.getLine() + 1) : "";
+            System.err.println("Error compiling script '" + filePath + "'" + where + ": " + error.getMessage());
+            System.exit(1);
+            return;
+        }
+
+        if (outputPath == null) {
+            String fileName = Paths.get(filePath).getFileName().toString();
+            int dot = fileName.lastIndexOf('.');
+            String baseName = dot > 0 ? fileName.substring(0, dot) : fileName;
+            Path parent = Paths.get(filePath).getParent();
+            outputPath = (parent == null ? Paths.get(baseName + EXTENSION) : parent.resolve(baseName + EXTENSION)).toString();
+        }
+        try {
+            write(Paths.get(outputPath), compiled);
+        } catch (IOException e) {
+            System.err.println("Error writing file '" + outputPath + "': " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+        System.out.println("Wrote " + outputPath);
+    }
+
+    /**
+     * Writes preprocessed and optimized lines as a .musc file
+     */
+    public static void write(Path path, List<String> lines) throws IOException {
+        try (DataOutputStream out = new DataOutputStream(new BufferedOutputStream(Files.newOutputStream(path)))) {
+            out.writeInt(MAGIC);
+            out.writeInt(FORMAT_VERSION);
+            out.writeInt(lines.size());
+            for (String line : lines) {
+                byte[] bytes = line.getBytes(StandardCharsets.UTF_8);
+                out.writeInt(bytes.length);
+                out.write(bytes);
+            }
+        }
+    }
+
+    /**
+     * Reads the lines of a .musc file, ready for Interpreter.runCompiled
+     */
+    public static List<String> read(Path path) throws IOException {
+        try (DataInputStream in = new DataInputStream(new BufferedInputStream(Files.newInputStream(path)))) {
+            if (in.readInt() != MAGIC) {
+                throw new IOException("not a compiled MicroScript file");
+            }
+            int version = in.readInt();
+            if (version != FORMAT_VERSION) {
+                throw new IOException("compiled with format version " + version + ", but this MicroScript reads version "
+                    + FORMAT_VERSION + "; compile the script again");
+            }
+            int count = in.readInt();
+            List<String> lines = new ArrayList<>(Math.max(0, Math.min(count, 1 << 16)));
+            for (int i = 0; i < count; i++) {
+                int length = in.readInt();
+                if (length < 0) {
+                    throw new IOException("the compiled file is corrupt");
+                }
+                byte[] bytes = new byte[length];
+                in.readFully(bytes);
+                lines.add(new String(bytes, StandardCharsets.UTF_8));
+            }
+            return lines;
+        } catch (EOFException e) {
+            throw new IOException("the compiled file is truncated");
+        }
+    }
+}
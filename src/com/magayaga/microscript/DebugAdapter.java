@@ -0,0 +1,392 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.io.PrintStream;
+import java.nio.charset.StandardCharsets;
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+
+/**
+ * Debug Adapter Protocol over stdin/stdout, started with
+ * `microscript debug <file>`. Supports line breakpoints, stepping over,
+ * into and out of MicroScript functions, per-frame variables and
+ * evaluating expressions while paused. Script output is forwarded to the
+ * client as output events because stdout carries the protocol.
+ */
+public class DebugAdapter {
+    private static final int THREAD_ID = 1;
+    private static final int GLOBALS_REFERENCE = 1;
+    // Locals of frame i are reference i + FIRST_FRAME_REFERENCE
+    private static final int FIRST_FRAME_REFERENCE = 2;
+
+    private final MessageStream stream;
+    private final Debugger debugger;
+    private int seq = 1;
+    private String program;
+    private boolean stopOnEntry;
+    private List<Integer> pendingBreakpoints = new ArrayList<>();
+    private Interpreter interpreter;
+
+    public DebugAdapter(InputStream in, OutputStream out, String program) {
+        this.stream = new MessageStream(in, out);
+        this.program = program;
+        this.debugger = new Debugger((reason, line) -> {
+            Map<String, Object> body = new LinkedHashMap<>();
+            body.put("reason", reason);
+            body.put("threadId", THREAD_ID);
+            body.put("allThreadsStopped", true);
+            sendEvent("stopped", body);
+        });
+    }
+
+    public static void main(String[] args) {
+        PrintStream protocol = System.out;
+        DebugAdapter adapter = new DebugAdapter(System.in, protocol, args.length > 0 ? args[0] : null);
+        // Everything the script prints becomes an output event
+        System.setOut(new PrintStream(adapter.new OutputEvents("stdout"), true));
+        System.setErr(new PrintStream(adapter.new OutputEvents("stderr"), true));
+        try {
+            adapter.serve();
+        } catch (IOException e) {
+            // The client is gone; nothing left to report to
+        }
+        System.exit(0);
+    }
+
+    /**
+     * Handles requests until the client disconnects
+     */
+    public void serve() throws IOException {
+        while (true) {
+            Map<String, Object> message = stream.read();
+            if (message == null || !handle(message)) {
+                return;
+            }
+        }
+    }
+
+    // Returns false once the session is over
+    private boolean handle(Map<String, Object> request) {
+        String command = (String) request.get("command");
+        Map<String, Object> arguments = asMap(request.get("arguments"));
+        if (command == null) {
+            return true;
+        }
+        try {
+            switch (command) {
+                case "initialize":
+                    respond(request, capabilities());
+                    sendEvent("initialized", null);
+                    break;
+                case "launch":
+                    if (arguments.get("program") != null) {
+                        program = (String) arguments.get("program");
+                    }
+                    stopOnEntry = Boolean.TRUE.equals(arguments.get("stopOnEntry"));
+                    if (program == null) {
+                        throw new RuntimeException("No program to debug");
+                    }
+                    respond(request, null);
+                    break;
+                case "setBreakpoints":
+                    respond(request, setBreakpoints(arguments));
+                    break;
+                case "configurationDone":
+                    respond(request, null);
+                    start();
+                    break;
+                case "threads": {
+                    Map<String, Object> thread = new LinkedHashMap<>();
+                    thread.put("id", THREAD_ID);
+                    thread.put("name", "main");
+                    Map<String, Object> body = new LinkedHashMap<>();
+                    body.put("threads", Arrays.asList(thread));
+                    respond(request, body);
+                    break;
+                }
+                case "stackTrace":
+                    respond(request, stackTrace());
+                    break;
+                case "scopes":
+                    respond(request, scopes(arguments));
+                    break;
+                case "variables":
+                    respond(request, variables(arguments));
+                    break;
+                case "continue": {
+                    debugger.resume();
+                    Map<String, Object> body = new LinkedHashMap<>();
+                    body.put("allThreadsContinued", true);
+                    respond(request, body);
+                    break;
+                }
+                case "next":
+                    debugger.stepOver();
+                    respond(request, null);
+                    break;
+                case "stepIn":
+                    debugger.stepIn();
+                    respond(request, null);
+                    break;
+                case "stepOut":
+                    debugger.stepOut();
+                    respond(request, null);
+                    break;
+                case "pause":
+                    debugger.pause();
+                    respond(request, null);
+                    break;
+                case "evaluate":
+                    respond(request, evaluate(arguments));
+                    break;
+                case "disconnect":
+                case "terminate":
+                    respond(request, null);
+                    return false;
+                default:
+                    respondError(request, "Unsupported request: " + command);
+            }
+        } catch (RuntimeException e) {
+            respondError(request, e.getMessage());
+        }
+        return true;
+    }
+
+    private Map<String, Object> capabilities() {
+        Map<String, Object> capabilities = new LinkedHashMap<>();
+        capabilities.put("supportsConfigurationDoneRequest", true);
+        capabilities.put("supportsEvaluateForHovers", true);
+        capabilities.put("supportsTerminateRequest", true);
+        return capabilities;
+    }
+
+    // Lines are 1-based on the wire and 0-based in the debugger
+    private Map<String, Object> setBreakpoints(Map<String, Object> arguments) {
+        List<Integer> lines = new ArrayList<>();
+        List<Object> breakpoints = new ArrayList<>();
+        Object requested = arguments.get("breakpoints");
+        if (requested instanceof List) {
+            for (Object item : (List<?>) requested) {
+                int line = ((Number) asMap(item).get("line")).intValue();
+                lines.add(line - 1);
+                Map<String, Object> breakpoint = new LinkedHashMap<>();
+                breakpoint.put("verified", true);
+                breakpoint.put("line", line);
+                breakpoints.add(breakpoint);
+            }
+        }
+        pendingBreakpoints = lines;
+        debugger.setBreakpoints(lines);
+        Map<String, Object> body = new LinkedHashMap<>();
+        body.put("breakpoints", breakpoints);
+        return body;
+    }
+
+    /**
+     * Runs the program on its own thread so requests keep being served while it's paused
+     */
+    private void start() {
+        interpreter = new Interpreter();
+        interpreter.getEnvironment().setDebugger(debugger);
+        debugger.setBreakpoints(pendingBreakpoints);
+        Thread thread = new Thread(() -> {
+            int exitCode = 0;
+            debugger.attach(interpreter.getEnvironment(), stopOnEntry);
+            try {
+                interpreter.runFile(program);
+            } catch (Exception e) {
+                System.err.println("Error executing script '" + program + "': " + e.getMessage());
+                exitCode = 1;
+            }
+            Map<String, Object> exited = new LinkedHashMap<>();
+            exited.put("exitCode", exitCode);
+            sendEvent("exited", exited);
+            sendEvent("terminated", null);
+        }, "microscript-debuggee");
+        thread.setDaemon(true);
+        thread.start();
+    }
+
+    private Map<String, Object> stackTrace() {
+        List<Object> stackFrames = new ArrayList<>();
+        List<Debugger.Frame> frames = debugger.getFrames();
+        for (int i = 0; i < frames.size(); i++) {
+            Debugger.Frame frame = frames.get(i);
+            Map<String, Object> source = new LinkedHashMap<>();
+            source.put("path", program);
+            Map<String, Object> stackFrame = new LinkedHashMap<>();
+            stackFrame.put("id", i);
+            stackFrame.put("name", frame.getName());
+            stackFrame.put("source", source);
+            stackFrame.put("line", frame.getLine() + 1);
+            stackFrame.put("column", 1);
+            stackFrames.add(stackFrame);
+        }
+        Map<String, Object> body = new LinkedHashMap<>();
+        body.put("stackFrames", stackFrames);
+        body.put("totalFrames", stackFrames.size());
+        return body;
+    }
+
+    private Map<String, Object> scopes(Map<String, Object> arguments) {
+        int frameId = ((Number) arguments.get("frameId")).intValue();
+        List<Object> scopes = new ArrayList<>();
+        scopes.add(scope("Locals", frameId + FIRST_FRAME_REFERENCE, false));
+        scopes.add(scope("Globals", GLOBALS_REFERENCE, true));
+        Map<String, Object> body = new LinkedHashMap<>();
+        body.put("scopes", scopes);
+        return body;
+    }
+
+    private static Map<String, Object> scope(String name, int reference, boolean expensive) {
+        Map<String, Object> scope = new LinkedHashMap<>();
+        scope.put("name", name);
+        scope.put("variablesReference", reference);
+        scope.put("expensive", expensive);
+        return scope;
+    }
+
+    private Map<String, Object> variables(Map<String, Object> arguments) {
+        int reference = ((Number) arguments.get("variablesReference")).intValue();
+        List<Debugger.Frame> frames = debugger.getFrames();
+        Environment environment;
+        if (reference == GLOBALS_REFERENCE) {
+            environment = interpreter.getEnvironment();
+        } else {
+            int frameId = reference - FIRST_FRAME_REFERENCE;
+            if (frameId < 0 || frameId >= frames.size()) {
+                throw new RuntimeException("No such frame: " + frameId);
+            }
+            environment = frames.get(frameId).getEnvironment();
+        }
+        List<Object> variables = new ArrayList<>();
+        for (Map.Entry<String, Object> entry : environment.getLocals().entrySet()) {
+            // Native module functions are registered as variables; they aren't interesting here
+            if (entry.getValue() instanceof Import.FunctionInterface) {
+                continue;
+            }
+            Map<String, Object> variable = new LinkedHashMap<>();
+            variable.put("name", entry.getKey());
+            variable.put("value", describe(entry.getValue()));
+            variable.put("type", entry.getValue() == null ? "null" : entry.getValue().getClass().getSimpleName());
+            variable.put("variablesReference", 0);
+            variables.add(variable);
+        }
+        Map<String, Object> body = new LinkedHashMap<>();
+        body.put("variables", variables);
+        return body;
+    }
+
+    private Map<String, Object> evaluate(Map<String, Object> arguments) {
+        if (!debugger.isPaused()) {
+            throw new RuntimeException("Expressions can only be evaluated while paused");
+        }
+        Object frameId = arguments.get("frameId");
+        Object value = debugger.evaluate((String) arguments.get("expression"),
+            frameId != null ? ((Number) frameId).intValue() : 0);
+        Map<String, Object> body = new LinkedHashMap<>();
+        body.put("result", describe(value));
+        body.put("variablesReference", 0);
+        return body;
+    }
+
+    private static String describe(Object value) {
+        if (value instanceof String) {
+            return "\"" + value + "\"";
+        }
+        return String.valueOf(value);
+    }
+
+    private void respond(Map<String, Object> request, Object body) {
+        Map<String, Object> response = new LinkedHashMap<>();
+        response.put("type", "response");
+        response.put("request_seq", request.get("seq"));
+        response.put("success", true);
+        response.put("command", request.get("command"));
+        if (body != null) {
+            response.put("body", body);
+        }
+        send(response);
+    }
+
+    private void respondError(Map<String, Object> request, String message) {
+        Map<String, Object> response = new LinkedHashMap<>();
+        response.put("type", "response");
+        response.put("request_seq", request.get("seq"));
+        response.put("success", false);
+        response.put("command", request.get("command"));
+        response.put("message", message);
+        send(response);
+    }
+
+    private void sendEvent(String event, Object body) {
+        Map<String, Object> message = new LinkedHashMap<>();
+        message.put("type", "event");
+        message.put("event", event);
+        if (body != null) {
+            message.put("body", body);
+        }
+        send(message);
+    }
+
+    // Requests and the script thread both send, so sequence numbers are handed out under the lock
+    private synchronized void send(Map<String, Object> message) {
+        Map<String, Object> framed = new LinkedHashMap<>();
+        framed.put("seq", seq++);
+        framed.putAll(message);
+        try {
+            stream.write(framed);
+        } catch (IOException e) {
+            // The client is gone; the read loop ends the session
+        }
+    }
+
+    @SuppressWarnings("unchecked")
+    private static Map<String, Object> asMap(Object value) {
+        return value instanceof Map ? (Map<String, Object>) value : new LinkedHashMap<>();
+    }
+
+    /**
+     * Sends each line written to it as an output event
+     */
+    private class OutputEvents extends OutputStream {
+        private final String category;
+        private final ByteArrayOutputStream buffer = new ByteArrayOutputStream();
+
+        OutputEvents(String category) {
+            this.category = category;
+        }
+
+        @Override
+        public synchronized void write(int b) {
+            buffer.write(b);
+            if (b == '\n') {
+                flush();
+            }
+        }
+
+        @Override
+        public synchronized void flush() {
+            if (buffer.size() == 0) {
+                return;
+            }
+            Map<String, Object> body = new LinkedHashMap<>();
+            body.put("category", category);
+            body.put("output", new String(buffer.toByteArray(), StandardCharsets.UTF_8));
+            buffer.reset();
+            sendEvent("output", body);
+        }
+    }
+}
@@ -0,0 +1,223 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayDeque;
+import java.util.ArrayList;
+import java.util.Collection;
+import java.util.Deque;
+import java.util.List;
+import java.util.Set;
+import java.util.concurrent.ConcurrentHashMap;
+
+/**
+ * Pauses a running script at breakpoints and steps through it. The executor
+ * reports each top-level statement and each statement of a function body;
+ * statements nested inside if, loop and switch blocks run as part of the
+ * statement that opens the block. Only the thread that runs the script is
+ * debugged; spawned tasks run freely.
+ */
+public class Debugger {
+    /**
+     * Receives stop notifications on the script thread
+     */
+    public interface Listener {
+        void stopped(String reason, int line);
+    }
+
+    /**
+     * A function call on the debugged thread
+     */
+    public static class Frame {
+        private final String name;
+        private final Environment environment;
+        private volatile int line;
+
+        Frame(String name, Environment environment) {
+            this.name = name;
+            this.environment = environment;
+        }
+
+        public String getName() {
+            return name;
+        }
+
+        public Environment getEnvironment() {
+            return environment;
+        }
+
+        /**
+         * The 0-based source line being executed
+         */
+        public int getLine() {
+            return line;
+        }
+    }
+
+    private enum Mode {
+        RUN, PAUSE, STEP_IN, STEP_OVER, STEP_OUT
+    }
+
+    private final Listener listener;
+    private final Set<Integer> breakpoints = ConcurrentHashMap.newKeySet();
+    private final Deque<Frame> frames = new ArrayDeque<>();
+    private volatile Thread thread;
+    private Mode mode = Mode.RUN;
+    private int stepDepth;
+    private boolean paused;
+
+    public Debugger(Listener listener) {
+        this.listener = listener;
+    }
+
+    /**
+     * Binds the debugger to the calling thread with a frame for the script's
+     * top level; call it right before running the script
+     */
+    public synchronized void attach(Environment globals, boolean stopOnEntry) {
+        thread = Thread.currentThread();
+        frames.clear();
+        frames.push(new Frame("<script>", globals));
+        mode = stopOnEntry ? Mode.STEP_IN : Mode.RUN;
+    }
+
+    /**
+     * Replaces the breakpoints with the given 0-based lines
+     */
+    public void setBreakpoints(Collection<Integer> lines) {
+        breakpoints.clear();
+        breakpoints.addAll(lines);
+    }
+
+    public void enterFunction(String name, Environment environment) {
+        if (Thread.currentThread() != thread) {
+            return;
+        }
+        synchronized (this) {
+            frames.push(new Frame(name, environment));
+        }
+    }
+
+    public void exitFunction() {
+        if (Thread.currentThread() != thread) {
+            return;
+        }
+        synchronized (this) {
+            if (frames.size() > 1) {
+                frames.pop();
+            }
+        }
+    }
+
+    /**
+     * Called before a statement runs; blocks while the debugger is paused
+     */
+    public void beforeStatement(int line) {
+        if (Thread.currentThread() != thread) {
+            return;
+        }
+        String reason;
+        synchronized (this) {
+            Frame frame = frames.peek();
+            frame.line = line;
+            reason = stopReason(line);
+            if (reason == null) {
+                return;
+            }
+            mode = Mode.RUN;
+            paused = true;
+        }
+        listener.stopped(reason, line);
+        synchronized (this) {
+            while (paused) {
+                try {
+                    wait();
+                } catch (InterruptedException e) {
+                    Thread.currentThread().interrupt();
+                    paused = false;
+                }
+            }
+        }
+    }
+
+    private String stopReason(int line) {
+        int depth = frames.size();
+        switch (mode) {
+            case PAUSE:
+                return "pause";
+            case STEP_IN:
+                return "step";
+            case STEP_OVER:
+                if (depth <= stepDepth) {
+                    return "step";
+                }
+                break;
+            case STEP_OUT:
+                if (depth < stepDepth) {
+                    return "step";
+                }
+                break;
+            default:
+                break;
+        }
+        return breakpoints.contains(line) ? "breakpoint" : null;
+    }
+
+    public synchronized void resume() {
+        resume(Mode.RUN);
+    }
+
+    public synchronized void stepIn() {
+        resume(Mode.STEP_IN);
+    }
+
+    public synchronized void stepOver() {
+        resume(Mode.STEP_OVER);
+    }
+
+    public synchronized void stepOut() {
+        resume(Mode.STEP_OUT);
+    }
+
+    /**
+     * Stops at the next statement the script reaches
+     */
+    public synchronized void pause() {
+        if (!paused) {
+            mode = Mode.PAUSE;
+        }
+    }
+
+    private void resume(Mode next) {
+        mode = next;
+        stepDepth = frames.size();
+        paused = false;
+        notifyAll();
+    }
+
+    public synchronized boolean isPaused() {
+        return paused;
+    }
+
+    /**
+     * The call stack, innermost frame first
+     */
+    public synchronized List<Frame> getFrames() {
+        return new ArrayList<>(frames);
+    }
+
+    /**
+     * Evaluates an expression in a frame's scope while the script is paused
+     */
+    public Object evaluate(String expression, int frameIndex) {
+        List<Frame> stack = getFrames();
+        if (frameIndex < 0 || frameIndex >= stack.size()) {
+            throw new RuntimeException("No such frame: " + frameIndex);
+        }
+        return new Executor(stack.get(frameIndex).getEnvironment()).evaluate(expression);
+    }
+}
@@ -10,48 +10,321 @@ import java.util.*;
 import java.util.regex.*;
 
 public class Define {
+    // Pre-compiled regex patterns
+    private static final Pattern FUNCTION_MACRO_PATTERN = Pattern.compile("#define\\s+([A-Z_][A-Z0-9_]*)\\s*\\(([^)]*)\\)\\s*(.*)");
+    private static final Pattern OBJECT_MACRO_PATTERN = Pattern.compile("#define\\s+([A-Z_][A-Z0-9_]*)(?:\\s+(.*))?");
+    private static final Pattern UNDEF_PATTERN = Pattern.compile("#undef\\s+([A-Z_][A-Z0-9_]*)");
+    // Function-like macro calls replaced within one pass
+    private static final int MAX_EXPANSIONS = 1000;
+
+    // Nested macros expand one level per pass
+    private int maxPasses = Limits.DEFAULT_MACRO_PASSES;
+
     // Stores object-like macros: NAME -> value
     private final Map<String, String> objectMacros = new HashMap<>();
+    // Word-boundary patterns for object-like macros, compiled once per definition
+    private final Map<String, Pattern> objectMacroPatterns = new HashMap<>();
     // Stores function-like macros: NAME -> MacroDef
     private final Map<String, MacroDef> functionMacros = new HashMap<>();
+    // Lines of the last preprocess call that macros changed, by 0-based line
+    private final Map<Integer, Expansion> expansions = new HashMap<>();
+
+    /**
+     * A source line that macro expansion rewrote, so errors in the expanded
+     * text can point at what the user wrote
+     */
+    public static class Expansion {
+        private final String source;
+        private final String expanded;
+        private final int column;
+        private final String invocation;
+        private final String replacement;
+
+        Expansion(String source, String expanded, int column, String invocation, String replacement) {
+            this.source = source;
+            this.expanded = expanded;
+            this.column = column;
+            this.invocation = invocation;
+            this.replacement = replacement;
+        }
+
+        /**
+         * The line as written in the source file
+         */
+        public String getSource() {
+            return source;
+        }
+
+        /**
+         * The line after every macro in it was expanded
+         */
+        public String getExpanded() {
+            return expanded;
+        }
+
+        /**
+         * The 0-based column of the first macro invocation in the source line
+         */
+        public int getColumn() {
+            return column;
+        }
+
+        /**
+         * The first macro invocation as written, e.g. SQUARE(x + 1)
+         */
+        public String getInvocation() {
+            return invocation;
+        }
+
+        public String getNote() {
+            return invocation + " expands to " + replacement;
+        }
+    }
 
     // Represents a function-like macro (name, parameter list, body)
     private static class MacroDef {
         final List<String> params;
         final String body;
-        MacroDef(List<String> params, String body) {
+        final Pattern callPattern;
+        final List<Pattern> paramPatterns = new ArrayList<>();
+        MacroDef(String name, List<String> params, String body) {
             this.params = params;
             this.body = body;
+            // Matches a macro call: NAME(arg1, arg2, ...), using a word boundary to avoid partial matches
+            this.callPattern = Pattern.compile("\\b" + Pattern.quote(name) + "\\s*\\(([^()]*(?:\\([^()]*\\)[^()]*)*)\\)");
+            for (String param : params) {
+                this.paramPatterns.add(Pattern.compile("\\b" + Pattern.quote(param.trim()) + "\\b"));
+            }
         }
     }
 
     /**
      * Processes lines for #define macros and expands macros in code.
+     * A line ending in a backslash, or with a '(' or '[' still open, continues
+     * on the next line; the joined statement takes the first line's place and
+     * the lines it absorbed are left blank. Block comments are removed first.
+     * A macro defined once and never #undef'd applies to the whole file, even
+     * above its #define.
      */
     public List<String> preprocess(List<String> lines) {
         List<String> output = new ArrayList<>();
-        for (String line : lines) {
+        expansions.clear();
+        lines = stripBlockComments(lines);
+        hoistMacros(lines);
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
             String trimmed = line.trim();
+            int end = continuationEnd(lines, i);
+            if (end > i) {
+                line = joinLines(lines, i, end);
+                trimmed = line.trim();
+            }
             if (trimmed.startsWith("#define")) {
                 parseDefine(trimmed);
+                output.add(""); // Keep line numbers matching the source
             } else if (trimmed.startsWith("#undef")) {
                 parseUndef(trimmed);
+                output.add("");
             } else {
                 // Only expand macros in non-directive lines
-                output.add(expandMacros(line));
+                String expanded;
+                try {
+                    expanded = expandMacros(line);
+                } catch (RuntimeException e) {
+                    throw new ScriptException(e.getMessage() + " in: " + trimmed, output.size(), e);
+                }
+                if (!expanded.equals(line)) {
+                    expansions.put(output.size(), locate(line, expanded));
+                }
+                output.add(expanded);
+            }
+            for (; i < end; i++) {
+                output.add("");
+            }
+        }
+        return output;
+    }
+
+    /**
+     * Blanks out block comments wherever they start, leaving strings, character
+     * literals and line comments alone. Block comments nest, so commenting out
+     * code that already has one needs no care. Comment text becomes spaces so
+     * columns still match the source, and a line that ends in a comment loses
+     * its trailing whitespace.
+     */
+    public static List<String> stripBlockComments(List<String> lines) {
+        List<String> output = new ArrayList<>(lines.size());
+        int depth = 0;
+        for (String line : lines) {
+            if (depth == 0 && line.indexOf("/*") < 0) {
+                output.add(line);
+                continue;
+            }
+            StringBuilder code = new StringBuilder(line.length());
+            boolean inString = false;
+            boolean stripped = depth > 0;
+            for (int i = 0; i < line.length(); i++) {
+                char c = line.charAt(i);
+                char next = i + 1 < line.length() ? line.charAt(i + 1) : '\0';
+                if (depth > 0) {
+                    if (c == '*' && next == '/') {
+                        depth--;
+                        code.append(' ');
+                        i++;
+                    } else if (c == '/' && next == '*') {
+                        depth++;
+                        code.append(' ');
+                        i++;
+                    }
+                    code.append(' ');
+                    continue;
+                }
+                if (inString) {
+                    if (c == '\\' && i + 1 < line.length()) {
+                        code.append(c);
+                        c = line.charAt(++i);
+                    } else if (c == '"') {
+                        inString = false;
+                    }
+                    code.append(c);
+                    continue;
+                }
+                int literal = charLiteralLength(line, i);
+                if (literal > 0) {
+                    code.append(line, i, i + literal);
+                    i += literal - 1;
+                } else if (c == '/' && next == '/' && Checker.startsComment(line, i)) {
+                    code.append(line, i, line.length());
+                    break;
+                } else if (c == '/' && next == '/') {
+                    code.append("//"); // a // b
+                    i++;
+                } else if (c == '/' && next == '*') {
+                    depth = 1;
+                    stripped = true;
+                    code.append("  ");
+                    i++;
+                } else {
+                    inString = c == '"';
+                    code.append(c);
+                }
             }
+            output.add(stripped ? code.toString().replaceAll("\\s+$", "") : code.toString());
         }
         return output;
     }
 
+    // Length of a character literal such as '"' or '\n' starting at i, or 0
+    static int charLiteralLength(String line, int i) {
+        if (line.charAt(i) != '\'') {
+            return 0;
+        }
+        if (i + 2 < line.length() && line.charAt(i + 1) != '\\' && line.charAt(i + 2) == '\'') {
+            return 3;
+        }
+        if (i + 3 < line.length() && line.charAt(i + 1) == '\\' && line.charAt(i + 3) == '\'') {
+            return 4;
+        }
+        return 0;
+    }
+
+    /**
+     * The last line of the statement starting on the given line. Unclosed
+     * brackets only continue a statement when a later line closes them, so a
+     * genuinely missing ')' is still reported on its own line.
+     */
+    private static int continuationEnd(List<String> lines, int start) {
+        int depth = 0;
+        for (int i = start; i < lines.size(); i++) {
+            String code = Checker.codeOnly(lines.get(i));
+            depth += bracketDelta(code);
+            if (depth < 0) {
+                return start;
+            }
+            // A trailing backslash joins the next line even when brackets are balanced
+            if (depth == 0 && !code.trim().endsWith("\\")) {
+                return i;
+            }
+        }
+        return start;
+    }
+
+    // Net count of '(' and '[' opened in a line with strings and comments removed
+    private static int bracketDelta(String code) {
+        int delta = 0;
+        for (int i = 0; i < code.length(); i++) {
+            char c = code.charAt(i);
+            int literal = charLiteralLength(code, i);
+            if (literal > 0) {
+                i += literal - 1; // Character literal such as '('
+            } else if (c == '(' || c == '[') {
+                delta++;
+            } else if (c == ')' || c == ']') {
+                delta--;
+            }
+        }
+        return delta;
+    }
+
+    // Joins lines start..end into one, dropping comments and continuation backslashes
+    private static String joinLines(List<String> lines, int start, int end) {
+        StringBuilder joined = new StringBuilder();
+        for (int i = start; i <= end; i++) {
+            String line = lines.get(i);
+            String code = line.substring(0, Checker.codeOnly(line).length());
+            if (i < end) {
+                code = code.replaceAll("\\s+$", "");
+                if (code.endsWith("\\")) {
+                    code = code.substring(0, code.length() - 1);
+                }
+            }
+            if (i == start) {
+                joined.append(code.replaceAll("\\s+$", ""));
+            } else if (!code.trim().isEmpty()) {
+                joined.append(' ').append(code.trim());
+            }
+        }
+        return joined.toString();
+    }
+
+    /**
+     * The expansion of the given 0-based line in the last preprocessed source,
+     * or null when no macro changed it
+     */
+    public Expansion getExpansion(int line) {
+        return expansions.get(line);
+    }
+
+    // Finds the leftmost macro invocation in a line that expansion changed
+    private Expansion locate(String line, String expanded) {
+        Matcher first = null;
+        for (MacroDef macro : functionMacros.values()) {
+            Matcher call = macro.callPattern.matcher(line);
+            if (call.find() && (first == null || call.start() < first.start())) {
+                first = call;
+            }
+        }
+        for (Pattern pattern : objectMacroPatterns.values()) {
+            Matcher use = pattern.matcher(line);
+            if (use.find() && (first == null || use.start() < first.start())) {
+                first = use;
+            }
+        }
+        if (first == null) {
+            String trimmed = line.trim();
+            return new Expansion(line, expanded, line.indexOf(trimmed), trimmed, expanded.trim());
+        }
+        String invocation = first.group();
+        return new Expansion(line, expanded, first.start(), invocation, expandMacros(invocation));
+    }
+
     /**
      * Parses a #define macro line.
      * Only accepts ALL UPPERCASE macro names (with underscores/numbers).
      */
     private void parseDefine(String line) {
         // Function-like macro: #define NAME(PARAMS) body (NAME is ALL UPPERCASE)
-        Pattern funcPat = Pattern.compile("#define\\s+([A-Z_][A-Z0-9_]*)\\s*\\(([^)]*)\\)\\s*(.*)");
-        Matcher mFunc = funcPat.matcher(line);
+        Matcher mFunc = FUNCTION_MACRO_PATTERN.matcher(line);
         if (mFunc.matches()) {
             String name = mFunc.group(1);
             String paramList = mFunc.group(2).trim();
@@ -59,17 +332,45 @@ public class Define {
                 Arrays.asList(paramList.split("\\s*,\\s*"));
             String body = mFunc.group(3).trim();
             // Allow empty body for function-like macros
-            functionMacros.put(name, new MacroDef(params, body));
+            functionMacros.put(name, new MacroDef(name, params, body));
             return;
         }
         // Object-like macro: #define NAME value (NAME is ALL UPPERCASE)
-        Pattern objPat = Pattern.compile("#define\\s+([A-Z_][A-Z0-9_]*)(?:\\s+(.*))?");
-        Matcher mObj = objPat.matcher(line);
+        Matcher mObj = OBJECT_MACRO_PATTERN.matcher(line);
         if (mObj.matches()) {
             String name = mObj.group(1);
             String value = mObj.group(2);
             // Allow empty value for object-like macros (equivalent to empty string)
             objectMacros.put(name, value != null ? value.trim() : "");
+            objectMacroPatterns.put(name, Pattern.compile("\\b" + Pattern.quote(name) + "\\b"));
+        }
+    }
+
+    // Defines the macros that have a single #define and no #undef before expanding anything
+    private void hoistMacros(List<String> lines) {
+        Map<String, List<String>> definitions = new HashMap<>();
+        Set<String> undefined = new HashSet<>();
+        for (int i = 0; i < lines.size(); i++) {
+            String trimmed = lines.get(i).trim();
+            if (trimmed.startsWith("#define")) {
+                int end = continuationEnd(lines, i);
+                String directive = end > i ? joinLines(lines, i, end).trim() : trimmed;
+                Matcher matcher = OBJECT_MACRO_PATTERN.matcher(directive);
+                if (matcher.lookingAt()) {
+                    definitions.computeIfAbsent(matcher.group(1), name -> new ArrayList<>()).add(directive);
+                }
+                i = end;
+            } else if (trimmed.startsWith("#undef")) {
+                Matcher matcher = UNDEF_PATTERN.matcher(trimmed);
+                if (matcher.matches()) {
+                    undefined.add(matcher.group(1));
+                }
+            }
+        }
+        for (Map.Entry<String, List<String>> definition : definitions.entrySet()) {
+            if (definition.getValue().size() == 1 && !undefined.contains(definition.getKey())) {
+                parseDefine(definition.getValue().get(0));
+            }
         }
     }
 
@@ -77,11 +378,11 @@ public class Define {
      * Parses a #undef directive to remove macro definitions.
      */
     private void parseUndef(String line) {
-        Pattern undefPat = Pattern.compile("#undef\\s+([A-Z_][A-Z0-9_]*)");
-        Matcher mUndef = undefPat.matcher(line);
+        Matcher mUndef = UNDEF_PATTERN.matcher(line);
         if (mUndef.matches()) {
             String name = mUndef.group(1);
             objectMacros.remove(name);
+            objectMacroPatterns.remove(name);
             functionMacros.remove(name);
         }
     }
@@ -90,46 +391,93 @@ public class Define {
      * Expands macros in a single line.
      * If a function-like macro is called with the wrong number of arguments,
      * replaces the macro call with a runtime error marker.
+     * Macros that expand to themselves, directly or through other macros,
+     * are an error naming the cycle.
      */
     public String expandMacros(String line) {
         String result = line;
+        Set<String> expanded = new LinkedHashSet<>();
 
         // Multiple passes to handle nested macro expansions
-        for (int pass = 0; pass < 10; pass++) { // Limit passes to prevent infinite loops
+        for (int pass = 0; pass < maxPasses; pass++) {
             String beforeExpansion = result;
+            expanded.clear();
 
             // Expand function-like macros first
-            result = expandFunctionMacros(result);
+            result = expandFunctionMacros(result, expanded);
 
             // Expand object-like macros
-            result = expandObjectMacros(result);
+            result = expandObjectMacros(result, expanded);
 
             // If no changes were made, we're done
             if (result.equals(beforeExpansion)) {
-                break;
+                return result;
             }
         }
 
-        return result;
+        throw runawayExpansion(expanded);
+    }
+
+    /**
+     * The error for expansion that doesn't finish, naming a cycle among the
+     * macros still expanding when there is one
+     */
+    private RuntimeException runawayExpansion(Set<String> expanding) {
+        for (String name : expanding) {
+            List<String> cycle = new ArrayList<>();
+            if (findCycle(name, name, cycle, new HashSet<>())) {
+                return new RuntimeException("Macro expansion does not terminate: " + String.join(" -> ", cycle)
+                    + " expands to itself");
+            }
+        }
+        return new RuntimeException("Macro expansion did not finish after " + maxPasses + " passes; still expanding "
+            + String.join(", ", expanding) + "; raise the limit with --max-macro-passes");
+    }
+
+    // Depth-first search for a chain of macro bodies that leads from name back to start
+    private boolean findCycle(String name, String start, List<String> path, Set<String> visited) {
+        path.add(name);
+        MacroDef function = functionMacros.get(name);
+        String body = function != null ? function.body : objectMacros.get(name);
+        if (body != null) {
+            List<String> names = new ArrayList<>(functionMacros.keySet());
+            names.addAll(objectMacros.keySet());
+            for (String next : names) {
+                if (!Pattern.compile("\\b" + Pattern.quote(next) + "\\b").matcher(body).find()) {
+                    continue;
+                }
+                if (next.equals(start)) {
+                    path.add(start);
+                    return true;
+                }
+                if (visited.add(next) && findCycle(next, start, path, visited)) {
+                    return true;
+                }
+            }
+        }
+        path.remove(path.size() - 1);
+        return false;
     }
 
     /**
-     * Expands function-like macros in a line.
+     * Expands function-like macros in a line, adding the name of each one
+     * expanded to the set.
      */
-    private String expandFunctionMacros(String line) {
+    private String expandFunctionMacros(String line, Set<String> expanded) {
         String result = line;
         boolean replaced;
+        int expansions = 0;
 
         do {
+            if (expansions++ > MAX_EXPANSIONS) {
+                throw runawayExpansion(expanded);
+            }
             replaced = false;
             for (Map.Entry<String, MacroDef> entry : functionMacros.entrySet()) {
                 String name = entry.getKey();
                 MacroDef macro = entry.getValue();
 
-                // Regex to match macro call: NAME(arg1, arg2, ...)
-                // Use word boundary to avoid partial matches
-                Pattern callPat = Pattern.compile("\\b" + Pattern.quote(name) + "\\s*\\(([^()]*(?:\\([^()]*\\)[^()]*)*)\\)");
-                Matcher mCall = callPat.matcher(result);
+                Matcher mCall = macro.callPattern.matcher(result);
 
                 if (mCall.find()) {
                     String argStr = mCall.group(1);
@@ -143,11 +491,10 @@ public class Define {
 
                         // Replace parameters with arguments
                         for (int i = 0; i < macro.params.size(); i++) {
-                            String param = macro.params.get(i).trim();
                             String arg = args.get(i).trim();
                             // Use word boundary to replace only complete parameter names
-                            body = body.replaceAll("\\b" + Pattern.quote(param) + "\\b",
-                                                 Matcher.quoteReplacement(arg));
+                            body = macro.paramPatterns.get(i).matcher(body)
+                                                 .replaceAll(Matcher.quoteReplacement(arg));
                         }
 
                         // Only wrap in parentheses if the body contains operators and isn't already wrapped
@@ -157,6 +504,7 @@ public class Define {
 
                         result = mCall.replaceFirst(Matcher.quoteReplacement(body));
                     }
+                    expanded.add(name);
                     replaced = true;
                     break;
                 }
@@ -167,17 +515,21 @@ public class Define {
     }
 
     /**
-     * Expands object-like macros in a line.
+     * Expands object-like macros in a line, adding the name of each one
+     * expanded to the set.
      */
-    private String expandObjectMacros(String line) {
+    private String expandObjectMacros(String line, Set<String> expanded) {
         String result = line;
 
         for (Map.Entry<String, String> entry : objectMacros.entrySet()) {
             String name = entry.getKey();
             String value = entry.getValue();
             // Use word boundary to replace only complete macro names
-            result = result.replaceAll("\\b" + Pattern.quote(name) + "\\b",
-                                     Matcher.quoteReplacement(value));
+            Matcher matcher = objectMacroPatterns.get(name).matcher(result);
+            if (matcher.find()) {
+                expanded.add(name);
+                result = matcher.replaceAll(Matcher.quoteReplacement(value));
+            }
         }
 
         return result;
@@ -242,6 +594,14 @@ public class Define {
         return args;
     }
 
+    /**
+     * Sets how many passes of nested expansion a line gets before
+     * expansion is reported as not finishing
+     */
+    public void setMaxPasses(int maxPasses) {
+        this.maxPasses = maxPasses;
+    }
+
     /**
      * Checks if a macro is defined (either object-like or function-like).
      */
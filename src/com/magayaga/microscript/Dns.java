@@ -0,0 +1,186 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.net.InetAddress;
+import java.net.UnknownHostException;
+import java.util.ArrayList;
+import java.util.Hashtable;
+import java.util.List;
+import javax.naming.Context;
+import javax.naming.NameNotFoundException;
+import javax.naming.NamingEnumeration;
+import javax.naming.NamingException;
+import javax.naming.directory.Attribute;
+import javax.naming.directory.DirContext;
+import javax.naming.directory.InitialDirContext;
+
+/**
+ * DNS queries for the dns module, so scripts don't have to shell out to dig:
+ *
+ *   import dns
+ *   dns::lookup("example.com")     // ["93.184.215.14", "2606:2800:21f:cb07:6820:80da:af6b:8b2c"]
+ *   dns::reverse("8.8.8.8")        // ["dns.google"]
+ *   dns::mx("gmail.com")           // mail hosts, most preferred first
+ *   dns::txt("example.com")        // ["v=spf1 -all", ...]
+ *
+ * Each returns a list, empty when the name has no records of that kind. A
+ * name that doesn't exist is an error.
+ */
+public class Dns {
+    // Milliseconds before the first retry; each retry doubles it
+    private static final String TIMEOUT = "2000";
+    private static final String RETRIES = "2";
+
+    private Dns() {
+    }
+
+    /**
+     * The IPv4 and IPv6 addresses of a host, resolved the way the system
+     * resolves them, so /etc/hosts counts
+     */
+    public static ListVariable lookup(String host) {
+        ListVariable addresses = new ListVariable();
+        try {
+            for (InetAddress address : InetAddress.getAllByName(host)) {
+                addresses.add(address.getHostAddress());
+            }
+        } catch (UnknownHostException e) {
+            throw new RuntimeException("dns::lookup: unknown host " + host);
+        }
+        return addresses;
+    }
+
+    /**
+     * The host names an IPv4 or IPv6 address points back to (its PTR records)
+     */
+    public static ListVariable reverse(String ip) {
+        // A host name here would be resolved, not reversed
+        if (!ip.matches("[0-9A-Fa-f:.]+") || !(ip.contains(".") || ip.contains(":"))) {
+            throw new RuntimeException("dns::reverse expects an IP address, got: " + ip);
+        }
+        byte[] bytes;
+        try {
+            bytes = InetAddress.getByName(ip).getAddress();
+        } catch (UnknownHostException e) {
+            throw new RuntimeException("dns::reverse expects an IP address, got: " + ip);
+        }
+        StringBuilder name = new StringBuilder();
+        for (int i = bytes.length - 1; i >= 0; i--) {
+            int octet = bytes[i] & 0xff;
+            if (bytes.length == 4) {
+                name.append(octet).append('.');
+            } else {
+                name.append(Character.forDigit(octet & 0xf, 16)).append('.')
+                    .append(Character.forDigit(octet >> 4, 16)).append('.');
+            }
+        }
+        name.append(bytes.length == 4 ? "in-addr.arpa" : "ip6.arpa");
+
+        ListVariable hosts = new ListVariable();
+        for (String host : query("reverse", name.toString(), "PTR")) {
+            hosts.add(stripDot(host));
+        }
+        return hosts;
+    }
+
+    /**
+     * The mail servers for a domain, most preferred first
+     */
+    public static ListVariable mx(String domain) {
+        List<String[]> records = new ArrayList<>();
+        for (String record : query("mx", domain, "MX")) {
+            // "10 mail.example.com."
+            String[] parts = record.trim().split("\\s+", 2);
+            records.add(parts.length == 2 ? parts : new String[] {"0", parts[0]});
+        }
+        records.sort((a, b) -> Integer.compare(preference(a[0]), preference(b[0])));
+        ListVariable hosts = new ListVariable();
+        for (String[] record : records) {
+            hosts.add(stripDot(record[1]));
+        }
+        return hosts;
+    }
+
+    /**
+     * The TXT records of a domain, each with its strings joined together
+     */
+    public static ListVariable txt(String domain) {
+        ListVariable texts = new ListVariable();
+        for (String record : query("txt", domain, "TXT")) {
+            texts.add(unquote(record));
+        }
+        return texts;
+    }
+
+    // The records of one type, as text, from the system's DNS servers
+    private static List<String> query(String function, String name, String type) {
+        Hashtable<String, String> environment = new Hashtable<>();
+        environment.put(Context.INITIAL_CONTEXT_FACTORY, "com.sun.jndi.dns.DnsContextFactory");
+        environment.put("com.sun.jndi.dns.timeout.initial", TIMEOUT);
+        environment.put("com.sun.jndi.dns.timeout.retries", RETRIES);
+        List<String> records = new ArrayList<>();
+        DirContext context = null;
+        try {
+            context = new InitialDirContext(environment);
+            Attribute attribute = context.getAttributes(name, new String[] {type}).get(type);
+            if (attribute != null) {
+                NamingEnumeration<?> values = attribute.getAll();
+                while (values.hasMore()) {
+                    records.add(String.valueOf(values.next()));
+                }
+            }
+        } catch (NameNotFoundException e) {
+            throw new RuntimeException("dns::" + function + ": no such domain " + name);
+        } catch (NamingException e) {
+            String reason = e.getExplanation() != null ? e.getExplanation() : e.getClass().getSimpleName();
+            throw new RuntimeException("dns::" + function + ": " + type + " query for " + name + " failed: " + reason);
+        } finally {
+            if (context != null) {
+                try {
+                    context.close();
+                } catch (NamingException e) {
+                    // Nothing to release
+                }
+            }
+        }
+        return records;
+    }
+
+    private static int preference(String text) {
+        try {
+            return Integer.parseInt(text);
+        } catch (NumberFormatException e) {
+            return Integer.MAX_VALUE;
+        }
+    }
+
+    // Names in records are absolute: "mail.example.com."
+    private static String stripDot(String host) {
+        return host.endsWith(".") ? host.substring(0, host.length() - 1) : host;
+    }
+
+    // A TXT record can hold several quoted strings: "v=spf1 " "-all"
+    private static String unquote(String record) {
+        if (!record.startsWith("\"")) {
+            return record;
+        }
+        StringBuilder text = new StringBuilder();
+        boolean quoted = false;
+        for (int i = 0; i < record.length(); i++) {
+            char c = record.charAt(i);
+            if (c == '"') {
+                quoted = !quoted;
+            } else if (c == '\\' && quoted && i + 1 < record.length()) {
+                text.append(record.charAt(++i));
+            } else if (quoted) {
+                text.append(c);
+            }
+        }
+        return text.toString();
+    }
+}
@@ -6,40 +6,276 @@
  */
 package com.magayaga.microscript;
 
+import java.util.ArrayList;
 import java.util.HashMap;
+import java.util.HashSet;
+import java.util.List;
 import java.util.Map;
+import java.util.TreeMap;
 import java.util.Set;
-import java.util.HashSet;
+import java.util.concurrent.ConcurrentHashMap;
 
 public class Environment {
+    // Stands in for a variable set to null, which concurrent maps can't hold
+    private static final Object NULL = new Object();
+
     private final Map<String, Object> variables;
     private final Map<String, Function> functions;
     private final Map<String, Struct> structs;
+    private final Map<String, Interface> interfaces;
     private final Set<String> immutableVariables;
+    // Names declared global or static, which read and assign another environment's variable
+    private final Map<String, Environment> boundElsewhere;
     private final Environment parent;
+    // Shared by every environment under the same root
+    private final Memory memory;
+    private final Interner interner;
+    private final EventLoop eventLoop;
+    // Inherited from the parent, so set these on the root before running
+    private Debugger debugger;
+    private Tracer tracer;
+    private Cancellation cancellation;
+    private Limits limits;
+    private boolean strict;
+    // Function calls between the root and this environment
+    private int callDepth;
+    private boolean released;
+    // Set once a lambda closes over this scope or one inside it, so release leaves it alone
+    private boolean captured;
 
     public Environment() {
-        this.variables = new HashMap<>();
-        this.functions = new HashMap<>();
-        this.structs = new HashMap<>();
-        this.immutableVariables = new HashSet<>();
-        this.parent = null;
+        this(null);
     }
 
     public Environment(Environment parent) {
-        this.variables = new HashMap<>();
-        this.functions = new HashMap<>();
-        this.structs = new HashMap<>();
-        this.immutableVariables = new HashSet<>();
+        // Tasks started with spawn share only the root, running their calls in
+        // scopes of their own, so the root alone needs concurrent maps
+        this(parent, parent == null);
+    }
+
+    private Environment(Environment parent, boolean shared) {
+        this.variables = shared ? new ConcurrentHashMap<>() : new HashMap<>();
+        this.functions = shared ? new ConcurrentHashMap<>() : new HashMap<>();
+        this.structs = shared ? new ConcurrentHashMap<>() : new HashMap<>();
+        this.interfaces = shared ? new ConcurrentHashMap<>() : new HashMap<>();
+        this.immutableVariables = shared ? ConcurrentHashMap.newKeySet() : new HashSet<>();
+        this.boundElsewhere = shared ? new ConcurrentHashMap<>() : new HashMap<>();
         this.parent = parent;
+        this.memory = parent != null ? parent.memory : new Memory();
+        this.interner = parent != null ? parent.interner : new Interner(memory);
+        this.eventLoop = parent != null ? parent.eventLoop : new EventLoop();
+        this.debugger = parent != null ? parent.debugger : null;
+        this.tracer = parent != null ? parent.tracer : null;
+        this.strict = parent == null || parent.strict;
+        this.cancellation = parent != null ? parent.cancellation : new Cancellation();
+        this.limits = parent != null ? parent.limits : new Limits();
+        this.callDepth = parent != null ? parent.callDepth : 0;
+        memory.environmentCreated();
+    }
+
+    /**
+     * A scope under this one's root for values that outlive any call, such as
+     * a function's static variables. Tasks started with spawn can reach it,
+     * so it has concurrent maps like the root.
+     */
+    public Environment newSharedScope() {
+        return new Environment(getRoot(), true);
+    }
+
+    /**
+     * The scopes between this one and the root copied into one scope under the
+     * root, inner names shadowing outer ones. A task started with spawn runs a
+     * lambda in the copy, so it reads the values it closed over as they were
+     * when it was spawned while the caller goes on changing its own.
+     */
+    public Environment snapshot() {
+        List<Environment> scopes = new ArrayList<>();
+        for (Environment scope = this; scope.parent != null; scope = scope.parent) {
+            scopes.add(scope);
+        }
+        Environment copy = new Environment(getRoot());
+        for (int i = scopes.size() - 1; i >= 0; i--) {
+            Environment scope = scopes.get(i);
+            copy.variables.putAll(scope.variables);
+            copy.functions.putAll(scope.functions);
+            copy.structs.putAll(scope.structs);
+            copy.interfaces.putAll(scope.interfaces);
+            copy.immutableVariables.addAll(scope.immutableVariables);
+            copy.boundElsewhere.putAll(scope.boundElsewhere);
+        }
+        return copy;
+    }
+
+    /**
+     * The outermost environment, which holds the script's globals
+     */
+    public Environment getRoot() {
+        Environment root = this;
+        while (root.parent != null) {
+            root = root.parent;
+        }
+        return root;
+    }
+
+    /**
+     * Makes the name refer to the global of that name in this scope, so
+     * assigning it changes the global instead of creating a local. Fails
+     * when the name is already a local here.
+     */
+    public void declareGlobal(String name) {
+        if (parent == null) {
+            return; // Already global
+        }
+        bindElsewhere(name, getRoot(), "global");
+    }
+
+    /**
+     * Makes the name refer to a static variable kept in storage, which
+     * outlives this scope. Fails when the name is already a local here.
+     */
+    public void declareStatic(String name, Environment storage) {
+        bindElsewhere(name, storage, "static");
+    }
+
+    private void bindElsewhere(String name, Environment storage, String keyword) {
+        if (boundElsewhere.get(name) == storage) {
+            return;
+        }
+        if (definesVariable(name)) {
+            throw new RuntimeException("'" + name + "' is already a local variable; declare it " + keyword
+                + " before using it");
+        }
+        boundElsewhere.put(name, storage);
+    }
+
+    public Memory getMemory() {
+        return memory;
+    }
+
+    public Debugger getDebugger() {
+        return debugger;
+    }
+
+    public void setDebugger(Debugger debugger) {
+        this.debugger = debugger;
+    }
+
+    public Tracer getTracer() {
+        return tracer;
+    }
+
+    public void setTracer(Tracer tracer) {
+        this.tracer = tracer;
+    }
+
+    public Cancellation getCancellation() {
+        return cancellation;
+    }
+
+    public void setCancellation(Cancellation cancellation) {
+        this.cancellation = cancellation;
+    }
+
+    public Limits getLimits() {
+        return limits;
+    }
+
+    public void setLimits(Limits limits) {
+        this.limits = limits;
+    }
+
+    /**
+     * Where timers and server callbacks queue to run, one at a time
+     */
+    public EventLoop getEventLoop() {
+        return eventLoop;
+    }
+
+    /**
+     * Marks this environment as the body of a call to the named function,
+     * one call deeper than its parent. Fails once the depth passes the
+     * callDepth limit.
+     */
+    public void enterCall(String functionName) {
+        callDepth++;
+        int limit = limits.getCallDepth();
+        if (limit > 0 && callDepth > limit) {
+            throw new RuntimeException("Stack overflow: calling " + functionName + " goes past the call depth limit of "
+                + limit + "; raise it with --max-depth");
+        }
+    }
+
+    /**
+     * In strict mode (the default) a failing statement, such as one that uses
+     * an undefined variable or function, stops the script. Lenient mode prints
+     * the error and continues with the next statement.
+     */
+    public boolean isStrict() {
+        return strict;
+    }
+
+    public void setStrict(boolean strict) {
+        this.strict = strict;
+    }
+
+    /**
+     * The variables defined directly in this scope, sorted by name
+     */
+    public Map<String, Object> getLocals() {
+        Map<String, Object> locals = new TreeMap<>(variables);
+        locals.replaceAll((name, value) -> value == NULL ? null : value);
+        return locals;
+    }
+
+    /**
+     * Keeps this scope and the ones enclosing it intact after their block or
+     * function exits, since a lambda written here can still read them
+     */
+    public void capture() {
+        for (Environment scope = this; scope != null && !scope.captured; scope = scope.parent) {
+            scope.captured = true;
+        }
+    }
+
+    /**
+     * Drops everything this environment holds once its block or function has
+     * exited, so values don't stay reachable through stale references. A
+     * captured environment is left for the garbage collector instead.
+     */
+    public void release() {
+        if (released || captured) {
+            return;
+        }
+        released = true;
+        variables.clear();
+        functions.clear();
+        structs.clear();
+        interfaces.clear();
+        immutableVariables.clear();
+        boundElsewhere.clear();
+        memory.environmentReleased();
     }
 
     public void setVariable(String name, Object value) {
-        variables.put(name, value);
+        Environment storage = boundElsewhere.get(name);
+        if (storage != null) {
+            storage.setVariable(name, value);
+            return;
+        }
+        value = interner.intern(value);
+        trace(name, value);
+        variables.put(name, value == null ? NULL : value);
+    }
+
+    // Module functions are registered as variables too; only script values are traced
+    private void trace(String name, Object value) {
+        if (tracer != null && !(value instanceof Import.FunctionInterface) && !(value instanceof Function)) {
+            tracer.variable(name, value);
+        }
     }
 
     public void setImmutableVariable(String name, Object value) {
-        variables.put(name, value);
+        setVariable(name, value);
         immutableVariables.add(name);
     }
 
@@ -54,9 +290,13 @@ public class Environment {
     }
 
     public Object getVariable(String name) {
+        Environment storage = boundElsewhere.get(name);
+        if (storage != null) {
+            return storage.getVariable(name);
+        }
         Object value = variables.get(name);
         if (value != null) {
-            return value;
+            return value == NULL ? null : value;
         }
         if (parent != null) {
             return parent.getVariable(name);
@@ -64,6 +304,54 @@ public class Environment {
         return null;
     }
 
+    /**
+     * Whether the variable is defined here or in an enclosing scope, which
+     * tells a variable holding null apart from an undefined one
+     */
+    public boolean hasVariable(String name) {
+        for (Environment scope = this; scope != null; scope = scope.parent) {
+            if (scope.definesVariable(name)) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    /**
+     * Whether the variable is defined in this scope itself rather than an
+     * enclosing one
+     */
+    public boolean hasLocalVariable(String name) {
+        return definesVariable(name);
+    }
+
+    /**
+     * Applies an update to a variable in the scope that defines it, atomically
+     * with respect to other updates of the same scope
+     */
+    public Object updateVariable(String name, java.util.function.UnaryOperator<Object> update) {
+        Environment owner = boundElsewhere.getOrDefault(name, this);
+        while (owner != null && !owner.definesVariable(name)) {
+            owner = owner.parent;
+        }
+        if (owner == null) {
+            throw new RuntimeException("Undefined variable: " + name);
+        }
+        synchronized (owner) {
+            Object value = update.apply(owner.getVariable(name));
+            owner.setVariable(name, value);
+            return value;
+        }
+    }
+
+    private boolean definesVariable(String name) {
+        Environment storage = boundElsewhere.get(name);
+        if (storage != null) {
+            return storage.definesVariable(name);
+        }
+        return variables.containsKey(name);
+    }
+
     public void defineFunction(Function function) {
         functions.put(function.getName(), function);
     }
@@ -86,6 +374,13 @@ public class Environment {
         return null;
     }
 
+    /**
+     * Names of the functions defined directly in this environment, in no particular order
+     */
+    public Set<String> getFunctionNames() {
+        return functions.keySet();
+    }
+
     public void defineStruct(Struct struct) {
         structs.put(struct.getName(), struct);
     }
@@ -100,4 +395,19 @@ public class Environment {
         }
         return null;
     }
+
+    public void defineInterface(Interface definition) {
+        interfaces.put(definition.getName(), definition);
+    }
+
+    public Interface getInterface(String name) {
+        Interface definition = interfaces.get(name);
+        if (definition != null) {
+            return definition;
+        }
+        if (parent != null) {
+            return parent.getInterface(name);
+        }
+        return null;
+    }
 }
\ No newline at end of file
@@ -0,0 +1,159 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.Collection;
+import java.util.List;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Shows where a script error happened, for errors printed to a terminal:
+ *
+ *      4 |     var total = 0;
+ *      5 |     console.write(totl);
+ *        |                   ^^^^
+ *      6 | }
+ *   hint: did you mean total?
+ *
+ * The failing line is shown with two lines on either side and the token at
+ * the error's column underlined. When the column isn't known, the name the
+ * message complains about is underlined, or else the whole statement. Errors
+ * about an unknown name get a hint with the closest known one.
+ */
+public class ErrorReport {
+    private static final String RESET = "\u001B[0m";
+    private static final String RED = "\u001B[31;1m";
+    private static final String YELLOW = "\u001B[33;1m";
+    private static final String DIM = "\u001B[2m";
+    private static final int CONTEXT = 2;
+    private static final Pattern NAME_PATTERN = Pattern.compile("^(?:Undefined variable|Function not found): (\\S+)");
+
+    private ErrorReport() {
+    }
+
+    /**
+     * The source snippet for an error and, when there is one, a hint; empty
+     * when the error's line isn't in the source
+     */
+    public static String render(List<String> lines, ScriptException error, Collection<String> names, boolean color) {
+        int line = error.getLine();
+        if (lines == null || line < 0 || line >= lines.size()) {
+            return "";
+        }
+        String source = lines.get(line);
+        String name = unknownName(error.getMessage());
+        int start;
+        int end;
+        if (error.getColumn() >= 0 && error.getColumn() < source.length()) {
+            start = error.getColumn();
+            end = tokenEnd(source, start);
+        } else if (name != null && find(source, name) >= 0) {
+            start = find(source, name);
+            end = start + name.length();
+        } else {
+            start = source.length() - source.stripLeading().length();
+            end = source.stripTrailing().length();
+        }
+        if (end <= start) {
+            return "";
+        }
+
+        int first = Math.max(0, line - CONTEXT);
+        int last = Math.min(lines.size() - 1, line + CONTEXT);
+        int width = String.valueOf(last + 1).length();
+        StringBuilder out = new StringBuilder();
+        for (int i = first; i <= last; i++) {
+            String number = String.format("%" + width + "d", i + 1);
+            String gutter = "  " + number + " | ";
+            out.append(i == line || !color ? gutter : DIM + gutter + RESET).append(lines.get(i)).append('\n');
+            if (i == line) {
+                StringBuilder marker = new StringBuilder();
+                for (int j = 0; j < start; j++) {
+                    // Tabs stay tabs so the carets line up however the terminal shows them
+                    marker.append(source.charAt(j) == '\t' ? '\t' : ' ');
+                }
+                marker.append("^".repeat(end - start));
+                out.append("  ").append(" ".repeat(width)).append(" | ")
+                    .append(color ? RED + marker + RESET : marker).append('\n');
+            }
+        }
+        String suggestion = name == null ? null : closest(name, names);
+        if (suggestion != null) {
+            out.append(color ? YELLOW + "hint" + RESET : "hint").append(": did you mean ").append(suggestion).append("?\n");
+        }
+        return out.toString();
+    }
+
+    // The variable or function an "Undefined variable" or "Function not found" error names
+    private static String unknownName(String message) {
+        if (message == null) {
+            return null;
+        }
+        Matcher matcher = NAME_PATTERN.matcher(message);
+        return matcher.find() ? matcher.group(1) : null;
+    }
+
+    // Where name appears in the line as a whole word, or -1
+    private static int find(String source, String name) {
+        Matcher matcher = Pattern.compile("(?<![\\w.:])" + Pattern.quote(name) + "(?![\\w])").matcher(source);
+        return matcher.find() ? matcher.start() : -1;
+    }
+
+    // The end of the name, number or operator starting at start
+    private static int tokenEnd(String source, int start) {
+        int end = start;
+        while (end < source.length() && isNameChar(source.charAt(end))) {
+            end++;
+        }
+        return end > start ? end : start + 1;
+    }
+
+    private static boolean isNameChar(char c) {
+        return Character.isLetterOrDigit(c) || c == '_' || c == '.' || c == ':';
+    }
+
+    /**
+     * The known name nearest to an unknown one, or null when none is close;
+     * a name may differ by about one edit for every three characters
+     */
+    public static String closest(String name, Collection<String> names) {
+        String best = null;
+        int bestDistance = Math.max(1, name.length() / 3) + 1;
+        for (String candidate : names) {
+            if (candidate.equals(name)) {
+                continue;
+            }
+            int distance = distance(name, candidate);
+            if (distance < bestDistance) {
+                best = candidate;
+                bestDistance = distance;
+            }
+        }
+        return best;
+    }
+
+    // Levenshtein distance
+    private static int distance(String a, String b) {
+        int[] previous = new int[b.length() + 1];
+        int[] current = new int[b.length() + 1];
+        for (int j = 0; j <= b.length(); j++) {
+            previous[j] = j;
+        }
+        for (int i = 1; i <= a.length(); i++) {
+            current[0] = i;
+            for (int j = 1; j <= b.length(); j++) {
+                int cost = a.charAt(i - 1) == b.charAt(j - 1) ? 0 : 1;
+                current[j] = Math.min(Math.min(current[j - 1] + 1, previous[j] + 1), previous[j - 1] + cost);
+            }
+            int[] swap = previous;
+            previous = current;
+            current = swap;
+        }
+        return previous[b.length()];
+    }
+}
@@ -0,0 +1,180 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayDeque;
+import java.util.Deque;
+import java.util.HashMap;
+import java.util.Map;
+import java.util.PriorityQueue;
+import java.util.function.BooleanSupplier;
+
+/**
+ * Runs the callbacks a script registers, one at a time on the thread that
+ * runs the loop:
+ *
+ *   setTimeout(announce, 500);
+ *   var ticker = setInterval(tick, 1000);
+ *   clearInterval(ticker);
+ *
+ * Timers, HTTP route handlers, WebSocket handlers, Redis messages and
+ * os.onSignal handlers are queued here rather than run on the thread they
+ * arrive on, so a callback never runs alongside the script or another
+ * callback. The loop runs while http::wait and redis::wait block, and once
+ * the script's top level has finished, until no timers are left. Every
+ * environment under the same root shares an instance.
+ */
+public class EventLoop {
+    // How often a waiting loop checks for cancellation and for its condition
+    private static final long POLL_MILLIS = 100;
+
+    private final Deque<Runnable> events = new ArrayDeque<>();
+    private final PriorityQueue<Timer> timers = new PriorityQueue<>((a, b) -> Long.compare(a.due, b.due));
+    private final Map<Integer, Timer> timersById = new HashMap<>();
+    private int nextTimerId = 1;
+    // The thread running callbacks, or null when the loop isn't running
+    private volatile Thread runner;
+
+    private static class Timer {
+        final int id;
+        final long interval; // 0 for a timer that runs once
+        final Runnable callback;
+        long due; // System.nanoTime()
+
+        Timer(int id, long interval, Runnable callback, long due) {
+            this.id = id;
+            this.interval = interval;
+            this.callback = callback;
+            this.due = due;
+        }
+    }
+
+    /**
+     * Runs the callback after the given number of milliseconds, and every
+     * time that many more pass when repeat is set; returns the timer's ID
+     */
+    public synchronized int setTimer(Runnable callback, long millis, boolean repeat) {
+        long nanos = Math.max(0, millis) * 1_000_000L;
+        // An interval of 0 would never let the loop finish a turn, so it runs at most once a millisecond
+        Timer timer = new Timer(nextTimerId++, repeat ? Math.max(nanos, 1_000_000L) : 0, callback, System.nanoTime() + nanos);
+        timers.add(timer);
+        timersById.put(timer.id, timer);
+        notifyAll();
+        return timer.id;
+    }
+
+    /**
+     * Stops a timer; unknown and finished IDs are ignored
+     */
+    public synchronized void clearTimer(int id) {
+        Timer timer = timersById.remove(id);
+        if (timer != null) {
+            timers.remove(timer);
+        }
+    }
+
+    /**
+     * Queues the callback to run on the loop and returns at once
+     */
+    public synchronized void post(Runnable callback) {
+        events.add(callback);
+        notifyAll();
+    }
+
+    /**
+     * Runs the callback on the loop and waits for it to finish. On the loop's
+     * own thread it runs at once, since waiting there would never end.
+     */
+    public void invoke(Runnable callback) {
+        if (Thread.currentThread() == runner) {
+            callback.run();
+            return;
+        }
+        Object done = new Object();
+        boolean[] finished = new boolean[1];
+        post(() -> {
+            try {
+                callback.run();
+            } finally {
+                synchronized (done) {
+                    finished[0] = true;
+                    done.notifyAll();
+                }
+            }
+        });
+        synchronized (done) {
+            while (!finished[0]) {
+                try {
+                    done.wait();
+                } catch (InterruptedException e) {
+                    Thread.currentThread().interrupt();
+                    return;
+                }
+            }
+        }
+    }
+
+    /**
+     * Runs callbacks until no events are queued and no timers are left
+     */
+    public void run(Cancellation cancellation) {
+        runWhile(null, cancellation);
+    }
+
+    /**
+     * Runs callbacks for as long as the condition holds, waiting for more
+     * when none are due; with a null condition, until no timers are left
+     */
+    public void runWhile(BooleanSupplier condition, Cancellation cancellation) {
+        Thread previous = runner;
+        runner = Thread.currentThread();
+        try {
+            Runnable next;
+            while ((next = next(condition, cancellation)) != null) {
+                next.run();
+            }
+        } finally {
+            runner = previous;
+        }
+    }
+
+    // The next callback to run, waiting until one is due; null once there is nothing left to wait for
+    private synchronized Runnable next(BooleanSupplier condition, Cancellation cancellation) {
+        while (true) {
+            cancellation.check();
+            // Queued events still run once the condition fails, since their senders may be waiting on them
+            if (!events.isEmpty()) {
+                return events.poll();
+            }
+            if (condition != null && !condition.getAsBoolean()) {
+                return null;
+            }
+            Timer timer = timers.peek();
+            long now = System.nanoTime();
+            if (timer != null && now - timer.due >= 0) {
+                timers.poll();
+                if (timer.interval > 0) {
+                    timer.due = now + timer.interval;
+                    timers.add(timer);
+                } else {
+                    timersById.remove(timer.id);
+                }
+                return timer.callback;
+            }
+            if (timer == null && condition == null) {
+                return null;
+            }
+            long waitMillis = timer != null ? Math.min(POLL_MILLIS, (timer.due - now) / 1_000_000L + 1) : POLL_MILLIS;
+            try {
+                wait(waitMillis);
+            } catch (InterruptedException e) {
+                Thread.currentThread().interrupt();
+                return null;
+            }
+        }
+    }
+}
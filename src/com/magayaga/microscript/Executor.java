@@ -8,11 +8,13 @@ package com.magayaga.microscript;
 
 import java.util.List;
 import java.util.ArrayList;
+import java.util.Map;
 import java.util.regex.Matcher;
 import java.util.regex.Pattern;
-import java.io.BufferedReader;
-import java.io.InputStreamReader;
 import java.util.Arrays;
+import java.util.ArrayDeque;
+import java.util.Collections;
+import java.util.Deque;
 import java.util.Scanner;
 
 public class Executor {
@@ -21,6 +23,11 @@ public class Executor {
     // Flag to track if we're inside a loop context
     private boolean inLoopContext = false;
 
+    // Statements deferred by the function body being run, or null outside one
+    private Deque<String> deferred;
+    // The function whose body is being run, or null outside one
+    private Function function;
+
     private static final Scanner scanner = new Scanner(System.in);
 
     // Pre-compiled regex patterns
@@ -29,10 +36,15 @@ public class Executor {
     private static final Pattern FUNCTION_CALL_PATTERN = Pattern.compile("(\\w+)\\((.*)\\)");
     private static final Pattern STRING_TEMPLATE_EXPR_PATTERN = Pattern.compile("\\{([^{}]+)\\}");
     private static final Pattern STRING_TEMPLATE_POSITIONAL_PATTERN = Pattern.compile("\\{\\}");
+    private static final Pattern IDENTIFIER_OR_CALL_PATTERN = Pattern.compile("[A-Za-z_]\\w*(\\(.*\\))?");
     private static final Pattern SWITCH_DETECT_PATTERN = Pattern.compile("^\\s*switch\\s*\\(.*\\)\\s*\\{?\\s*$");
     private static final Pattern DEFINE_FUNC_MACRO_PATTERN =
         Pattern.compile("#define\\s+([A-Z_][A-Z0-9_]*)\\s*\\(([^)]*)\\)\\s+(.+)");
     private static final Pattern CONSOLE_WRITEF_PATTERN = Pattern.compile("console\\.writef\\((.*)\\);");
+    private static final Pattern ELEMENT_ASSIGNMENT_PATTERN = Pattern.compile("^([A-Za-z_]\\w*)\\s*(\\[.+\\])\\s*=(?!=)(.*)$");
+    private static final Pattern FIELD_ASSIGNMENT_PATTERN = Pattern.compile("^([A-Za-z_]\\w*)\\.([A-Za-z_]\\w*)\\s*=(?!=)(.*)$");
+    private static final Pattern NULL_ASSIGNMENT_PATTERN = Pattern.compile("^([A-Za-z_]\\w*)\\s*\\?\\?=(.*)$");
+    private static final Pattern MEMBER_CHAIN_PATTERN = Pattern.compile("[A-Za-z_]\\w*(?:\\.[A-Za-z_]\\w*)*");
     
     // Patterns for increment/decrement operations
     private static final Pattern PRE_INCREMENT_PATTERN = Pattern.compile("\\+\\+([a-zA-Z_][a-zA-Z0-9_]*)\\s*;?");
@@ -43,6 +55,14 @@ public class Executor {
     // Pattern for input operation
     private static final Pattern INPUT_PATTERN = Pattern.compile("input\\((.*)\\)");
 
+    // drawRect(width: 10, height: 5) passes arguments by parameter name
+    private static final Pattern NAMED_ARGUMENT_PATTERN = Pattern.compile("^([A-Za-z_]\\w*)\\s*:(?!:)(.*)$", Pattern.DOTALL);
+
+    // defer f.close(); runs when the enclosing function returns or fails
+    private static final Pattern DEFER_PATTERN = Pattern.compile("defer\\s+(?!=)(.+)");
+    private static final Pattern STATIC_PATTERN = Pattern.compile("static\\s+(var\\s+([A-Za-z_]\\w*)\\s*(?::[^=]+)?=(?!=).+)");
+    private static final Pattern GLOBAL_PATTERN = Pattern.compile("global\\s+([A-Za-z_]\\w*(?:\\s*,\\s*[A-Za-z_]\\w*)*)\\s*;?");
+
     public Executor(Environment environment) {
         this.environment = environment;
     }
@@ -90,6 +110,38 @@ public class Executor {
                 throw new Statements.ContinueException();
             }
 
+            Matcher deferMatcher = DEFER_PATTERN.matcher(trimmed);
+            if (deferMatcher.matches()) {
+                if (deferred == null) {
+                    throw new RuntimeException("defer can only be used inside a function");
+                }
+                String statement = deferMatcher.group(1).trim();
+                deferred.push(statement.endsWith(";") ? statement : statement + ";");
+                return;
+            }
+
+            // global count; lets a function assign the script's count instead of a local
+            Matcher globalMatcher = GLOBAL_PATTERN.matcher(trimmed);
+            if (globalMatcher.matches()) {
+                if (deferred == null) {
+                    throw new RuntimeException("global can only be used inside a function");
+                }
+                for (String name : globalMatcher.group(1).split(",")) {
+                    environment.declareGlobal(name.trim());
+                }
+                return;
+            }
+
+            // static var calls: Int32 = 0; keeps its value from one call to the next
+            Matcher staticMatcher = STATIC_PATTERN.matcher(trimmed);
+            if (staticMatcher.matches()) {
+                if (function == null) {
+                    throw new RuntimeException("static can only be used inside a function");
+                }
+                declareStatic(staticMatcher.group(2), staticMatcher.group(1));
+                return;
+            }
+
             // Handle increment/decrement operations first
             if (handleIncrementDecrement(expression)) {
                 return;
@@ -152,18 +204,32 @@ public class Executor {
                     
                     while (placeholderMatcher.find()) {
                         String expr = placeholderMatcher.group(1).trim();
+                        // {price:.2f} formats the value by the spec after the colon
+                        int specStart = FormatSpec.specStart(expr);
+                        String spec = specStart >= 0 ? expr.substring(specStart) : null;
+                        String value = spec != null ? expr.substring(0, specStart - 1).trim() : expr;
                         
+                        Object result;
                         try {
                             // Evaluate the expression inside the braces
-                            Object result = evaluate(expr);
-                            // Replace with the string representation of the result
-                            placeholderMatcher.appendReplacement(output, result.toString().replace("$", "\\$"));
+                            result = evaluate(value);
                         }
                         
-                        catch (Exception e) {
+                        catch (RuntimeException e) {
+                            if (isUndefinedName(value, e)) {
+                                throw e;
+                            }
                             // If evaluation fails, leave the placeholder as is
                             placeholderMatcher.appendReplacement(output, "{" + expr + "}");
+                            continue;
+                        }
+                        if (result == null) {
+                            placeholderMatcher.appendReplacement(output, "{" + expr + "}");
+                            continue;
                         }
+                        // Replace with the string representation of the result
+                        String text = spec != null ? FormatSpec.format(result, spec) : result.toString();
+                        placeholderMatcher.appendReplacement(output, text.replace("\\", "\\\\").replace("$", "\\$"));
                     }
                     placeholderMatcher.appendTail(output);
                     
@@ -220,18 +286,32 @@ public class Executor {
                     
                     while (placeholderMatcher.find()) {
                         String expr = placeholderMatcher.group(1).trim();
+                        // {price:.2f} formats the value by the spec after the colon
+                        int specStart = FormatSpec.specStart(expr);
+                        String spec = specStart >= 0 ? expr.substring(specStart) : null;
+                        String value = spec != null ? expr.substring(0, specStart - 1).trim() : expr;
                         
+                        Object result;
                         try {
                             // Evaluate the expression inside the braces
-                            Object result = evaluate(expr);
-                            // Replace with the string representation of the result
-                            placeholderMatcher.appendReplacement(output, result.toString().replace("$", "\\$"));
+                            result = evaluate(value);
                         }
                         
-                        catch (Exception e) {
+                        catch (RuntimeException e) {
+                            if (isUndefinedName(value, e)) {
+                                throw e;
+                            }
                             // If evaluation fails, leave the placeholder as is
                             placeholderMatcher.appendReplacement(output, "{" + expr + "}");
+                            continue;
                         }
+                        if (result == null) {
+                            placeholderMatcher.appendReplacement(output, "{" + expr + "}");
+                            continue;
+                        }
+                        // Replace with the string representation of the result
+                        String text = spec != null ? FormatSpec.format(result, spec) : result.toString();
+                        placeholderMatcher.appendReplacement(output, text.replace("\\", "\\\\").replace("$", "\\$"));
                     }
                     placeholderMatcher.appendTail(output);
                     
@@ -259,8 +339,7 @@ public class Executor {
                 // Extract the command inside console.system()
                 Matcher matcher = CONSOLE_SYSTEM_PATTERN.matcher(expression);
                 if (matcher.matches()) {
-                    String command = matcher.group(1).trim();
-                    executeSystemCommand(command);
+                    executeSystemCommand(matcher.group(1).trim());
                 }
             }
             
@@ -271,49 +350,41 @@ public class Executor {
                 if (equalsIndex != -1) {
                     String varDeclaration = declaration.substring(0, equalsIndex).trim();
                     int typeSeparator = varDeclaration.lastIndexOf(':');
-                    if (typeSeparator == -1) {
+                    String varName = varDeclaration;
+                    String typeAnnotation = null; // Without an annotation the value's own type is kept
+                    if (typeSeparator != -1) {
+                        varName = varDeclaration.substring(0, typeSeparator).trim();
+                        typeAnnotation = varDeclaration.substring(typeSeparator + 1).trim();
+                    }
+                    if (varName.isEmpty()) {
                         throw new RuntimeException("Syntax error in variable declaration: " + expression);
                     }
-                    String varName = varDeclaration.substring(0, typeSeparator).trim();
-                    String typeAnnotation = varDeclaration.substring(typeSeparator + 1).trim();
                     String valueExpression = declaration.substring(equalsIndex + 1).trim().replace(";", "");
                     Object value;
 
+                    // Unpack a value: var (quotient, remainder) = divide(7, 2); var [first, ...rest] = xs;
+                    // var {name, age} = person;
+                    if (isPattern(varName)) {
+                        destructure(varName, evaluate(valueExpression));
+                        return;
+                    }
+
                     // Support struct initialization: var person: Person = {"Jane", 35.0};
-                    Struct structDefinition = environment.getStruct(typeAnnotation);
+                    Struct structDefinition = typeAnnotation != null ? environment.getStruct(erasure(typeAnnotation)) : null;
                     if (structDefinition != null && valueExpression.startsWith("{") && valueExpression.endsWith("}")) {
                         value = createStructInstance(structDefinition, valueExpression);
                     } else {
                         value = evaluate(valueExpression);
                     }
 
-                    // Ensure the value matches the type annotation
-                    switch (typeAnnotation) {
-                        case "String":
-                        case "Int32":
-                        case "Int64":
-                        case "Float32":
-                        case "Float64":
-                            value = coerceTypedValue(typeAnnotation, value, valueExpression);
-                            break;
-                        case "Char":
-                            if (!(value instanceof Character)) {
-                                throw new RuntimeException("Type error: " + valueExpression + " is not a Character.");
-                            }
-                            break;
-                        default:
-                            Struct structDef = environment.getStruct(typeAnnotation);
-                            if (structDef == null) {
-                                throw new RuntimeException("Unknown type annotation: " + typeAnnotation);
-                            }
-                            if (!(value instanceof Struct)) {
-                                throw new RuntimeException("Type error: " + valueExpression + " is not a struct instance.");
-                            }
-                            Struct structValue = (Struct) value;
-                            if (!typeAnnotation.equals(structValue.getName())) {
-                                throw new RuntimeException("Type error: struct instance type mismatch. Expected " +
-                                        typeAnnotation + " but got " + structValue.getName());
-                            }
+                    // Ensure the value matches the type annotation; String? and Int32 | null also take null
+                    if (typeAnnotation != null && value == null) {
+                        if (!isNullable(typeAnnotation)) {
+                            throw nullError("variable '" + varName + "'", typeAnnotation);
+                        }
+                    }
+                    else if (typeAnnotation != null) {
+                        value = checkType(typeAnnotation, value, "variable '" + varName + "'", Collections.emptyList());
                     }
                     
                     environment.setVariable(varName, value);
@@ -421,6 +492,27 @@ public class Executor {
                 // This will be processed in executeFunction
                 return;
             }
+
+            else if (ELEMENT_ASSIGNMENT_PATTERN.matcher(expression).matches()) {
+                // Handle list element assignment: xs[0] = 1; xs[-1] = 2; m[1][2] = 3;
+                Matcher matcher = ELEMENT_ASSIGNMENT_PATTERN.matcher(expression);
+                matcher.matches();
+                assignElement(matcher.group(1), matcher.group(2), stripSemicolon(matcher.group(3).trim()));
+            }
+
+            else if (FIELD_ASSIGNMENT_PATTERN.matcher(expression).matches()) {
+                // Handle field assignment: p.x = 1; self.count = count + 1; config.name = "demo";
+                Matcher matcher = FIELD_ASSIGNMENT_PATTERN.matcher(expression);
+                matcher.matches();
+                assignField(matcher.group(1), matcher.group(2), stripSemicolon(matcher.group(3).trim()));
+            }
+
+            else if (NULL_ASSIGNMENT_PATTERN.matcher(expression).matches()) {
+                // Handle null-coalescing assignment: name ??= "guest";
+                Matcher matcher = NULL_ASSIGNMENT_PATTERN.matcher(expression);
+                matcher.matches();
+                assignIfNull(matcher.group(1), stripSemicolon(matcher.group(2).trim()));
+            }
             
             else {
                 // Evaluate as a general expression (for variable assignments, etc.)
@@ -434,10 +526,25 @@ public class Executor {
         }
         
         catch (Exception e) {
+            // Strict mode stops the script; lenient mode reports the error and carries on
+            if (environment.isStrict() || environment.getCancellation().isCancelled()) {
+                throw e instanceof RuntimeException ? (RuntimeException) e : new RuntimeException(e.getMessage(), e);
+            }
             System.out.println("Evaluation error: " + e.getMessage());
         }
     }
 
+    /**
+     * Whether a failed template placeholder names a variable or function that
+     * doesn't exist, which strict mode reports instead of printing the
+     * placeholder as text
+     */
+    private boolean isUndefinedName(String expr, RuntimeException error) {
+        String message = error.getMessage();
+        return environment.isStrict() && IDENTIFIER_OR_CALL_PATTERN.matcher(expr).matches() && message != null
+            && (message.startsWith("Undefined variable") || message.startsWith("Function not found"));
+    }
+
     private Struct createStructInstance(Struct structDef, String valueExpression) {
         if (!valueExpression.startsWith("{") || !valueExpression.endsWith("}")) {
             throw new RuntimeException("Struct initialization must use {} syntax: " + valueExpression);
@@ -597,19 +704,32 @@ public class Executor {
         int level = 0;
         boolean inQuotes = false;
         
+        int quoteStart = -1;
+        
         for (int i = 0; i < content.length(); i++) {
             char c = content.charAt(i);
             
             if (c == '"' && (i == 0 || content.charAt(i - 1) != '\\')) {
                 inQuotes = !inQuotes;
+                quoteStart = i;
             }
             
             else if (!inQuotes) {
-                if (c == '(') {
+                int literal = Define.charLiteralLength(content, i);
+                int lambdaEnd = c == '|' ? ArrowFunction.lambdaEnd(content, i) : -1;
+                if (literal > 0) {
+                    i += literal - 1; // '"' and '(' are characters, not quotes or brackets
+                }
+                
+                else if (lambdaEnd > i) {
+                    i = lambdaEnd - 1; // A lambda's parameters and body hold commas of their own
+                }
+                
+                else if (c == '(' || c == '[') {
                     level++;
                 }
                 
-                else if (c == ')') {
+                else if (c == ')' || c == ']') {
                     level--;
                 }
                 
@@ -620,6 +740,11 @@ public class Executor {
             }
         }
         
+        if (inQuotes) {
+            throw new RuntimeException("Unterminated string literal: no closing quote for the string starting at "
+                + content.substring(quoteStart));
+        }
+        
         // Add the last argument
         if (start < content.length()) {
             result.add(content.substring(start).trim());
@@ -628,114 +753,561 @@ public class Executor {
         return result;
     }
 
-    private void executeSystemCommand(String command) throws Exception {
-        String[] cmdArray = command.split(" ");
-        Process process = new ProcessBuilder(cmdArray).start();
-        BufferedReader reader = new BufferedReader(new InputStreamReader(process.getInputStream()));
-        String line;
-        while ((line = reader.readLine()) != null) {
-            System.out.println(line);
+    /**
+     * Stores a value in a list element; a negative index counts from the end.
+     * Each index after the first selects from the element before it, so
+     * m[1][2] is the third element of m's second row.
+     */
+    private void assignElement(String name, String indices, String valueExpression) {
+        Object target = environment.getVariable(name);
+        if (target == null) {
+            throw new RuntimeException("Undefined variable: " + name);
+        }
+        List<String> indexExpressions = splitIndices(indices);
+        String path = name;
+        for (int i = 0; i < indexExpressions.size(); i++) {
+            if (!(target instanceof ListVariable)) {
+                String kind = target instanceof Tuple ? "tuple" : target instanceof List ? "collection" : "non-list variable";
+                throw new RuntimeException("Cannot assign to an element of " + kind + ": " + path);
+            }
+            ListVariable list = (ListVariable) target;
+            int position = ListVariable.position(list, evaluate(indexExpressions.get(i)));
+            if (i == indexExpressions.size() - 1) {
+                list.set(position, evaluate(valueExpression));
+            } else {
+                target = list.get(position);
+                path += "[" + indexExpressions.get(i) + "]";
+            }
+        }
+    }
+
+    /**
+     * Stores a value in a struct field or map key. Readonly fields and
+     * frozen values refuse the change.
+     */
+    @SuppressWarnings("unchecked")
+    private void assignField(String name, String field, String valueExpression) {
+        Object target = environment.getVariable(name);
+        if (target == null) {
+            throw new RuntimeException("Undefined variable: " + name);
+        }
+        if (target instanceof Struct) {
+            ((Struct) target).setField(field, evaluate(valueExpression));
+        } else if (target instanceof Map) {
+            ((Map<String, Object>) target).put(field, evaluate(valueExpression));
+        } else {
+            throw new RuntimeException("Cannot assign to field '" + field + "' of " + name + ": it is not a struct or map");
+        }
+    }
+
+    /**
+     * Assigns a variable only when it holds null; the value isn't evaluated
+     * otherwise
+     */
+    private void assignIfNull(String name, String valueExpression) {
+        if (!environment.hasVariable(name)) {
+            throw new RuntimeException("Undefined variable: " + name);
+        }
+        if (environment.getVariable(name) == null) {
+            environment.setVariable(name, evaluate(valueExpression));
+        }
+    }
+
+    /**
+     * Binds a static variable in this call, running its declaration the first
+     * time any call of the function reaches it
+     */
+    private void declareStatic(String name, String declaration) {
+        Environment statics = function.getStatics(environment);
+        synchronized (statics) {
+            // Only the statics' own names count, not globals of the same name in the root above them
+            if (!statics.hasLocalVariable(name)) {
+                // The initializer sees the first call's parameters and locals
+                Environment scope = new Environment(environment);
+                nested(scope, false).execute(declaration);
+                statics.setVariable(name, scope.getVariable(name));
+                scope.release();
+            }
+        }
+        environment.declareStatic(name, statics);
+    }
+
+    // Splits "[1][i + 1]" into its index expressions
+    private static List<String> splitIndices(String indices) {
+        List<String> result = new ArrayList<>();
+        int depth = 0;
+        int start = 0;
+        boolean inQuotes = false;
+        for (int i = 0; i < indices.length(); i++) {
+            char c = indices.charAt(i);
+            if (c == '"' && (i == 0 || indices.charAt(i - 1) != '\\')) {
+                inQuotes = !inQuotes;
+            } else if (inQuotes) {
+                continue;
+            } else if (c == '[') {
+                if (depth++ == 0) {
+                    start = i + 1;
+                }
+            } else if (c == ']' && --depth == 0) {
+                result.add(indices.substring(start, i).trim());
+            } else if (depth == 0 && !Character.isWhitespace(c)) {
+                throw new RuntimeException("Syntax error in element assignment: " + indices);
+            }
+        }
+        if (depth != 0) {
+            throw new RuntimeException("Missing ']' in element assignment: " + indices);
+        }
+        return result;
+    }
+
+    /**
+     * Whether a declared name is a destructuring pattern: (a, b), [a, b] or
+     * {name, age}
+     */
+    static boolean isPattern(String name) {
+        return (name.startsWith("(") && name.endsWith(")")) || (name.startsWith("[") && name.endsWith("]"))
+            || (name.startsWith("{") && name.endsWith("}"));
+    }
+
+    /**
+     * Binds the names in a destructuring pattern. (a, b) takes every element
+     * of a tuple or list by position, [a, b] takes the first elements of a
+     * list, and {name, age} takes struct fields or map keys by name. _ skips
+     * an element and ...rest collects the ones left over.
+     */
+    void destructure(String pattern, Object value) {
+        List<String> names = splitArguments(pattern.substring(1, pattern.length() - 1));
+        if (pattern.startsWith("{")) {
+            destructureFields(pattern, names, value);
+            return;
+        }
+        if (!(value instanceof List)) {
+            throw new RuntimeException("Type error: cannot unpack " + value + " into " + pattern);
+        }
+        List<?> items = (List<?>) value;
+        boolean rest = !names.isEmpty() && names.get(names.size() - 1).startsWith("...");
+        int fixed = rest ? names.size() - 1 : names.size();
+        if (pattern.startsWith("(") && !rest && items.size() != names.size()) {
+            throw new RuntimeException("Cannot unpack " + items.size() + " value" + (items.size() == 1 ? "" : "s")
+                + " into " + names.size() + " name" + (names.size() == 1 ? "" : "s") + ": " + pattern);
+        }
+        if (items.size() < fixed) {
+            throw new RuntimeException("Cannot unpack " + pattern + ": index " + items.size() + " ('"
+                + names.get(items.size()) + "') is out of range for length " + items.size());
+        }
+        for (int i = 0; i < fixed; i++) {
+            bindName(pattern, names.get(i), items.get(i));
+        }
+        if (rest) {
+            bindName(pattern, names.get(fixed).substring(3).trim(), ListVariable.slice(items, fixed, null));
+        }
+    }
+
+    private void destructureFields(String pattern, List<String> names, Object value) {
+        for (String name : names) {
+            Object field;
+            if (value instanceof Struct) {
+                Struct struct = (Struct) value;
+                if (!struct.getFields().containsKey(name)) {
+                    throw new RuntimeException("Cannot unpack " + pattern + ": struct " + struct.getName()
+                        + " has no field '" + name + "'");
+                }
+                field = struct.getField(name);
+            } else if (value instanceof Map) {
+                Map<?, ?> map = (Map<?, ?>) value;
+                if (!map.containsKey(name)) {
+                    throw new RuntimeException("Cannot unpack " + pattern + ": the map has no key '" + name + "'");
+                }
+                field = map.get(name);
+            } else {
+                throw new RuntimeException("Type error: cannot unpack " + value + " into " + pattern
+                    + "; expected a struct or map");
+            }
+            bindName(pattern, name, field);
+        }
+    }
+
+    private void bindName(String pattern, String name, Object value) {
+        if (!name.matches("\\w+")) {
+            throw new RuntimeException("Syntax error: invalid name '" + name + "' in " + pattern);
+        }
+        if (!name.equals("_")) {
+            environment.setVariable(name, value);
+        }
+    }
+
+    // The command is usually a string expression, but older scripts give it
+    // bare, as in console.system(ls -la), so text that doesn't evaluate to a
+    // string is run as written
+    private void executeSystemCommand(String content) throws Exception {
+        List<String> arguments = splitArguments(content);
+        Object command;
+        try {
+            command = arguments.isEmpty() ? null : evaluate(arguments.get(0));
+        } catch (RuntimeException e) {
+            command = null;
+        }
+        if (!(command instanceof String)) {
+            SystemCommand.run(content, null);
+            return;
+        }
+        List<Object> options = new ArrayList<>();
+        for (String argument : arguments.subList(1, arguments.size())) {
+            options.add(evaluate(argument));
+        }
+        SystemCommand.run((String) command, SystemCommand.options(options));
+    }
+
+    /**
+     * Call a function with already evaluated argument values, used when native
+     * code such as the HTTP server calls back into a script
+     * @param functionName The function to call
+     * @param values The argument values
+     * @return The function's return value
+     */
+    public Object callFunction(String functionName, Object... values) {
+        return callIn(new Environment(environment), functionName, values);
+    }
+
+    /**
+     * Calls a method defined in a struct body. Inside the method the struct's
+     * fields can be read by name, and self is the struct itself.
+     */
+    public Object callMethod(Struct target, String methodName, Object... values) {
+        Function method = target.getMethod(methodName);
+        if (method == null) {
+            throw new RuntimeException("Struct " + target.getName() + " has no method " + methodName);
+        }
+        Environment methodEnv = new Environment(environment);
+        for (Map.Entry<String, Object> field : target.getValues().entrySet()) {
+            methodEnv.setVariable(field.getKey(), field.getValue());
+        }
+        methodEnv.setVariable("self", target);
+        methodEnv.defineFunction(method);
+        try {
+            return new Executor(methodEnv).callFunction(methodName, values);
+        } finally {
+            methodEnv.release();
+        }
+    }
+
+    /**
+     * Calls a function value, such as a lambda or a function passed by name,
+     * with already evaluated argument values
+     */
+    public Object call(Function function, Object... values) {
+        Environment callEnv = new Environment(environment);
+        callEnv.setVariable("__callee", function);
+        return callIn(callEnv, "__callee", values);
+    }
+
+    private static Object callIn(Environment callEnv, String functionName, Object[] values) {
+        String[] args = new String[values.length];
+        for (int i = 0; i < values.length; i++) {
+            args[i] = "__arg" + i;
+            callEnv.setVariable(args[i], values[i]);
+        }
+        try {
+            return new Executor(callEnv).executeFunction(functionName, args);
+        } finally {
+            callEnv.release();
         }
-        reader.close();
     }
-    
-    
 
     public Object executeFunction(String functionName, String[] args) {
+        // f(...xs) passes the elements of xs as separate arguments
+        if (args != null && Arrays.stream(args).anyMatch(arg -> arg.trim().startsWith("..."))) {
+            List<Object> values = new ArrayList<>();
+            for (String arg : args) {
+                String trimmed = arg.trim();
+                if (trimmed.startsWith("...")) {
+                    values.addAll(ListVariable.spread(evaluate(trimmed.substring(3).trim()), trimmed));
+                } else {
+                    values.add(evaluate(trimmed));
+                }
+            }
+            return callFunction(functionName, values.toArray());
+        }
         Function function = environment.getFunction(functionName);
         if (function != null) {
             List<Parameter> parameters = function.getParameters();
             if (args == null) {
                 args = new String[0]; // Ensure args is not null
             }
+            List<String> names = new ArrayList<>();
+            List<String> expressions = new ArrayList<>();
+            for (String arg : args) {
+                Matcher named = NAMED_ARGUMENT_PATTERN.matcher(arg.trim());
+                names.add(named.matches() ? named.group(1) : null);
+                expressions.add(named.matches() ? named.group(2).trim() : arg);
+            }
+            if (names.stream().anyMatch(name -> name != null)) {
+                args = bindArguments(function, names, expressions).toArray(new String[0]);
+            }
             if (parameters.size() != args.length) {
-                throw new RuntimeException("Argument count mismatch for function: " + functionName);
+                throw argumentCountError(function, args.length);
             }
 
-            Environment localEnv = new Environment(environment);
+            Object[] values = new Object[args.length];
             for (int i = 0; i < args.length; i++) {
                 Object value = evaluate(args[i]);
                 String expectedType = parameters.get(i).getType();
+                String subject = "parameter '" + parameters.get(i).getName() + "' of " + declaration(function);
+                if (value == null) {
+                    if (!isNullable(expectedType)) {
+                        throw nullError(subject, expectedType);
+                    }
+                    continue;
+                }
                 // Ensure the value matches the expected type
-                switch (expectedType) {
-                    case "String":
-                    case "Int32":
-                    case "Int64":
-                    case "Float32":
-                    case "Float64":
-                        value = coerceTypedValue(expectedType, value, "Argument " + args[i]);
-                        break;
-                    case "Char":
-                        if (!(value instanceof Character)) {
-                            throw new RuntimeException("Type error: Argument " + args[i] + " is not a Character.");
-                        }
-                        break;
-                    default:
-                        throw new RuntimeException("Unknown type annotation: " + expectedType);
+                value = checkType(expectedType, value, subject, function.getTypeParameters());
+                values[i] = value;
+            }
+
+            // Inline lambdas see the variables where they were written, and functions
+            // exported from a module see the module's private functions and globals
+            Environment scope = function instanceof ArrowFunction && ((ArrowFunction) function).getClosure() != null
+                ? ((ArrowFunction) function).getClosure()
+                : function.getModule() != null ? function.getModule() : environment;
+            Environment localEnv = new Environment(scope);
+            Debugger debugger = localEnv.getDebugger();
+            Tracer tracer = localEnv.getTracer();
+            long enteredAt = System.nanoTime();
+            String caller = tracer != null ? tracer.enter(function.getName()) : null;
+            try {
+                localEnv.enterCall(function.getName());
+                for (int i = 0; i < values.length; i++) {
+                    localEnv.setVariable(parameters.get(i).getName(), values[i]);
+                }
+                if (debugger != null) {
+                    debugger.enterFunction(function.getName(), localEnv);
                 }
-                localEnv.setVariable(parameters.get(i).getName(), value);
+                return executeBody(function, localEnv);
+            } finally {
+                if (debugger != null) {
+                    debugger.exitFunction();
+                }
+                if (tracer != null) {
+                    tracer.exit(function.getName(), caller, enteredAt);
+                }
+                // Drop the call's locals as soon as it returns
+                localEnv.release();
+            }
+        }
+        // Support for native functions (Import.FunctionInterface)
+        Object nativeFunc = environment.getVariable(functionName);
+        if (nativeFunc instanceof Import.FunctionInterface) {
+            Object[] evaluatedArgs = new Object[args == null ? 0 : args.length];
+            for (int i = 0; i < evaluatedArgs.length; i++) {
+                evaluatedArgs[i] = evaluate(args[i]);
             }
+            return ((Import.FunctionInterface) nativeFunc).call(evaluatedArgs);
+        }
+        // mutex() creates a lock for protecting state shared between tasks
+        if (functionName.equals("mutex")) {
+            if (args != null && args.length != 0) throw new RuntimeException("mutex expects no arguments");
+            return new Mutex();
+        }
+        // stack(), queue() and deque() create empty collections
+        if (functionName.equals("stack") || functionName.equals("queue") || functionName.equals("deque")) {
+            if (args != null && args.length != 0) throw new RuntimeException(functionName + " expects no arguments");
+            return new CollectionVariable(CollectionVariable.Kind.valueOf(functionName.toUpperCase()));
+        }
+        // range(), the conversions, freeze() and the reflection builtins take evaluated values
+        if (Builtins.has(functionName)) {
+            Object[] evaluatedArgs = new Object[args == null ? 0 : args.length];
+            for (int i = 0; i < evaluatedArgs.length; i++) {
+                evaluatedArgs[i] = evaluate(args[i].trim());
+            }
+            return Builtins.call(functionName, evaluatedArgs, environment);
+        }
+        // docs(name) returns the /// comment written above a function
+        if (functionName.equals("docs")) {
+            if (args == null || args.length != 1) throw new RuntimeException("docs expects 1 argument: a function name");
+            String name = args[0].trim();
+            if (name.startsWith("\"") && name.endsWith("\"") && name.length() >= 2) {
+                name = name.substring(1, name.length() - 1);
+            }
+            Function documented = environment.getFunction(name);
+            if (documented == null) {
+                throw new RuntimeException("Function not found: " + name);
+            }
+            return documented.getDocs();
+        }
+        // Support for higher-order functions: map, filter, foldlt, foldrt
+        if (functionName.equals("map")) {
+            if (args.length != 2) throw new RuntimeException("map expects 2 arguments: lambda, list");
+            // Lambda as string, list as comma-separated string
+            String lambda = args[0];
+            String listStr = args[1];
+            List<Object> list = new ArrayList<>();
+            for (String s : listStr.replace("[","").replace("]","").split(",")) {
+                list.add(evaluate(s.trim()));
+            }
+            java.util.function.Function<Object, Object> fn = new Parser(new ArrayList<>()).makeUnaryLambda(lambda, this);
+            return FunctionHigherOrder.map(fn, list);
+        } else if (functionName.equals("filter")) {
+            if (args.length != 2) throw new RuntimeException("filter expects 2 arguments: lambda, list");
+            String lambda = args[0];
+            String listStr = args[1];
+            List<Object> list = new ArrayList<>();
+            for (String s : listStr.replace("[","").replace("]","").split(",")) {
+                list.add(evaluate(s.trim()));
+            }
+            java.util.function.Function<Object, Boolean> pred = new Parser(new ArrayList<>()).makePredicateLambda(lambda, this);
+            return FunctionHigherOrder.filter(pred, list);
+        } else if (functionName.equals("foldlt")) {
+            if (args.length != 3) throw new RuntimeException("foldlt expects 3 arguments: lambda, initial, list");
+            String lambda = args[0];
+            Object initial = evaluate(args[1]);
+            String listStr = args[2];
+            List<Object> list = new ArrayList<>();
+            for (String s : listStr.replace("[","").replace("]","").split(",")) {
+                list.add(evaluate(s.trim()));
+            }
+            java.util.function.BiFunction<Object, Object, Object> bifn = new Parser(new ArrayList<>()).makeBinaryLambda(lambda, this);
+            return FunctionHigherOrder.foldlt(bifn, initial, list);
+        } else if (functionName.equals("foldrt")) {
+            if (args.length != 3) throw new RuntimeException("foldrt expects 3 arguments: lambda, initial, list");
+            String lambda = args[0];
+            Object initial = evaluate(args[1]);
+            String listStr = args[2];
+            List<Object> list = new ArrayList<>();
+            for (String s : listStr.replace("[","").replace("]","").split(",")) {
+                list.add(evaluate(s.trim()));
+            }
+            java.util.function.BiFunction<Object, Object, Object> bifn = new Parser(new ArrayList<>()).makeBinaryLambda(lambda, this);
+            return FunctionHigherOrder.foldrt(bifn, initial, list);
+        }
+        throw new RuntimeException("Function not found: " + functionName);
+    }
 
-            Object returnValue = null;
-            List<String> body = function.getBody();
-            // Process function body, handling control flow structures like if/else
-            for (int i = 0; i < body.size(); i++) {
-                String line = body.get(i).trim();
-                try {
+    /**
+     * Runs a function's body, then the statements it deferred, last first,
+     * whether it returned or failed. A deferred statement's error replaces a
+     * return value but not an error the body already raised.
+     */
+    private Object executeBody(Function function, Environment localEnv) {
+        Deque<String> deferred = new ArrayDeque<>();
+        Executor bodyExecutor = new Executor(localEnv, false);
+        bodyExecutor.deferred = deferred;
+        bodyExecutor.function = function;
+        Object returnValue;
+        try {
+            returnValue = executeStatements(function, localEnv, bodyExecutor);
+        } catch (RuntimeException e) {
+            runDeferred(deferred, bodyExecutor, e);
+            throw e;
+        }
+        runDeferred(deferred, bodyExecutor, null);
+        return returnValue;
+    }
+
+    /**
+     * An executor for a block inside the function this one runs, so defer,
+     * global and static in the block still reach the function
+     */
+    private Executor nested(Environment blockEnvironment, boolean inLoop) {
+        Executor executor = new Executor(blockEnvironment, inLoop);
+        executor.deferred = deferred;
+        executor.function = function;
+        return executor;
+    }
+
+    private static void runDeferred(Deque<String> deferred, Executor executor, RuntimeException failure) {
+        RuntimeException error = null;
+        while (!deferred.isEmpty()) {
+            String statement = deferred.pop();
+            try {
+                executor.execute(statement);
+            } catch (RuntimeException e) {
+                if (failure != null) {
+                    failure.addSuppressed(e);
+                } else if (error == null) {
+                    error = e;
+                }
+            }
+        }
+        if (error != null) {
+            throw error;
+        }
+    }
+
+    private Object executeStatements(Function function, Environment localEnv, Executor bodyExecutor) {
+        Object returnValue = null;
+        List<String> body = function.getBody();
+        List<Statement> statements = function.getStatements();
+        // One executor for statements and one for loop bodies, shared across the whole call
+        Executor loopExecutor = bodyExecutor.nested(localEnv, true);
+        Debugger debugger = function.getLine() >= 0 ? localEnv.getDebugger() : null;
+        Tracer tracer = function.getLine() >= 0 ? localEnv.getTracer() : null;
+        Cancellation cancellation = localEnv.getCancellation();
+        // Process function body, handling control flow structures like if/else
+        for (int i = 0; i < statements.size(); i++) {
+            Statement statement = statements.get(i);
+            String line = statement.getText();
+            if (statement.getKind() != Statement.Kind.SKIP) {
+                cancellation.check();
+                if (tracer != null) {
+                    tracer.statement(function.getLine() + 1 + i);
+                }
+                if (debugger != null) {
+                    debugger.beforeStatement(function.getLine() + 1 + i);
+                }
+            }
+            try {
+                switch (statement.getKind()) {
                     // Skip empty lines and comments
-                    if (line.isEmpty() || line.startsWith("//")) {
+                    case SKIP:
                         continue;
-                    }
 
                     // Handle break/continue - they should never bubble up to function level
-                    if (line.equals("break;") || line.equals("break") ||
-                        line.equals("continue;") || line.equals("continue")) {
+                    case BREAK_CONTINUE:
                         throw new RuntimeException("Break/continue statements are only allowed inside loops");
-                    }
-                    
+
                     // Handle if statements
-                    if (line.startsWith("if")) {
+                    case IF: {
                         try {
                             // Use the Statements class to process the conditional
-                            int newIndex = Statements.processConditionalStatement(body, i, new Executor(localEnv, false));
+                            int newIndex = Statements.processConditionalStatement(body, i, bodyExecutor);
                             i = newIndex - 1; // -1 because the loop will increment i
                             continue;
                         } catch (Statements.BreakException | Statements.ContinueException e) {
                             throw new RuntimeException("Break/continue statements are only allowed inside loops");
                         }
                     }
-                    
+
                     // Handle for loops
-                    if (line.startsWith("for")) {
-                        int newIndex = ForLoop.processForLoop(body, i, new Executor(localEnv, true));
+                    case FOR: {
+                        int newIndex = ForLoop.processForLoop(body, i, loopExecutor);
                         i = newIndex - 1;
                         continue;
                     }
-                    
+
                     // Handle while loops
-                    if (line.startsWith("while")) {
-                        int newIndex = Loop.processLoop(body, i, new Executor(localEnv, true));
+                    case WHILE: {
+                        int newIndex = Loop.processLoop(body, i, loopExecutor);
                         i = newIndex - 1;
                         continue;
                     }
 
                     // Handle @map statements
-                    if (line.startsWith("@map")) {
+                    case MAP: {
                         // Use the Parser to handle the @map operation
                         Parser parser = new Parser(new ArrayList<>(), localEnv);
-                        parser.parseMapOperation(line, new Executor(localEnv));
+                        parser.parseMapOperation(line, bodyExecutor);
                         i++;
                         continue;
                     }
 
                     // Handle @__globalfn__ blocks
-                    if (line.startsWith("@__globalfn__")) {
+                    case GLOBAL_FN: {
                         // Find the closing brace for the @__globalfn__ block
                         int endIndex = i + 1;
                         int braceLevel = 1;
-                        
+
                         while (endIndex < body.size() && braceLevel > 0) {
-                            String bodyLine = body.get(endIndex).trim();
+                            String bodyLine = statements.get(endIndex).getText();
                             if (bodyLine.equals("{")) {
                                 braceLevel++;
                             } else if (bodyLine.equals("}")) {
@@ -751,126 +1323,87 @@ public class Executor {
                             }
                             endIndex++;
                         }
-                        
+
                         // Process the @__globalfn__ block
                         List<String> globalFnBlock = new ArrayList<>();
                         for (int j = i + 1; j < endIndex; j++) {
-                            String blockLine = body.get(j).trim();
+                            String blockLine = statements.get(j).getText();
                             if (!blockLine.equals("}") && !blockLine.isEmpty()) {
                                 globalFnBlock.add(blockLine);
                             }
                         }
-                        
+
                         // Create a parser to handle the @__globalfn__ block with local environment
                         Parser parser = new Parser(globalFnBlock, localEnv);
                         parser.parseGlobalFunctionBlock(0, globalFnBlock.size());
-                        
+
                         i = endIndex; // Skip to after the block
                         continue;
                     }
 
                     // Handle switch statements
-                    if (line.startsWith("switch")) {
+                    case SWITCH: {
                         // Process the switch statement
-                        int newIndex = Switch.processSwitchStatement(body, i, new Executor(localEnv, false));
-                        
+                        int newIndex = Switch.processSwitchStatement(body, i, bodyExecutor);
+
                         // Ensure we're making progress
                         if (newIndex <= i) {
                             throw new RuntimeException("Error processing switch statement at line: " + line);
                         }
-                        
+
                         i = newIndex - 1; // -1 because the loop will increment i
                         continue;
                     }
-                    
+
+                    // Handle with blocks, which close their value however they end
+                    case WITH: {
+                        int newIndex = With.processWithStatement(body, i, bodyExecutor);
+                        i = newIndex - 1;
+                        continue;
+                    }
+
                     // Handle return statements
-                    if (line.startsWith("return")) {
-                        String returnExpression = line.substring(line.indexOf("return") + 6).trim().replace(";", "");
+                    case RETURN: {
                         // Evaluate complex expressions in return statements
-                        returnValue = new Executor(localEnv).evaluate(returnExpression);
+                        // Several values come back as a tuple: return quotient, remainder;
+                        List<String> values = bodyExecutor.splitArguments(statement.getExpression());
+                        if (values.size() > 1) {
+                            List<Object> items = new ArrayList<>();
+                            for (String value : values) {
+                                items.add(bodyExecutor.evaluate(value));
+                            }
+                            returnValue = new Tuple(items);
+                        } else {
+                            returnValue = bodyExecutor.evaluate(statement.getExpression());
+                        }
                         // Ensure the return value matches the expected return type
                         String expectedReturnType = function.getReturnType();
-                        switch (expectedReturnType) {
-                            case "String":
-                            case "Int32":
-                            case "Int64":
-                            case "Float32":
-                            case "Float64":
-                                returnValue = coerceTypedValue(expectedReturnType, returnValue, "Return value " + returnValue);
-                                break;
-                            case "Char":
-                                if (!(returnValue instanceof Character)) {
-                                    throw new RuntimeException("Type error: Return value " + returnValue + " is not a Character.");
-                                }
-                                break;
-                            default:
-                                throw new RuntimeException("Unknown return type annotation: " + expectedReturnType);
+                        if (returnValue == null) {
+                            if (!isNullable(expectedReturnType) && !expectedReturnType.equals("void")) {
+                                throw nullError("return value of " + declaration(function), expectedReturnType);
+                            }
+                            return null;
                         }
+                        returnValue = checkType(expectedReturnType, returnValue, "return value of " + declaration(function),
+                            function.getTypeParameters());
                         return returnValue; // Exit the function immediately after return
                     }
-                    // Use a local executor to ensure variable modifications are retained
-                    new Executor(localEnv, false).execute(line); // Pass the already trimmed line
-                } catch (Statements.BreakException | Statements.ContinueException e) {
-                    throw new RuntimeException("Break/continue statements are only allowed inside loops");
+
+                    default:
+                        // Use the local executor to ensure variable modifications are retained
+                        bodyExecutor.execute(line); // Pass the already trimmed line
                 }
+            } catch (Statements.BreakException | Statements.ContinueException e) {
+                throw lineError(new RuntimeException("Break/continue statements are only allowed inside loops"), function, i);
+            } catch (RuntimeException e) {
+                throw lineError(e, function, i);
             }
-            return returnValue;
-        }
-        // Support for native functions (Import.FunctionInterface)
-        Object nativeFunc = environment.getVariable(functionName);
-        if (nativeFunc instanceof Import.FunctionInterface) {
-            Object[] evaluatedArgs = new Object[args == null ? 0 : args.length];
-            for (int i = 0; i < evaluatedArgs.length; i++) {
-                evaluatedArgs[i] = evaluate(args[i]);
-            }
-            return ((Import.FunctionInterface) nativeFunc).call(evaluatedArgs);
         }
-        // Support for higher-order functions: map, filter, foldlt, foldrt
-        if (functionName.equals("map")) {
-            if (args.length != 2) throw new RuntimeException("map expects 2 arguments: lambda, list");
-            // Lambda as string, list as comma-separated string
-            String lambda = args[0];
-            String listStr = args[1];
-            List<Object> list = new ArrayList<>();
-            for (String s : listStr.replace("[","").replace("]","").split(",")) {
-                list.add(evaluate(s.trim()));
-            }
-            java.util.function.Function<Object, Object> fn = new Parser(new ArrayList<>()).makeUnaryLambda(lambda, this);
-            return FunctionHigherOrder.map(fn, list);
-        } else if (functionName.equals("filter")) {
-            if (args.length != 2) throw new RuntimeException("filter expects 2 arguments: lambda, list");
-            String lambda = args[0];
-            String listStr = args[1];
-            List<Object> list = new ArrayList<>();
-            for (String s : listStr.replace("[","").replace("]","").split(",")) {
-                list.add(evaluate(s.trim()));
-            }
-            java.util.function.Function<Object, Boolean> pred = new Parser(new ArrayList<>()).makePredicateLambda(lambda, this);
-            return FunctionHigherOrder.filter(pred, list);
-        } else if (functionName.equals("foldlt")) {
-            if (args.length != 3) throw new RuntimeException("foldlt expects 3 arguments: lambda, initial, list");
-            String lambda = args[0];
-            Object initial = evaluate(args[1]);
-            String listStr = args[2];
-            List<Object> list = new ArrayList<>();
-            for (String s : listStr.replace("[","").replace("]","").split(",")) {
-                list.add(evaluate(s.trim()));
-            }
-            java.util.function.BiFunction<Object, Object, Object> bifn = new Parser(new ArrayList<>()).makeBinaryLambda(lambda, this);
-            return FunctionHigherOrder.foldlt(bifn, initial, list);
-        } else if (functionName.equals("foldrt")) {
-            if (args.length != 3) throw new RuntimeException("foldrt expects 3 arguments: lambda, initial, list");
-            String lambda = args[0];
-            Object initial = evaluate(args[1]);
-            String listStr = args[2];
-            List<Object> list = new ArrayList<>();
-            for (String s : listStr.replace("[","").replace("]","").split(",")) {
-                list.add(evaluate(s.trim()));
-            }
-            java.util.function.BiFunction<Object, Object, Object> bifn = new Parser(new ArrayList<>()).makeBinaryLambda(lambda, this);
-            return FunctionHigherOrder.foldrt(bifn, initial, list);
-        }
-        throw new RuntimeException("Function not found: " + functionName);
+        return returnValue;
+    }
+
+    private static RuntimeException lineError(RuntimeException error, Function function, int index) {
+        return function.getLine() >= 0 ? ScriptException.at(error, function.getLine() + 1 + index) : error;
     }
 
     public Object evaluate(String expression) {
@@ -880,7 +1413,7 @@ public class Executor {
         }
 
         // Check if the expression is a string literal
-        if (expression.startsWith("\"") && expression.endsWith("\"")) {
+        if (isStringLiteral(expression)) {
             return expression.substring(1, expression.length() - 1);
         }
 
@@ -889,12 +1422,35 @@ public class Executor {
             return expression.charAt(1);
         }
 
+        // An inline lambda: |Int32: x| => x * 2
+        if (expression.startsWith("|")) {
+            String lambda = stripSemicolon(expression.trim());
+            if (ArrowFunction.lambdaEnd(lambda, 0) == lambda.length()) {
+                return ArrowFunction.lambda(lambda, environment);
+            }
+        }
+
+        // spawn f(args) starts the call on another thread and returns a Task
+        if (expression.startsWith("spawn ")) {
+            return spawn(stripSemicolon(expression.substring(6).trim()));
+        }
+
+        // await task blocks until the task finishes and returns its result
+        if (expression.startsWith("await ")) {
+            Object task = evaluate(stripSemicolon(expression.substring(6).trim()));
+            if (!(task instanceof Task)) {
+                throw new RuntimeException("await expects a task started with spawn, got: " + task);
+            }
+            return ((Task) task).await();
+        }
+
         // Check if the expression is a function call
         Matcher matcher = FUNCTION_CALL_PATTERN.matcher(expression);
         if (matcher.matches()) {
             String functionName = matcher.group(1);
             String args = matcher.group(2).trim();
-            String[] arguments = args.isEmpty() ? new String[0] : splitByCommaWithTrim(args).toArray(new String[0]);
+            // Split respecting quotes, brackets and lambdas, so f(g(1, 2), |x| => x) has two arguments
+            String[] arguments = args.isEmpty() ? new String[0] : splitArguments(args).toArray(new String[0]);
             return executeFunction(functionName, arguments);
         }
         
@@ -921,6 +1477,26 @@ public class Executor {
             return sqrtFunc.call(new Object[]{arg});
         }
         
+        // Built-in runtime introspection
+        if (expression.equals("runtime.memory()")) {
+            return environment.getMemory().snapshot();
+        }
+
+        // Atomic updates of shared variables: atomic.add(counter, 1)
+        if (expression.startsWith("atomic.")) {
+            return evaluateAtomic(stripSemicolon(expression));
+        }
+
+        // Operating system hooks: os.onSignal("SIGINT", shutdown), unless a variable is called os
+        if (expression.startsWith("os.") && environment.getVariable("os") == null) {
+            return evaluateOs(stripSemicolon(expression));
+        }
+
+        // Matrix helpers on lists of rows: matrix.multiply(a, b), unless a variable is called matrix
+        if (expression.startsWith("matrix.") && environment.getVariable("matrix") == null) {
+            return evaluateMatrix(stripSemicolon(expression));
+        }
+
         // Check for member access (struct field access): varName.fieldName
         if (expression.contains(".") && !expression.startsWith("console.") && !expression.startsWith("io::") && !expression.startsWith("math::")) {
             String[] parts = expression.split("\\.", 2);
@@ -929,10 +1505,38 @@ public class Executor {
                 String fieldName = parts[1].trim();
                 
                 Object obj = environment.getVariable(varName);
+                // Method calls and operators such as ?? after a field go to the expression evaluator
+                boolean memberChain = MEMBER_CHAIN_PATTERN.matcher(fieldName).matches();
+                if (obj instanceof Struct && !memberChain) {
+                    return new ExpressionEvaluator(stripSemicolon(expression), environment).parse();
+                }
                 if (obj instanceof Struct) {
                     Struct structInstance = (Struct) obj;
                     return structInstance.getField(fieldName);
                 }
+                // Collection and list methods take arguments, which the expression evaluator parses
+                if (obj instanceof CollectionVariable || obj instanceof ListVariable) {
+                    return new ExpressionEvaluator(stripSemicolon(expression), environment).parse();
+                }
+                // Mutex methods: m.lock(), m.unlock(), m.tryLock()
+                if (obj instanceof Mutex) {
+                    String method = stripSemicolon(fieldName);
+                    if (!method.endsWith("()")) {
+                        throw new RuntimeException("Mutex methods take no arguments: " + expression);
+                    }
+                    return ((Mutex) obj).call(method.substring(0, method.length() - 2).trim());
+                }
+                // Maps (e.g. parsed JSON objects) allow nested access: body.user.name
+                if (obj instanceof Map && memberChain) {
+                    Object value = obj;
+                    for (String key : fieldName.split("\\.")) {
+                        if (!(value instanceof Map)) {
+                            throw new RuntimeException("Cannot access field '" + key + "' on " + value);
+                        }
+                        value = ((Map<?, ?>) value).get(key.trim());
+                    }
+                    return value;
+                }
             }
         }
 
@@ -974,7 +1578,7 @@ public class Executor {
         }
 
         // Handle ternary expressions in the main evaluate method
-        int questionPos = expression.indexOf('?');
+        int questionPos = conditionalMark(expression);
         if (questionPos > 0) {
             int colonPos = expression.indexOf(':', questionPos);
             if (colonPos > questionPos) {
@@ -1000,13 +1604,408 @@ public class Executor {
         return evaluator.parse();
     }
 
+    /**
+     * Evaluates the arguments and looks up the function on the calling thread,
+     * then runs it in its own scope on top of the globals so it can't see or
+     * race on the caller's locals. A lambda that closed over locals gets a
+     * snapshot of them instead of the live scopes.
+     */
+    private Task spawn(String call) {
+        Matcher matcher = FUNCTION_CALL_PATTERN.matcher(call);
+        if (!matcher.matches()) {
+            throw new RuntimeException("spawn expects a function call: " + call);
+        }
+        String functionName = matcher.group(1);
+        String args = matcher.group(2).trim();
+        List<String> arguments = args.isEmpty() ? new ArrayList<>() : splitArguments(args);
+        Object[] values = new Object[arguments.size()];
+        for (int i = 0; i < values.length; i++) {
+            values[i] = evaluate(arguments.get(i).trim());
+        }
+        // Resolved here, since a lambda held in a local can't be found from the root
+        Function function = environment.getFunction(functionName);
+        if (function instanceof ArrowFunction) {
+            Environment closure = ((ArrowFunction) function).getClosure();
+            if (closure != null && closure.getRoot() != closure) {
+                function = ((ArrowFunction) function).withClosure(closure.snapshot());
+            }
+        }
+        // The task shares only the root; the call gets a scope of its own on the task's thread
+        Executor taskExecutor = new Executor(environment.getRoot());
+        if (function == null) {
+            // Native and built-in functions aren't Function values
+            return Task.start(functionName, () -> taskExecutor.callFunction(functionName, values));
+        }
+        Function target = function;
+        return Task.start(functionName, () -> taskExecutor.call(target, values));
+    }
+
+    /**
+     * Handles atomic.add(name, delta), atomic.get(name) and atomic.set(name, value).
+     * The first argument names a variable rather than passing its value, so the
+     * read and the write happen as one step.
+     */
+    private Object evaluateAtomic(String call) {
+        int open = call.indexOf('(');
+        if (open == -1 || !call.endsWith(")")) {
+            throw new RuntimeException("Invalid atomic call: " + call);
+        }
+        String operation = call.substring("atomic.".length(), open).trim();
+        List<String> arguments = splitArguments(call.substring(open + 1, call.length() - 1));
+        if (arguments.isEmpty()) {
+            throw new RuntimeException("atomic." + operation + " expects a variable name");
+        }
+        String name = arguments.get(0).trim();
+
+        switch (operation) {
+            case "get":
+                return environment.updateVariable(name, value -> value);
+            case "set": {
+                if (arguments.size() != 2) {
+                    throw new RuntimeException("atomic.set expects a variable name and a value");
+                }
+                Object newValue = evaluate(arguments.get(1).trim());
+                return environment.updateVariable(name, value -> newValue);
+            }
+            case "add": {
+                if (arguments.size() != 2) {
+                    throw new RuntimeException("atomic.add expects a variable name and an amount");
+                }
+                Object delta = evaluate(arguments.get(1).trim());
+                if (!(delta instanceof Number)) {
+                    throw new RuntimeException("atomic.add amount is not a number: " + delta);
+                }
+                return environment.updateVariable(name, value -> addNumbers(name, value, (Number) delta));
+            }
+            default:
+                throw new RuntimeException("Unknown atomic operation: " + operation);
+        }
+    }
+
+    /**
+     * Handles os.onSignal(name, handler)
+     */
+    private Object evaluateOs(String call) {
+        int open = call.indexOf('(');
+        if (open == -1 || !call.endsWith(")")) {
+            throw new RuntimeException("Invalid os call: " + call);
+        }
+        String operation = call.substring("os.".length(), open).trim();
+        List<Object> values = new ArrayList<>();
+        for (String argument : splitArguments(call.substring(open + 1, call.length() - 1))) {
+            values.add(evaluate(argument));
+        }
+        return Os.call(operation, values, environment);
+    }
+
+    /**
+     * Handles matrix.transpose(m), matrix.multiply(a, b) and matrix.identity(n)
+     */
+    private Object evaluateMatrix(String call) {
+        int open = call.indexOf('(');
+        if (open == -1 || !call.endsWith(")")) {
+            throw new RuntimeException("Invalid matrix call: " + call);
+        }
+        String operation = call.substring("matrix.".length(), open).trim();
+        List<Object> values = new ArrayList<>();
+        for (String argument : splitArguments(call.substring(open + 1, call.length() - 1))) {
+            values.add(evaluate(argument));
+        }
+        return Matrix.call(operation, values);
+    }
+
+    // Keeps Int32/Int64 counters integral when adding whole numbers
+    private static Object addNumbers(String name, Object value, Number delta) {
+        if (!(value instanceof Number)) {
+            throw new RuntimeException("atomic.add on non-numeric variable: " + name);
+        }
+        double amount = delta.doubleValue();
+        boolean whole = amount == Math.rint(amount);
+        if (value instanceof Integer && whole) {
+            return Math.addExact((Integer) value, (int) amount);
+        }
+        if (value instanceof Long && whole) {
+            return Math.addExact((Long) value, (long) amount);
+        }
+        return ((Number) value).doubleValue() + amount;
+    }
+
+    /**
+     * Checks a value against a type annotation and returns it converted to
+     * the type (a whole Float64 becomes an Int32 for Int32, and so on). A
+     * union such as Int32 | String takes the first member the value fits.
+     * Type parameters accept any value.
+     */
+    private Object checkType(String type, Object value, String subject, List<String> typeParameters) {
+        List<String> members = unionMembers(type);
+        if (members.size() > 1) {
+            for (String member : members) {
+                try {
+                    return checkType(member, value, subject, typeParameters);
+                } catch (RuntimeException e) {
+                    if (e.getMessage() != null && e.getMessage().startsWith("Unknown type annotation")) {
+                        throw e;
+                    }
+                }
+            }
+            throw typeError(subject, String.join(" | ", members), value);
+        }
+        String checked = erasure(type);
+        switch (checked) {
+            case "String":
+            case "Int32":
+            case "Int64":
+            case "Float32":
+            case "Float64":
+                return coerceTypedValue(checked, value, subject);
+            case "Char":
+                if (!(value instanceof Character)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "Bool":
+                if (!(value instanceof Boolean)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "List":
+                if (!(value instanceof List)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "Map":
+                if (!(value instanceof Map)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "Task":
+                if (!(value instanceof Task)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "Mutex":
+                if (!(value instanceof Mutex)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "Tuple":
+                if (!(value instanceof Tuple)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "Function":
+                if (!(value instanceof Function) && !(value instanceof Import.FunctionInterface)) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+            case "null":
+                // Only reached with a value, since null itself is checked with isNullable
+                throw typeError(subject, type, value);
+            default:
+                // Type parameters are erased: the type checker matches them up at each call
+                if (typeParameters.contains(checked)) {
+                    return value;
+                }
+                // Interfaces accept any struct that has their methods
+                Interface contract = environment.getInterface(checked);
+                if (contract != null) {
+                    contract.check(value, subject);
+                    return value;
+                }
+                Struct structDef = environment.getStruct(checked);
+                if (structDef == null) {
+                    throw new RuntimeException("Unknown type annotation: " + checked);
+                }
+                if (!(value instanceof Struct) || !checked.equals(((Struct) value).getName())) {
+                    throw typeError(subject, type, value);
+                }
+                return value;
+        }
+    }
+
+    /**
+     * Whether the value fits the type, as tested by the is operator:
+     * id is String, count is Int32, shape is Printable
+     */
+    public boolean isType(Object value, String type) {
+        if (value == null) {
+            return isNullable(type);
+        }
+        try {
+            checkType(type, value, "Value " + value, Collections.emptyList());
+            return true;
+        } catch (RuntimeException e) {
+            if (e.getMessage() != null && e.getMessage().startsWith("Unknown type annotation")) {
+                throw e;
+            }
+            return false;
+        }
+    }
+
+    // String?, Int32? | String and Int32 | null all accept null
+    private static boolean isNullable(String type) {
+        for (String member : unionMembers(type)) {
+            if (member.endsWith("?") || member.equals("null")) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    // The members of a union type such as Int32 | String, or just the type itself
+    private static List<String> unionMembers(String type) {
+        List<String> members = new ArrayList<>();
+        int depth = 0;
+        int start = 0;
+        for (int i = 0; i < type.length(); i++) {
+            char c = type.charAt(i);
+            if (c == '<') {
+                depth++;
+            } else if (c == '>') {
+                depth--;
+            } else if (c == '|' && depth == 0) {
+                members.add(type.substring(start, i).trim());
+                start = i + 1;
+            }
+        }
+        members.add(type.substring(start).trim());
+        return members;
+    }
+
+    // The type checked at run time: List<T>? is checked as List
+    private static String erasure(String type) {
+        if (type.endsWith("?")) {
+            type = type.substring(0, type.length() - 1).trim();
+        }
+        int generic = type.indexOf('<');
+        return generic == -1 ? type : type.substring(0, generic).trim();
+    }
+
+    // Names the signature, so the caller can see what the function takes
+    /**
+     * Puts arguments in parameter order. Each has the name it was passed by, or
+     * null when it was passed by position; positional arguments come first and
+     * fill the leading parameters, and named ones fill the rest in any order.
+     */
+    static <T> List<T> bindArguments(Function function, List<String> names, List<T> values) {
+        List<Parameter> parameters = function.getParameters();
+        List<T> bound = new ArrayList<>(Collections.nCopies(parameters.size(), null));
+        boolean[] given = new boolean[parameters.size()];
+        boolean seenNamed = false;
+        for (int i = 0; i < values.size(); i++) {
+            String name = names.get(i);
+            int index;
+            if (name == null) {
+                if (seenNamed) {
+                    throw new RuntimeException("Positional argument after named arguments in call to " + declaration(function));
+                }
+                if (i >= parameters.size()) {
+                    throw argumentCountError(function, values.size());
+                }
+                index = i;
+            } else {
+                seenNamed = true;
+                index = -1;
+                for (int j = 0; j < parameters.size(); j++) {
+                    if (parameters.get(j).getName().equals(name)) {
+                        index = j;
+                    }
+                }
+                if (index < 0) {
+                    throw new RuntimeException("Unknown parameter '" + name + "' for " + declaration(function));
+                }
+                if (given[index]) {
+                    throw new RuntimeException("Parameter '" + name + "' of " + declaration(function) + " is given twice");
+                }
+            }
+            bound.set(index, values.get(i));
+            given[index] = true;
+        }
+        for (int j = 0; j < parameters.size(); j++) {
+            if (!given[j]) {
+                throw new RuntimeException("Missing argument for parameter '" + parameters.get(j).getName() + "' of "
+                    + declaration(function));
+            }
+        }
+        return bound;
+    }
+
+    private static RuntimeException argumentCountError(Function function, int given) {
+        int expected = function.getParameters().size();
+        return new RuntimeException("Argument count mismatch for function " + function.getName() + ": "
+            + declaration(function) + " expects " + expected + (expected == 1 ? " argument" : " arguments")
+            + ", got " + given);
+    }
+
+    // The signature and where it was written: area(side: Float64) -> Float64 (defined at line 19)
+    private static String declaration(Function function) {
+        String defined = function.getLine() >= 0 ? " (defined at line " + (function.getLine() + 1) + ")" : "";
+        return function.getSignature() + defined;
+    }
+
+    // Says what was declared and what arrived: expected String, got Int32 (42)
+    private static RuntimeException typeError(String subject, String type, Object value) {
+        return new RuntimeException("Type error: " + subject + " expected " + type + ", got "
+            + Builtins.describe(value) + ".");
+    }
+
+    // Only types written with a trailing ?, such as String?, accept null
+    private static RuntimeException nullError(String subject, String type) {
+        return new RuntimeException("Type error: " + subject + " is null, but " + type
+            + " is not nullable; declare it as " + type + "? to allow null.");
+    }
+
+    /**
+     * Whether the expression is a single string literal, unlike "sub" in "substring",
+     * which also starts and ends with quotes. Quotes in template placeholders such
+     * as {greet("Ada")} are part of the string.
+     */
+    private static boolean isStringLiteral(String expression) {
+        if (expression.length() < 2 || !expression.startsWith("\"") || !expression.endsWith("\"")) {
+            return false;
+        }
+        int depth = 0;
+        for (int i = 1; i < expression.length() - 1; i++) {
+            char c = expression.charAt(i);
+            if (c == '\\') {
+                i++;
+            } else if (c == '{') {
+                depth++;
+            } else if (c == '}') {
+                depth = Math.max(0, depth - 1);
+            } else if (c == '"' && depth == 0) {
+                return false;
+            }
+        }
+        return true;
+    }
+
+    // The ? of a conditional, as opposed to ?? and ?., or -1
+    private static int conditionalMark(String expression) {
+        for (int i = 0; i < expression.length(); i++) {
+            if (expression.charAt(i) != '?') {
+                continue;
+            }
+            if (expression.startsWith("??", i)) {
+                i++;
+            } else if (!expression.startsWith("?.", i)) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    private static String stripSemicolon(String text) {
+        return text.endsWith(";") ? text.substring(0, text.length() - 1).trim() : text;
+    }
+
     private Object coerceTypedValue(String typeAnnotation, Object value, String subject) {
         switch (typeAnnotation) {
             case "String":
                 if (value instanceof String) {
                     return value;
                 }
-                throw new RuntimeException("Type error: " + subject + " is not a String.");
+                throw typeError(subject, "String", value);
             case "Int32":
                 return coerceInteger(value, Integer.MIN_VALUE, Integer.MAX_VALUE, "Int32", subject, true);
             case "Int64":
@@ -1015,12 +2014,12 @@ public class Executor {
                 if (value instanceof Number) {
                     return ((Number) value).floatValue();
                 }
-                throw new RuntimeException("Type error: " + subject + " is not a Float32.");
+                throw typeError(subject, "Float32", value);
             case "Float64":
                 if (value instanceof Number) {
                     return ((Number) value).doubleValue();
                 }
-                throw new RuntimeException("Type error: " + subject + " is not a Float64.");
+                throw typeError(subject, "Float64", value);
             default:
                 throw new RuntimeException("Unknown type annotation: " + typeAnnotation);
         }
@@ -1028,17 +2027,18 @@ public class Executor {
 
     private Object coerceInteger(Object value, long min, long max, String typeName, String subject, boolean asInt) {
         if (!(value instanceof Number)) {
-            throw new RuntimeException("Type error: " + subject + " is not an " + typeName + ".");
+            throw typeError(subject, typeName, value);
         }
 
         double number = ((Number) value).doubleValue();
         if (Math.abs(number % 1) > 0.0000001) {
-            throw new RuntimeException("Type error: " + subject + " is not an " + typeName + ".");
+            throw typeError(subject, typeName, value);
         }
 
         long longValue = (long) number;
         if (longValue < min || longValue > max) {
-            throw new RuntimeException("Type error: " + subject + " is out of range for " + typeName + ".");
+            throw new RuntimeException("Type error: " + subject + " expected " + typeName + ", got "
+                + Builtins.describe(value) + ", which is out of range for " + typeName + ".");
         }
 
         return asInt ? (int) longValue : longValue;
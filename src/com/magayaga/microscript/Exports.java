@@ -0,0 +1,120 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.LinkedHashSet;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * What a script module shares with the scripts that import it:
+ *
+ *   export function clamp(x: Float64, low: Float64, high: Float64) -> Float64 { ... }
+ *   export var VERSION = "1.2";
+ *   function round2(x: Float64) -> Float64 { ... }   // Private to the module
+ *
+ * A module without any export shares everything, as modules always have.
+ * Once it exports something, only its exported functions, variables,
+ * structs, interfaces and namespaces reach the importer; the rest stay in
+ * the module, where the exported functions can still use them. Macros are
+ * never shared, since each file is preprocessed on its own.
+ */
+public class Exports {
+    private static final String DECLARATIONS = "function|var|struct|interface|namespace|class|String|Int32|Int64|Float32|Float64|fn";
+    private static final Pattern EXPORT_PATTERN = Pattern.compile("^(\\s*)export\\s+(?=(?:" + DECLARATIONS + ")\\s)");
+    private static final Pattern NAME_PATTERN = Pattern.compile("^(?:" + DECLARATIONS + ")\\s+([A-Za-z_][\\w:]*)");
+
+    private Exports() {
+    }
+
+    /**
+     * The names the lines export, in the order they are declared
+     */
+    public static Set<String> names(List<String> lines) {
+        Set<String> names = new LinkedHashSet<>();
+        for (String line : lines) {
+            Matcher export = EXPORT_PATTERN.matcher(line);
+            if (export.find()) {
+                Matcher name = NAME_PATTERN.matcher(line.substring(export.end()));
+                if (name.find()) {
+                    names.add(name.group(1));
+                }
+            }
+        }
+        return names;
+    }
+
+    /**
+     * Blanks out the export keywords, so the declarations read as usual and
+     * keep their lines and columns
+     */
+    public static List<String> strip(List<String> lines) {
+        List<String> output = new ArrayList<>(lines.size());
+        for (String line : lines) {
+            output.add(strip(line));
+        }
+        return output;
+    }
+
+    public static String strip(String line) {
+        Matcher export = EXPORT_PATTERN.matcher(line);
+        if (!export.find()) {
+            return line;
+        }
+        return export.group(1) + " ".repeat(export.end() - export.group(1).length()) + line.substring(export.end());
+    }
+
+    /**
+     * Copies what a module exports from the environment it ran in to the
+     * importer's, pointing the exported functions back at the module
+     */
+    public static void share(Set<String> names, Environment module, Environment importer, String moduleName) {
+        Map<String, Object> locals = module.getLocals();
+        for (String name : names) {
+            boolean found = false;
+            // A namespace's functions are named geometry::area
+            for (String function : module.getFunctionNames()) {
+                if (function.equals(name) || function.startsWith(name + "::")) {
+                    Function definition = module.getFunction(function);
+                    definition.setModule(module);
+                    importer.defineFunction(definition);
+                    found = true;
+                }
+            }
+            if (locals.containsKey(name)) {
+                Object value = locals.get(name);
+                if (value instanceof Function) {
+                    ((Function) value).setModule(module);
+                }
+                if (module.isImmutable(name)) {
+                    importer.setImmutableVariable(name, value);
+                } else {
+                    importer.setVariable(name, value);
+                }
+                found = true;
+            }
+            // The module sees the importer's structs too, so only its own are copied
+            Struct struct = module.getStruct(name);
+            if (struct != null && struct != importer.getStruct(name)) {
+                importer.defineStruct(struct);
+                found = true;
+            }
+            Interface definition = module.getInterface(name);
+            if (definition != null && definition != importer.getInterface(name)) {
+                importer.defineInterface(definition);
+                found = true;
+            }
+            if (!found) {
+                throw new RuntimeException("Module " + moduleName + " exports " + name + ", which it doesn't define");
+            }
+        }
+    }
+}
@@ -9,12 +9,18 @@ package com.magayaga.microscript;
 import java.util.Stack;
 import java.util.ArrayList;
 import java.util.List;
+import java.util.Map;
+import java.util.Objects;
 
 public class ExpressionEvaluator {
+    // Deeper nesting is reported as an error instead of overflowing the stack
+    private static final int MAX_NESTING = 256;
+
     private final String expression;
     private final Environment environment;
     private int pos = -1;
     private int ch;
+    private int nesting = 0;
 
     public ExpressionEvaluator(String expression, Environment environment) {
         this.expression = expression;
@@ -241,9 +247,7 @@ public class ExpressionEvaluator {
                         result = currentNum * rightNum;
                         break;
                     case "/=":
-                        if (Math.abs(rightNum) < 0.0001) {
-                            throw new RuntimeException("Division by zero in compound assignment");
-                        }
+                        checkDivisor(currentValue, rightValue, "Division");
                         result = currentNum / rightNum;
                         break;
                     default:
@@ -267,7 +271,7 @@ public class ExpressionEvaluator {
     }
 
     private Object parseTernary() {
-        Object condition = parseLogicalOr();
+        Object condition = parseNullCoalescing();
         skipWhitespace();
         
         // Look for ternary operator ?
@@ -294,6 +298,59 @@ public class ExpressionEvaluator {
         return condition;
     }
 
+    // Parse the null-coalescing operator: name ?? "guest" is "guest" only when name is null
+    private Object parseNullCoalescing() {
+        Object left = parseLogicalOr();
+        skipWhitespace();
+
+        while (ch == '?' && pos + 1 < expression.length() && expression.charAt(pos + 1) == '?') {
+            nextChar(); // consume ?
+            nextChar(); // consume second ?
+            skipWhitespace();
+            if (left == null) {
+                left = parseLogicalOr();
+            } else {
+                // The fallback isn't needed, so it isn't evaluated
+                skipOperand();
+            }
+            skipWhitespace();
+        }
+
+        return left;
+    }
+
+    // Skips an operand without evaluating it, up to the comma, bracket, ?, : or ; that ends it
+    private void skipOperand() {
+        int depth = 0;
+        while (ch != -1) {
+            if (ch == '"' || ch == '\'') {
+                int quote = ch;
+                nextChar(); // consume opening quote
+                while (ch != -1 && ch != quote) {
+                    if (ch == '\\') {
+                        nextChar();
+                    }
+                    nextChar();
+                }
+                nextChar(); // consume closing quote
+                continue;
+            }
+            if (ch == '(' || ch == '[' || ch == '{') {
+                depth++;
+            } else if (ch == ')' || ch == ']' || ch == '}') {
+                if (depth == 0) {
+                    break;
+                }
+                depth--;
+            } else if (depth == 0 && ch == ':' && pos + 1 < expression.length() && expression.charAt(pos + 1) == ':') {
+                nextChar(); // math::sqrt
+            } else if (depth == 0 && (ch == ',' || ch == ';' || ch == ':' || ch == '?' && !isSafeAccess())) {
+                break;
+            }
+            nextChar();
+        }
+    }
+
     // Parse logical OR operator (||)
     private Object parseLogicalOr() {
         Object left = parseLogicalAnd();
@@ -364,82 +421,314 @@ public class ExpressionEvaluator {
     }
 
     // Support comparison operators and spaceship operator
+    // Comparisons chain as in mathematics: 0 <= x < 10 is 0 <= x && x < 10,
+    // with x evaluated once
     private Object parseComparison() {
-        Object x = parseExpression();
+        Object x = parseRange();
         skipWhitespace();
-        
-        // Handle spaceship operator <=>
+
+        String operator = comparisonOperator();
+        if (operator != null) {
+            Object left = x;
+            boolean result = true;
+            boolean chained = false;
+            while (operator != null) {
+                skipWhitespace();
+                Object right = parseRange();
+                skipWhitespace();
+                if (operator.equals("<=>")) {
+                    if (chained || comparisonOperator() != null) {
+                        throw new RuntimeException("'<=>' can't be chained with other comparisons at position " + pos);
+                    }
+                    return (double) Double.compare(objectToDouble(left), objectToDouble(right));
+                }
+                result = compare(operator, left, right) && result;
+                left = right;
+                chained = true;
+                operator = comparisonOperator();
+            }
+            return result;
+        }
+        if (ch == 'i' && expression.startsWith("is", pos)
+                && (pos + 2 == expression.length() || !Character.isLetterOrDigit(expression.charAt(pos + 2)))) {
+            // Type test: id is String, name is String?, shape is Printable
+            pos += 1;
+            nextChar(); // consume is
+            skipWhitespace();
+            int start = pos;
+            while (ch != -1 && (Character.isLetterOrDigit(ch) || ch == '_')) {
+                nextChar();
+            }
+            String type = expression.substring(start, pos);
+            if (type.isEmpty()) {
+                throw new RuntimeException("Expected a type after 'is' at position " + pos);
+            }
+            // String? right after the name; a ? after a space starts a ternary
+            if (ch == '?') {
+                nextChar();
+                type += "?";
+            }
+            skipWhitespace();
+            return new Executor(environment).isType(x, type);
+        } else if (ch == 'i' && expression.startsWith("in", pos)
+                && (pos + 2 == expression.length() || !Character.isLetterOrDigit(expression.charAt(pos + 2)))) {
+            // Membership test: x in xs, "key" in config, "sub" in "substring"
+            pos += 1;
+            nextChar(); // consume in
+            skipWhitespace();
+            return contains(parseRange(), x);
+        }
+        return x;
+    }
+
+    // Consumes a comparison operator, or returns null when there is none
+    private String comparisonOperator() {
         if (ch == '<') {
             nextChar();
             if (ch == '=') {
                 nextChar();
-                if (ch == '>') { // Found <=> operator
+                if (ch == '>') {
                     nextChar();
-                    skipWhitespace();
-                    Object rightObj = parseExpression();
-                    double left = objectToDouble(x);
-                    double right = objectToDouble(rightObj);
-                    return (double)Double.compare(left, right);
-                } else {
-                    // It's <= operator
-                    skipWhitespace();
-                    Object rightObj = parseExpression();
-                    double left = objectToDouble(x);
-                    double right = objectToDouble(rightObj);
-                    return left <= right;
+                    return "<=>";
                 }
-            } else {
-                // It's < operator
-                skipWhitespace();
-                Object rightObj = parseExpression();
-                double left = objectToDouble(x);
-                double right = objectToDouble(rightObj);
-                return left < right;
+                return "<=";
             }
+            return "<";
         } else if (ch == '>') {
             nextChar();
             if (ch == '=') {
                 nextChar();
-                skipWhitespace();
-                Object rightObj = parseExpression();
-                double left = objectToDouble(x);
-                double right = objectToDouble(rightObj);
-                return left >= right;
-            } else {
-                skipWhitespace();
-                Object rightObj = parseExpression();
-                double left = objectToDouble(x);
-                double right = objectToDouble(rightObj);
-                return left > right;
+                return ">=";
             }
+            return ">";
         } else if (ch == '=') {
             nextChar();
             if (ch == '=') {
                 nextChar();
-                skipWhitespace();
-                // Equal ==
-                Object rightObj = parseExpression();
-                double left = objectToDouble(x);
-                double right = objectToDouble(rightObj);
-                return Math.abs(left - right) < 0.0001;
+                return "==";
             }
             throw new RuntimeException("Unexpected '=' at position " + pos + ". Did you mean '=='?");
         } else if (ch == '!') {
             nextChar();
             if (ch == '=') {
                 nextChar();
-                skipWhitespace();
-                // Not equal !=
-                Object rightObj = parseExpression();
-                double left = objectToDouble(x);
-                double right = objectToDouble(rightObj);
-                return Math.abs(left - right) >= 0.0001;
+                return "!=";
             }
             throw new RuntimeException("Unexpected '!' at position " + pos + ". Did you mean '!='?");
         }
+        return null;
+    }
+
+    private boolean compare(String operator, Object leftObj, Object rightObj) {
+        if (operator.equals("==") || operator.equals("!=")) {
+            boolean equal;
+            if (leftObj == null || rightObj == null) {
+                equal = leftObj == rightObj; // name == null
+            } else {
+                equal = Math.abs(objectToDouble(leftObj) - objectToDouble(rightObj)) < 0.0001;
+            }
+            return operator.equals("==") == equal;
+        }
+        double left = objectToDouble(leftObj);
+        double right = objectToDouble(rightObj);
+        switch (operator) {
+            case "<":
+                return left < right;
+            case "<=":
+                return left <= right;
+            case ">":
+                return left > right;
+            default:
+                return left >= right;
+        }
+    }
+
+    // Ranges: 1..10 includes 10, 1..<10 stops before it
+    private Object parseRange() {
+        Object x = parseExpression();
+        skipWhitespace();
+        if (ch == '.' && expression.startsWith("..", pos)) {
+            pos += 1;
+            nextChar(); // consume ..
+            boolean inclusive = ch != '<';
+            if (!inclusive) {
+                nextChar(); // consume <
+            }
+            skipWhitespace();
+            return Range.of(x, parseExpression(), inclusive);
+        }
         return x;
     }
 
+    /**
+     * Whether a list, tuple or collection holds the item, a map has it as a
+     * key, or a string has it as a substring. Numbers match by value, so 2 is
+     * in [1.0, 2.0].
+     */
+    private static boolean contains(Object container, Object item) {
+        if (container instanceof Range) {
+            return ((Range) container).includes(item);
+        }
+        if (container instanceof String) {
+            if (!(item instanceof String || item instanceof Character)) {
+                throw new RuntimeException("'in' a string expects a string, got " + Builtins.describe(item));
+            }
+            return ((String) container).contains(String.valueOf(item));
+        }
+        if (container instanceof Map) {
+            return ((Map<?, ?>) container).containsKey(item);
+        }
+        Iterable<?> items = container == null ? null : ForLoop.convertToIterable(container);
+        if (items == null) {
+            throw new RuntimeException("'in' expects a list, map or string, got " + Builtins.describe(container));
+        }
+        for (Object element : items) {
+            if (item instanceof Number && element instanceof Number
+                    ? ((Number) item).doubleValue() == ((Number) element).doubleValue()
+                    : Objects.equals(item, element)) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    // Consumes the ... before a spread element or argument
+    /**
+     * Consumes the name of an argument passed by name, as in f(width: 10), and
+     * returns it; returns null, consuming nothing, for a positional argument
+     */
+    private String argumentName() {
+        if (ch == -1 || !Character.isLetter(ch) && ch != '_') {
+            return null;
+        }
+        int end = pos;
+        while (end < expression.length()
+                && (Character.isLetterOrDigit(expression.charAt(end)) || expression.charAt(end) == '_')) {
+            end++;
+        }
+        int colon = end;
+        while (colon < expression.length() && Character.isWhitespace(expression.charAt(colon))) {
+            colon++;
+        }
+        if (colon >= expression.length() || expression.charAt(colon) != ':' || expression.startsWith("::", colon)) {
+            return null;
+        }
+        String name = expression.substring(pos, end);
+        pos = colon;
+        nextChar();
+        skipWhitespace();
+        return name;
+    }
+
+    private boolean isSpread() {
+        if (ch != '.' || !expression.startsWith("...", pos)) {
+            return false;
+        }
+        pos += 2;
+        nextChar();
+        skipWhitespace();
+        return true;
+    }
+
+    /**
+     * The position of the ']' closing the list at pos if it holds a
+     * comprehension, or -1 for a plain list literal
+     */
+    private int comprehensionEnd() {
+        int close = matchingBracket(expression, pos);
+        if (close == -1 || findKeyword(expression.substring(pos + 1, close), "for", 0) == -1) {
+            return -1;
+        }
+        return close;
+    }
+
+    /**
+     * Runs a comprehension such as "x * x for x in range(10) if x % 2 == 0"
+     * as a loop, evaluating the element in a scope where the loop variable
+     * is bound
+     */
+    private ListVariable evaluateComprehension(String body) {
+        int forAt = findKeyword(body, "for", 0);
+        int inAt = findKeyword(body, "in", forAt + 3);
+        if (inAt == -1) {
+            throw new RuntimeException("Syntax error: expected 'in' in list comprehension: [" + body + "]");
+        }
+        int ifAt = findKeyword(body, "if", inAt + 2);
+        String element = body.substring(0, forAt).trim();
+        String variable = body.substring(forAt + 3, inAt).trim();
+        String source = body.substring(inAt + 2, ifAt == -1 ? body.length() : ifAt).trim();
+        String condition = ifAt == -1 ? null : body.substring(ifAt + 2).trim();
+        if (element.isEmpty() || source.isEmpty() || (condition != null && condition.isEmpty())
+                || !(variable.matches("[A-Za-z_]\\w*") || Executor.isPattern(variable))) {
+            throw new RuntimeException("Syntax error in list comprehension: [" + body + "]");
+        }
+
+        Object items = new Executor(environment).evaluate(source);
+        Iterable<?> iterable = items == null ? null : ForLoop.convertToIterable(items);
+        if (iterable == null) {
+            throw new RuntimeException("'" + source + "' is not iterable");
+        }
+        ListVariable result = new ListVariable();
+        for (Object item : iterable) {
+            environment.getCancellation().check();
+            Environment scope = new Environment(environment);
+            Executor executor = new Executor(scope);
+            if (Executor.isPattern(variable)) {
+                executor.destructure(variable, item);
+            } else {
+                scope.setVariable(variable, item);
+            }
+            if (condition != null && !Boolean.TRUE.equals(executor.evaluate(condition))) {
+                continue;
+            }
+            result.add(executor.evaluate(element));
+        }
+        return result;
+    }
+
+    // The index of the bracket closing the one at open, skipping strings, or -1
+    private static int matchingBracket(String text, int open) {
+        int depth = 0;
+        for (int i = open; i < text.length(); i++) {
+            char c = text.charAt(i);
+            if (c == '"') {
+                i = text.indexOf('"', i + 1);
+                if (i == -1) {
+                    return -1;
+                }
+            } else if (c == '(' || c == '[' || c == '{') {
+                depth++;
+            } else if ((c == ')' || c == ']' || c == '}') && --depth == 0) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    // The index of keyword as a whole word outside brackets and strings, or -1
+    private static int findKeyword(String text, String keyword, int from) {
+        int depth = 0;
+        for (int i = Math.max(from, 0); i < text.length(); i++) {
+            char c = text.charAt(i);
+            if (c == '"') {
+                int end = text.indexOf('"', i + 1);
+                if (end == -1) {
+                    return -1;
+                }
+                i = end;
+            } else if (c == '(' || c == '[' || c == '{') {
+                depth++;
+            } else if (c == ')' || c == ']' || c == '}') {
+                depth--;
+            } else if (depth == 0 && text.startsWith(keyword, i)
+                    && (i == 0 || !Character.isLetterOrDigit(text.charAt(i - 1)) && text.charAt(i - 1) != '_')
+                    && (i + keyword.length() == text.length() || Character.isWhitespace(text.charAt(i + keyword.length())))) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
     private void nextChar() {
         ch = (++pos < expression.length()) ? expression.charAt(pos) : -1;
     }
@@ -462,6 +751,11 @@ public class ExpressionEvaluator {
                 nextChar();
                 skipWhitespace();
                 Object termObj = parseTerm();
+                if (x instanceof List || termObj instanceof List) {
+                    x = ListVariable.concat(x, termObj); // list concatenation
+                    skipWhitespace();
+                    continue;
+                }
                 double xValue = objectToDouble(x);
                 double termValue = objectToDouble(termObj);
                 x = xValue + termValue; // addition
@@ -488,20 +782,35 @@ public class ExpressionEvaluator {
                 nextChar();
                 skipWhitespace();
                 Object factorObj = parseFactor();
+                if (x instanceof List || factorObj instanceof List) {
+                    x = ListVariable.repeat(x, factorObj); // list repetition
+                    skipWhitespace();
+                    continue;
+                }
+                if (x instanceof String || factorObj instanceof String) {
+                    x = repeat(x, factorObj); // string repetition: "-" * 40
+                    skipWhitespace();
+                    continue;
+                }
                 double xValue = objectToDouble(x);
                 double factorValue = objectToDouble(factorObj);
                 x = xValue * factorValue; // multiplication
                 skipWhitespace();
             }
+            else if (ch == '/' && pos + 1 < expression.length() && expression.charAt(pos + 1) == '/') {
+                nextChar(); // consume first /
+                nextChar(); // consume second /
+                skipWhitespace();
+                x = Operators.floorDivide(x, parseFactor()); // floor division, whole for whole numbers
+                skipWhitespace();
+            }
             else if (ch == '/') {
                 nextChar();
                 skipWhitespace();
                 Object factorObj = parseFactor();
+                checkDivisor(x, factorObj, "Division");
                 double xValue = objectToDouble(x);
                 double factorValue = objectToDouble(factorObj);
-                if (Math.abs(factorValue) < 0.0001) {
-                    throw new RuntimeException("Division by zero");
-                }
                 x = xValue / factorValue; // division
                 skipWhitespace();
             }
@@ -509,11 +818,9 @@ public class ExpressionEvaluator {
                 nextChar();
                 skipWhitespace();
                 Object factorObj = parseFactor();
+                checkDivisor(x, factorObj, "Division");
                 double xValue = objectToDouble(x);
                 double factorValue = objectToDouble(factorObj);
-                if (Math.abs(factorValue) < 0.0001) {
-                    throw new RuntimeException("Division by zero");
-                }
                 x = Math.floor(xValue / factorValue); // floor division
                 skipWhitespace();
             }
@@ -521,11 +828,9 @@ public class ExpressionEvaluator {
                 nextChar();
                 skipWhitespace();
                 Object factorObj = parseFactor();
+                checkDivisor(x, factorObj, "Modulo");
                 double xValue = objectToDouble(x);
                 double factorValue = objectToDouble(factorObj);
-                if (Math.abs(factorValue) < 0.0001) {
-                    throw new RuntimeException("Division by zero");
-                }
                 x = xValue % factorValue; // modulus
                 skipWhitespace();
             }
@@ -533,6 +838,35 @@ public class ExpressionEvaluator {
         }
     }
 
+    // Repeats a string a whole number of times; a count below one gives ""
+    private static String repeat(Object left, Object right) {
+        String text = (String) (left instanceof String ? left : right);
+        Object count = left instanceof String ? right : left;
+        if (!(count instanceof Number) || ((Number) count).doubleValue() != Math.floor(((Number) count).doubleValue())) {
+            throw new RuntimeException("Type error: a string can only be repeated a whole number of times, got " + count);
+        }
+        int times = (int) Math.max(0, Math.min(((Number) count).doubleValue(), Integer.MAX_VALUE));
+        if ((long) text.length() * times > Integer.MAX_VALUE - 8) {
+            throw new RuntimeException("String repetition is too long: " + text.length() + " characters " + times + " times");
+        }
+        return text.repeat(times);
+    }
+
+    /**
+     * Whole numbers (Int32 and Int64 values) have no infinity, so dividing
+     * one by zero is an error. Any other division follows IEEE 754, since
+     * number literals are Float64: 1 / 0 is Infinity and 0 / 0 is NaN.
+     */
+    private static void checkDivisor(Object dividend, Object divisor, String operation) {
+        if (isWholeNumber(dividend) && divisor instanceof Number && ((Number) divisor).doubleValue() == 0) {
+            throw new RuntimeException(operation + " by zero: " + dividend + " " + (operation.equals("Modulo") ? "%" : "/") + " 0");
+        }
+    }
+
+    private static boolean isWholeNumber(Object value) {
+        return value instanceof Integer || value instanceof Long || value instanceof Short || value instanceof Byte;
+    }
+
     private double objectToDouble(Object obj) {
         if (obj instanceof Number) {
             return ((Number) obj).doubleValue();
@@ -546,8 +880,72 @@ public class ExpressionEvaluator {
     }
 
     private Object parseFactor() {
+        if (++nesting > MAX_NESTING) {
+            throw new RuntimeException("Expression is nested too deeply at position " + pos);
+        }
+        try {
+            Object x = parseOperand();
+            // Exponentiation groups from the right and binds tighter than unary minus: -2 ** 2 is -4
+            skipWhitespace();
+            if (ch == '^' || ch == '*' && pos + 1 < expression.length() && expression.charAt(pos + 1) == '*') {
+                if (ch == '*') {
+                    nextChar(); // consume first *
+                }
+                nextChar(); // consume ^ or second *
+                skipWhitespace();
+                x = power(x, parseFactor());
+                skipWhitespace();
+            }
+            return x;
+        } finally {
+            nesting--;
+        }
+    }
+
+    /**
+     * Whole numbers raised to whole powers stay whole, multiplied out by
+     * squaring; anything else uses Math.pow
+     */
+    private Object power(Object base, Object exponent) {
+        if (isWholeNumber(base) && isWholeNumber(exponent) && ((Number) exponent).longValue() >= 0) {
+            long result = 1;
+            long factor = ((Number) base).longValue();
+            long remaining = ((Number) exponent).longValue();
+            try {
+                while (remaining > 0) {
+                    if ((remaining & 1) == 1) {
+                        result = Math.multiplyExact(result, factor);
+                    }
+                    remaining >>= 1;
+                    if (remaining > 0) {
+                        factor = Math.multiplyExact(factor, factor);
+                    }
+                }
+            } catch (ArithmeticException e) {
+                throw new RuntimeException("Integer overflow: " + base + " ** " + exponent + " is too large for Int64");
+            }
+            if (base instanceof Integer && result >= Integer.MIN_VALUE && result <= Integer.MAX_VALUE) {
+                return (int) result;
+            }
+            return result;
+        }
+        return Math.pow(objectToDouble(base), objectToDouble(exponent));
+    }
+
+    private Object parseOperand() {
         skipWhitespace();
         
+        // Inline lambda: apply(|Int32: x| => x * 2, 5)
+        if (ch == '|') {
+            int end = ArrowFunction.lambdaEnd(expression, pos);
+            if (end > pos) {
+                String lambda = expression.substring(pos, end);
+                pos = end - 1;
+                nextChar();
+                return ArrowFunction.lambda(lambda, environment);
+            }
+        }
+        
         // Handle logical NOT operator (!)
         if (ch == '!') {
             // Check if it's not part of != operator
@@ -660,6 +1058,20 @@ public class ExpressionEvaluator {
             skipWhitespace();
             x = parseAssignment(); // Allow walrus operator inside parentheses
             skipWhitespace();
+            if (ch == ',') { // tuple literals: (1, "a", true) or (x,)
+                List<Object> items = new ArrayList<>();
+                items.add(x);
+                while (ch == ',') {
+                    nextChar(); // consume ,
+                    skipWhitespace();
+                    if (ch == ')') {
+                        break;
+                    }
+                    items.add(parseAssignment());
+                    skipWhitespace();
+                }
+                x = new Tuple(items);
+            }
             if (ch != ')') {
                 throw new RuntimeException("Missing closing parenthesis at position " + pos);
             }
@@ -667,9 +1079,64 @@ public class ExpressionEvaluator {
             skipWhitespace();
         }
         
+        else if (ch == '"') { // string literals
+            nextChar(); // consume opening quote
+            int contentStart = pos;
+            while (ch != '"' && ch != -1) nextChar();
+            if (ch != '"') {
+                throw new RuntimeException("Unterminated string literal at position " + startPos);
+            }
+            x = expression.substring(contentStart, pos);
+            nextChar(); // consume closing quote
+            skipWhitespace();
+        }
+
+        else if (ch == '[' && comprehensionEnd() != -1) { // comprehensions: [x * x for x in xs if x > 1]
+            int close = comprehensionEnd();
+            x = evaluateComprehension(expression.substring(pos + 1, close));
+            pos = close;
+            nextChar(); // consume ]
+            skipWhitespace();
+        }
+
+        else if (ch == '[') { // list literals: [1.0, "a", x]
+            nextChar(); // consume [
+            skipWhitespace();
+            ListVariable list = new ListVariable();
+            if (ch != ']') {
+                while (true) {
+                    if (isSpread()) { // [1, ...rest, 9]
+                        int spreadStart = pos;
+                        Object spread = parseAssignment();
+                        list.addAll(ListVariable.spread(spread, "..." + expression.substring(spreadStart, pos).trim()));
+                    } else {
+                        list.add(parseAssignment());
+                    }
+                    skipWhitespace();
+                    if (ch == ']') {
+                        break;
+                    }
+                    if (ch != ',') {
+                        throw new RuntimeException("Expected ',' or ']' in list at position " + pos);
+                    }
+                    nextChar(); // consume ,
+                    skipWhitespace();
+                }
+            }
+            nextChar(); // consume ]
+            skipWhitespace();
+            x = list;
+        }
+
         else if ((ch >= '0' && ch <= '9') || ch == '.') { // numbers
-            while ((ch >= '0' && ch <= '9') || ch == '.') nextChar();
-            x = Double.parseDouble(expression.substring(startPos, this.pos));
+            // A number stops at .., so 1..10 is a range
+            while ((ch >= '0' && ch <= '9') || ch == '.' && !expression.startsWith("..", pos)) nextChar();
+            String number = expression.substring(startPos, this.pos);
+            try {
+                x = Double.parseDouble(number);
+            } catch (NumberFormatException e) {
+                throw new RuntimeException("Invalid number '" + number + "' at position " + startPos);
+            }
             skipWhitespace();
         }
         
@@ -826,9 +1293,22 @@ public class ExpressionEvaluator {
                 nextChar(); // consume (
                 skipWhitespace();
                 List<Object> args = new ArrayList<>();
+                List<String> names = new ArrayList<>(); // Parameter names, or null for positional arguments
+                boolean spread = false;
                 if (ch != ')') {  // If not empty arguments
                     while (true) {
-                        args.add(parseAssignment()); // Support walrus operator in arguments
+                        if (isSpread()) { // f(...xs) passes each element of xs
+                            spread = true;
+                            int spreadStart = pos;
+                            Object values = parseAssignment();
+                            args.addAll(ListVariable.spread(values, "..." + expression.substring(spreadStart, pos).trim()));
+                            while (names.size() < args.size()) {
+                                names.add(null);
+                            }
+                        } else {
+                            names.add(argumentName());
+                            args.add(parseAssignment()); // Support walrus operator in arguments
+                        }
                         skipWhitespace();
                         if (ch == ')') {
                             nextChar(); // consume )
@@ -847,6 +1327,16 @@ public class ExpressionEvaluator {
                 
                 // Handle function call
                 Function function = environment.getFunction(func);
+                if (names.stream().anyMatch(name -> name != null)) {
+                    if (function == null) {
+                        throw new RuntimeException("Named arguments can only be passed to script functions, not " + func);
+                    }
+                    return new Executor(environment).callFunction(func, Executor.bindArguments(function, names, args).toArray());
+                }
+                if (function != null && (spread || args.stream().anyMatch(arg -> arg instanceof Function))) {
+                    // Spread elements may be lists or strings, and lambdas have no source form, so pass the values themselves
+                    return new Executor(environment).callFunction(func, args.toArray());
+                }
                 if (function != null) {
                     // Convert arguments to strings for Executor.executeFunction
                     String[] argStrings = new String[args.size()];
@@ -868,46 +1358,134 @@ public class ExpressionEvaluator {
                     
                     Executor executor = new Executor(environment);
                     return executor.executeFunction(func, argStrings);
-                } else {
-                    throw new RuntimeException("Function not found: " + func);
                 }
+
+                // Native and host-registered functions take the evaluated values directly
+                Object nativeFunction = environment.getVariable(func);
+                if (nativeFunction instanceof Import.FunctionInterface) {
+                    return ((Import.FunctionInterface) nativeFunction).call(args.toArray());
+                }
+                if (Builtins.has(func)) {
+                    return Builtins.call(func, args.toArray(), environment);
+                }
+
+                throw new RuntimeException("Function not found: " + func);
             }
             else {
                 Object varValue = environment.getVariable(func);
-                if (varValue != null) {
-                    // Check for array access syntax: variable[index]
+                // A function's name is the function itself, so it can be passed along and bound
+                if (varValue == null && !environment.hasVariable(func)) {
+                    varValue = environment.getFunction(func);
+                }
+                if (varValue != null || func.equals("null") || environment.hasVariable(func)) {
                     skipWhitespace();
-                    if (ch == '[') {
-                        if (!(varValue instanceof ListVariable)) {
-                            throw new RuntimeException("Cannot use array access on non-list variable: " + func);
+                    // add.bind(5) is add with its first argument given
+                    if ((varValue instanceof Function || varValue instanceof Import.FunctionInterface)
+                            && ch == '.' && expression.startsWith(".bind(", pos)) {
+                        nextChar(); // consume .
+                        String method = parseIdentifier();
+                        return Partial.of(varValue, parseCallArguments(method), environment);
+                    }
+
+                    // Collection methods: s.push(1), q.shift(), d.peekLast()
+                    // List methods, which chain: xs.unique().join(", ")
+                    if (isMemberAccess() && (varValue instanceof CollectionVariable || varValue instanceof ListVariable)) {
+                        while (isMemberAccess() && (varValue instanceof CollectionVariable || varValue instanceof ListVariable)) {
+                            nextChar(); // consume .
+                            String method = parseIdentifier();
+                            List<Object> args = parseCallArguments(method);
+                            varValue = varValue instanceof ListVariable
+                                ? ((ListVariable) varValue).call(method, args)
+                                : ((CollectionVariable) varValue).call(method, args);
+                        }
+                        return varValue;
+                    }
+
+                    // Check for array access syntax: variable[index], and m[1][2] for nested lists
+                    String indexed = func;
+                    while (ch == '[') {
+                        if (!(varValue instanceof List)) {
+                            throw new RuntimeException("Cannot use array access on non-list variable: " + indexed);
                         }
+                        int indexStart = pos;
                         
                         nextChar(); // consume [
                         skipWhitespace();
                         
-                        // Parse the index expression
-                        Object indexValue = parseAssignment();
-                        
-                        if (!(indexValue instanceof Number)) {
-                            throw new RuntimeException("Array index must be a number, got: " + indexValue);
+                        // Parse the index expression; either side of a slice may be left out
+                        Object indexValue = ch == ':' ? null : parseAssignment();
+                        skipWhitespace();
+                        boolean slice = ch == ':';
+                        Object endValue = null;
+                        if (slice) {
+                            nextChar(); // consume :
+                            skipWhitespace();
+                            if (ch != ']') {
+                                endValue = parseAssignment();
+                                skipWhitespace();
+                            }
                         }
                         
-                        skipWhitespace();
                         if (ch != ']') {
                             throw new RuntimeException("Missing ']' in array access at position " + pos);
                         }
                         nextChar(); // consume ]
                         skipWhitespace();
                         
-                        // Get the element from the list
-                        ListVariable list = (ListVariable) varValue;
-                        int index = ((Number) indexValue).intValue();
-                        
-                        if (index < 0 || index >= list.size()) {
-                            throw new RuntimeException("Array index out of bounds: " + index + " (size: " + list.size() + ")");
+                        // Get the element from the list; negative indices count from the end
+                        // A range index slices too: xs[1..3] is xs[1:4]
+                        List<?> list = (List<?>) varValue;
+                        if (!slice && indexValue instanceof Range) {
+                            slice = true;
+                            endValue = ((Range) indexValue).getEnd();
+                            indexValue = ((Range) indexValue).getStart();
                         }
-                        
-                        return list.get(index);
+                        varValue = slice
+                            ? ListVariable.slice(list, indexValue, endValue)
+                            : list.get(ListVariable.position(list, indexValue));
+                        indexed += expression.substring(indexStart, pos).trim();
+                    }
+                    
+                    // Member access on structs, maps and aliased modules: person.name, body.user.id,
+                    // shape.area(), mu.clamp(x, 0, 1)
+                    // person?.address.city gives null instead of failing when a value is null
+                    String accessed = func;
+                    while (isMemberAccess() || isSafeAccess()) {
+                        boolean safe = ch == '?';
+                        if (varValue == null) {
+                            if (!safe) {
+                                throw new RuntimeException("Cannot access a field of null: " + accessed);
+                            }
+                            skipMemberChain();
+                            return null;
+                        }
+                        if (!(varValue instanceof Struct || varValue instanceof Map || varValue instanceof ModuleNamespace)) {
+                            break;
+                        }
+                        if (safe) {
+                            nextChar(); // consume ?
+                        }
+                        nextChar(); // consume .
+                        skipWhitespace();
+                        StringBuilder field = new StringBuilder();
+                        while ((ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_') {
+                            field.append((char)ch);
+                            nextChar();
+                        }
+                        skipWhitespace();
+                        if (varValue instanceof ModuleNamespace) {
+                            varValue = ch == '('
+                                ? ((ModuleNamespace) varValue).call(field.toString(), parseCallArguments(field.toString()))
+                                : ((ModuleNamespace) varValue).get(field.toString());
+                        } else if (varValue instanceof Struct && ch == '(') {
+                            List<Object> args = parseCallArguments(field.toString());
+                            varValue = new Executor(environment).callMethod((Struct) varValue, field.toString(), args.toArray());
+                        } else if (varValue instanceof Struct) {
+                            varValue = ((Struct) varValue).getField(field.toString());
+                        } else {
+                            varValue = ((Map<?, ?>) varValue).get(field.toString());
+                        }
+                        accessed += "." + field;
                     }
                     
                     return varValue;
@@ -923,20 +1501,80 @@ public class ExpressionEvaluator {
             throw new RuntimeException("Unexpected character: '" + (char)ch + "' at position " + pos);
         }
 
-        // Handle exponentiation
         skipWhitespace();
-        if (ch == '^') {
-            nextChar(); // consume ^
-            skipWhitespace();
-            Object factorObj = parseFactor();
-            double xValue = objectToDouble(x);
-            double factorValue = objectToDouble(factorObj);
-            x = Math.pow(xValue, factorValue); // exponentiation
-            skipWhitespace();
-        }
         return x;
     }
 
+    // A . before a field or method, rather than the .. of a range
+    private boolean isMemberAccess() {
+        return ch == '.' && !expression.startsWith("..", pos);
+    }
+
+    // A ?. safe access, as opposed to the ? of a ternary
+    private boolean isSafeAccess() {
+        return ch == '?' && pos + 1 < expression.length() && expression.charAt(pos + 1) == '.';
+    }
+
+    // Skips the rest of a member access chain once ?. has met null
+    private void skipMemberChain() {
+        while (isMemberAccess() || isSafeAccess()) {
+            if (ch == '?') {
+                nextChar(); // consume ?
+            }
+            nextChar(); // consume .
+            parseIdentifier();
+            if (ch == '(') {
+                // A method call's arguments aren't evaluated
+                int close = matchingBracket(expression, pos);
+                if (close == -1) {
+                    throw new RuntimeException("Missing ')' in method call at position " + pos);
+                }
+                pos = close;
+                nextChar(); // consume )
+                skipWhitespace();
+            }
+        }
+    }
+
+    // Reads an identifier and the whitespace after it
+    private String parseIdentifier() {
+        skipWhitespace();
+        StringBuilder identifier = new StringBuilder();
+        while ((ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') || ch == '_') {
+            identifier.append((char)ch);
+            nextChar();
+        }
+        skipWhitespace();
+        return identifier.toString();
+    }
+
+    // Reads a parenthesized argument list: (a, b + 1, f(c))
+    private List<Object> parseCallArguments(String method) {
+        if (ch != '(') {
+            throw new RuntimeException("Expected '(' after method " + method + " at position " + pos);
+        }
+        nextChar(); // consume (
+        skipWhitespace();
+        List<Object> args = new ArrayList<>();
+        if (ch != ')') {
+            while (true) {
+                args.add(parseAssignment());
+                skipWhitespace();
+                if (ch == ')') {
+                    break;
+                }
+                if (ch != ',') {
+                    throw new RuntimeException("Expected ',' or ')' in argument list at position " + pos);
+                }
+                nextChar(); // consume ,
+                skipWhitespace();
+            }
+        }
+        nextChar(); // consume )
+        skipWhitespace();
+        return args;
+    }
+
     // Helper method to skip whitespace
     private void skipWhitespace() {
         while (pos < expression.length() && 
@@ -12,6 +12,19 @@ import java.util.regex.Pattern;
 
 public class ForLoop {
 
+    // Pre-compiled regex patterns
+    // Range-based loops have the format: for (varDecl : collection)
+    // where varDecl does NOT contain "=" and the colon is followed by a collection name
+    private static final Pattern RANGE_DETECT_PATTERN = Pattern.compile(
+        "for\\s*\\(\\s*([^:=]+)\\s*:\\s*([^)]+)\\s*\\)"
+    );
+    private static final Pattern RANGE_PATTERN = Pattern.compile(
+        "for\\s*\\(\\s*([^:]+)\\s*:\\s*([^)]+)\\s*\\)\\s*(\\{)?"
+    );
+    private static final Pattern FOR_PATTERN = Pattern.compile(
+        "for\\s*\\(\\s*([^;]+)\\s*;\\s*([^;]+)\\s*;\\s*([^)]+)\\s*\\)\\s*(\\{)?"
+    );
+
     /**
      * Process a for loop statement in the code
      * @param lines The list of code lines to process
@@ -47,12 +60,7 @@ public class ForLoop {
 
         // Pattern to match: for (type variableName : arrayName) or for (var variableName : arrayName)
         // Note: This should NOT match type annotations like "var i: Float64"
-        // Range-based loops have the format: for (varDecl : collection)
-        // where varDecl does NOT contain "=" and the colon is followed by a collection name
-        Pattern rangePattern = Pattern.compile(
-            "for\\s*\\(\\s*([^:=]+)\\s*:\\s*([^)]+)\\s*\\)"
-        );
-        Matcher matcher = rangePattern.matcher(line);
+        Matcher matcher = RANGE_DETECT_PATTERN.matcher(line);
 
         if (!matcher.find()) {
             return false;
@@ -221,10 +229,7 @@ public class ForLoop {
     }
 
     private static RangeBasedForComponents parseRangeBasedForSyntax(String line) {
-        Pattern rangePattern = Pattern.compile(
-            "for\\s*\\(\\s*([^:]+)\\s*:\\s*([^)]+)\\s*\\)\\s*(\\{)?"
-        );
-        Matcher rangeMatcher = rangePattern.matcher(line);
+        Matcher rangeMatcher = RANGE_PATTERN.matcher(line);
 
         if (!rangeMatcher.find()) {
             throw new RuntimeException(
@@ -249,10 +254,7 @@ public class ForLoop {
      * @return ForLoopComponents containing parsed information
      */
     private static ForLoopComponents parseForLoopSyntax(String line) {
-        Pattern forPattern = Pattern.compile(
-            "for\\s*\\(\\s*([^;]+)\\s*;\\s*([^;]+)\\s*;\\s*([^)]+)\\s*\\)\\s*(\\{)?"
-        );
-        Matcher forMatcher = forPattern.matcher(line);
+        Matcher forMatcher = FOR_PATTERN.matcher(line);
 
         if (!forMatcher.find()) {
             throw new RuntimeException(
@@ -294,7 +296,7 @@ public class ForLoop {
         int endIndex,
         Executor executor
     ) {
-        final int MAX_ITERATIONS = 1000000;
+        int maxIterations = executor.getEnvironment().getLimits().getLoopIterations();
         int iterations = 0;
 
         try {
@@ -323,18 +325,27 @@ public class ForLoop {
 
             // Iterate over each element
             for (Object element : iterable) {
+                executor.getEnvironment().getCancellation().check();
                 // Safety check for infinite loops
-                if (iterations >= MAX_ITERATIONS) {
+                if (iterations >= maxIterations) {
                     throw new RuntimeException(
-                        "Possible infinite loop detected: exceeded " + MAX_ITERATIONS + " iterations"
+                        "Possible infinite loop detected: exceeded " + maxIterations
+                            + " iterations; raise the limit with --max-iterations"
                     );
                 }
 
                 // Assign the current element to the loop variable
                 try {
-                    executor.execute(
-                        variableName + " = " + formatValueForAssignment(element)
-                    );
+                    if (Executor.isPattern(variableName)) {
+                        executor.destructure(variableName, element);
+                    } else if (isScalar(element)) {
+                        executor.execute(
+                            variableName + " = " + formatValueForAssignment(element)
+                        );
+                    } else {
+                        // Lists, tuples and structs can't be written back as source text
+                        executor.getEnvironment().setVariable(variableName, element);
+                    }
                 } catch (Exception e) {
                     throw new RuntimeException(
                         "Error assigning loop variable '" + variableName + "': " + e.getMessage()
@@ -367,11 +378,20 @@ public class ForLoop {
      * @param arrayObject The object to convert
      * @return An Iterable, or null if conversion is not possible
      */
-    private static Iterable<?> convertToIterable(Object arrayObject) {
+    static Iterable<?> convertToIterable(Object arrayObject) {
         if (arrayObject instanceof Iterable) {
             return (Iterable<?>) arrayObject;
         }
 
+        // Maps yield their entries as (key, value) tuples
+        if (arrayObject instanceof java.util.Map) {
+            java.util.List<Tuple> entries = new java.util.ArrayList<>();
+            for (java.util.Map.Entry<?, ?> entry : ((java.util.Map<?, ?>) arrayObject).entrySet()) {
+                entries.add(new Tuple(entry.getKey(), entry.getValue()));
+            }
+            return entries;
+        }
+
         // Handle arrays
         if (arrayObject.getClass().isArray()) {
             // Convert array to list for iteration
@@ -442,6 +462,16 @@ public class ForLoop {
         return variableDeclaration.trim();
     }
 
+    /**
+     * Check whether a value can be written back as a MicroScript literal
+     * @param value The value to check
+     * @return true for null, strings, characters, numbers and booleans
+     */
+    private static boolean isScalar(Object value) {
+        return value == null || value instanceof String || value instanceof Character
+            || value instanceof Number || value instanceof Boolean;
+    }
+
     /**
      * Format a value for assignment in MicroScript syntax
      * @param value The value to format
@@ -480,8 +510,8 @@ public class ForLoop {
         int endIndex,
         Executor executor
     ) {
-        // Set a reasonable maximum iteration count to prevent infinite loops
-        final int MAX_ITERATIONS = 1000000;
+        // The loopIterations limit stops infinite loops
+        int maxIterations = executor.getEnvironment().getLimits().getLoopIterations();
         int iterations = 0;
 
         try {
@@ -497,7 +527,8 @@ public class ForLoop {
             }
 
             // For loop execution
-            while (iterations < MAX_ITERATIONS) {
+            while (iterations < maxIterations) {
+                executor.getEnvironment().getCancellation().check();
                 // Evaluate the condition
                 Object conditionResult;
                 try {
@@ -542,9 +573,10 @@ public class ForLoop {
                 iterations++;
 
                 // Safety check for infinite loops
-                if (iterations >= MAX_ITERATIONS) {
+                if (iterations >= maxIterations) {
                     throw new RuntimeException(
-                        "Possible infinite loop detected: exceeded " + MAX_ITERATIONS + " iterations"
+                        "Possible infinite loop detected: exceeded " + maxIterations
+                            + " iterations; raise the limit with --max-iterations"
                     );
                 }
             }
@@ -563,8 +595,9 @@ public class ForLoop {
         int endIndex,
         Executor executor
     ) throws Statements.BreakException, Statements.ContinueException {
+        List<Statement> statements = Statement.of(lines);
         for (int i = startIndex; i < endIndex; i++) {
-            String line = lines.get(i).trim();
+            String line = statements.get(i).getText();
 
             if (line.isEmpty() || line.startsWith("//")) {
                 continue;
@@ -670,8 +703,9 @@ public class ForLoop {
         List<String> lines,
         int startIndex
     ) {
+        List<Statement> statements = Statement.of(lines);
         for (int i = startIndex; i < lines.size(); i++) {
-            String line = lines.get(i).trim();
+            String line = statements.get(i).getText();
             if (!line.isEmpty() && !line.startsWith("//") && line.contains("{")) {
                 return i;
             }
@@ -680,40 +714,7 @@ public class ForLoop {
     }
 
     private static int findMatchingClosingBrace(List<String> lines, int openBraceIndex) {
-        int braceCount = 0;
-        boolean foundOpenBrace = false;
-
-        for (int i = openBraceIndex; i < lines.size(); i++) {
-            String line = lines.get(i).trim();
-
-            // Skip empty lines and comments
-            if (line.isEmpty() || line.startsWith("//")) {
-                continue;
-            }
-
-            // Handle multi-line comments
-            if (line.startsWith("/*")) {
-                while (i < lines.size() && !lines.get(i).contains("*/")) {
-                    i++;
-                }
-                continue;
-            }
-
-            // Count braces in the line
-            for (char c : line.toCharArray()) {
-                if (c == '{') {
-                    braceCount++;
-                    foundOpenBrace = true;
-                } else if (c == '}') {
-                    braceCount--;
-                    if (foundOpenBrace && braceCount == 0) {
-                        return i;
-                    }
-                }
-            }
-        }
-
-        return -1;
+        return Statement.of(lines).get(openBraceIndex).closingBrace(lines, openBraceIndex);
     }
 
     /**
@@ -0,0 +1,215 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Paths;
+import java.util.ArrayDeque;
+import java.util.ArrayList;
+import java.util.Deque;
+import java.util.List;
+
+/**
+ * Formats source files in place, or standard input to standard output.
+ * Usage:
+ *   microscript fmt [--check] <file>...
+ *   microscript fmt [--stdin-filename <name>] -
+ *
+ * Lines are indented four spaces for every brace, parenthesis or bracket
+ * left open before them, trailing whitespace is removed, runs of blank
+ * lines become one and the file ends with a single newline. A line after
+ * one ending in a backslash is indented one more level. Text inside block
+ * comments keeps its own indentation, and brackets in #define lines aren't
+ * counted. Line endings stay as they were.
+ *
+ * With -, editors can format on save without a temporary file; the name
+ * given with --stdin-filename is used in error messages. --check prints the
+ * files that would change and exits with status 1 instead of writing them.
+ */
+public class Format {
+    private static final String INDENT = "    ";
+    private static final String STDIN = "-";
+
+    private Format() {
+    }
+
+    public static void main(String[] args) {
+        boolean check = false;
+        String stdinName = "<stdin>";
+        List<String> files = new ArrayList<>();
+        for (int i = 0; i < args.length; i++) {
+            if (args[i].equals("--check")) {
+                check = true;
+            } else if (args[i].equals("--stdin-filename") && i + 1 < args.length) {
+                stdinName = args[++i];
+            } else if (args[i].equals(STDIN) || !args[i].startsWith("-")) {
+                files.add(args[i]);
+            } else {
+                files.clear();
+                break;
+            }
+        }
+        if (files.isEmpty() || files.contains(STDIN) && files.size() > 1) {
+            System.err.println("Usage: microscript fmt [--check] <file>... | microscript fmt [--stdin-filename <name>] -");
+            return;
+        }
+
+        boolean failed = false;
+        for (String file : files) {
+            boolean stdin = file.equals(STDIN);
+            String name = stdin ? stdinName : file;
+            String text;
+            try {
+                text = stdin ? new String(System.in.readAllBytes(), StandardCharsets.UTF_8)
+                    : Files.readString(Paths.get(file), StandardCharsets.UTF_8);
+            } catch (IOException e) {
+                System.err.println("Error reading file '" + name + "': " + e.getMessage());
+                failed = true;
+                continue;
+            }
+            String formatted;
+            try {
+                formatted = format(text);
+            } catch (ScriptException e) {
+                System.err.println("Error formatting '" + name + "' at line " + (e.getLine() + 1) + ": " + e.getMessage());
+                failed = true;
+                continue;
+            }
+            if (stdin && !check) {
+                System.out.print(formatted);
+                System.out.flush();
+            } else if (!formatted.equals(text)) {
+                if (check) {
+                    System.out.println(name);
+                    failed = true;
+                } else {
+                    try {
+                        Files.writeString(Paths.get(file), formatted, StandardCharsets.UTF_8);
+                    } catch (IOException e) {
+                        System.err.println("Error writing file '" + name + "': " + e.getMessage());
+                        failed = true;
+                    }
+                }
+            }
+        }
+        if (failed) {
+            System.exit(1);
+        }
+    }
+
+    /**
+     * Formats a whole source text, throwing a ScriptException at a brace,
+     * parenthesis or bracket that doesn't match
+     */
+    public static String format(String text) {
+        if (text.startsWith("\uFEFF")) {
+            text = text.substring(1);
+        }
+        String separator = text.contains("\r\n") ? "\r\n" : "\n";
+        List<String> lines = new ArrayList<>(List.of(text.split("\r\n|\r|\n", -1)));
+        StringBuilder out = new StringBuilder();
+        for (String line : format(lines)) {
+            out.append(line).append(separator);
+        }
+        return out.toString();
+    }
+
+    public static List<String> format(List<String> lines) {
+        List<String> output = new ArrayList<>();
+        // Open brackets with the lines they were opened on
+        Deque<Character> open = new ArrayDeque<>();
+        Deque<Integer> openedAt = new ArrayDeque<>();
+        int comment = 0; // Block comment nesting at the start of the line
+        boolean continued = false; // The previous line ended in a backslash
+        boolean directive = false; // The line is part of a #define or #undef
+        for (int number = 0; number < lines.size(); number++) {
+            String line = lines.get(number);
+            if (comment > 0) {
+                output.add(line.stripTrailing());
+                comment = scan(line, number, comment, open, openedAt);
+                continue;
+            }
+            String trimmed = line.strip();
+            if (!continued) {
+                directive = trimmed.startsWith("#");
+            }
+            if (trimmed.isEmpty()) {
+                continued = false;
+                if (!output.isEmpty() && !output.get(output.size() - 1).isEmpty()) {
+                    output.add("");
+                }
+                continue;
+            }
+            int closers = 0;
+            while (closers < trimmed.length() && "})]".indexOf(trimmed.charAt(closers)) >= 0) {
+                closers++;
+            }
+            int depth = Math.max(0, open.size() - closers) + (continued ? 1 : 0);
+            output.add(INDENT.repeat(depth) + trimmed);
+            if (!directive) {
+                comment = scan(trimmed, number, 0, open, openedAt);
+            }
+            continued = trimmed.endsWith("\\");
+        }
+        while (!output.isEmpty() && output.get(output.size() - 1).isEmpty()) {
+            output.remove(output.size() - 1);
+        }
+        if (!open.isEmpty()) {
+            throw new ScriptException("Unclosed '" + open.peek() + "'", openedAt.peek(), null);
+        }
+        return output;
+    }
+
+    // Tracks the brackets a line opens and closes outside strings and comments,
+    // returning the block comment nesting at its end
+    private static int scan(String line, int number, int comment, Deque<Character> open, Deque<Integer> openedAt) {
+        char quote = 0;
+        for (int i = 0; i < line.length(); i++) {
+            char c = line.charAt(i);
+            char next = i + 1 < line.length() ? line.charAt(i + 1) : 0;
+            if (comment > 0) {
+                if (c == '*' && next == '/') {
+                    comment--;
+                    i++;
+                } else if (c == '/' && next == '*') {
+                    comment++;
+                    i++;
+                }
+            } else if (quote != 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == quote) {
+                    quote = 0;
+                }
+            } else if (c == '"' || c == '\'') {
+                quote = c;
+            } else if (c == '/' && next == '/') {
+                break;
+            } else if (c == '/' && next == '*') {
+                comment++;
+                i++;
+            } else if (c == '{' || c == '(' || c == '[') {
+                open.push(c);
+                openedAt.push(number);
+            } else if (c == '}' || c == ')' || c == ']') {
+                char expected = c == '}' ? '{' : c == ')' ? '(' : '[';
+                if (open.isEmpty()) {
+                    throw new ScriptException("Unmatched '" + c + "'", number, null);
+                }
+                if (open.peek() != expected) {
+                    throw new ScriptException("Mismatched '" + c + "' for '" + open.peek() + "' opened at line "
+                        + (openedAt.peek() + 1), number, null);
+                }
+                open.pop();
+                openedAt.pop();
+            }
+        }
+        return comment;
+    }
+}
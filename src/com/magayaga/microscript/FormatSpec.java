@@ -0,0 +1,206 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.math.BigDecimal;
+import java.math.BigInteger;
+import java.math.RoundingMode;
+import java.util.Locale;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Format specs after a colon in template placeholders:
+ *
+ *   console.write("{price:.2f}");    // 3.50
+ *   console.write("[{name:>10}]");   // [     Alice]
+ *   console.write("{n:x} {n:08b}");  // ff 11111111
+ *
+ * A spec is [[fill]align][+][0][width][,][.precision][type], as in Python:
+ *
+ *   align      < left, > right, ^ centre; numbers go right and text left
+ *   +          a sign on positive numbers too
+ *   0          pad numbers with zeros after the sign
+ *   ,          separate thousands
+ *   precision  digits after the point, or the characters of text kept
+ *   type       f fixed, e scientific, % percent, d whole number,
+ *              x X o b hexadecimal, octal or binary, s text
+ *
+ * Whole-number types take numbers without a fraction, so 5.0 formats as 5.
+ */
+public class FormatSpec {
+    private static final Pattern SPEC_PATTERN =
+        Pattern.compile("(?:(.)?([<>^]))?(\\+)?(0)?(\\d+)?(,)?(?:\\.(\\d+))?([feE%dxXobs])?");
+
+    private FormatSpec() {
+    }
+
+    /**
+     * Where the spec starts in a placeholder, after its colon, or -1 when it has
+     * none. The colon must be outside strings and brackets and not part of ::
+     * or a ? : conditional, and what follows must be a valid spec.
+     */
+    public static int specStart(String placeholder) {
+        int depth = 0;
+        char quote = 0;
+        int colon = -1;
+        boolean conditional = false;
+        for (int i = 0; i < placeholder.length(); i++) {
+            char c = placeholder.charAt(i);
+            if (quote != 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == quote) {
+                    quote = 0;
+                }
+            } else if (c == '"' || c == '\'') {
+                quote = c;
+            } else if (c == '(' || c == '[') {
+                depth++;
+            } else if (c == ')' || c == ']') {
+                depth--;
+            } else if (depth == 0 && c == '?') {
+                conditional = true;
+            } else if (depth == 0 && c == ':') {
+                if (i + 1 < placeholder.length() && placeholder.charAt(i + 1) == ':') {
+                    i++; // math::sqrt
+                } else {
+                    colon = i;
+                }
+            }
+        }
+        if (colon <= 0 || conditional || !SPEC_PATTERN.matcher(placeholder.substring(colon + 1)).matches()) {
+            return -1;
+        }
+        return colon + 1;
+    }
+
+    /**
+     * Formats a value by a spec, throwing when the spec doesn't suit the value
+     */
+    public static String format(Object value, String spec) {
+        Matcher matcher = SPEC_PATTERN.matcher(spec);
+        if (!matcher.matches()) {
+            throw new RuntimeException("Invalid format spec '" + spec + "'");
+        }
+        String fill = matcher.group(1) != null ? matcher.group(1) : " ";
+        String align = matcher.group(2);
+        boolean plus = matcher.group(3) != null;
+        boolean zero = matcher.group(4) != null;
+        int width = matcher.group(5) != null ? Integer.parseInt(matcher.group(5)) : 0;
+        boolean grouping = matcher.group(6) != null;
+        int precision = matcher.group(7) != null ? Integer.parseInt(matcher.group(7)) : -1;
+        String type = matcher.group(8) != null ? matcher.group(8) : "";
+
+        if (!(value instanceof Number) || type.equals("s")) {
+            if (plus || zero || grouping || !type.isEmpty() && !type.equals("s")) {
+                throw new RuntimeException("Format spec '" + spec + "' needs a number, got " + describe(value));
+            }
+            String text = String.valueOf(value);
+            if (precision >= 0 && precision < text.length()) {
+                text = text.substring(0, precision);
+            }
+            return pad(text, "", fill, align != null ? align : "<", width);
+        }
+
+        double number = ((Number) value).doubleValue();
+        boolean negative = number < 0 || number == 0 && 1 / number < 0;
+        String digits;
+        switch (type) {
+            case "d":
+            case "x":
+            case "X":
+            case "o":
+            case "b": {
+                if (precision >= 0) {
+                    throw new RuntimeException("Format spec '" + spec + "' can't have a precision");
+                }
+                BigInteger whole = whole(value, spec).abs();
+                int radix = type.equals("d") ? 10 : type.equals("o") ? 8 : type.equals("b") ? 2 : 16;
+                digits = whole.toString(radix);
+                if (type.equals("X")) {
+                    digits = digits.toUpperCase(Locale.ROOT);
+                }
+                negative = whole.signum() != 0 && negative;
+                break;
+            }
+            case "e":
+            case "E":
+                digits = String.format(Locale.ROOT, "%." + (precision >= 0 ? precision : 6) + type, Math.abs(number));
+                break;
+            case "%":
+                digits = fixed(Math.abs(number) * 100, precision >= 0 ? precision : 6) + "%";
+                break;
+            case "f":
+                digits = fixed(Math.abs(number), precision >= 0 ? precision : 6);
+                break;
+            default:
+                // No type: a precision fixes the decimals, otherwise the number prints as usual
+                digits = precision >= 0 ? fixed(Math.abs(number), precision)
+                    : String.valueOf(value).replaceFirst("^-", "");
+                break;
+        }
+        if (grouping) {
+            digits = group(digits);
+        }
+        String sign = negative ? "-" : plus ? "+" : "";
+        if (zero && align == null) {
+            return sign + "0".repeat(Math.max(0, width - sign.length() - digits.length())) + digits;
+        }
+        return pad(digits, sign, fill, align != null ? align : ">", width);
+    }
+
+    private static BigInteger whole(Object value, String spec) {
+        if (value instanceof Integer || value instanceof Long || value instanceof Short || value instanceof Byte) {
+            return BigInteger.valueOf(((Number) value).longValue());
+        }
+        double number = ((Number) value).doubleValue();
+        if (Double.isNaN(number) || Double.isInfinite(number) || number != Math.rint(number)) {
+            throw new RuntimeException("Format spec '" + spec + "' needs a whole number, got " + value);
+        }
+        return new BigDecimal(number).toBigInteger();
+    }
+
+    private static String fixed(double number, int precision) {
+        if (Double.isNaN(number) || Double.isInfinite(number)) {
+            return String.valueOf(number);
+        }
+        return new BigDecimal(number).setScale(precision, RoundingMode.HALF_EVEN).toPlainString();
+    }
+
+    // Puts commas between groups of three digits before the point
+    private static String group(String digits) {
+        int end = 0;
+        while (end < digits.length() && Character.isDigit(digits.charAt(end))) {
+            end++;
+        }
+        StringBuilder grouped = new StringBuilder();
+        for (int i = 0; i < end; i++) {
+            if (i > 0 && (end - i) % 3 == 0) {
+                grouped.append(',');
+            }
+            grouped.append(digits.charAt(i));
+        }
+        return grouped + digits.substring(end);
+    }
+
+    private static String pad(String text, String sign, String fill, String align, int width) {
+        int padding = Math.max(0, width - sign.length() - text.length());
+        switch (align) {
+            case "<":
+                return sign + text + fill.repeat(padding);
+            case "^":
+                return fill.repeat(padding / 2) + sign + text + fill.repeat(padding - padding / 2);
+            default:
+                return fill.repeat(padding) + sign + text;
+        }
+    }
+
+    private static String describe(Object value) {
+        return value == null ? "null" : value instanceof String ? "\"" + value + "\"" : value.toString();
+    }
+}
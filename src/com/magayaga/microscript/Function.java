@@ -6,19 +6,27 @@
  */
 package com.magayaga.microscript;
 
+import java.util.Collections;
 import java.util.List;
 
 public class Function {
     private final String name;
     private final List<Parameter> parameters;
     private final String returnType;
-    private final List<String> body;
+    private final Body body;
+    // The function's static variables, shared by all its calls
+    private Environment statics;
+    // The module an exported function came from, whose private names its body sees
+    private Environment module;
+    private int line = -1;
+    private String docs = "";
+    private List<String> typeParameters = Collections.emptyList();
 
     public Function(String name, List<Parameter> parameters, String returnType, List<String> body) {
         this.name = name;
         this.parameters = parameters;
         this.returnType = returnType;
-        this.body = body;
+        this.body = body != null ? Body.of(body) : null;
     }
 
     public String getName() {
@@ -33,7 +41,94 @@ public class Function {
         return returnType;
     }
 
-    public List<String> getBody() {
+    public Body getBody() {
         return body;
     }
+
+    /**
+     * The 0-based source line of the header, or -1 when it isn't known.
+     * Body line i is source line getLine() + 1 + i.
+     */
+    public int getLine() {
+        return line;
+    }
+
+    public void setLine(int line) {
+        this.line = line;
+    }
+
+    /**
+     * The /// comment block written directly above the definition, one line
+     * per comment line, or an empty string
+     */
+    public String getDocs() {
+        return docs;
+    }
+
+    public void setDocs(String docs) {
+        this.docs = docs;
+    }
+
+    /**
+     * The type parameters of a generic function, such as T in
+     * function first<T>(xs: List<T>) -> T; empty for other functions.
+     * Their values aren't checked at run time.
+     */
+    public List<String> getTypeParameters() {
+        return typeParameters;
+    }
+
+    public void setTypeParameters(List<String> typeParameters) {
+        this.typeParameters = typeParameters;
+    }
+
+    /**
+     * The signature as written in source, without the function keyword:
+     * first<T>(xs: List<T>) -> T. A void return type is left out.
+     */
+    public String getSignature() {
+        StringBuilder text = new StringBuilder(name);
+        if (!typeParameters.isEmpty()) {
+            text.append("<").append(String.join(", ", typeParameters)).append(">");
+        }
+        text.append("(");
+        for (int i = 0; i < parameters.size(); i++) {
+            Parameter parameter = parameters.get(i);
+            text.append(i > 0 ? ", " : "").append(parameter.getName()).append(": ").append(parameter.getType());
+        }
+        text.append(")");
+        return returnType.equals("void") ? text.toString() : text + " -> " + returnType;
+    }
+
+    /**
+     * The environment of the script module that exported the function, which
+     * its body runs in instead of the caller's, or null
+     */
+    public Environment getModule() {
+        return module;
+    }
+
+    public void setModule(Environment module) {
+        this.module = module;
+    }
+
+    /**
+     * Where the function's static variables live, created under the calling
+     * script's root when the first one is declared, so that they share its
+     * memory accounting, tracer and limits
+     */
+    public synchronized Environment getStatics(Environment caller) {
+        if (statics == null) {
+            statics = caller.newSharedScope();
+        }
+        return statics;
+    }
+
+    /**
+     * The body classified into statements, built when the function is
+     * defined and reused by every call and by the blocks inside the body
+     */
+    public List<Statement> getStatements() {
+        return getBody().getStatements();
+    }
 }
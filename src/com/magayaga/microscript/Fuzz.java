@@ -0,0 +1,310 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.io.OutputStream;
+import java.io.PrintStream;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.List;
+import java.util.Random;
+import java.util.concurrent.ExecutionException;
+import java.util.concurrent.ExecutorService;
+import java.util.concurrent.Executors;
+import java.util.concurrent.Future;
+import java.util.concurrent.TimeUnit;
+import java.util.concurrent.TimeoutException;
+import java.util.stream.Collectors;
+import java.util.stream.Stream;
+
+/**
+ * Mutation fuzzer for the parser, the macro preprocessor and the expression
+ * evaluator. Script errors are reported as plain RuntimeExceptions; any other
+ * exception or error, or an input that doesn't finish in time, is a bug and is
+ * saved to the crashers directory.
+ * Usage:
+ *   microscript fuzz <parse|macros|evaluate> [--corpus <dir>] [--iterations <n>]
+ *                    [--seed <n>] [--timeout <ms>] [--crashers <dir>]
+ *   microscript fuzz <parse|macros|evaluate> --run <file>
+ */
+public class Fuzz {
+    private static final List<String> TARGETS = Arrays.asList("parse", "macros", "evaluate");
+
+    // Fragments spliced into inputs to reach interesting parser states
+    private static final String[] TOKENS = {
+        "{", "}", "(", ")", "[", "]", "\"", ",", ";", ":", "::", "->", "=>", "|x|", "\n",
+        "#define ", "#undef ", "#define A(x) (x * x)\n", "A(", "function f(a: Int32) {\n", "fn main() {\n",
+        "var x: Int32 = ", "if (", "elif (", "else {", "while (", "for (", "switch (", "return ",
+        "console.write(", "console.writef(\"{", "math::sqrt(", "spawn ", "await ", "atomic.add(",
+        "0", "1.5", "-", "+", "*", "/", "%", "==", "!=", "<=", "&&", "||", "!", "?", "++", "--", ".",
+        "true", "false", "null", "/*", "*/", "//", "\\", "'a'", "@map => (x) [1, 2]", "@__globalfn__ {"
+    };
+
+    // Statements that touch the outside world or wait for input are dropped from inputs
+    private static final List<String> UNSAFE = Arrays.asList(
+        "console.system", "import", "input(", "ffi::", "http::", "io::", "dns::", "redis::", "spawn", "mutex");
+
+    private static final String[] EXPRESSIONS = {
+        "1 + 2 * 3", "(1 + 2) * 3", "10 / 4", "7 % 3", "1 < 2 && 3 > 2", "!(1 == 2)",
+        "1 < 2 ? 3 : 4", "x := 5", "[1, 2, 3]", "\"text\"", "-(-1)", "2 * (3 + (4 - 1))"
+    };
+
+    public static void main(String[] args) {
+        if (args.length < 1 || !TARGETS.contains(args[0])) {
+            System.err.println("Usage: microscript fuzz <parse|macros|evaluate> [--corpus <dir>] [--iterations <n>]"
+                + " [--seed <n>] [--timeout <ms>] [--crashers <dir>] [--run <file>]");
+            return;
+        }
+        String target = args[0];
+        String corpus = "testdata";
+        String crashers = "fuzz-crashers";
+        String runFile = null;
+        long iterations = 100_000;
+        long seed = System.nanoTime();
+        long timeoutMillis = 2_000;
+        try {
+            for (int i = 1; i < args.length; i++) {
+                String arg = args[i];
+                if (i + 1 >= args.length) {
+                    throw new IllegalArgumentException("Missing value for " + arg);
+                }
+                switch (arg) {
+                    case "--corpus": corpus = args[++i]; break;
+                    case "--crashers": crashers = args[++i]; break;
+                    case "--run": runFile = args[++i]; break;
+                    case "--iterations": iterations = Long.parseLong(args[++i]); break;
+                    case "--seed": seed = Long.parseLong(args[++i]); break;
+                    case "--timeout": timeoutMillis = Long.parseLong(args[++i]); break;
+                    default: throw new IllegalArgumentException("Unexpected argument: " + arg);
+                }
+            }
+        } catch (IllegalArgumentException e) {
+            System.err.println(e.getMessage());
+            return;
+        }
+
+        try {
+            Fuzz fuzz = new Fuzz(target, timeoutMillis, Paths.get(crashers));
+            if (runFile != null) {
+                String input = new String(Files.readAllBytes(Paths.get(runFile)), StandardCharsets.UTF_8);
+                Throwable failure = fuzz.execute(input);
+                System.out.println(failure == null ? "ok" : describe(failure));
+            } else {
+                fuzz.run(loadCorpus(target, Paths.get(corpus)), iterations, seed);
+            }
+            fuzz.shutdown();
+        } catch (IOException e) {
+            System.err.println("Fuzz error: " + e.getMessage());
+        }
+    }
+
+    private final String target;
+    private final long timeoutMillis;
+    private final Path crashersDir;
+    private ExecutorService worker = newWorker();
+
+    public Fuzz(String target, long timeoutMillis, Path crashersDir) {
+        this.target = target;
+        this.timeoutMillis = timeoutMillis;
+        this.crashersDir = crashersDir;
+    }
+
+    /**
+     * Feeds one input to the target. Returns null when it finished normally or
+     * with a script error, and the failure otherwise.
+     */
+    public Throwable execute(String input) {
+        Future<?> result = worker.submit(() -> {
+            runTarget(input);
+            return null;
+        });
+        try {
+            result.get(timeoutMillis, TimeUnit.MILLISECONDS);
+            return null;
+        } catch (ExecutionException e) {
+            Throwable cause = e.getCause();
+            // Line information wraps the error that actually happened
+            if (cause instanceof ScriptException && cause.getCause() != null) {
+                cause = cause.getCause();
+            }
+            return isScriptError(cause) ? null : cause;
+        } catch (TimeoutException e) {
+            // The stuck thread can't be stopped safely; abandon it and start over
+            result.cancel(true);
+            worker.shutdownNow();
+            worker = newWorker();
+            return new TimeoutException("No result after " + timeoutMillis + "ms");
+        } catch (InterruptedException e) {
+            Thread.currentThread().interrupt();
+            return null;
+        }
+    }
+
+    public static void fuzzParse(String source) {
+        new Interpreter().run(sanitize(source));
+    }
+
+    public static void fuzzExpandMacros(String source) {
+        new Define().preprocess(Arrays.asList(source.split("\\r?\\n", -1)));
+    }
+
+    public static void fuzzEvaluate(String expression) {
+        new ExpressionEvaluator(expression, new Environment()).parse();
+    }
+
+    private void runTarget(String input) {
+        switch (target) {
+            case "parse":
+                fuzzParse(input);
+                break;
+            case "macros":
+                fuzzExpandMacros(input);
+                break;
+            default:
+                fuzzEvaluate(input);
+        }
+    }
+
+    private void run(List<String> corpus, long iterations, long seed) throws IOException {
+        System.err.println("Fuzzing " + target + " with " + corpus.size() + " seed inputs, seed " + seed);
+        Random random = new Random(seed);
+        int failures = 0;
+        PrintStream stdout = System.out;
+        // Scripts print as they run; keep the terminal for the fuzzer's own report
+        System.setOut(new PrintStream(new OutputStream() {
+            @Override
+            public void write(int b) {
+            }
+        }));
+        try {
+            for (long i = 0; i < iterations; i++) {
+                String input = mutate(corpus.get(random.nextInt(corpus.size())), corpus, random);
+                Throwable failure = execute(input);
+                if (failure != null) {
+                    failures++;
+                    Path saved = save(input);
+                    System.err.println("Failure saved to " + saved + ": " + describe(failure));
+                }
+                if ((i + 1) % 10_000 == 0) {
+                    System.err.println((i + 1) + " inputs, " + failures + " failures");
+                }
+            }
+        } finally {
+            System.setOut(stdout);
+        }
+        System.err.println("Done: " + iterations + " inputs, " + failures + " failures");
+    }
+
+    private void shutdown() {
+        worker.shutdownNow();
+    }
+
+    /**
+     * Applies one to four random edits: inserting a token, deleting or
+     * duplicating a span, replacing a character or splicing in another input
+     */
+    static String mutate(String input, List<String> corpus, Random random) {
+        StringBuilder text = new StringBuilder(input);
+        int edits = 1 + random.nextInt(4);
+        for (int e = 0; e < edits; e++) {
+            int at = text.length() == 0 ? 0 : random.nextInt(text.length() + 1);
+            switch (random.nextInt(5)) {
+                case 0:
+                    text.insert(at, TOKENS[random.nextInt(TOKENS.length)]);
+                    break;
+                case 1:
+                    if (text.length() > 0) {
+                        int end = Math.min(text.length(), at + 1 + random.nextInt(16));
+                        text.delete(Math.min(at, end), end);
+                    }
+                    break;
+                case 2:
+                    if (text.length() > 0) {
+                        int start = random.nextInt(text.length());
+                        int end = Math.min(text.length(), start + 1 + random.nextInt(32));
+                        text.insert(at, text.substring(start, end));
+                    }
+                    break;
+                case 3:
+                    if (text.length() > 0) {
+                        text.setCharAt(Math.min(at, text.length() - 1), (char) (32 + random.nextInt(95)));
+                    }
+                    break;
+                default:
+                    String other = corpus.get(random.nextInt(corpus.size()));
+                    int start = other.isEmpty() ? 0 : random.nextInt(other.length());
+                    text.insert(at, other.substring(start, Math.min(other.length(), start + 1 + random.nextInt(64))));
+            }
+        }
+        return text.toString();
+    }
+
+    static String sanitize(String source) {
+        return Arrays.stream(source.split("\\r?\\n", -1))
+            .filter(line -> UNSAFE.stream().noneMatch(line::contains))
+            .collect(Collectors.joining("\n"));
+    }
+
+    private static boolean isScriptError(Throwable failure) {
+        return failure.getClass() == RuntimeException.class;
+    }
+
+    private static String describe(Throwable failure) {
+        StackTraceElement[] trace = failure.getStackTrace();
+        String where = trace.length > 0 ? " at " + trace[0] : "";
+        return failure.getClass().getName() + ": " + failure.getMessage() + where;
+    }
+
+    private Path save(String input) throws IOException {
+        Files.createDirectories(crashersDir);
+        Path path = crashersDir.resolve(target + "-" + Integer.toHexString(input.hashCode()) + ".txt");
+        Files.write(path, input.getBytes(StandardCharsets.UTF_8));
+        return path;
+    }
+
+    private static List<String> loadCorpus(String target, Path directory) throws IOException {
+        List<String> corpus = new ArrayList<>();
+        if (Files.isDirectory(directory)) {
+            try (Stream<Path> files = Files.walk(directory)) {
+                for (Path file : files.filter(Files::isRegularFile).collect(Collectors.toList())) {
+                    String text = new String(Files.readAllBytes(file), StandardCharsets.UTF_8);
+                    if (target.equals("evaluate")) {
+                        // Expressions come from the right-hand side of declarations
+                        for (String line : text.split("\\r?\\n")) {
+                            int equals = line.indexOf('=');
+                            if (line.trim().startsWith("var ") && equals > 0) {
+                                corpus.add(line.substring(equals + 1).trim().replaceAll(";$", ""));
+                            }
+                        }
+                    } else {
+                        corpus.add(text);
+                    }
+                }
+            }
+        }
+        if (target.equals("evaluate")) {
+            corpus.addAll(Arrays.asList(EXPRESSIONS));
+        }
+        if (corpus.isEmpty()) {
+            corpus.add("fn main() {\n    console.write(1 + 2);\n}\n");
+        }
+        return corpus;
+    }
+
+    private static ExecutorService newWorker() {
+        return Executors.newSingleThreadExecutor(runnable -> {
+            Thread thread = new Thread(runnable, "microscript-fuzz");
+            thread.setDaemon(true);
+            return thread;
+        });
+    }
+}
@@ -0,0 +1,567 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.LinkedHashSet;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+import java.util.TreeSet;
+
+import com.magayaga.microscript.Transpiler.Assign;
+import com.magayaga.microscript.Transpiler.Binary;
+import com.magayaga.microscript.Transpiler.Call;
+import com.magayaga.microscript.Transpiler.Declare;
+import com.magayaga.microscript.Transpiler.Each;
+import com.magayaga.microscript.Transpiler.Evaluate;
+import com.magayaga.microscript.Transpiler.Expr;
+import com.magayaga.microscript.Transpiler.For;
+import com.magayaga.microscript.Transpiler.Function;
+import com.magayaga.microscript.Transpiler.If;
+import com.magayaga.microscript.Transpiler.Index;
+import com.magayaga.microscript.Transpiler.Jump;
+import com.magayaga.microscript.Transpiler.ListOf;
+import com.magayaga.microscript.Transpiler.Literal;
+import com.magayaga.microscript.Transpiler.Name;
+import com.magayaga.microscript.Transpiler.Node;
+import com.magayaga.microscript.Transpiler.Print;
+import com.magayaga.microscript.Transpiler.Program;
+import com.magayaga.microscript.Transpiler.Return;
+import com.magayaga.microscript.Transpiler.Step;
+import com.magayaga.microscript.Transpiler.Unary;
+import com.magayaga.microscript.Transpiler.Variable;
+import com.magayaga.microscript.Transpiler.While;
+
+/**
+ * Writes a transpiled script as a Go main package:
+ *
+ *   microscript transpile --target go fib.mus && go build fib.go
+ *
+ * Types map directly (Int32 is int32, Float64 is float64, Char is rune and
+ * List<T> is []T) and console.write prints values the way the interpreter
+ * does, so a Float64 5 still prints as 5.0. The script's top level becomes
+ * Go's main, so a script function called main is renamed scriptMain.
+ */
+public class GoTarget implements Transpiler.Target {
+    // Go keywords, predeclared names and the names the output itself uses
+    private static final Set<String> RESERVED = Set.of("break", "case", "chan", "const", "continue", "default",
+        "defer", "else", "fallthrough", "for", "func", "go", "goto", "if", "import", "interface", "map", "package",
+        "range", "return", "select", "struct", "switch", "type", "var", "any", "append", "bool", "byte", "cap",
+        "clear", "close", "complex", "copy", "delete", "error", "false", "float32", "float64", "imag", "int",
+        "int32", "int64", "iota", "len", "make", "max", "min", "new", "nil", "panic", "print", "println", "real",
+        "recover", "rune", "string", "true", "init", "fmt", "math", "strconv", "strings", "formatFloat", "formatList");
+
+    private static final Map<String, String> MATH_CONSTANTS = Map.ofEntries(
+        Map.entry("pi", "math.Pi"),
+        Map.entry("e", "math.E"),
+        Map.entry("eulerNumber", "math.E"),
+        Map.entry("tau", "2 * math.Pi"),
+        Map.entry("phi", "math.Phi"),
+        Map.entry("silverRatio", "1 + math.Sqrt2"),
+        Map.entry("eulerConstant", "0.57721566490153286060"),
+        Map.entry("catalan", "0.91596559417721901505"),
+        Map.entry("apery", "1.20205690315959428540"),
+        Map.entry("feigenbaumDelta", "4.66920160910299067185"),
+        Map.entry("feigenbaumAlpha", "2.50290787509589282228"),
+        Map.entry("plastic", "1.32471795724474602596"),
+        Map.entry("twinPrime", "0.66016181584686957392"));
+
+    // Go operator precedence, loosest first; a primary expression binds tightest
+    private static final int OR = 1;
+    private static final int AND = 2;
+    private static final int COMPARE = 3;
+    private static final int ADD = 4;
+    private static final int MULTIPLY = 5;
+    private static final int UNARY = 6;
+    private static final int PRIMARY = 7;
+
+    private final Set<String> imports = new TreeSet<>();
+    private final Set<String> helpers = new LinkedHashSet<>();
+    private StringBuilder out;
+    private int indent;
+    private Function current;
+
+    @Override
+    public String extension() {
+        return ".go";
+    }
+
+    @Override
+    public String emit(Program program) {
+        out = new StringBuilder();
+        for (Function function : program.functions) {
+            function(function);
+        }
+        current = program.main;
+        out.append("func main() {\n");
+        indent = 1;
+        block(program.main.body);
+        out.append("}\n");
+        String body = out.toString();
+
+        StringBuilder file = new StringBuilder();
+        file.append("// Code generated by microscript transpile from ").append(program.name).append(". DO NOT EDIT.\n\n");
+        file.append("package main\n\n");
+        if (!imports.isEmpty() || !helpers.isEmpty()) {
+            if (helpers.contains("formatFloat")) {
+                imports.add("math");
+                imports.add("strconv");
+                imports.add("strings");
+            }
+            if (helpers.contains("formatList")) {
+                imports.add("strings");
+            }
+            file.append("import (\n");
+            for (String name : imports) {
+                file.append("\t\"").append(name).append("\"\n");
+            }
+            file.append(")\n\n");
+        }
+        if (!program.globals.isEmpty()) {
+            file.append("var (\n");
+            for (Map.Entry<String, String> global : program.globals.entrySet()) {
+                file.append('\t').append(name(global.getKey())).append(' ').append(type(global.getValue())).append('\n');
+            }
+            file.append(")\n\n");
+        }
+        file.append(body);
+        if (helpers.contains("formatFloat")) {
+            file.append("\n// formatFloat prints a number the way the interpreter does: 5.0, 0.25, 1.0E10\n");
+            file.append("func formatFloat(value float64, bits int) string {\n");
+            file.append("\tswitch {\n");
+            file.append("\tcase math.IsNaN(value):\n\t\treturn \"NaN\"\n");
+            file.append("\tcase math.IsInf(value, 1):\n\t\treturn \"Infinity\"\n");
+            file.append("\tcase math.IsInf(value, -1):\n\t\treturn \"-Infinity\"\n");
+            file.append("\t}\n");
+            file.append("\tif abs := math.Abs(value); value == 0 || abs >= 1e-3 && abs < 1e7 {\n");
+            file.append("\t\ttext := strconv.FormatFloat(value, 'f', -1, bits)\n");
+            file.append("\t\tif !strings.Contains(text, \".\") {\n\t\t\ttext += \".0\"\n\t\t}\n");
+            file.append("\t\treturn text\n");
+            file.append("\t}\n");
+            file.append("\tmantissa, exponent, _ := strings.Cut(strconv.FormatFloat(value, 'E', -1, bits), \"E\")\n");
+            file.append("\tif !strings.Contains(mantissa, \".\") {\n\t\tmantissa += \".0\"\n\t}\n");
+            file.append("\tpower, _ := strconv.Atoi(exponent)\n");
+            file.append("\treturn mantissa + \"E\" + strconv.Itoa(power)\n");
+            file.append("}\n");
+        }
+        if (helpers.contains("formatList")) {
+            file.append("\n// formatList prints a list the way the interpreter does: [1, 2, 3]\n");
+            file.append("func formatList[T any](items []T, format func(T) string) string {\n");
+            file.append("\ttexts := make([]string, len(items))\n");
+            file.append("\tfor i, item := range items {\n\t\ttexts[i] = format(item)\n\t}\n");
+            file.append("\treturn \"[\" + strings.Join(texts, \", \") + \"]\"\n");
+            file.append("}\n");
+        }
+        if (helpers.contains("floorDiv")) {
+            file.append("\n// floorDiv divides the way the interpreter's // does, rounding down: floorDiv(-7, 2) is -4\n");
+            file.append("func floorDiv[T int32 | int64](a, b T) T {\n");
+            file.append("\tquotient := a / b\n");
+            file.append("\tif a%b != 0 && (a < 0) != (b < 0) {\n\t\tquotient--\n\t}\n");
+            file.append("\treturn quotient\n");
+            file.append("}\n");
+        }
+        return file.toString();
+    }
+
+    private void function(Function function) {
+        current = function;
+        out.append("func ").append(functionName(function.name)).append('(');
+        List<Variable> parameters = function.parameters;
+        for (int i = 0; i < parameters.size(); i++) {
+            out.append(i > 0 ? ", " : "").append(name(parameters.get(i).name)).append(' ').append(type(parameters.get(i).type));
+        }
+        out.append(')');
+        if (!function.returnType.equals(Transpiler.VOID)) {
+            out.append(' ').append(type(function.returnType));
+        }
+        out.append(" {\n");
+        indent = 1;
+        block(function.body);
+        if (!function.returnType.equals(Transpiler.VOID) && !terminates(function.body)) {
+            line("panic(\"" + function.name + " ended without returning a value\")");
+        }
+        out.append("}\n\n");
+    }
+
+    // Whether Go sees the block as always returning, so no return is missing after it
+    private static boolean terminates(List<Node> body) {
+        if (body.isEmpty()) {
+            return false;
+        }
+        Node last = body.get(body.size() - 1);
+        if (last instanceof Return) {
+            return true;
+        }
+        if (last instanceof If) {
+            If statement = (If) last;
+            if (statement.otherwise == null || !terminates(statement.otherwise)) {
+                return false;
+            }
+            for (List<Node> branch : statement.branches) {
+                if (!terminates(branch)) {
+                    return false;
+                }
+            }
+            return true;
+        }
+        return false;
+    }
+
+    private static String functionName(String name) {
+        return name.equals("main") ? "scriptMain" : name(name);
+    }
+
+    private static String name(String name) {
+        return RESERVED.contains(name) ? name + "_" : name;
+    }
+
+    private static String type(String type) {
+        String element = Transpiler.elementType(type);
+        if (element != null) {
+            return "[]" + type(element);
+        }
+        switch (type) {
+            case "Int32":
+                return "int32";
+            case "Int64":
+                return "int64";
+            case "Float32":
+                return "float32";
+            case "String":
+                return "string";
+            case "Char":
+                return "rune";
+            case "Bool":
+                return "bool";
+            default:
+                return "float64";
+        }
+    }
+
+    private void line(String text) {
+        out.append("\t".repeat(indent)).append(text).append('\n');
+    }
+
+    private void block(List<Node> body) {
+        for (Node node : body) {
+            statement(node);
+        }
+    }
+
+    private void nested(String header, List<Node> body) {
+        line(header + " {");
+        indent++;
+        block(body);
+        indent--;
+    }
+
+    private void statement(Node node) {
+        if (node instanceof Declare) {
+            Declare declaration = (Declare) node;
+            String name = name(declaration.name);
+            if (declaration.global) {
+                if (declaration.value != null) {
+                    line(name + " = " + convert(declaration.value, declaration.type, 0));
+                }
+                return;
+            }
+            line("var " + name + " " + type(declaration.type)
+                + (declaration.value != null ? " = " + convert(declaration.value, declaration.type, 0) : ""));
+            if (!current.reads.contains(declaration.name)) {
+                line("_ = " + name);
+            }
+        } else if (node instanceof Assign) {
+            Assign assign = (Assign) node;
+            String target = expression(assign.target, PRIMARY);
+            String value = assign.target.type.equals("String") && assign.operator.equals("+=")
+                ? format(assign.value, 0) : convert(assign.value, assign.target.type, 0);
+            line(target + " " + assign.operator + " " + value);
+        } else if (node instanceof Step) {
+            Step step = (Step) node;
+            line(expression(step.target, PRIMARY) + step.operator);
+        } else if (node instanceof If) {
+            If statement = (If) node;
+            for (int i = 0; i < statement.conditions.size(); i++) {
+                String keyword = i == 0 ? "if " : "} else if ";
+                nested(keyword + condition(statement.conditions.get(i), 0), statement.branches.get(i));
+            }
+            if (statement.otherwise != null) {
+                nested("} else", statement.otherwise);
+            }
+            line("}");
+        } else if (node instanceof While) {
+            While loop = (While) node;
+            boolean forever = loop.condition instanceof Literal && ((Literal) loop.condition).text.equals("true");
+            nested(forever ? "for" : "for " + condition(loop.condition, 0), loop.body);
+            line("}");
+        } else if (node instanceof For) {
+            For loop = (For) node;
+            String init = loop.init == null ? "" : simple(loop.init);
+            String condition = loop.condition == null ? "" : condition(loop.condition, 0);
+            String update = loop.update == null ? "" : simple(loop.update);
+            nested(init.isEmpty() && update.isEmpty() ? "for " + condition : "for " + init + "; " + condition + "; " + update, loop.body);
+            line("}");
+        } else if (node instanceof Each) {
+            Each loop = (Each) node;
+            String items = expression(loop.items, 0);
+            nested(current.reads.contains(loop.name) ? "for _, " + name(loop.name) + " := range " + items : "for range " + items, loop.body);
+            line("}");
+        } else if (node instanceof Return) {
+            Return statement = (Return) node;
+            line(statement.value == null ? "return" : "return " + convert(statement.value, current.returnType, 0));
+        } else if (node instanceof Jump) {
+            line(((Jump) node).keyword);
+        } else if (node instanceof Print) {
+            Print print = (Print) node;
+            imports.add("fmt");
+            StringBuilder text = new StringBuilder();
+            List<Expr> pieces = print.pieces;
+            for (int i = 0; i < pieces.size(); i++) {
+                text.append(i > 0 ? " + " : "").append(format(pieces.get(i), pieces.size() > 1 ? ADD + 1 : 0));
+            }
+            line((print.newline ? "fmt.Println(" : "fmt.Print(") + text + ")");
+        } else if (node instanceof Evaluate) {
+            line(expression(((Evaluate) node).call, 0));
+        }
+    }
+
+    // A statement in a for header, where declarations must be short
+    private String simple(Node node) {
+        if (node instanceof Declare) {
+            Declare declaration = (Declare) node;
+            Expr value = declaration.value;
+            String code;
+            if (value == null) {
+                code = zero(declaration.type);
+            } else if (value instanceof ListOf || value.type.equals(declaration.type)) {
+                code = convert(value, declaration.type, 0);
+            } else {
+                code = type(declaration.type) + "(" + expression(value, 0) + ")";
+            }
+            return name(declaration.name) + " := " + code;
+        }
+        int mark = out.length();
+        int saved = indent;
+        indent = 0;
+        statement(node);
+        indent = saved;
+        String code = out.substring(mark).trim();
+        out.setLength(mark);
+        return code;
+    }
+
+    private static String zero(String type) {
+        switch (type) {
+            case "String":
+                return "\"\"";
+            case "Bool":
+                return "false";
+            default:
+                return Transpiler.elementType(type) != null ? type(type) + "(nil)" : type(type) + "(0)";
+        }
+    }
+
+    // An expression of type to, converting numbers of another type
+    private String convert(Expr expr, String to, int precedence) {
+        String from = expr.type;
+        if (expr instanceof ListOf) {
+            return list((ListOf) expr, to);
+        }
+        boolean constant = from.equals(Transpiler.NUMBER);
+        // A literal such as 5 or -5 takes whatever numeric type Go needs
+        boolean literal = expr instanceof Literal || expr instanceof Unary && ((Unary) expr).operand instanceof Literal;
+        if (from.equals(to) || constant && literal) {
+            return expression(expr, precedence);
+        }
+        if (constant && !Transpiler.isWhole(to) && !to.equals("Float32")) {
+            return expression(expr, precedence);
+        }
+        if (Transpiler.isNumeric(from) && Transpiler.isNumeric(to)) {
+            return type(to) + "(" + expression(expr, 0) + ")";
+        }
+        return expression(expr, precedence);
+    }
+
+    private String list(ListOf list, String type) {
+        String element = Transpiler.elementType(type);
+        if (element == null || element.equals(Transpiler.NUMBER) || element.equals(Transpiler.VOID)) {
+            element = "Float64";
+        }
+        StringBuilder code = new StringBuilder(type("List<" + element + ">")).append('{');
+        for (int i = 0; i < list.items.size(); i++) {
+            code.append(i > 0 ? ", " : "").append(convert(list.items.get(i), element, 0));
+        }
+        return code.append('}').toString();
+    }
+
+    // A value as a bool, the way the interpreter tests conditions
+    private String condition(Expr expr, int precedence) {
+        if (expr.type.equals("Bool")) {
+            return expression(expr, precedence);
+        }
+        String zero = expr.type.equals("String") ? "\"\"" : "0";
+        return wrap(expression(expr, COMPARE + 1) + " != " + zero, COMPARE, precedence);
+    }
+
+    // A value as the text console.write prints for it
+    private String format(Expr expr, int precedence) {
+        if (expr.type.equals("String")) {
+            return expression(expr, precedence);
+        }
+        return formatValue(expression(expr, 0), expr.type);
+    }
+
+    private String formatValue(String code, String type) {
+        String element = Transpiler.elementType(type);
+        if (element != null) {
+            helpers.add("formatList");
+            return "formatList(" + code + ", func(v " + type(element) + ") string { return " + formatValue("v", element) + " })";
+        }
+        switch (type) {
+            case "String":
+                return code;
+            case "Int32":
+                imports.add("strconv");
+                return "strconv.Itoa(int(" + code + "))";
+            case "Int64":
+                imports.add("strconv");
+                return "strconv.FormatInt(" + code + ", 10)";
+            case "Float32":
+                helpers.add("formatFloat");
+                return "formatFloat(float64(" + code + "), 32)";
+            case "Char":
+                return "string(" + code + ")";
+            case "Bool":
+                imports.add("strconv");
+                return "strconv.FormatBool(" + code + ")";
+            default:
+                helpers.add("formatFloat");
+                return "formatFloat(" + code + ", 64)";
+        }
+    }
+
+    private static String wrap(String code, int own, int precedence) {
+        return own < precedence ? "(" + code + ")" : code;
+    }
+
+    private String expression(Expr expr, int precedence) {
+        if (expr instanceof Literal) {
+            return ((Literal) expr).text;
+        }
+        if (expr instanceof Name) {
+            String name = ((Name) expr).name;
+            if (name.startsWith("math::numbers::")) {
+                imports.add("math");
+                String constant = MATH_CONSTANTS.get(name.substring(15));
+                return constant.contains(" ") ? wrap(constant, constant.contains("*") ? MULTIPLY : ADD, precedence) : constant;
+            }
+            return name(name);
+        }
+        if (expr instanceof Call) {
+            return call((Call) expr);
+        }
+        if (expr instanceof Unary) {
+            Unary unary = (Unary) expr;
+            String operand = unary.operator.equals("!") ? condition(unary.operand, UNARY) : expression(unary.operand, UNARY);
+            return wrap(unary.operator + operand, UNARY, precedence);
+        }
+        if (expr instanceof Binary) {
+            return binary((Binary) expr, precedence);
+        }
+        if (expr instanceof Index) {
+            Index index = (Index) expr;
+            String position = Transpiler.isWhole(index.index.type) || index.index instanceof Literal
+                ? expression(index.index, 0) : "int(" + expression(index.index, 0) + ")";
+            return expression(index.list, PRIMARY) + "[" + position + "]";
+        }
+        return list((ListOf) expr, expr.type);
+    }
+
+    private String call(Call call) {
+        StringBuilder code = new StringBuilder();
+        if (call.function == null) {
+            imports.add("math");
+            String function = call.name.substring(6);
+            String argument = convert(call.arguments.get(0), "Float64", 0);
+            switch (function) {
+                case "square":
+                    return "math.Pow(" + argument + ", 2)";
+                case "cube":
+                    return "math.Pow(" + argument + ", 3)";
+                case "atan2":
+                    return "math.Atan2(" + argument + ", " + convert(call.arguments.get(1), "Float64", 0) + ")";
+                default:
+                    return "math." + Character.toUpperCase(function.charAt(0)) + function.substring(1) + "(" + argument + ")";
+            }
+        }
+        code.append(functionName(call.name)).append('(');
+        for (int i = 0; i < call.arguments.size(); i++) {
+            code.append(i > 0 ? ", " : "").append(convert(call.arguments.get(i), call.function.parameters.get(i).type, 0));
+        }
+        return code.append(')').toString();
+    }
+
+    private String binary(Binary binary, int precedence) {
+        String operator = binary.operator;
+        String operands = binary.operandType;
+        switch (operator) {
+            case "||":
+                return wrap(condition(binary.left, OR) + " || " + condition(binary.right, OR + 1), OR, precedence);
+            case "&&":
+                return wrap(condition(binary.left, AND) + " && " + condition(binary.right, AND + 1), AND, precedence);
+            case "==":
+            case "!=":
+            case "<":
+            case "<=":
+            case ">":
+            case ">=":
+                return wrap(convert(binary.left, operands, COMPARE + 1) + " " + operator + " "
+                    + convert(binary.right, operands, COMPARE + 1), COMPARE, precedence);
+            case "+":
+                if (operands.equals("String")) {
+                    return wrap(format(binary.left, ADD) + " + " + format(binary.right, ADD + 1), ADD, precedence);
+                }
+                // Fall through to arithmetic
+            case "-":
+                return wrap(convert(binary.left, operands, ADD) + " " + operator + " "
+                    + convert(binary.right, operands, ADD + 1), ADD, precedence);
+            case "*":
+                return wrap(convert(binary.left, operands, MULTIPLY) + " * "
+                    + convert(binary.right, operands, MULTIPLY + 1), MULTIPLY, precedence);
+            case "/":
+                return wrap(division(binary), MULTIPLY, precedence);
+            case "#":
+                imports.add("math");
+                return "math.Floor(" + division(binary) + ")";
+            case "//":
+                // Go's / truncates toward zero, where // rounds down
+                if (Transpiler.isWhole(operands)) {
+                    helpers.add("floorDiv");
+                    return "floorDiv(" + convert(binary.left, operands, 0) + ", " + convert(binary.right, operands, 0) + ")";
+                }
+                imports.add("math");
+                String floor = "math.Floor(" + convert(binary.left, "Float64", MULTIPLY) + " / " + convert(binary.right, "Float64", MULTIPLY + 1) + ")";
+                return operands.equals("Float32") ? "float32(" + floor + ")" : floor;
+            default:
+                if (Transpiler.isWhole(operands)) {
+                    return wrap(convert(binary.left, operands, MULTIPLY) + " % "
+                        + convert(binary.right, operands, MULTIPLY + 1), MULTIPLY, precedence);
+                }
+                imports.add("math");
+                String code = "math.Mod(" + convert(binary.left, "Float64", 0) + ", " + convert(binary.right, "Float64", 0) + ")";
+                return operands.equals("Float32") ? "float32(" + code + ")" : code;
+        }
+    }
+
+    // Two constants would divide as whole numbers in Go, so the left one is made a float64
+    private String division(Binary binary) {
+        String operands = binary.operandType;
+        String left = operands.equals(Transpiler.NUMBER)
+            ? "float64(" + expression(binary.left, 0) + ")" : convert(binary.left, operands, MULTIPLY);
+        return left + " / " + convert(binary.right, operands, MULTIPLY + 1);
+    }
+}
@@ -0,0 +1,813 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.HashMap;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+
+/**
+ * A GraphQL endpoint whose resolvers are script functions, served with
+ * http::graphql(server, "/graphql", schema, resolvers[, graphiql]):
+ *
+ *   var schema: String = "type Query { user(id: ID!): User } type User { id: ID! name: String }";
+ *
+ *   function Query_user(parent: Map, args: Map) -> Map { ... }
+ *
+ * The resolver for Type.field is the function the resolvers map names under
+ * "Type.field", or else the function named Type_field. It is called with
+ * the parent value (an empty map for root fields) and a map of the field's
+ * arguments. A field without a resolver reads the same-named key of a map
+ * parent or field of a struct parent.
+ *
+ * Queries and mutations are supported with arguments, variables, aliases,
+ * fragments and @include/@skip; introspection is not. A failing resolver
+ * nulls its field and adds an entry to "errors". With graphiql set, a GET
+ * request without a query gets the GraphiQL page.
+ */
+public class GraphQL {
+    private final Map<String, Map<String, String>> types; // type -> field -> type as written, such as [User!]!
+    private final Map<String, String> operationTypes;     // query/mutation -> root type
+    private final Map<?, ?> resolvers;
+    private final Environment environment;
+    private final boolean graphiql;
+
+    public GraphQL(String schema, Map<?, ?> resolvers, Environment environment, boolean graphiql) {
+        this.types = new HashMap<>();
+        this.operationTypes = new HashMap<>();
+        this.resolvers = resolvers;
+        this.environment = environment;
+        this.graphiql = graphiql;
+        operationTypes.put("query", "Query");
+        operationTypes.put("mutation", "Mutation");
+        parseSchema(new Tokens(schema));
+        if (!types.containsKey(operationTypes.get("query"))) {
+            throw new RuntimeException("GraphQL schema has no " + operationTypes.get("query") + " type");
+        }
+    }
+
+    /**
+     * Answers a request to the endpoint: GET with ?query= or POST with a JSON
+     * body of query, variables and operationName
+     */
+    public void handle(int requestId) {
+        String query;
+        Object variables = null;
+        String operationName = null;
+        if (NativeHttp.getRequestMethod(requestId).equals("POST")) {
+            Object body;
+            try {
+                body = Json.parse(NativeHttp.getRequestBody(requestId));
+            } catch (RuntimeException e) {
+                body = null;
+            }
+            if (!(body instanceof Map) || !(((Map<?, ?>) body).get("query") instanceof String)) {
+                sendErrors(requestId, "Expected a JSON body with a query");
+                return;
+            }
+            query = (String) ((Map<?, ?>) body).get("query");
+            variables = ((Map<?, ?>) body).get("variables");
+            Object name = ((Map<?, ?>) body).get("operationName");
+            operationName = name instanceof String ? (String) name : null;
+        } else {
+            query = NativeHttp.getQueryParam(requestId, "query");
+            if (query == null || query.isEmpty()) {
+                if (graphiql) {
+                    NativeHttp.sendResponse(requestId, 200, "text/html; charset=utf-8", GRAPHIQL_PAGE);
+                } else {
+                    sendErrors(requestId, "Expected a query parameter");
+                }
+                return;
+            }
+            variables = NativeHttp.getQueryParam(requestId, "variables");
+            operationName = NativeHttp.getQueryParam(requestId, "operationName");
+        }
+        Map<String, Object> result;
+        try {
+            if (variables instanceof String) {
+                variables = ((String) variables).isEmpty() ? null : Json.parse((String) variables);
+            }
+            result = execute(query, variables instanceof Map ? (Map<?, ?>) variables : new HashMap<>(), operationName);
+        } catch (RuntimeException e) {
+            // The query itself is malformed
+            sendErrors(requestId, e.getMessage());
+            return;
+        }
+        NativeHttp.sendJsonResponse(requestId, 200, Json.stringify(result));
+    }
+
+    private static void sendErrors(int requestId, String message) {
+        Map<String, Object> error = new LinkedHashMap<>();
+        error.put("message", message);
+        List<Object> errors = new ArrayList<>();
+        errors.add(error);
+        Map<String, Object> result = new LinkedHashMap<>();
+        result.put("errors", errors);
+        NativeHttp.sendJsonResponse(requestId, 400, Json.stringify(result));
+    }
+
+    /**
+     * Runs a query document and returns the response: data, plus errors when
+     * any resolver failed. A malformed document is thrown.
+     */
+    public Map<String, Object> execute(String document, Map<?, ?> variables, String operationName) {
+        Tokens tokens = new Tokens(document);
+        Map<String, Operation> operations = new LinkedHashMap<>();
+        Map<String, List<Selection>> fragments = new HashMap<>();
+        while (!tokens.atEnd()) {
+            if (tokens.peek("fragment")) {
+                tokens.next();
+                String name = tokens.name();
+                tokens.expect("on");
+                tokens.name();
+                skipDirectives(tokens);
+                fragments.put(name, parseSelections(tokens));
+            } else {
+                Operation operation = parseOperation(tokens);
+                operations.put(operation.name, operation);
+            }
+        }
+        Operation operation;
+        if (operationName != null && !operationName.isEmpty()) {
+            operation = operations.get(operationName);
+            if (operation == null) {
+                throw new RuntimeException("Unknown operation named '" + operationName + "'");
+            }
+        } else if (operations.size() == 1) {
+            operation = operations.values().iterator().next();
+        } else {
+            throw new RuntimeException(operations.isEmpty() ? "The document has no operation"
+                : "The document has several operations; choose one with operationName");
+        }
+
+        String rootType = operationTypes.get(operation.kind);
+        if (rootType == null || !types.containsKey(rootType)) {
+            throw new RuntimeException("The schema does not support " + operation.kind + " operations");
+        }
+        Map<String, Object> values = new HashMap<>();
+        for (Map.Entry<String, Object> variable : operation.defaults.entrySet()) {
+            values.put(variable.getKey(), variables.containsKey(variable.getKey())
+                ? variables.get(variable.getKey()) : variable.getValue());
+        }
+        Execution execution = new Execution(values, fragments);
+        Map<String, Object> result = new LinkedHashMap<>();
+        result.put("data", execution.selectionSet(rootType, new MapVariable(), operation.selections, new ArrayList<>()));
+        if (!execution.errors.isEmpty()) {
+            result.put("errors", execution.errors);
+        }
+        return result;
+    }
+
+    // One run of a query: its variables, fragments and the errors it collects
+    private class Execution {
+        final Map<String, Object> variables;
+        final Map<String, List<Selection>> fragments;
+        final List<Object> errors = new ArrayList<>();
+
+        Execution(Map<String, Object> variables, Map<String, List<Selection>> fragments) {
+            this.variables = variables;
+            this.fragments = fragments;
+        }
+
+        Map<String, Object> selectionSet(String typeName, Object parent, List<Selection> selections, List<Object> path) {
+            Map<String, Object> result = new LinkedHashMap<>();
+            Map<String, List<Selection>> fields = new LinkedHashMap<>();
+            collect(selections, fields);
+            for (Map.Entry<String, List<Selection>> entry : fields.entrySet()) {
+                List<Object> fieldPath = new ArrayList<>(path);
+                fieldPath.add(entry.getKey());
+                result.put(entry.getKey(), field(typeName, parent, entry.getValue(), fieldPath));
+            }
+            return result;
+        }
+
+        // Groups fields by response key, expanding fragments and applying @include/@skip
+        void collect(List<Selection> selections, Map<String, List<Selection>> fields) {
+            for (Selection selection : selections) {
+                if (!included(selection)) {
+                    continue;
+                }
+                if (selection.fragment != null) {
+                    List<Selection> fragment = fragments.get(selection.fragment);
+                    if (fragment == null) {
+                        throw new RuntimeException("Unknown fragment '" + selection.fragment + "'");
+                    }
+                    collect(fragment, fields);
+                } else if (selection.name == null) {
+                    collect(selection.selections, fields);
+                } else {
+                    fields.computeIfAbsent(selection.key(), key -> new ArrayList<>()).add(selection);
+                }
+            }
+        }
+
+        boolean included(Selection selection) {
+            for (Map.Entry<String, Map<String, Object>> directive : selection.directives.entrySet()) {
+                Object condition = value(directive.getValue().get("if"));
+                if (directive.getKey().equals("include") && !Boolean.TRUE.equals(condition)) {
+                    return false;
+                }
+                if (directive.getKey().equals("skip") && Boolean.TRUE.equals(condition)) {
+                    return false;
+                }
+            }
+            return true;
+        }
+
+        Object field(String typeName, Object parent, List<Selection> selections, List<Object> path) {
+            Selection first = selections.get(0);
+            if (first.name.equals("__typename")) {
+                return typeName;
+            }
+            String fieldType = types.get(typeName).get(first.name);
+            if (fieldType == null) {
+                error("Cannot query field '" + first.name + "' on type '" + typeName + "'", path);
+                return null;
+            }
+            List<Selection> subselections = new ArrayList<>();
+            for (Selection selection : selections) {
+                subselections.addAll(selection.selections);
+            }
+            try {
+                MapVariable arguments = new MapVariable();
+                for (Map.Entry<String, Object> argument : first.arguments.entrySet()) {
+                    arguments.put(argument.getKey(), value(argument.getValue()));
+                }
+                Object value = resolve(typeName, first.name, parent, arguments);
+                return complete(fieldType, value, subselections, path);
+            } catch (RuntimeException e) {
+                error(e.getMessage(), path);
+                return null;
+            }
+        }
+
+        Object complete(String type, Object value, List<Selection> selections, List<Object> path) {
+            if (type.endsWith("!")) {
+                if (value == null) {
+                    throw new RuntimeException("Cannot return null for non-null type " + type);
+                }
+                return complete(type.substring(0, type.length() - 1), value, selections, path);
+            }
+            if (value == null) {
+                return null;
+            }
+            if (type.startsWith("[")) {
+                if (!(value instanceof List)) {
+                    throw new RuntimeException("Expected a list for " + type + ", got " + Builtins.describe(value));
+                }
+                String itemType = type.substring(1, type.length() - 1);
+                List<Object> items = new ArrayList<>();
+                List<?> list = (List<?>) value;
+                for (int i = 0; i < list.size(); i++) {
+                    List<Object> itemPath = new ArrayList<>(path);
+                    itemPath.add(i);
+                    items.add(complete(itemType, list.get(i), selections, itemPath));
+                }
+                return items;
+            }
+            if (types.containsKey(type)) {
+                if (selections.isEmpty()) {
+                    throw new RuntimeException("Field of type " + type + " must have a selection of subfields");
+                }
+                return selectionSet(type, value, selections, path);
+            }
+            switch (type) {
+                case "Int":
+                    return value instanceof Number ? (Object) ((Number) value).longValue() : value;
+                case "Float":
+                    return value instanceof Number ? (Object) ((Number) value).doubleValue() : value;
+                case "String":
+                case "ID":
+                    return value instanceof Double && (Double) value == Math.rint((Double) value)
+                        ? String.valueOf(((Double) value).longValue()) : String.valueOf(value);
+                default:
+                    return value; // Boolean, enums and custom scalars
+            }
+        }
+
+        Object value(Object literal) {
+            if (literal instanceof Variable) {
+                return variables.get(((Variable) literal).name);
+            }
+            if (literal instanceof List) {
+                ListVariable items = new ListVariable();
+                for (Object item : (List<?>) literal) {
+                    items.add(value(item));
+                }
+                return items;
+            }
+            if (literal instanceof Map) {
+                MapVariable fields = new MapVariable();
+                for (Map.Entry<?, ?> field : ((Map<?, ?>) literal).entrySet()) {
+                    fields.put(String.valueOf(field.getKey()), value(field.getValue()));
+                }
+                return fields;
+            }
+            return literal;
+        }
+
+        void error(String message, List<Object> path) {
+            Map<String, Object> error = new LinkedHashMap<>();
+            error.put("message", message);
+            error.put("path", path);
+            errors.add(error);
+        }
+    }
+
+    // A resolver function, or else the parent's field of that name
+    private Object resolve(String typeName, String fieldName, Object parent, MapVariable arguments) {
+        Object resolver = resolvers.get(typeName + "." + fieldName);
+        String functionName = resolver instanceof String ? (String) resolver : typeName + "_" + fieldName;
+        if (resolver != null || environment.getFunction(functionName) != null) {
+            return new Executor(environment).callFunction(functionName, parent, arguments);
+        }
+        if (parent instanceof Map) {
+            return ((Map<?, ?>) parent).get(fieldName);
+        }
+        if (parent instanceof Struct) {
+            return ((Struct) parent).getValues().get(fieldName);
+        }
+        return null;
+    }
+
+    // type Name { field(arg: Type): Type }, plus schema { query: Q } and definitions it doesn't need
+    private void parseSchema(Tokens tokens) {
+        while (!tokens.atEnd()) {
+            tokens.skipDescription();
+            String keyword = tokens.name();
+            if (keyword.equals("extend")) {
+                keyword = tokens.name();
+            }
+            switch (keyword) {
+                case "schema":
+                    skipDirectives(tokens);
+                    tokens.expect("{");
+                    while (!tokens.peek("}")) {
+                        String operation = tokens.name();
+                        tokens.expect(":");
+                        operationTypes.put(operation, tokens.name());
+                    }
+                    tokens.expect("}");
+                    break;
+                case "type":
+                case "interface":
+                case "input": {
+                    String name = tokens.name();
+                    if (tokens.peek("implements")) {
+                        tokens.next();
+                        while (tokens.peek("&") || tokens.peekName()) {
+                            tokens.next();
+                        }
+                    }
+                    skipDirectives(tokens);
+                    Map<String, String> fields = types.computeIfAbsent(name, key -> new LinkedHashMap<>());
+                    if (tokens.peek("{")) {
+                        tokens.next();
+                        while (!tokens.peek("}")) {
+                            tokens.skipDescription();
+                            String field = tokens.name();
+                            if (tokens.peek("(")) {
+                                tokens.skipBalanced("(", ")");
+                            }
+                            tokens.expect(":");
+                            fields.put(field, parseType(tokens));
+                            if (tokens.peek("=")) {
+                                tokens.next();
+                                parseValue(tokens);
+                            }
+                            skipDirectives(tokens);
+                        }
+                        tokens.expect("}");
+                    }
+                    break;
+                }
+                case "enum":
+                    tokens.name();
+                    skipDirectives(tokens);
+                    if (tokens.peek("{")) {
+                        tokens.skipBalanced("{", "}");
+                    }
+                    break;
+                case "scalar":
+                    tokens.name();
+                    skipDirectives(tokens);
+                    break;
+                case "union":
+                    tokens.name();
+                    skipDirectives(tokens);
+                    if (tokens.peek("=")) {
+                        tokens.next();
+                        if (tokens.peek("|")) {
+                            tokens.next();
+                        }
+                        tokens.name();
+                        while (tokens.peek("|")) {
+                            tokens.next();
+                            tokens.name();
+                        }
+                    }
+                    break;
+                case "directive":
+                    tokens.expect("@");
+                    tokens.name();
+                    if (tokens.peek("(")) {
+                        tokens.skipBalanced("(", ")");
+                    }
+                    if (tokens.peek("repeatable")) {
+                        tokens.next();
+                    }
+                    tokens.expect("on");
+                    if (tokens.peek("|")) {
+                        tokens.next();
+                    }
+                    tokens.name();
+                    while (tokens.peek("|")) {
+                        tokens.next();
+                        tokens.name();
+                    }
+                    break;
+                default:
+                    throw tokens.error("Unexpected '" + keyword + "' in schema");
+            }
+        }
+    }
+
+    private static String parseType(Tokens tokens) {
+        String type;
+        if (tokens.peek("[")) {
+            tokens.next();
+            type = "[" + parseType(tokens) + "]";
+            tokens.expect("]");
+        } else {
+            type = tokens.name();
+        }
+        if (tokens.peek("!")) {
+            tokens.next();
+            type += "!";
+        }
+        return type;
+    }
+
+    // query Name($id: ID = 1) @directive { ... }, or just { ... }
+    private Operation parseOperation(Tokens tokens) {
+        Operation operation = new Operation();
+        if (tokens.peek("{")) {
+            operation.kind = "query";
+        } else {
+            operation.kind = tokens.name();
+            if (!operation.kind.equals("query") && !operation.kind.equals("mutation") && !operation.kind.equals("subscription")) {
+                throw tokens.error("Unexpected '" + operation.kind + "'");
+            }
+            if (tokens.peekName()) {
+                operation.name = tokens.name();
+            }
+            if (tokens.peek("(")) {
+                tokens.next();
+                while (!tokens.peek(")")) {
+                    tokens.expect("$");
+                    String variable = tokens.name();
+                    tokens.expect(":");
+                    parseType(tokens);
+                    Object defaultValue = null;
+                    if (tokens.peek("=")) {
+                        tokens.next();
+                        defaultValue = parseValue(tokens);
+                    }
+                    operation.defaults.put(variable, defaultValue);
+                    skipDirectives(tokens);
+                }
+                tokens.expect(")");
+            }
+            skipDirectives(tokens);
+        }
+        operation.selections = parseSelections(tokens);
+        return operation;
+    }
+
+    private List<Selection> parseSelections(Tokens tokens) {
+        List<Selection> selections = new ArrayList<>();
+        tokens.expect("{");
+        while (!tokens.peek("}")) {
+            Selection selection = new Selection();
+            if (tokens.peek("...")) {
+                tokens.next();
+                if (tokens.peekName() && !tokens.peek("on")) {
+                    selection.fragment = tokens.name();
+                    selection.directives = parseDirectives(tokens);
+                } else {
+                    if (tokens.peek("on")) {
+                        tokens.next();
+                        tokens.name();
+                    }
+                    selection.directives = parseDirectives(tokens);
+                    selection.selections = parseSelections(tokens);
+                }
+            } else {
+                selection.name = tokens.name();
+                if (tokens.peek(":")) {
+                    tokens.next();
+                    selection.alias = selection.name;
+                    selection.name = tokens.name();
+                }
+                if (tokens.peek("(")) {
+                    selection.arguments = parseArguments(tokens);
+                }
+                selection.directives = parseDirectives(tokens);
+                if (tokens.peek("{")) {
+                    selection.selections = parseSelections(tokens);
+                }
+            }
+            selections.add(selection);
+        }
+        tokens.expect("}");
+        return selections;
+    }
+
+    private Map<String, Object> parseArguments(Tokens tokens) {
+        Map<String, Object> arguments = new LinkedHashMap<>();
+        tokens.expect("(");
+        while (!tokens.peek(")")) {
+            String name = tokens.name();
+            tokens.expect(":");
+            arguments.put(name, parseValue(tokens));
+        }
+        tokens.expect(")");
+        return arguments;
+    }
+
+    private Map<String, Map<String, Object>> parseDirectives(Tokens tokens) {
+        Map<String, Map<String, Object>> directives = new LinkedHashMap<>();
+        while (tokens.peek("@")) {
+            tokens.next();
+            String name = tokens.name();
+            directives.put(name, tokens.peek("(") ? parseArguments(tokens) : new LinkedHashMap<>());
+        }
+        return directives;
+    }
+
+    private void skipDirectives(Tokens tokens) {
+        parseDirectives(tokens);
+    }
+
+    // A literal: variables, numbers, strings, true/false/null, enum values, lists and objects
+    private static Object parseValue(Tokens tokens) {
+        if (tokens.peek("$")) {
+            tokens.next();
+            return new Variable(tokens.name());
+        }
+        if (tokens.peek("[")) {
+            tokens.next();
+            List<Object> items = new ArrayList<>();
+            while (!tokens.peek("]")) {
+                items.add(parseValue(tokens));
+            }
+            tokens.expect("]");
+            return items;
+        }
+        if (tokens.peek("{")) {
+            tokens.next();
+            Map<String, Object> fields = new LinkedHashMap<>();
+            while (!tokens.peek("}")) {
+                String name = tokens.name();
+                tokens.expect(":");
+                fields.put(name, parseValue(tokens));
+            }
+            tokens.expect("}");
+            return fields;
+        }
+        Token token = tokens.next();
+        switch (token.kind) {
+            case STRING:
+                return token.text;
+            case NUMBER:
+                return token.text.matches("-?\\d+") ? (Object) Long.parseLong(token.text) : (Object) Double.parseDouble(token.text);
+            case NAME:
+                switch (token.text) {
+                    case "true":
+                        return true;
+                    case "false":
+                        return false;
+                    case "null":
+                        return null;
+                    default:
+                        return token.text; // An enum value
+                }
+            default:
+                throw tokens.error("Unexpected '" + token.text + "'");
+        }
+    }
+
+    private static class Operation {
+        String kind;
+        String name;
+        final Map<String, Object> defaults = new LinkedHashMap<>(); // variable -> default value
+        List<Selection> selections;
+    }
+
+    // A field, a fragment spread (fragment set) or an inline fragment (neither set)
+    private static class Selection {
+        String name;
+        String alias;
+        String fragment;
+        Map<String, Object> arguments = new LinkedHashMap<>();
+        Map<String, Map<String, Object>> directives = new LinkedHashMap<>();
+        List<Selection> selections = new ArrayList<>();
+
+        String key() {
+            return alias != null ? alias : name;
+        }
+    }
+
+    private static class Variable {
+        final String name;
+
+        Variable(String name) {
+            this.name = name;
+        }
+    }
+
+    private enum Kind { NAME, NUMBER, STRING, PUNCTUATOR }
+
+    private static class Token {
+        final Kind kind;
+        final String text;
+        final int line;
+
+        Token(Kind kind, String text, int line) {
+            this.kind = kind;
+            this.text = text;
+            this.line = line;
+        }
+    }
+
+    // The tokens of a schema or query; commas, whitespace and # comments are ignored
+    private static class Tokens {
+        private final List<Token> tokens = new ArrayList<>();
+        private int position;
+
+        Tokens(String source) {
+            int line = 1;
+            int i = 0;
+            while (i < source.length()) {
+                char c = source.charAt(i);
+                if (c == '\n') {
+                    line++;
+                    i++;
+                } else if (Character.isWhitespace(c) || c == ',' || c == '\uFEFF') {
+                    i++;
+                } else if (c == '#') {
+                    while (i < source.length() && source.charAt(i) != '\n') {
+                        i++;
+                    }
+                } else if (source.startsWith("...", i)) {
+                    tokens.add(new Token(Kind.PUNCTUATOR, "...", line));
+                    i += 3;
+                } else if (source.startsWith("\"\"\"", i)) {
+                    int end = source.indexOf("\"\"\"", i + 3);
+                    if (end == -1) {
+                        throw new RuntimeException("Syntax error at line " + line + ": unterminated block string");
+                    }
+                    String text = source.substring(i + 3, end);
+                    tokens.add(new Token(Kind.STRING, text.trim(), line));
+                    line += text.length() - text.replace("\n", "").length();
+                    i = end + 3;
+                } else if (c == '"') {
+                    StringBuilder text = new StringBuilder();
+                    i++;
+                    while (i < source.length() && source.charAt(i) != '"') {
+                        char d = source.charAt(i);
+                        if (d == '\n') {
+                            break;
+                        }
+                        if (d == '\\' && i + 1 < source.length()) {
+                            char escaped = source.charAt(++i);
+                            switch (escaped) {
+                                case 'n': text.append('\n'); break;
+                                case 't': text.append('\t'); break;
+                                case 'r': text.append('\r'); break;
+                                case 'b': text.append('\b'); break;
+                                case 'f': text.append('\f'); break;
+                                case 'u':
+                                    if (i + 4 < source.length()) {
+                                        text.append((char) Integer.parseInt(source.substring(i + 1, i + 5), 16));
+                                        i += 4;
+                                    }
+                                    break;
+                                default: text.append(escaped);
+                            }
+                        } else {
+                            text.append(d);
+                        }
+                        i++;
+                    }
+                    if (i >= source.length() || source.charAt(i) != '"') {
+                        throw new RuntimeException("Syntax error at line " + line + ": unterminated string");
+                    }
+                    tokens.add(new Token(Kind.STRING, text.toString(), line));
+                    i++;
+                } else if (c == '-' || Character.isDigit(c)) {
+                    int start = i++;
+                    while (i < source.length() && (Character.isLetterOrDigit(source.charAt(i))
+                            || source.charAt(i) == '.' || source.charAt(i) == '+' || source.charAt(i) == '-')) {
+                        i++;
+                    }
+                    tokens.add(new Token(Kind.NUMBER, source.substring(start, i), line));
+                } else if (Character.isLetter(c) || c == '_') {
+                    int start = i;
+                    while (i < source.length() && (Character.isLetterOrDigit(source.charAt(i)) || source.charAt(i) == '_')) {
+                        i++;
+                    }
+                    tokens.add(new Token(Kind.NAME, source.substring(start, i), line));
+                } else if ("!$&()=:@[]{}|".indexOf(c) >= 0) {
+                    tokens.add(new Token(Kind.PUNCTUATOR, String.valueOf(c), line));
+                    i++;
+                } else {
+                    throw new RuntimeException("Syntax error at line " + line + ": unexpected character '" + c + "'");
+                }
+            }
+        }
+
+        boolean atEnd() {
+            return position >= tokens.size();
+        }
+
+        boolean peek(String text) {
+            return !atEnd() && tokens.get(position).text.equals(text) && tokens.get(position).kind != Kind.STRING;
+        }
+
+        boolean peekName() {
+            return !atEnd() && tokens.get(position).kind == Kind.NAME;
+        }
+
+        Token next() {
+            if (atEnd()) {
+                throw new RuntimeException("Syntax error: unexpected end of document");
+            }
+            return tokens.get(position++);
+        }
+
+        String name() {
+            if (!peekName()) {
+                throw error(atEnd() ? "Expected a name at the end of the document"
+                    : "Expected a name, found '" + tokens.get(position).text + "'");
+            }
+            return next().text;
+        }
+
+        void expect(String text) {
+            if (!peek(text)) {
+                throw error(atEnd() ? "Expected '" + text + "' at the end of the document"
+                    : "Expected '" + text + "', found '" + tokens.get(position).text + "'");
+            }
+            position++;
+        }
+
+        void skipDescription() {
+            if (!atEnd() && tokens.get(position).kind == Kind.STRING) {
+                position++;
+            }
+        }
+
+        void skipBalanced(String open, String close) {
+            int depth = 0;
+            do {
+                Token token = next();
+                if (token.kind == Kind.PUNCTUATOR && token.text.equals(open)) {
+                    depth++;
+                } else if (token.kind == Kind.PUNCTUATOR && token.text.equals(close)) {
+                    depth--;
+                }
+            } while (depth > 0);
+        }
+
+        RuntimeException error(String message) {
+            int line = atEnd() ? (tokens.isEmpty() ? 1 : tokens.get(tokens.size() - 1).line) : tokens.get(position).line;
+            return new RuntimeException("Syntax error at line " + line + ": " + message);
+        }
+    }
+
+    private static final String GRAPHIQL_PAGE = "<!DOCTYPE html>\n"
+        + "<html>\n"
+        + "<head>\n"
+        + "  <title>GraphiQL</title>\n"
+        + "  <link rel=\"stylesheet\" href=\"https://unpkg.com/graphiql@3/graphiql.min.css\">\n"
+        + "</head>\n"
+        + "<body style=\"margin: 0\">\n"
+        + "  <div id=\"graphiql\" style=\"height: 100vh\"></div>\n"
+        + "  <script src=\"https://unpkg.com/react@18/umd/react.production.min.js\"></script>\n"
+        + "  <script src=\"https://unpkg.com/react-dom@18/umd/react-dom.production.min.js\"></script>\n"
+        + "  <script src=\"https://unpkg.com/graphiql@3/graphiql.min.js\"></script>\n"
+        + "  <script>\n"
+        + "    const fetcher = GraphiQL.createFetcher({ url: window.location.pathname });\n"
+        + "    ReactDOM.createRoot(document.getElementById('graphiql'))\n"
+        + "      .render(React.createElement(GraphiQL, { fetcher }));\n"
+        + "  </script>\n"
+        + "</body>\n"
+        + "</html>\n";
+}
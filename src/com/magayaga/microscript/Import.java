@@ -6,27 +6,227 @@
  */
 package com.magayaga.microscript;
 
+import java.io.File;
+import java.io.IOException;
+import java.net.MalformedURLException;
+import java.net.URL;
+import java.net.URLClassLoader;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.Collections;
 import java.util.HashMap;
+import java.util.HashSet;
+import java.util.List;
 import java.util.Map;
+import java.util.ServiceConfigurationError;
+import java.util.ServiceLoader;
+import java.util.Set;
+import java.util.WeakHashMap;
+import java.util.concurrent.ConcurrentHashMap;
+import java.util.concurrent.CopyOnWriteArrayList;
 import com.magayaga.microscript.NativeIo; // import native IO bindings
 
 public class Import {
-    private static final Map<String, Module> modules = new HashMap<>();
+    private static final String PLUGIN_PATH_VARIABLE = "MICROSCRIPT_PLUGIN_PATH";
+    private static final String SEARCH_PATH_VARIABLE = "MICROSCRIPT_PATH";
+    private static final List<String> SCRIPT_EXTENSIONS = Arrays.asList(".mus", ".microscript", ".micros");
+    private static final Map<String, Module> modules = new ConcurrentHashMap<>();
+    // Directories searched for script modules before MICROSCRIPT_PATH, in order
+    private static final List<Path> searchPaths = new CopyOnWriteArrayList<>();
+    // Script modules already loaded into each root environment
+    private static final Map<Environment, Set<Path>> loadedScripts = Collections.synchronizedMap(new WeakHashMap<>());
 
     static {
         // Register built-in modules
         modules.put("math", new MathModule());
         modules.put("io", new IoModule());
         modules.put("http", new HttpModule());
+        modules.put("ffi", new FfiModule());
+        modules.put("dns", new DnsModule());
+        modules.put("redis", new RedisModule());
+        modules.put("jwt", new JwtModule());
+        loadPlugins();
     }
 
+    /**
+     * Makes a module available to `import` under the given name
+     */
+    public static void registerModule(String name, Module module) {
+        if (modules.putIfAbsent(name, module) != null) {
+            throw new RuntimeException("Module already registered: " + name);
+        }
+    }
+
+    public static void registerModule(NativeModule module) {
+        registerModule(module.getName(), module);
+    }
+
+    /**
+     * Registers the NativeModule implementations found on the classpath and in
+     * the plugin directories. A broken plugin is reported and skipped so it
+     * can't stop scripts that don't use it.
+     */
+    private static void loadPlugins() {
+        ServiceLoader<NativeModule> loader = ServiceLoader.load(NativeModule.class, pluginClassLoader());
+        try {
+            for (NativeModule module : loader) {
+                try {
+                    registerModule(module);
+                } catch (RuntimeException e) {
+                    System.err.println("Warning: skipping plugin " + module.getClass().getName() + ": " + e.getMessage());
+                }
+            }
+        } catch (ServiceConfigurationError e) {
+            System.err.println("Warning: failed to load plugins: " + e.getMessage());
+        }
+    }
+
+    private static ClassLoader pluginClassLoader() {
+        ClassLoader parent = Import.class.getClassLoader();
+        String pluginPath = System.getenv(PLUGIN_PATH_VARIABLE);
+        if (pluginPath == null || pluginPath.isEmpty()) {
+            return parent;
+        }
+        List<URL> jars = new ArrayList<>();
+        for (String directory : pluginPath.split(File.pathSeparator)) {
+            File[] files = new File(directory).listFiles((dir, name) -> name.endsWith(".jar"));
+            if (files == null) {
+                continue;
+            }
+            for (File file : files) {
+                try {
+                    jars.add(file.toURI().toURL());
+                } catch (MalformedURLException e) {
+                    System.err.println("Warning: ignoring plugin " + file + ": " + e.getMessage());
+                }
+            }
+        }
+        return new URLClassLoader(jars.toArray(new URL[0]), parent);
+    }
+
+    /**
+     * Adds a directory searched by `import` for script modules, after the
+     * ones added before it and ahead of MICROSCRIPT_PATH
+     */
+    public static void addSearchPath(String directory) {
+        searchPaths.add(Paths.get(directory));
+    }
+
+    /**
+     * The directories searched for script modules: the ones added with
+     * addSearchPath, then MICROSCRIPT_PATH, then the working directory
+     */
+    public static List<Path> getSearchPaths() {
+        List<Path> paths = new ArrayList<>(searchPaths);
+        String variable = System.getenv(SEARCH_PATH_VARIABLE);
+        if (variable != null && !variable.isEmpty()) {
+            for (String directory : variable.split(File.pathSeparator)) {
+                if (!directory.isEmpty()) {
+                    paths.add(Paths.get(directory));
+                }
+            }
+        }
+        paths.add(Paths.get(""));
+        return paths;
+    }
+
+    /**
+     * The script file `import name` loads, or null when no search path has one
+     */
+    public static Path findScript(String name) {
+        return findScript(name, getSearchPaths());
+    }
+
+    public static Path findScript(String name, List<Path> directories) {
+        for (Path directory : directories) {
+            for (String extension : SCRIPT_EXTENSIONS) {
+                Path script = directory.resolve(name + extension);
+                if (Files.isRegularFile(script)) {
+                    return script;
+                }
+            }
+        }
+        return null;
+    }
+
+    /**
+     * Imports a native module, or else a script module: name.mus (or
+     * .microscript, .micros) from the search paths. A script module runs once
+     * per root environment, defining its functions and globals there, or only
+     * the ones it exports when it uses export.
+     */
     public static void importModule(String name, Environment env) {
         Module module = modules.get(name);
         if (module != null) {
             module.register(env);
-        } else {
+            return;
+        }
+        Path script = findScript(name);
+        if (script == null) {
             throw new RuntimeException("Module not found: " + name);
         }
+        loadScript(name, script, env.getRoot());
+    }
+
+    /**
+     * Imports a module under an alias, as in import math_utils as mu: the
+     * module gets an environment of its own, and its functions and globals
+     * are reached through the alias, mu.clamp(x, 0, 1), rather than defined
+     * in the importing script
+     */
+    public static void importModule(String name, String alias, Environment env) {
+        Environment scope = new Environment(env.getRoot());
+        Set<String> exports = Collections.emptySet();
+        Module module = modules.get(name);
+        if (module != null) {
+            module.register(scope);
+        } else {
+            Path script = findScript(name);
+            if (script == null) {
+                throw new RuntimeException("Module not found: " + name);
+            }
+            List<String> lines = readScript(name, script);
+            exports = Exports.names(lines);
+            runScript(name, script, lines, scope);
+        }
+        env.setVariable(alias, new ModuleNamespace(name, scope, exports));
+    }
+
+    private static void loadScript(String name, Path script, Environment root) {
+        Path key = script.toAbsolutePath().normalize();
+        Set<Path> loaded = loadedScripts.computeIfAbsent(root, r -> Collections.synchronizedSet(new HashSet<>()));
+        if (!loaded.add(key)) {
+            return; // Already imported, or being imported by a cycle
+        }
+        List<String> lines = readScript(name, script);
+        // A module that exports names runs in its own environment, which keeps the rest private
+        Set<String> exports = Exports.names(lines);
+        Environment module = exports.isEmpty() ? root : new Environment(root);
+        runScript(name, script, lines, module);
+        if (!exports.isEmpty()) {
+            Exports.share(exports, module, root, name);
+        }
+    }
+
+    private static List<String> readScript(String name, Path script) {
+        try {
+            return new Scanner(script.toString()).readLines();
+        } catch (IOException e) {
+            throw new RuntimeException("Error reading module " + name + " (" + script + "): " + e.getMessage());
+        }
+    }
+
+    private static void runScript(String name, Path script, List<String> lines, Environment environment) {
+        try {
+            new Interpreter(environment).run(lines);
+        } catch (ScriptException e) {
+            // The line belongs to the module's file, not to the importing script
+            throw new RuntimeException("In module " + name + " (" + script + ") at line " + (e.getLine() + 1)
+                + ": " + e.getMessage(), e);
+        }
     }
 
     // Module interface
@@ -103,6 +303,84 @@ public class Import {
 
     // HTTP module
     public static class HttpModule implements Module {
+        // Script functions per WebSocket endpoint: { onConnect, onMessage, onClose }
+        private static final Map<Integer, String[]> webSocketHandlers = new ConcurrentHashMap<>();
+        // Endpoints added with http::graphql, by the handler name their routes use
+        private static final Map<String, GraphQL> graphqlEndpoints = new ConcurrentHashMap<>();
+        private static volatile Environment callbackEnvironment;
+        
+        // Events arrive on server threads and the interpreter is not
+        // thread-safe, so each waits for its callback to run on the event loop
+        private static void onEvent(int event, int handle, String id, String payload) {
+            Environment environment = callbackEnvironment;
+            if (environment != null) {
+                environment.getEventLoop().invoke(() -> dispatch(event, handle, id, payload));
+            }
+        }
+        
+        private static void dispatch(int event, int handle, String id, String payload) {
+            if (event == NativeHttp.EVENT_HTTP_REQUEST) {
+                GraphQL endpoint = graphqlEndpoints.get(id);
+                if (endpoint != null) {
+                    endpoint.handle(handle);
+                    return;
+                }
+                // Route handlers receive the request ID
+                callHandler(id, handle);
+                return;
+            }
+            
+            String[] handlers = webSocketHandlers.get(handle);
+            if (handlers == null) {
+                return;
+            }
+            
+            String functionName;
+            Object[] values;
+            switch (event) {
+                case NativeHttp.EVENT_WEBSOCKET_CONNECT:
+                    functionName = handlers[0];
+                    values = new Object[] { id };
+                    break;
+                case NativeHttp.EVENT_WEBSOCKET_MESSAGE:
+                    functionName = handlers[1];
+                    values = new Object[] { id, payload };
+                    break;
+                case NativeHttp.EVENT_WEBSOCKET_CLOSE:
+                    functionName = handlers[2];
+                    values = new Object[] { id };
+                    break;
+                default:
+                    return;
+            }
+            if (functionName != null) {
+                callHandler(functionName, values);
+            }
+        }
+        
+        private static void callHandler(String functionName, Object... values) {
+            try {
+                new Executor(callbackEnvironment).callFunction(functionName, values);
+            } catch (RuntimeException e) {
+                System.err.println("Error in handler " + functionName + ": " + e.getMessage());
+            }
+        }
+        
+        private static void listen(Environment env) {
+            callbackEnvironment = env;
+            NativeHttp.setEventListener(HttpModule::onEvent);
+        }
+        
+        private static void setWebSocketHandler(Environment env, Object[] args, int slot) {
+            NativeHttp.checkLibrary();
+            int endpointHandle = ((Number) args[0]).intValue();
+            String functionName = (String) args[1];
+            
+            webSocketHandlers.computeIfAbsent(endpointHandle, key -> new String[3])[slot] = functionName;
+            listen(env);
+            NativeHttp.enableWebSocketCallbacks(endpointHandle);
+        }
+        
         @Override
         public void register(Environment env) {
             // Check if library is loaded
@@ -117,6 +395,32 @@ public class Import {
                 return NativeHttp.createServer(port);
             });
             
+            env.setVariable("http::createTLS", (Import.FunctionInterface) (args) -> {
+                NativeHttp.checkLibrary();
+                int port = ((Number) args[0]).intValue();
+                String certFile = (String) args[1];
+                String keyFile = (String) args[2];
+                int serverHandle = NativeHttp.createTLSServer(port, certFile, keyFile);
+                if (serverHandle < 0) {
+                    throw new RuntimeException("Unable to start HTTPS server with certificate " + certFile);
+                }
+                return serverHandle;
+            });
+            
+            // Let's Encrypt: http::createAutoTLS("example.com,www.example.com", "./certs", "admin@example.com")
+            env.setVariable("http::createAutoTLS", (Import.FunctionInterface) (args) -> {
+                NativeHttp.checkLibrary();
+                String domains = (String) args[0];
+                String cacheDir = (String) args[1];
+                String email = args.length > 2 ? (String) args[2] : "";
+                int serverHandle = NativeHttp.createAutocertServer(domains, cacheDir, email);
+                if (serverHandle < 0) {
+                    throw new RuntimeException("Unable to start HTTPS server for " + domains +
+                                               " (is the library built with -tags autocert?)");
+                }
+                return serverHandle;
+            });
+            
             env.setVariable("http::stopServer", (Import.FunctionInterface) (args) -> {
                 int serverHandle = ((Number) args[0]).intValue();
                 NativeHttp.stopServer(serverHandle);
@@ -128,12 +432,31 @@ public class Import {
                 return NativeHttp.isRunning(serverHandle);
             });
             
+            // Block until the server stops, running callbacks meanwhile
+            env.setVariable("http::wait", (Import.FunctionInterface) (args) -> {
+                int serverHandle = ((Number) args[0]).intValue();
+                env.getEventLoop().runWhile(() -> NativeHttp.isRunning(serverHandle), env.getCancellation());
+                return null;
+            });
+            
+            env.setVariable("http::stopAll", (Import.FunctionInterface) (args) -> {
+                NativeHttp.stopAllServers();
+                return null;
+            });
+            
+            env.setVariable("http::setShutdownGracePeriod", (Import.FunctionInterface) (args) -> {
+                int milliseconds = ((Number) args[0]).intValue();
+                NativeHttp.setShutdownGracePeriod(milliseconds);
+                return null;
+            });
+            
             // Route management
             env.setVariable("http::addRoute", (Import.FunctionInterface) (args) -> {
                 int serverHandle = ((Number) args[0]).intValue();
                 String method = (String) args[1];
                 String path = (String) args[2];
                 String handlerName = (String) args[3];
+                listen(env);
                 NativeHttp.addRoute(serverHandle, method, path, handlerName);
                 return null;
             });
@@ -142,8 +465,7 @@ public class Import {
                 int serverHandle = ((Number) args[0]).intValue();
                 String method = (String) args[1];
                 String path = (String) args[2];
-                NativeHttp.removeRoute(serverHandle, method, path);
-                return null;
+                return NativeHttp.removeRoute(serverHandle, method, path);
             });
             
             // Response utilities
@@ -172,6 +494,15 @@ public class Import {
                 return null;
             });
             
+            // Serialize a script value: http::responseJson(requestId, value[, statusCode])
+            env.setVariable("http::responseJson", (Import.FunctionInterface) (args) -> {
+                int requestId = ((Number) args[0]).intValue();
+                String jsonBody = Json.stringify(args[1]);
+                int statusCode = args.length > 2 ? ((Number) args[2]).intValue() : 200;
+                NativeHttp.sendJsonResponse(requestId, statusCode, jsonBody);
+                return null;
+            });
+            
             env.setVariable("http::sendFileResponse", (Import.FunctionInterface) (args) -> {
                 int requestId = ((Number) args[0]).intValue();
                 String filePath = (String) args[1];
@@ -179,6 +510,55 @@ public class Import {
                 return null;
             });
             
+            // Static file serving: http::static(server, "/assets", "./public"[, listDirectories])
+            env.setVariable("http::static", (Import.FunctionInterface) (args) -> {
+                int serverHandle = ((Number) args[0]).intValue();
+                String urlPrefix = (String) args[1];
+                String directory = (String) args[2];
+                boolean allowListing = args.length > 3 && Boolean.TRUE.equals(args[3]);
+                NativeHttp.serveStatic(serverHandle, urlPrefix, directory, allowListing);
+                return null;
+            });
+            
+            // Reverse proxy: http::proxy(server, "/api/", "http://localhost:9000") sends /api/users upstream as /users
+            env.setVariable("http::proxy", (Import.FunctionInterface) (args) -> {
+                NativeHttp.checkLibrary();
+                int serverHandle = ((Number) args[0]).intValue();
+                String urlPrefix = (String) args[1];
+                String upstream = (String) args[2];
+                if (!NativeHttp.proxy(serverHandle, urlPrefix, upstream)) {
+                    throw new RuntimeException("Unable to proxy " + urlPrefix + " to " + upstream
+                        + " (expected an http:// or https:// URL and a running server)");
+                }
+                return null;
+            });
+            
+            // GraphQL: http::graphql(server, "/graphql", schema, resolvers[, graphiql]), where resolvers
+            // maps "Type.field" to a function name (or is omitted, calling Type_field functions)
+            env.setVariable("http::graphql", (Import.FunctionInterface) (args) -> {
+                if (args.length < 3) {
+                    throw new RuntimeException("http::graphql expects (server, path, schema[, resolvers[, graphiql]])");
+                }
+                int serverHandle = ((Number) args[0]).intValue();
+                String path = (String) args[1];
+                Map<?, ?> resolvers = args.length > 3 && args[3] instanceof Map ? (Map<?, ?>) args[3] : new HashMap<>();
+                if (args.length > 3 && args[3] instanceof String) {
+                    Object parsed = Json.parse((String) args[3]);
+                    if (!(parsed instanceof Map)) {
+                        throw new RuntimeException("http::graphql: resolvers must be a map of \"Type.field\" to function names");
+                    }
+                    resolvers = (Map<?, ?>) parsed;
+                }
+                boolean graphiql = args.length > 4 && Boolean.TRUE.equals(args[4]);
+                GraphQL endpoint = new GraphQL((String) args[2], resolvers, env, graphiql);
+                String handlerName = "graphql:" + serverHandle + ":" + path;
+                graphqlEndpoints.put(handlerName, endpoint);
+                listen(env);
+                NativeHttp.addRoute(serverHandle, "GET", path, handlerName);
+                NativeHttp.addRoute(serverHandle, "POST", path, handlerName);
+                return null;
+            });
+            
             // Request information
             env.setVariable("http::getRequestPath", (Import.FunctionInterface) (args) -> {
                 int requestId = ((Number) args[0]).intValue();
@@ -201,6 +581,16 @@ public class Import {
                 return NativeHttp.getRequestBody(requestId);
             });
             
+            // Parse the body into maps and lists: http::requestJson(requestId)
+            env.setVariable("http::requestJson", (Import.FunctionInterface) (args) -> {
+                int requestId = ((Number) args[0]).intValue();
+                String json = NativeHttp.getRequestJson(requestId);
+                if (json == null) {
+                    throw new RuntimeException("Request body is not valid JSON");
+                }
+                return Json.parse(json);
+            });
+            
             env.setVariable("http::getQueryParam", (Import.FunctionInterface) (args) -> {
                 int requestId = ((Number) args[0]).intValue();
                 String paramName = (String) args[1];
@@ -215,6 +605,60 @@ public class Import {
                 return null;
             });
             
+            // Basic auth: http::basicAuth(server, users), where users maps names to passwords
+            // (a Map or a JSON object); every request then needs valid credentials
+            env.setVariable("http::basicAuth", (Import.FunctionInterface) (args) -> {
+                if (args.length != 2) {
+                    throw new RuntimeException("http::basicAuth expects (server, users)");
+                }
+                int serverHandle = ((Number) args[0]).intValue();
+                String users = args[1] instanceof String ? (String) args[1] : Json.stringify(args[1]);
+                if (!NativeHttp.basicAuth(serverHandle, users)) {
+                    throw new RuntimeException("Unable to enable basic auth (expected a map of user names to passwords)");
+                }
+                return null;
+            });
+            
+            // JWT auth: http::jwt(server, secret[, algorithms]) or http::jwt(server, options) with
+            // options.secret and options.algorithms; every request then needs a signed bearer token
+            env.setVariable("http::jwt", (Import.FunctionInterface) (args) -> {
+                if (args.length < 2) {
+                    throw new RuntimeException("http::jwt expects (server, secret[, algorithms]) or (server, options)");
+                }
+                int serverHandle = ((Number) args[0]).intValue();
+                Object secret = args[1];
+                Object algorithms = args.length > 2 ? args[2] : null;
+                if (args[1] instanceof Map) {
+                    secret = ((Map<?, ?>) args[1]).get("secret");
+                    algorithms = ((Map<?, ?>) args[1]).get("algorithms");
+                }
+                if (!(secret instanceof String)) {
+                    throw new RuntimeException("http::jwt: the secret must be a String");
+                }
+                String names = String.join(",", Jwt.algorithms(algorithms));
+                if (!NativeHttp.jwtAuth(serverHandle, (String) secret, names)) {
+                    throw new RuntimeException("Unable to enable JWT auth with " + names + " (expected HS256, HS384 or HS512)");
+                }
+                return null;
+            });
+            
+            // The verified user of a request: {"name": ...} for basic auth, the token's claims for JWT
+            env.setVariable("http::requestUser", (Import.FunctionInterface) (args) -> {
+                int requestId = ((Number) args[0]).intValue();
+                return Json.parse(NativeHttp.getRequestUser(requestId));
+            });
+            
+            // Access logging: http::accessLog(server, "common" | "combined" | "json" | "off"[, "stderr" | "stdout" | path])
+            env.setVariable("http::accessLog", (Import.FunctionInterface) (args) -> {
+                int serverHandle = ((Number) args[0]).intValue();
+                String format = (String) args[1];
+                String destination = args.length > 2 ? (String) args[2] : "stderr";
+                if (!NativeHttp.setAccessLog(serverHandle, format, destination)) {
+                    throw new RuntimeException("Unable to configure access log '" + format + "' to " + destination);
+                }
+                return null;
+            });
+            
             // Utility functions
             env.setVariable("http::urlEncode", (Import.FunctionInterface) (args) -> {
                 String input = (String) args[0];
@@ -226,6 +670,34 @@ public class Import {
                 return NativeHttp.urlDecode(input);
             });
             
+            // Split a URL into a map of scheme, user, host, hostname, port, path, query and fragment
+            env.setVariable("http::parseUrl", (Import.FunctionInterface) (args) -> {
+                String input = (String) args[0];
+                String parts = NativeHttp.parseUrl(input);
+                if (parts == null) {
+                    throw new RuntimeException("Invalid URL: " + input);
+                }
+                return Json.parse(parts);
+            });
+            
+            // Build a URL from a map or struct with the same fields as http::parseUrl
+            env.setVariable("http::buildUrl", (Import.FunctionInterface) (args) -> {
+                String url = NativeHttp.buildUrl(Json.stringify(args[0]));
+                if (url == null) {
+                    throw new RuntimeException("Invalid URL parts: " + args[0]);
+                }
+                return url;
+            });
+            
+            // Encode a map of keys to values (or lists of values) as a query string
+            env.setVariable("http::buildQuery", (Import.FunctionInterface) (args) -> {
+                String query = NativeHttp.buildQuery(Json.stringify(args[0]));
+                if (query == null) {
+                    throw new RuntimeException("Invalid query parameters: " + args[0]);
+                }
+                return query;
+            });
+            
             env.setVariable("http::generateUuid", (Import.FunctionInterface) (args) -> {
                 return NativeHttp.generateUuid();
             });
@@ -258,6 +730,190 @@ public class Import {
                 NativeHttp.closeWebSocketConnection(endpointHandle, clientId);
                 return null;
             });
+            
+            // WebSocket callbacks: handlers are called with the client ID,
+            // and onMessage also receives the message text
+            env.setVariable("http::onWebSocketConnect", (Import.FunctionInterface) (args) -> {
+                setWebSocketHandler(env, args, 0);
+                return null;
+            });
+            
+            env.setVariable("http::onWebSocketMessage", (Import.FunctionInterface) (args) -> {
+                setWebSocketHandler(env, args, 1);
+                return null;
+            });
+            
+            env.setVariable("http::onWebSocketClose", (Import.FunctionInterface) (args) -> {
+                setWebSocketHandler(env, args, 2);
+                return null;
+            });
+        }
+    }
+
+    // FFI module: calls functions exported by C libraries
+    public static class FfiModule implements Module {
+        private static NativeFfi.Library library(Object value) {
+            if (!(value instanceof NativeFfi.Library)) {
+                throw new RuntimeException("ffi: expected a library from ffi::open, got " + value);
+            }
+            return (NativeFfi.Library) value;
+        }
+
+        @Override
+        public void register(Environment env) {
+            // ffi::open("libm.so.6")
+            env.setVariable("ffi::open", (Import.FunctionInterface) (args) -> {
+                return NativeFfi.openLibrary((String) args[0]);
+            });
+
+            // ffi::call(lib, "cos", [1.0], "double")
+            env.setVariable("ffi::call", (Import.FunctionInterface) (args) -> {
+                if (args.length != 4 || !(args[2] instanceof List)) {
+                    throw new RuntimeException("ffi::call expects (library, name, [arguments], returnType)");
+                }
+                @SuppressWarnings("unchecked")
+                List<Object> callArgs = (List<Object>) args[2];
+                return NativeFfi.call(library(args[0]), (String) args[1], callArgs, (String) args[3]);
+            });
+
+            env.setVariable("ffi::close", (Import.FunctionInterface) (args) -> {
+                NativeFfi.closeLibrary(library(args[0]));
+                return null;
+            });
+        }
+    }
+
+    // DNS module
+    public static class DnsModule implements Module {
+        private static String name(String function, Object[] args) {
+            if (args.length != 1 || !(args[0] instanceof String)) {
+                throw new RuntimeException("dns::" + function + " expects 1 argument: a name or address as a String");
+            }
+            return (String) args[0];
+        }
+
+        @Override
+        public void register(Environment env) {
+            env.setVariable("dns::lookup", (Import.FunctionInterface) (args) -> Dns.lookup(name("lookup", args)));
+            env.setVariable("dns::reverse", (Import.FunctionInterface) (args) -> Dns.reverse(name("reverse", args)));
+            env.setVariable("dns::mx", (Import.FunctionInterface) (args) -> Dns.mx(name("mx", args)));
+            env.setVariable("dns::txt", (Import.FunctionInterface) (args) -> Dns.txt(name("txt", args)));
+        }
+    }
+
+    // JWT module
+    public static class JwtModule implements Module {
+        @Override
+        public void register(Environment env) {
+            // jwt::sign(claims, secret[, algorithm]), where claims is a Map or a JSON object
+            env.setVariable("jwt::sign", (Import.FunctionInterface) (args) -> {
+                if (args.length < 2 || !(args[1] instanceof String)) {
+                    throw new RuntimeException("jwt::sign expects (claims, secret[, algorithm])");
+                }
+                String algorithm = args.length > 2 ? (String) args[2] : Jwt.DEFAULT_ALGORITHM;
+                return Jwt.sign(args[0], (String) args[1], algorithm);
+            });
+
+            // jwt::verify(token, secret[, algorithms]) returns the claims, or null for a bad token
+            env.setVariable("jwt::verify", (Import.FunctionInterface) (args) -> {
+                if (args.length < 2 || !(args[0] instanceof String) || !(args[1] instanceof String)) {
+                    throw new RuntimeException("jwt::verify expects (token, secret[, algorithms])");
+                }
+                return Jwt.verify((String) args[0], (String) args[1], Jwt.algorithms(args.length > 2 ? args[2] : null));
+            });
+        }
+    }
+
+    // Redis module
+    public static class RedisModule implements Module {
+        private static Redis connection(String function, Object[] args, int count) {
+            if (args.length < count || !(args[0] instanceof Redis)) {
+                throw new RuntimeException("redis::" + function + " expects a connection from redis::connect and "
+                    + (count - 1) + (count == 2 ? " more argument" : " more arguments"));
+            }
+            return (Redis) args[0];
+        }
+
+        private static String text(Object value) {
+            if (value instanceof Double && (Double) value == Math.rint((Double) value)) {
+                return String.valueOf(((Double) value).longValue()); // 5.0 is stored as 5
+            }
+            return String.valueOf(value);
+        }
+
+        @Override
+        public void register(Environment env) {
+            // redis::connect(), redis::connect("cache.local", 6379) or redis::connect(host, port, password)
+            env.setVariable("redis::connect", (Import.FunctionInterface) (args) -> {
+                String host = args.length > 0 ? (String) args[0] : "localhost";
+                int port = args.length > 1 ? ((Number) args[1]).intValue() : 6379;
+                String password = args.length > 2 ? (String) args[2] : null;
+                return Redis.connect(host, port, password);
+            });
+
+            env.setVariable("redis::get", (Import.FunctionInterface) (args) -> {
+                return connection("get", args, 2).command("GET", text(args[1]));
+            });
+
+            // redis::set(r, key, value) or redis::set(r, key, value, seconds)
+            env.setVariable("redis::set", (Import.FunctionInterface) (args) -> {
+                Redis redis = connection("set", args, 3);
+                if (args.length > 3) {
+                    return redis.command("SET", text(args[1]), text(args[2]), "EX", text(args[3]));
+                }
+                return redis.command("SET", text(args[1]), text(args[2]));
+            });
+
+            // Returns how many of the keys existed
+            env.setVariable("redis::del", (Import.FunctionInterface) (args) -> {
+                Redis redis = connection("del", args, 2);
+                String[] command = new String[args.length];
+                command[0] = "DEL";
+                for (int i = 1; i < args.length; i++) {
+                    command[i] = text(args[i]);
+                }
+                return redis.command(command);
+            });
+
+            env.setVariable("redis::expire", (Import.FunctionInterface) (args) -> {
+                return ((Long) connection("expire", args, 3).command("EXPIRE", text(args[1]), text(args[2]))) == 1;
+            });
+
+            // redis::incr(r, key) or redis::incr(r, key, amount)
+            env.setVariable("redis::incr", (Import.FunctionInterface) (args) -> {
+                Redis redis = connection("incr", args, 2);
+                if (args.length > 2) {
+                    return redis.command("INCRBY", text(args[1]), text(args[2]));
+                }
+                return redis.command("INCR", text(args[1]));
+            });
+
+            // Returns how many subscribers received the message
+            env.setVariable("redis::publish", (Import.FunctionInterface) (args) -> {
+                return connection("publish", args, 3).command("PUBLISH", text(args[1]), text(args[2]));
+            });
+
+            // redis::subscribe(r, "events", "onEvent") calls onEvent(channel, message)
+            env.setVariable("redis::subscribe", (Import.FunctionInterface) (args) -> {
+                connection("subscribe", args, 3).subscribe(text(args[1]), text(args[2]), env);
+                return null;
+            });
+
+            env.setVariable("redis::unsubscribe", (Import.FunctionInterface) (args) -> {
+                connection("unsubscribe", args, 2).unsubscribe(text(args[1]));
+                return null;
+            });
+
+            // Block until every subscription ends, running callbacks meanwhile
+            env.setVariable("redis::wait", (Import.FunctionInterface) (args) -> {
+                connection("wait", args, 1).await(env);
+                return null;
+            });
+
+            env.setVariable("redis::close", (Import.FunctionInterface) (args) -> {
+                connection("close", args, 1).close();
+                return null;
+            });
         }
     }
 
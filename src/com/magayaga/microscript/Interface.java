@@ -0,0 +1,126 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * A named set of method signatures:
+ *
+ *   interface Printable {
+ *       function describe() -> String;
+ *   }
+ *
+ * A struct satisfies an interface when it defines every method with the same
+ * number of parameters and the same return type. Declaring it with
+ * struct Point implements Printable { ... } is checked when the struct is
+ * defined; a parameter or variable typed Printable accepts any struct that
+ * satisfies it, declared or not, and is checked when the value arrives.
+ */
+public class Interface {
+    private static final Pattern METHOD_PATTERN =
+        Pattern.compile("^function\\s+(\\w+)\\s*\\(([^)]*)\\)\\s*(?:->\\s*(\\w+(?:<[^>{]*>+)?\\??(?:\\s*\\|\\s*\\w+(?:<[^>{]*>+)?\\??)*))?\\s*;?$");
+
+    private final String name;
+    private final Map<String, Function> methods; // method name -> signature, with an empty body
+
+    public Interface(String name, Map<String, Function> methods) {
+        this.name = name;
+        this.methods = new LinkedHashMap<>(methods);
+    }
+
+    public String getName() {
+        return name;
+    }
+
+    public Map<String, Function> getMethods() {
+        return new LinkedHashMap<>(methods);
+    }
+
+    /**
+     * The signatures the struct doesn't provide, written as in the interface;
+     * empty when the struct satisfies it
+     */
+    public List<String> missingMethods(Struct struct) {
+        List<String> missing = new ArrayList<>();
+        for (Function required : methods.values()) {
+            Function method = struct.getMethod(required.getName());
+            if (method == null || method.getParameters().size() != required.getParameters().size()
+                    || !method.getReturnType().equals(required.getReturnType())) {
+                missing.add(required.getSignature());
+            }
+        }
+        return missing;
+    }
+
+    /**
+     * Fails with a type error unless the value is a struct that satisfies
+     * this interface
+     */
+    public void check(Object value, String subject) {
+        if (!(value instanceof Struct)) {
+            throw new RuntimeException("Type error: " + subject + " expected " + name + ", got " + Builtins.describe(value)
+                + ", which is not a struct, so it can't satisfy interface " + name + ".");
+        }
+        List<String> missing = missingMethods((Struct) value);
+        if (!missing.isEmpty()) {
+            throw new RuntimeException("Type error: " + subject + " does not satisfy interface " + name + ": struct "
+                + ((Struct) value).getName() + " has no method " + String.join(", ", missing) + ".");
+        }
+    }
+
+    /**
+     * Parses "interface Name {" through its closing brace
+     */
+    public static Interface parseInterfaceDefinition(List<String> lines, int startIndex) {
+        String firstLine = lines.get(startIndex).trim();
+        String interfaceName = firstLine.substring("interface".length(),
+            firstLine.contains("{") ? firstLine.indexOf('{') : firstLine.length()).trim();
+        if (!interfaceName.matches("[A-Za-z_]\\w*")) {
+            throw new RuntimeException("Invalid interface declaration: " + firstLine);
+        }
+
+        Map<String, Function> methods = new LinkedHashMap<>();
+        for (int i = startIndex + 1; i < lines.size(); i++) {
+            String line = lines.get(i).trim();
+            if (line.equals("}")) {
+                break;
+            }
+            if (line.isEmpty() || line.equals("{") || line.startsWith("//")) {
+                continue;
+            }
+            Matcher matcher = METHOD_PATTERN.matcher(line);
+            if (!matcher.matches()) {
+                throw new RuntimeException("Invalid syntax in interface " + interfaceName
+                    + ": expected a method signature such as function name(a: Int32) -> String, got: " + line);
+            }
+            List<Parameter> parameters = new ArrayList<>();
+            if (!matcher.group(2).trim().isEmpty()) {
+                for (String parameter : Parser.splitParameters(matcher.group(2))) {
+                    String[] parts = parameter.split(":");
+                    if (parts.length != 2) {
+                        throw new RuntimeException("Invalid parameter declaration in interface " + interfaceName + ": " + line);
+                    }
+                    parameters.add(new Parameter(parts[0].trim(), parts[1].trim()));
+                }
+            }
+            String returnType = matcher.group(3) != null ? matcher.group(3) : "void";
+            methods.put(matcher.group(1), new Function(matcher.group(1), parameters, returnType, new ArrayList<>()));
+        }
+        return new Interface(interfaceName, methods);
+    }
+
+    @Override
+    public String toString() {
+        return "interface " + name + " { " + methods.keySet() + " }";
+    }
+}
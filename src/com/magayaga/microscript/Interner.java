@@ -0,0 +1,81 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.Map;
+import java.util.concurrent.ConcurrentHashMap;
+
+/**
+ * Shares one instance of common small values so long-running scripts don't keep
+ * thousands of equal copies alive. Whole numbers are cached in a fixed range and
+ * short strings in a table with a size cap, so interning itself can't grow without bound.
+ * The root environment owns an instance, so each interpreter has its own string table.
+ */
+public final class Interner {
+    private static final int SMALL_NUMBER_MIN = -128;
+    private static final int SMALL_NUMBER_MAX = 1023;
+    private static final int MAX_STRING_LENGTH = 32;
+    private static final int MAX_STRINGS = 4096;
+
+    // Immutable, so every interpreter shares them
+    private static final Double[] SMALL_DOUBLES = new Double[SMALL_NUMBER_MAX - SMALL_NUMBER_MIN + 1];
+    private static final Integer[] SMALL_INTEGERS = new Integer[SMALL_NUMBER_MAX - SMALL_NUMBER_MIN + 1];
+
+    static {
+        for (int i = SMALL_NUMBER_MIN; i <= SMALL_NUMBER_MAX; i++) {
+            SMALL_DOUBLES[i - SMALL_NUMBER_MIN] = (double) i;
+            SMALL_INTEGERS[i - SMALL_NUMBER_MIN] = i;
+        }
+    }
+
+    // Concurrent because tasks started with spawn set variables too
+    private final Map<String, String> strings = new ConcurrentHashMap<>();
+    private final Memory memory;
+
+    Interner(Memory memory) {
+        this.memory = memory;
+    }
+
+    /**
+     * Returns the shared instance for small whole numbers and short strings,
+     * or the value itself
+     */
+    public Object intern(Object value) {
+        if (value instanceof Double) {
+            double number = (Double) value;
+            // Skip -0.0, which equals 0.0 but prints differently
+            if (number >= SMALL_NUMBER_MIN && number <= SMALL_NUMBER_MAX && number == Math.rint(number)
+                    && !(number == 0 && 1 / number < 0)) {
+                memory.internHit();
+                return SMALL_DOUBLES[(int) number - SMALL_NUMBER_MIN];
+            }
+        } else if (value instanceof Integer) {
+            int number = (Integer) value;
+            if (number >= SMALL_NUMBER_MIN && number <= SMALL_NUMBER_MAX) {
+                memory.internHit();
+                return SMALL_INTEGERS[number - SMALL_NUMBER_MIN];
+            }
+        } else if (value instanceof String) {
+            String text = (String) value;
+            if (text.length() <= MAX_STRING_LENGTH) {
+                String shared = strings.get(text);
+                if (shared != null) {
+                    memory.internHit();
+                    return shared;
+                }
+                if (strings.size() < MAX_STRINGS) {
+                    shared = strings.putIfAbsent(text, text);
+                    if (shared != null) {
+                        return shared;
+                    }
+                    memory.stringInterned();
+                }
+            }
+        }
+        return value;
+    }
+}
@@ -0,0 +1,173 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.util.Arrays;
+import java.util.List;
+
+/**
+ * Entry point for embedding MicroScript in a host application:
+ *
+ *   Interpreter interp = new Interpreter();
+ *   interp.run(source);
+ *   Object total = interp.getVariable("total");
+ *
+ * Globals, functions and macros persist across runs, so a host can load a
+ * script once and then call into it.
+ */
+public class Interpreter {
+    private static final String LINE_SEPARATOR = "\\r\\n|\\r|\\n";
+
+    private final Environment environment;
+    private final Define define = new Define();
+    private final Optimizer optimizer = new Optimizer();
+
+    public Interpreter() {
+        this(new Environment());
+    }
+
+    public Interpreter(Environment environment) {
+        this.environment = environment;
+    }
+
+    /**
+     * Preprocesses, optimizes and runs MicroScript source code
+     */
+    public void run(String source) {
+        if (source.startsWith("\uFEFF")) {
+            source = source.substring(1);
+        }
+        run(Arrays.asList(source.split(LINE_SEPARATOR, -1)));
+    }
+
+    public void run(List<String> lines) {
+        List<String> optimized;
+        try {
+            define.setMaxPasses(environment.getLimits().getMacroPasses());
+            optimized = optimizer.optimize(define.preprocess(lines));
+        } catch (RuntimeException | StackOverflowError e) {
+            throw ScriptException.at(e, -1);
+        }
+        execute(optimized, true);
+    }
+
+    /**
+     * Runs the lines of a .musc file, which were preprocessed and optimized
+     * when the script was compiled
+     */
+    public void runCompiled(List<String> lines) {
+        execute(lines, false);
+    }
+
+    private void execute(List<String> lines, boolean fromSource) {
+        try {
+            new Parser(lines, environment).parse();
+        } catch (ScriptException e) {
+            Cancellation cancellation = environment.getCancellation();
+            if (cancellation.isCancelled()) {
+                // Loops wrap the error in their own messages; report the cancellation plainly
+                throw new ScriptException(cancellation.getMessage(), e.getLine(), e);
+            }
+            // Point errors in expanded macros back at the invocation
+            Define.Expansion expansion = fromSource ? define.getExpansion(e.getLine()) : null;
+            throw expansion != null && e.getExpansion() == null ? e.withExpansion(expansion) : e;
+        }
+    }
+
+    public void runFile(String filePath) throws IOException {
+        run(new Scanner(filePath).readLines());
+    }
+
+    /**
+     * Runs the timers and other callbacks the script left waiting until none
+     * are left, as the command line does once the script's top level is done
+     */
+    public void runEventLoop() {
+        try {
+            environment.getEventLoop().run(environment.getCancellation());
+        } catch (RuntimeException e) {
+            throw ScriptException.at(e, -1);
+        }
+    }
+
+    /**
+     * Stops the running script from another thread; it fails with an
+     * "Execution cancelled" error at its next statement or loop iteration
+     */
+    public void cancel() {
+        environment.getCancellation().cancel();
+    }
+
+    /**
+     * Cancels the script once it has run for the given number of milliseconds.
+     * Call before run; the limit covers every later run and call.
+     */
+    public void setTimeout(long millis) {
+        environment.getCancellation().cancelAfter(millis);
+    }
+
+    /**
+     * A function implemented by the host application. Scripts call it like any
+     * other function; an exception it throws is reported as a script error.
+     */
+    @FunctionalInterface
+    public interface HostFunction {
+        Object call(Object... args) throws Exception;
+    }
+
+    /**
+     * Exposes a host function to scripts under the given name, e.g.
+     *
+     *   interp.registerFunction("host_log", args -> { log(args[0]); return null; });
+     */
+    public void registerFunction(String name, HostFunction function) {
+        environment.setVariable(name, (Import.FunctionInterface) args -> {
+            try {
+                return function.call(args);
+            } catch (RuntimeException e) {
+                throw e;
+            } catch (Exception e) {
+                throw new RuntimeException(name + ": " + e.getMessage(), e);
+            }
+        });
+    }
+
+    /**
+     * Calls a script function with values from the host
+     */
+    public Object call(String functionName, Object... args) {
+        try {
+            return new Executor(environment).callFunction(functionName, args);
+        } catch (RuntimeException | StackOverflowError e) {
+            throw ScriptException.at(e, -1);
+        }
+    }
+
+    /**
+     * Evaluates a single expression against the script's globals
+     */
+    public Object evaluate(String expression) {
+        try {
+            return new Executor(environment).evaluate(expression);
+        } catch (RuntimeException | StackOverflowError e) {
+            throw ScriptException.at(e, -1);
+        }
+    }
+
+    public Object getVariable(String name) {
+        return environment.getVariable(name);
+    }
+
+    public void setVariable(String name, Object value) {
+        environment.setVariable(name, value);
+    }
+
+    public Environment getEnvironment() {
+        return environment;
+    }
+}
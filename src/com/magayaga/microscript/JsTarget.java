@@ -0,0 +1,401 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.LinkedHashSet;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+
+import com.magayaga.microscript.Transpiler.Assign;
+import com.magayaga.microscript.Transpiler.Binary;
+import com.magayaga.microscript.Transpiler.Call;
+import com.magayaga.microscript.Transpiler.Declare;
+import com.magayaga.microscript.Transpiler.Each;
+import com.magayaga.microscript.Transpiler.Evaluate;
+import com.magayaga.microscript.Transpiler.Expr;
+import com.magayaga.microscript.Transpiler.For;
+import com.magayaga.microscript.Transpiler.Function;
+import com.magayaga.microscript.Transpiler.If;
+import com.magayaga.microscript.Transpiler.Index;
+import com.magayaga.microscript.Transpiler.Jump;
+import com.magayaga.microscript.Transpiler.ListOf;
+import com.magayaga.microscript.Transpiler.Literal;
+import com.magayaga.microscript.Transpiler.Name;
+import com.magayaga.microscript.Transpiler.Node;
+import com.magayaga.microscript.Transpiler.Print;
+import com.magayaga.microscript.Transpiler.Program;
+import com.magayaga.microscript.Transpiler.Return;
+import com.magayaga.microscript.Transpiler.Step;
+import com.magayaga.microscript.Transpiler.Unary;
+import com.magayaga.microscript.Transpiler.While;
+
+/**
+ * Writes a transpiled script as plain JavaScript for Node or a browser:
+ *
+ *   microscript transpile --target js fib.mus && node fib.js
+ *
+ * console.write becomes console.log, with its template turned into a
+ * template literal, and values print the way the interpreter prints them,
+ * so a Float64 5 still prints as 5.0. Every number is a JavaScript number:
+ * values stored in Int32 and Int64 variables are truncated to whole numbers,
+ * and Int64 is only exact up to 2^53. console.writef writes without a
+ * newline through process.stdout, so scripts that use it need Node.
+ */
+public class JsTarget implements Transpiler.Target {
+    // JavaScript keywords, globals and the names the output itself uses
+    private static final Set<String> RESERVED = Set.of("arguments", "await", "case", "catch", "class", "const",
+        "debugger", "default", "delete", "do", "enum", "eval", "export", "extends", "false", "finally", "function",
+        "implements", "import", "in", "instanceof", "interface", "let", "new", "null", "package", "private",
+        "protected", "public", "static", "super", "switch", "this", "throw", "true", "try", "typeof", "undefined",
+        "void", "with", "yield", "Infinity", "NaN", "Math", "Number", "String", "Boolean", "console", "process",
+        "formatFloat", "formatList");
+
+    private static final Map<String, String> MATH_CONSTANTS = Map.ofEntries(
+        Map.entry("pi", "Math.PI"),
+        Map.entry("e", "Math.E"),
+        Map.entry("eulerNumber", "Math.E"),
+        Map.entry("tau", "2 * Math.PI"),
+        Map.entry("phi", "1.618033988749895"),
+        Map.entry("silverRatio", "1 + Math.SQRT2"),
+        Map.entry("eulerConstant", "0.5772156649015329"),
+        Map.entry("catalan", "0.915965594177219"),
+        Map.entry("apery", "1.2020569031595942"),
+        Map.entry("feigenbaumDelta", "4.66920160910299"),
+        Map.entry("feigenbaumAlpha", "2.5029078750958926"),
+        Map.entry("plastic", "1.324717957244746"),
+        Map.entry("twinPrime", "0.6601618158468696"));
+
+    // JavaScript operator precedence, loosest first; a primary expression binds tightest
+    private static final int OR = 1;
+    private static final int AND = 2;
+    private static final int COMPARE = 3;
+    private static final int ADD = 4;
+    private static final int MULTIPLY = 5;
+    private static final int UNARY = 6;
+    private static final int POWER = 7;
+    private static final int PRIMARY = 8;
+
+    private final Set<String> helpers = new LinkedHashSet<>();
+    private StringBuilder out;
+    private int indent;
+    private Function current;
+
+    @Override
+    public String extension() {
+        return ".js";
+    }
+
+    @Override
+    public String emit(Program program) {
+        out = new StringBuilder();
+        for (Function function : program.functions) {
+            function(function);
+        }
+        current = program.main;
+        indent = 0;
+        block(program.main.body);
+        String body = out.toString();
+
+        StringBuilder file = new StringBuilder();
+        file.append("// Code generated by microscript transpile from ").append(program.name).append(". DO NOT EDIT.\n\n");
+        file.append("\"use strict\";\n\n");
+        file.append(body);
+        if (helpers.contains("formatFloat")) {
+            file.append("\n// formatFloat prints a number the way the interpreter does: 5.0, 0.25, 1.0E10\n");
+            file.append("function formatFloat(value) {\n");
+            file.append("    if (!Number.isFinite(value)) {\n");
+            file.append("        return String(value);\n");
+            file.append("    }\n");
+            file.append("    const abs = Math.abs(value);\n");
+            file.append("    if (value === 0 || (abs >= 1e-3 && abs < 1e7)) {\n");
+            file.append("        const text = Object.is(value, -0) ? \"-0\" : String(value);\n");
+            file.append("        return text.includes(\".\") ? text : text + \".0\";\n");
+            file.append("    }\n");
+            file.append("    const [mantissa, exponent] = value.toExponential().split(\"e\");\n");
+            file.append("    return (mantissa.includes(\".\") ? mantissa : mantissa + \".0\") + \"E\" + Number(exponent);\n");
+            file.append("}\n");
+        }
+        if (helpers.contains("formatList")) {
+            file.append("\n// formatList prints a list the way the interpreter does: [1, 2, 3]\n");
+            file.append("function formatList(items, format) {\n");
+            file.append("    return \"[\" + items.map(format).join(\", \") + \"]\";\n");
+            file.append("}\n");
+        }
+        return file.toString();
+    }
+
+    private void function(Function function) {
+        current = function;
+        StringBuilder header = new StringBuilder("function ").append(name(function.name)).append('(');
+        for (int i = 0; i < function.parameters.size(); i++) {
+            header.append(i > 0 ? ", " : "").append(name(function.parameters.get(i).name));
+        }
+        indent = 0;
+        nested(header.append(')').toString(), function.body);
+        line("}");
+        out.append('\n');
+    }
+
+    private static String name(String name) {
+        return RESERVED.contains(name) ? name + "_" : name;
+    }
+
+    private void line(String text) {
+        out.append("    ".repeat(indent)).append(text).append('\n');
+    }
+
+    private void block(List<Node> body) {
+        for (Node node : body) {
+            statement(node);
+        }
+    }
+
+    private void nested(String header, List<Node> body) {
+        line(header + " {");
+        indent++;
+        block(body);
+        indent--;
+    }
+
+    private void statement(Node node) {
+        if (node instanceof If) {
+            If statement = (If) node;
+            for (int i = 0; i < statement.conditions.size(); i++) {
+                String keyword = i == 0 ? "if (" : "} else if (";
+                nested(keyword + expression(statement.conditions.get(i), 0) + ")", statement.branches.get(i));
+            }
+            if (statement.otherwise != null) {
+                nested("} else", statement.otherwise);
+            }
+            line("}");
+        } else if (node instanceof While) {
+            While loop = (While) node;
+            nested("while (" + expression(loop.condition, 0) + ")", loop.body);
+            line("}");
+        } else if (node instanceof For) {
+            For loop = (For) node;
+            String init = loop.init == null ? "" : simple(loop.init);
+            String condition = loop.condition == null ? "" : " " + expression(loop.condition, 0);
+            String update = loop.update == null ? "" : " " + simple(loop.update);
+            nested("for (" + init + ";" + condition + ";" + update + ")", loop.body);
+            line("}");
+        } else if (node instanceof Each) {
+            Each loop = (Each) node;
+            nested("for (let " + name(loop.name) + " of " + expression(loop.items, 0) + ")", loop.body);
+            line("}");
+        } else if (node instanceof Return) {
+            Return statement = (Return) node;
+            line(statement.value == null ? "return;" : "return " + convert(statement.value, current.returnType, 0) + ";");
+        } else if (node instanceof Jump) {
+            line(((Jump) node).keyword + ";");
+        } else if (node instanceof Print) {
+            Print print = (Print) node;
+            line((print.newline ? "console.log(" : "process.stdout.write(") + text(print.pieces) + ");");
+        } else {
+            line(simple(node) + ";");
+        }
+    }
+
+    // A statement that also fits in a for header, without its semicolon
+    private String simple(Node node) {
+        if (node instanceof Declare) {
+            Declare declaration = (Declare) node;
+            return "let " + name(declaration.name) + " = "
+                + (declaration.value != null ? convert(declaration.value, declaration.type, 0) : zero(declaration.type));
+        }
+        if (node instanceof Assign) {
+            Assign assign = (Assign) node;
+            String target = expression(assign.target, PRIMARY);
+            String type = assign.target.type;
+            if (type.equals("String") && assign.operator.equals("+=")) {
+                return target + " += " + format(assign.value, 0);
+            }
+            if (Transpiler.isWhole(type) && assign.operator.equals("/=")) {
+                return target + " = Math.trunc(" + target + " / " + expression(assign.value, MULTIPLY + 1) + ")";
+            }
+            return target + " " + assign.operator + " " + convert(assign.value, type, 0);
+        }
+        if (node instanceof Step) {
+            Step step = (Step) node;
+            return expression(step.target, PRIMARY) + step.operator;
+        }
+        return expression(((Evaluate) node).call, 0);
+    }
+
+    private static String zero(String type) {
+        if (Transpiler.elementType(type) != null) {
+            return "[]";
+        }
+        switch (type) {
+            case "String":
+                return "\"\"";
+            case "Char":
+                return "\"\\0\"";
+            case "Bool":
+                return "false";
+            default:
+                return "0";
+        }
+    }
+
+    // The text console.write prints: a template literal when it mixes text and values
+    private String text(List<Expr> pieces) {
+        if (pieces.size() == 1) {
+            return format(pieces.get(0), 0);
+        }
+        StringBuilder template = new StringBuilder("`");
+        for (Expr piece : pieces) {
+            if (piece instanceof Literal && piece.type.equals("String")) {
+                template.append(((Literal) piece).content().replace("`", "\\`").replace("${", "\\${"));
+            } else {
+                template.append("${").append(format(piece, 0)).append('}');
+            }
+        }
+        return template.append('`').toString();
+    }
+
+    // An expression of type to, dropping the fraction when a whole number is stored
+    private String convert(Expr expr, String to, int precedence) {
+        if (expr instanceof ListOf) {
+            return list((ListOf) expr, to);
+        }
+        String from = expr.type;
+        boolean literal = expr instanceof Literal || expr instanceof Unary && ((Unary) expr).operand instanceof Literal;
+        if (Transpiler.isWhole(to) && Transpiler.isNumeric(from) && !Transpiler.isWhole(from) && !literal) {
+            return "Math.trunc(" + expression(expr, 0) + ")";
+        }
+        return expression(expr, precedence);
+    }
+
+    private String list(ListOf list, String type) {
+        String element = Transpiler.elementType(type);
+        StringBuilder code = new StringBuilder("[");
+        for (int i = 0; i < list.items.size(); i++) {
+            Expr item = list.items.get(i);
+            code.append(i > 0 ? ", " : "").append(element != null ? convert(item, element, 0) : expression(item, 0));
+        }
+        return code.append(']').toString();
+    }
+
+    // A value as a true or false, the way the interpreter tests conditions
+    private String truth(Expr expr, int precedence) {
+        return expr.type.equals("Bool") ? expression(expr, precedence) : "Boolean(" + expression(expr, 0) + ")";
+    }
+
+    // A value as the text console.write prints for it
+    private String format(Expr expr, int precedence) {
+        String type = expr.type;
+        if (Transpiler.elementType(type) == null && !type.startsWith("Float") && !type.equals(Transpiler.NUMBER)) {
+            return expression(expr, precedence); // Whole numbers, text and booleans already print alike
+        }
+        return formatValue(expression(expr, 0), type);
+    }
+
+    private String formatValue(String code, String type) {
+        String element = Transpiler.elementType(type);
+        if (element != null) {
+            helpers.add("formatList");
+            return "formatList(" + code + ", (v) => " + formatValue("v", element) + ")";
+        }
+        if (type.startsWith("Float") || type.equals(Transpiler.NUMBER)) {
+            helpers.add("formatFloat");
+            return "formatFloat(" + code + ")";
+        }
+        return type.equals("String") ? code : "String(" + code + ")";
+    }
+
+    private static String wrap(String code, int own, int precedence) {
+        return own < precedence ? "(" + code + ")" : code;
+    }
+
+    private String expression(Expr expr, int precedence) {
+        if (expr instanceof Literal) {
+            return ((Literal) expr).text;
+        }
+        if (expr instanceof Name) {
+            String name = ((Name) expr).name;
+            if (name.startsWith("math::numbers::")) {
+                String constant = MATH_CONSTANTS.get(name.substring(15));
+                return constant.contains(" ") ? wrap(constant, constant.contains("*") ? MULTIPLY : ADD, precedence) : constant;
+            }
+            return name(name);
+        }
+        if (expr instanceof Call) {
+            return call((Call) expr, precedence);
+        }
+        if (expr instanceof Unary) {
+            // -(x ** 2) and -(-x) both need their parentheses in JavaScript
+            Unary unary = (Unary) expr;
+            return wrap(unary.operator + expression(unary.operand, PRIMARY), UNARY, precedence);
+        }
+        if (expr instanceof Binary) {
+            return binary((Binary) expr, precedence);
+        }
+        if (expr instanceof Index) {
+            Index index = (Index) expr;
+            return expression(index.list, PRIMARY) + "[" + expression(index.index, 0) + "]";
+        }
+        return list((ListOf) expr, expr.type);
+    }
+
+    private String call(Call call, int precedence) {
+        if (call.function == null) {
+            String function = call.name.substring(6);
+            switch (function) {
+                case "square":
+                    return wrap(expression(call.arguments.get(0), PRIMARY) + " ** 2", POWER, precedence);
+                case "cube":
+                    return wrap(expression(call.arguments.get(0), PRIMARY) + " ** 3", POWER, precedence);
+                case "atan2":
+                    return "Math.atan2(" + expression(call.arguments.get(0), 0) + ", " + expression(call.arguments.get(1), 0) + ")";
+                default:
+                    return "Math." + function + "(" + expression(call.arguments.get(0), 0) + ")";
+            }
+        }
+        StringBuilder code = new StringBuilder(name(call.name)).append('(');
+        for (int i = 0; i < call.arguments.size(); i++) {
+            code.append(i > 0 ? ", " : "").append(convert(call.arguments.get(i), call.function.parameters.get(i).type, 0));
+        }
+        return code.append(')').toString();
+    }
+
+    private String binary(Binary binary, int precedence) {
+        String operator = binary.operator;
+        switch (operator) {
+            case "||":
+                return wrap(truth(binary.left, OR) + " || " + truth(binary.right, OR + 1), OR, precedence);
+            case "&&":
+                return wrap(truth(binary.left, AND) + " && " + truth(binary.right, AND + 1), AND, precedence);
+            case "==":
+            case "!=":
+            case "<":
+            case "<=":
+            case ">":
+            case ">=":
+                String comparison = operator.equals("==") ? "===" : operator.equals("!=") ? "!==" : operator;
+                return wrap(expression(binary.left, COMPARE + 1) + " " + comparison + " "
+                    + expression(binary.right, COMPARE + 1), COMPARE, precedence);
+            case "+":
+                if (binary.operandType.equals("String")) {
+                    return wrap(format(binary.left, ADD) + " + " + format(binary.right, ADD + 1), ADD, precedence);
+                }
+                // Fall through to arithmetic
+            case "-":
+                return wrap(expression(binary.left, ADD) + " " + operator + " "
+                    + expression(binary.right, ADD + 1), ADD, precedence);
+            case "#":
+            case "//":
+                // Math.floor rounds down like the interpreter's //, for whole and fractional operands alike
+                return "Math.floor(" + expression(binary.left, MULTIPLY) + " / " + expression(binary.right, MULTIPLY + 1) + ")";
+            default:
+                // *, / and %, which behave as they do in the interpreter
+                return wrap(expression(binary.left, MULTIPLY) + " " + operator + " "
+                    + expression(binary.right, MULTIPLY + 1), MULTIPLY, precedence);
+        }
+    }
+}
@@ -0,0 +1,292 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+
+/**
+ * Converts between JSON text and MicroScript values.
+ * Objects become maps, arrays become ListVariable, and numbers become
+ * Integer, Long or Double depending on their size and form.
+ */
+public class Json {
+    private final String text;
+    private int pos = 0;
+
+    private Json(String text) {
+        this.text = text;
+    }
+
+    /**
+     * Parse JSON text into MicroScript values
+     */
+    public static Object parse(String text) {
+        Json parser = new Json(text);
+        parser.skipWhitespace();
+        Object value = parser.parseValue();
+        parser.skipWhitespace();
+        if (parser.pos != text.length()) {
+            throw parser.error("Unexpected trailing characters");
+        }
+        return value;
+    }
+
+    /**
+     * Serialize a MicroScript value as JSON text
+     */
+    public static String stringify(Object value) {
+        StringBuilder out = new StringBuilder();
+        write(out, value);
+        return out.toString();
+    }
+
+    private static void write(StringBuilder out, Object value) {
+        if (value == null) {
+            out.append("null");
+        } else if (value instanceof String || value instanceof Character) {
+            writeString(out, value.toString());
+        } else if (value instanceof Double || value instanceof Float) {
+            double number = ((Number) value).doubleValue();
+            if (Double.isNaN(number) || Double.isInfinite(number)) {
+                throw new RuntimeException("JSON error: " + number + " cannot be represented in JSON");
+            }
+            out.append(number == Math.rint(number) && Math.abs(number) < 1e15
+                ? Long.toString((long) number) : Double.toString(number));
+        } else if (value instanceof Number || value instanceof Boolean) {
+            out.append(value);
+        } else if (value instanceof Struct) {
+            write(out, ((Struct) value).getValues());
+        } else if (value instanceof Map) {
+            out.append('{');
+            boolean first = true;
+            for (Map.Entry<?, ?> entry : ((Map<?, ?>) value).entrySet()) {
+                if (!first) {
+                    out.append(',');
+                }
+                first = false;
+                writeString(out, String.valueOf(entry.getKey()));
+                out.append(':');
+                write(out, entry.getValue());
+            }
+            out.append('}');
+        } else if (value instanceof List) {
+            out.append('[');
+            boolean first = true;
+            for (Object element : (List<?>) value) {
+                if (!first) {
+                    out.append(',');
+                }
+                first = false;
+                write(out, element);
+            }
+            out.append(']');
+        } else {
+            throw new RuntimeException("JSON error: cannot serialize value of type " + value.getClass().getSimpleName());
+        }
+    }
+
+    private static void writeString(StringBuilder out, String s) {
+        out.append('"');
+        for (int i = 0; i < s.length(); i++) {
+            char c = s.charAt(i);
+            switch (c) {
+                case '"': out.append("\\\""); break;
+                case '\\': out.append("\\\\"); break;
+                case '\n': out.append("\\n"); break;
+                case '\r': out.append("\\r"); break;
+                case '\t': out.append("\\t"); break;
+                case '\b': out.append("\\b"); break;
+                case '\f': out.append("\\f"); break;
+                default:
+                    if (c < 0x20) {
+                        out.append(String.format("\\u%04x", (int) c));
+                    } else {
+                        out.append(c);
+                    }
+            }
+        }
+        out.append('"');
+    }
+
+    private Object parseValue() {
+        if (pos >= text.length()) {
+            throw error("Unexpected end of input");
+        }
+        char c = text.charAt(pos);
+        switch (c) {
+            case '{': return parseObject();
+            case '[': return parseArray();
+            case '"': return parseString();
+            case 't': expectWord("true"); return true;
+            case 'f': expectWord("false"); return false;
+            case 'n': expectWord("null"); return null;
+            default:
+                if (c == '-' || (c >= '0' && c <= '9')) {
+                    return parseNumber();
+                }
+                throw error("Unexpected character '" + c + "'");
+        }
+    }
+
+    private Map<String, Object> parseObject() {
+        Map<String, Object> map = new MapVariable();
+        pos++; // consume {
+        skipWhitespace();
+        if (peek() == '}') {
+            pos++;
+            return map;
+        }
+        while (true) {
+            skipWhitespace();
+            if (peek() != '"') {
+                throw error("Expected string key");
+            }
+            String key = parseString();
+            skipWhitespace();
+            expect(':');
+            skipWhitespace();
+            map.put(key, parseValue());
+            skipWhitespace();
+            if (peek() == ',') {
+                pos++;
+                continue;
+            }
+            expect('}');
+            return map;
+        }
+    }
+
+    private ListVariable parseArray() {
+        ListVariable list = new ListVariable();
+        pos++; // consume [
+        skipWhitespace();
+        if (peek() == ']') {
+            pos++;
+            return list;
+        }
+        while (true) {
+            skipWhitespace();
+            list.add(parseValue());
+            skipWhitespace();
+            if (peek() == ',') {
+                pos++;
+                continue;
+            }
+            expect(']');
+            return list;
+        }
+    }
+
+    private String parseString() {
+        pos++; // consume opening quote
+        StringBuilder sb = new StringBuilder();
+        while (pos < text.length()) {
+            char c = text.charAt(pos++);
+            if (c == '"') {
+                return sb.toString();
+            }
+            if (c != '\\') {
+                sb.append(c);
+                continue;
+            }
+            if (pos >= text.length()) {
+                break;
+            }
+            char escape = text.charAt(pos++);
+            switch (escape) {
+                case '"': sb.append('"'); break;
+                case '\\': sb.append('\\'); break;
+                case '/': sb.append('/'); break;
+                case 'b': sb.append('\b'); break;
+                case 'f': sb.append('\f'); break;
+                case 'n': sb.append('\n'); break;
+                case 'r': sb.append('\r'); break;
+                case 't': sb.append('\t'); break;
+                case 'u':
+                    if (pos + 4 > text.length()) {
+                        throw error("Invalid unicode escape");
+                    }
+                    try {
+                        sb.append((char) Integer.parseInt(text.substring(pos, pos + 4), 16));
+                    } catch (NumberFormatException e) {
+                        throw error("Invalid unicode escape");
+                    }
+                    pos += 4;
+                    break;
+                default:
+                    throw error("Invalid escape '\\" + escape + "'");
+            }
+        }
+        throw error("Unterminated string");
+    }
+
+    private Object parseNumber() {
+        int start = pos;
+        if (peek() == '-') {
+            pos++;
+        }
+        boolean isFloat = false;
+        while (pos < text.length()) {
+            char c = text.charAt(pos);
+            if (c >= '0' && c <= '9') {
+                pos++;
+            } else if (c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-') {
+                isFloat = true;
+                pos++;
+            } else {
+                break;
+            }
+        }
+        String number = text.substring(start, pos);
+        try {
+            if (isFloat) {
+                return Double.parseDouble(number);
+            }
+            long value = Long.parseLong(number);
+            if (value >= Integer.MIN_VALUE && value <= Integer.MAX_VALUE) {
+                return (int) value;
+            }
+            return value;
+        } catch (NumberFormatException e) {
+            try {
+                return Double.parseDouble(number);
+            } catch (NumberFormatException invalid) {
+                throw error("Invalid number '" + number + "'");
+            }
+        }
+    }
+
+    private void expectWord(String word) {
+        if (!text.startsWith(word, pos)) {
+            throw error("Unexpected token");
+        }
+        pos += word.length();
+    }
+
+    private void expect(char c) {
+        if (peek() != c) {
+            throw error("Expected '" + c + "'");
+        }
+        pos++;
+    }
+
+    private char peek() {
+        return pos < text.length() ? text.charAt(pos) : '\0';
+    }
+
+    private void skipWhitespace() {
+        while (pos < text.length() && Character.isWhitespace(text.charAt(pos))) {
+            pos++;
+        }
+    }
+
+    private RuntimeException error(String message) {
+        return new RuntimeException("JSON error: " + message + " at position " + pos);
+    }
+}
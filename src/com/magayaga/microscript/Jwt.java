@@ -0,0 +1,156 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.nio.charset.StandardCharsets;
+import java.security.GeneralSecurityException;
+import java.security.MessageDigest;
+import java.util.ArrayList;
+import java.util.Base64;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import javax.crypto.Mac;
+import javax.crypto.spec.SecretKeySpec;
+
+/**
+ * JSON Web Tokens signed with a shared secret, for the jwt module:
+ *
+ *   import jwt
+ *   var token = jwt::sign("{\"sub\": \"alice\", \"exp\": 1893456000}", "s3cret");
+ *   var claims = jwt::verify(token, "s3cret");   // a map, or null
+ *
+ * Tokens made here are accepted by http::jwt on a server using the same
+ * secret. Only the HMAC algorithms (HS256, HS384 and HS512) are supported.
+ */
+public class Jwt {
+    public static final String DEFAULT_ALGORITHM = "HS256";
+
+    private static final Base64.Encoder ENCODER = Base64.getUrlEncoder().withoutPadding();
+    private static final Base64.Decoder DECODER = Base64.getUrlDecoder();
+
+    private Jwt() {
+    }
+
+    /**
+     * Signs the claims, a map or a JSON object, into a compact token
+     */
+    public static String sign(Object claims, String secret, String algorithm) {
+        String name = algorithm.toUpperCase();
+        mac(name, secret); // Rejects unsupported algorithms before encoding
+        if (claims instanceof String) {
+            claims = Json.parse((String) claims);
+        }
+        if (!(claims instanceof Map)) {
+            throw new RuntimeException("jwt::sign expects the claims as a Map or a JSON object");
+        }
+        Map<String, Object> header = new LinkedHashMap<>();
+        header.put("alg", name);
+        header.put("typ", "JWT");
+        String content = encode(Json.stringify(header)) + "." + encode(Json.stringify(claims));
+        return content + "." + ENCODER.encodeToString(signature(name, secret, content));
+    }
+
+    /**
+     * The claims of a token signed with the secret by one of the algorithms,
+     * or null when the signature doesn't match, the token is malformed, it
+     * has expired (exp) or it isn't valid yet (nbf)
+     */
+    public static Map<?, ?> verify(String token, String secret, List<String> algorithms) {
+        String[] parts = token.trim().split("\\.", -1);
+        if (parts.length != 3) {
+            return null;
+        }
+        try {
+            Object header = Json.parse(decode(parts[0]));
+            Object algorithm = header instanceof Map ? ((Map<?, ?>) header).get("alg") : null;
+            // Only the accepted algorithms, so "none" is never trusted
+            if (!(algorithm instanceof String) || !algorithms.contains(algorithm)) {
+                return null;
+            }
+            byte[] expected = signature((String) algorithm, secret, parts[0] + "." + parts[1]);
+            if (!MessageDigest.isEqual(expected, DECODER.decode(parts[2]))) {
+                return null;
+            }
+            Object claims = Json.parse(decode(parts[1]));
+            if (!(claims instanceof Map)) {
+                return null;
+            }
+            long now = System.currentTimeMillis() / 1000;
+            Object exp = ((Map<?, ?>) claims).get("exp");
+            Object nbf = ((Map<?, ?>) claims).get("nbf");
+            if (exp instanceof Number && now >= ((Number) exp).longValue()) {
+                return null;
+            }
+            if (nbf instanceof Number && now < ((Number) nbf).longValue()) {
+                return null;
+            }
+            return (Map<?, ?>) claims;
+        } catch (RuntimeException e) {
+            return null; // Not base64url or not JSON
+        }
+    }
+
+    /**
+     * The algorithm names in a list or a comma-separated string, such as
+     * "HS256,HS512"; HS256 when there are none
+     */
+    public static List<String> algorithms(Object value) {
+        List<String> names = new ArrayList<>();
+        Iterable<?> items = value instanceof List ? (List<?>) value
+            : value == null ? List.of() : List.of(String.valueOf(value).split(","));
+        for (Object item : items) {
+            String name = String.valueOf(item).trim().toUpperCase();
+            if (!name.isEmpty()) {
+                names.add(name);
+            }
+        }
+        if (names.isEmpty()) {
+            names.add(DEFAULT_ALGORITHM);
+        }
+        return names;
+    }
+
+    private static byte[] signature(String algorithm, String secret, String content) {
+        return mac(algorithm, secret).doFinal(content.getBytes(StandardCharsets.UTF_8));
+    }
+
+    private static Mac mac(String algorithm, String secret) {
+        if (secret.isEmpty()) {
+            throw new RuntimeException("jwt: the secret must not be empty");
+        }
+        String javaName;
+        switch (algorithm) {
+            case "HS256":
+                javaName = "HmacSHA256";
+                break;
+            case "HS384":
+                javaName = "HmacSHA384";
+                break;
+            case "HS512":
+                javaName = "HmacSHA512";
+                break;
+            default:
+                throw new RuntimeException("jwt: unsupported algorithm " + algorithm + " (expected HS256, HS384 or HS512)");
+        }
+        try {
+            Mac mac = Mac.getInstance(javaName);
+            mac.init(new SecretKeySpec(secret.getBytes(StandardCharsets.UTF_8), javaName));
+            return mac;
+        } catch (GeneralSecurityException e) {
+            throw new RuntimeException("jwt: " + algorithm + " is not available: " + e.getMessage());
+        }
+    }
+
+    private static String encode(String json) {
+        return ENCODER.encodeToString(json.getBytes(StandardCharsets.UTF_8));
+    }
+
+    private static String decode(String part) {
+        return new String(DECODER.decode(part), StandardCharsets.UTF_8);
+    }
+}
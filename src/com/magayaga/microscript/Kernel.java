@@ -0,0 +1,440 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.io.OutputStream;
+import java.io.PrintStream;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Paths;
+import java.security.GeneralSecurityException;
+import java.security.MessageDigest;
+import java.time.Instant;
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.UUID;
+import javax.crypto.Mac;
+import javax.crypto.spec.SecretKeySpec;
+
+/**
+ * A Jupyter kernel, so notebooks can run MicroScript cells.
+ * Usage:
+ *   microscript kernel --connection-file <file.json>
+ *
+ * Jupyter starts the kernel itself; install a kernel spec such as
+ *
+ *   {"argv": ["microscript", "kernel", "--connection-file", "{connection_file}"],
+ *    "display_name": "MicroScript", "language": "microscript", "interrupt_mode": "message"}
+ *
+ * as kernel.json with jupyter kernelspec install. Cells run one after
+ * another against the same interpreter, so globals, functions and macros
+ * defined in one cell are there in the next. What a cell prints with
+ * console.write is streamed to the notebook as it is written, and errors
+ * are shown with the line of the cell they happened on.
+ *
+ * Messages follow version 5.3 of the Jupyter messaging protocol over the
+ * channels in the connection file; stdin isn't supported, so a cell can't
+ * ask for input.
+ */
+public class Kernel {
+    private static final String PROTOCOL_VERSION = "5.3";
+    private static final String DELIMITER = "<IDS|MSG>";
+
+    private final Interpreter interpreter = new Interpreter();
+    private final String session = UUID.randomUUID().toString();
+    private final Mac mac;
+    private final Object executing = new Object();
+    private Zmq.Listener iopub;
+    private int executionCount;
+    // The request whose cell is running, which its output is sent in reply to
+    private volatile byte[] parent = "{}".getBytes(StandardCharsets.UTF_8);
+
+    private Kernel(String scheme, String key) throws GeneralSecurityException {
+        if (key.isEmpty()) {
+            mac = null; // Messages go unsigned
+        } else {
+            if (!scheme.equals("hmac-sha256")) {
+                throw new GeneralSecurityException("unsupported signature scheme " + scheme + " (expected hmac-sha256)");
+            }
+            mac = Mac.getInstance("HmacSHA256");
+            mac.init(new SecretKeySpec(key.getBytes(StandardCharsets.UTF_8), "HmacSHA256"));
+        }
+    }
+
+    /**
+     * A message from a frontend, split into its parts
+     */
+    private static final class Message {
+        final List<byte[]> identities;
+        final byte[] header;
+        final Map<?, ?> content;
+        final String type;
+
+        Message(List<byte[]> identities, byte[] header, Map<?, ?> content, String type) {
+            this.identities = identities;
+            this.header = header;
+            this.content = content;
+            this.type = type;
+        }
+    }
+
+    public static void main(String[] args) {
+        String connectionFile = null;
+        for (int i = 0; i < args.length; i++) {
+            if (args[i].equals("--connection-file") && i + 1 < args.length) {
+                connectionFile = args[++i];
+            } else {
+                connectionFile = null;
+                break;
+            }
+        }
+        if (connectionFile == null) {
+            System.err.println("Usage: microscript kernel --connection-file <file.json>");
+            return;
+        }
+
+        Map<?, ?> connection;
+        try {
+            Object parsed = Json.parse(Files.readString(Paths.get(connectionFile), StandardCharsets.UTF_8));
+            if (!(parsed instanceof Map)) {
+                throw new IOException("expected a JSON object");
+            }
+            connection = (Map<?, ?>) parsed;
+        } catch (IOException | RuntimeException e) {
+            System.err.println("Error reading connection file '" + connectionFile + "': " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+        try {
+            Object scheme = connection.get("signature_scheme");
+            Object key = connection.get("key");
+            new Kernel(scheme == null ? "hmac-sha256" : scheme.toString(), key == null ? "" : key.toString()).start(connection);
+        } catch (IOException | GeneralSecurityException e) {
+            System.err.println("Error starting kernel: " + e.getMessage());
+            System.exit(1);
+        }
+    }
+
+    private void start(Map<?, ?> connection) throws IOException {
+        Object transport = connection.get("transport");
+        if (transport != null && !transport.equals("tcp")) {
+            throw new IOException("unsupported transport " + transport + " (expected tcp)");
+        }
+        String ip = connection.get("ip") == null ? "127.0.0.1" : connection.get("ip").toString();
+        iopub = Zmq.bind("PUB", ip, port(connection, "iopub_port"), null);
+        Zmq.bind("ROUTER", ip, port(connection, "shell_port"), this::shell);
+        Zmq.bind("ROUTER", ip, port(connection, "control_port"), this::control);
+        Zmq.bind("ROUTER", ip, port(connection, "stdin_port"), null);
+        // The heartbeat echoes whatever it is sent
+        Zmq.bind("REP", ip, port(connection, "hb_port"), (peer, frames) -> peer.send(frames));
+
+        System.setOut(new PrintStream(new Stream("stdout"), true, StandardCharsets.UTF_8));
+        System.setErr(new PrintStream(new Stream("stderr"), true, StandardCharsets.UTF_8));
+        publish("status", Map.of("execution_state", "starting"));
+
+        // The listeners serve on daemon threads; this one only has to stay alive
+        synchronized (this) {
+            while (true) {
+                try {
+                    wait();
+                } catch (InterruptedException e) {
+                    return;
+                }
+            }
+        }
+    }
+
+    private static int port(Map<?, ?> connection, String name) throws IOException {
+        Object port = connection.get(name);
+        if (!(port instanceof Number)) {
+            throw new IOException("missing " + name);
+        }
+        return ((Number) port).intValue();
+    }
+
+    private void shell(Zmq.Connection peer, List<byte[]> frames) throws IOException {
+        Message message = parse(frames);
+        if (message == null) {
+            return;
+        }
+        publish(message, "status", Map.of("execution_state", "busy"));
+        try {
+            switch (message.type) {
+                case "kernel_info_request":
+                    reply(peer, message, "kernel_info_reply", kernelInfo());
+                    break;
+                case "execute_request":
+                    reply(peer, message, "execute_reply", execute(message));
+                    break;
+                case "is_complete_request":
+                    reply(peer, message, "is_complete_reply", isComplete(message));
+                    break;
+                case "complete_request":
+                    reply(peer, message, "complete_reply", complete(message));
+                    break;
+                case "shutdown_request":
+                    shutdown(peer, message);
+                    break;
+                default:
+                    break; // Requests the kernel doesn't implement get no reply
+            }
+        } finally {
+            publish(message, "status", Map.of("execution_state", "idle"));
+        }
+    }
+
+    private void control(Zmq.Connection peer, List<byte[]> frames) throws IOException {
+        Message message = parse(frames);
+        if (message == null) {
+            return;
+        }
+        switch (message.type) {
+            case "kernel_info_request":
+                reply(peer, message, "kernel_info_reply", kernelInfo());
+                break;
+            case "interrupt_request":
+                interpreter.cancel();
+                reply(peer, message, "interrupt_reply", Map.of("status", "ok"));
+                break;
+            case "shutdown_request":
+                shutdown(peer, message);
+                break;
+            default:
+                break;
+        }
+    }
+
+    private Map<String, Object> kernelInfo() {
+        Map<String, Object> language = new LinkedHashMap<>();
+        language.put("name", "microscript");
+        language.put("version", Cli.VERSION.substring(Cli.VERSION.lastIndexOf('v') + 1));
+        language.put("mimetype", "text/x-microscript");
+        language.put("file_extension", ".microscript");
+
+        Map<String, Object> info = new LinkedHashMap<>();
+        info.put("status", "ok");
+        info.put("protocol_version", PROTOCOL_VERSION);
+        info.put("implementation", "microscript");
+        info.put("implementation_version", language.get("version"));
+        info.put("language_info", language);
+        info.put("banner", Cli.VERSION);
+        info.put("help_links", List.of());
+        return info;
+    }
+
+    private Map<String, Object> execute(Message message) {
+        String code = String.valueOf(message.content.get("code"));
+        boolean silent = Boolean.TRUE.equals(message.content.get("silent"));
+        Map<String, Object> reply = new LinkedHashMap<>();
+        synchronized (executing) {
+            if (!silent) {
+                executionCount++;
+                publish(message, "execute_input", Map.of("code", code, "execution_count", executionCount));
+            }
+            // A fresh cancellation per cell, so an interrupt only stops the cell it was meant for
+            interpreter.getEnvironment().setCancellation(new Cancellation());
+            parent = message.header;
+            String error = null;
+            try {
+                interpreter.run(code);
+            } catch (ScriptException e) {
+                error = (e.getLine() >= 0 ? "Error at line " + (e.getLine() + 1) : "Error") + ": " + e.getMessage();
+            } catch (RuntimeException | StackOverflowError e) {
+                error = "Error: " + e.getMessage();
+            } finally {
+                System.out.flush();
+                System.err.flush();
+            }
+            reply.put("execution_count", executionCount);
+            if (error == null) {
+                reply.put("status", "ok");
+                reply.put("payload", List.of());
+                reply.put("user_expressions", Map.of());
+                return reply;
+            }
+            Map<String, Object> details = new LinkedHashMap<>();
+            details.put("ename", "Error");
+            details.put("evalue", error.substring(error.indexOf(": ") + 2));
+            details.put("traceback", List.of(error));
+            if (!silent) {
+                publish(message, "error", details);
+            }
+            reply.put("status", "error");
+            reply.putAll(details);
+            return reply;
+        }
+    }
+
+    private Map<String, Object> isComplete(Message message) {
+        String code = String.valueOf(message.content.get("code"));
+        Map<String, Object> reply = new LinkedHashMap<>();
+        if (Repl.depth(code) > 0) {
+            reply.put("status", "incomplete");
+            reply.put("indent", "    ");
+        } else {
+            reply.put("status", "complete");
+        }
+        return reply;
+    }
+
+    private Map<String, Object> complete(Message message) {
+        String code = String.valueOf(message.content.get("code"));
+        Object position = message.content.get("cursor_pos");
+        int end = position instanceof Number ? Math.min(((Number) position).intValue(), code.length()) : code.length();
+        int start = end;
+        while (start > 0 && (Character.isLetterOrDigit(code.charAt(start - 1)) || "_:.".indexOf(code.charAt(start - 1)) >= 0)) {
+            start--;
+        }
+        String word = code.substring(start, end);
+        List<String> matches = new ArrayList<>();
+        for (String name : Repl.completions(interpreter.getEnvironment())) {
+            if (name.startsWith(word)) {
+                matches.add(name);
+            }
+        }
+        Map<String, Object> reply = new LinkedHashMap<>();
+        reply.put("status", "ok");
+        reply.put("matches", matches);
+        reply.put("cursor_start", start);
+        reply.put("cursor_end", end);
+        reply.put("metadata", Map.of());
+        return reply;
+    }
+
+    private void shutdown(Zmq.Connection peer, Message message) throws IOException {
+        Object restart = message.content.get("restart");
+        Map<String, Object> reply = new LinkedHashMap<>();
+        reply.put("status", "ok");
+        reply.put("restart", Boolean.TRUE.equals(restart));
+        reply(peer, message, "shutdown_reply", reply);
+        publish(message, "status", Map.of("execution_state", "idle"));
+        System.exit(0);
+    }
+
+    // Splits a message into identities, header and content, or null when its signature is wrong
+    private Message parse(List<byte[]> frames) {
+        int delimiter = 0;
+        while (delimiter < frames.size() && !Arrays.equals(frames.get(delimiter), DELIMITER.getBytes(StandardCharsets.UTF_8))) {
+            delimiter++;
+        }
+        if (frames.size() < delimiter + 6) {
+            return null;
+        }
+        byte[] header = frames.get(delimiter + 2);
+        byte[] parentHeader = frames.get(delimiter + 3);
+        byte[] metadata = frames.get(delimiter + 4);
+        byte[] content = frames.get(delimiter + 5);
+        byte[] expected = sign(header, parentHeader, metadata, content).getBytes(StandardCharsets.UTF_8);
+        if (!MessageDigest.isEqual(expected, frames.get(delimiter + 1))) {
+            return null;
+        }
+        try {
+            Object parsedHeader = Json.parse(new String(header, StandardCharsets.UTF_8));
+            Object parsedContent = Json.parse(new String(content, StandardCharsets.UTF_8));
+            if (!(parsedHeader instanceof Map) || !(parsedContent instanceof Map)) {
+                return null;
+            }
+            String type = String.valueOf(((Map<?, ?>) parsedHeader).get("msg_type"));
+            return new Message(new ArrayList<>(frames.subList(0, delimiter)), header, (Map<?, ?>) parsedContent, type);
+        } catch (RuntimeException e) {
+            return null;
+        }
+    }
+
+    private void reply(Zmq.Connection peer, Message request, String type, Map<String, Object> content) throws IOException {
+        peer.send(frames(request.identities, request.header, type, content));
+    }
+
+    private void publish(String type, Map<String, Object> content) {
+        iopub.publish(frames(List.of(("kernel." + session + "." + type).getBytes(StandardCharsets.UTF_8)),
+            "{}".getBytes(StandardCharsets.UTF_8), type, content));
+    }
+
+    private void publish(Message request, String type, Map<String, Object> content) {
+        iopub.publish(frames(List.of(("kernel." + session + "." + type).getBytes(StandardCharsets.UTF_8)),
+            request.header, type, content));
+    }
+
+    private List<byte[]> frames(List<byte[]> identities, byte[] parentHeader, String type, Map<String, Object> content) {
+        Map<String, Object> header = new LinkedHashMap<>();
+        header.put("msg_id", UUID.randomUUID().toString());
+        header.put("session", session);
+        header.put("username", "microscript");
+        header.put("date", Instant.now().toString());
+        header.put("msg_type", type);
+        header.put("version", PROTOCOL_VERSION);
+        byte[] headerBytes = Json.stringify(header).getBytes(StandardCharsets.UTF_8);
+        byte[] metadata = "{}".getBytes(StandardCharsets.UTF_8);
+        byte[] contentBytes = Json.stringify(content).getBytes(StandardCharsets.UTF_8);
+
+        List<byte[]> frames = new ArrayList<>(identities);
+        frames.add(DELIMITER.getBytes(StandardCharsets.UTF_8));
+        frames.add(sign(headerBytes, parentHeader, metadata, contentBytes).getBytes(StandardCharsets.UTF_8));
+        frames.add(headerBytes);
+        frames.add(parentHeader);
+        frames.add(metadata);
+        frames.add(contentBytes);
+        return frames;
+    }
+
+    // The hex HMAC of a message's parts, empty when messages aren't signed
+    private String sign(byte[]... parts) {
+        if (mac == null) {
+            return "";
+        }
+        byte[] digest;
+        synchronized (mac) {
+            for (byte[] part : parts) {
+                mac.update(part);
+            }
+            digest = mac.doFinal();
+        }
+        StringBuilder hex = new StringBuilder();
+        for (byte b : digest) {
+            hex.append(String.format("%02x", b));
+        }
+        return hex.toString();
+    }
+
+    /**
+     * Sends what a cell prints to the notebook, a line at a time
+     */
+    private final class Stream extends OutputStream {
+        private final String name;
+        private final ByteArrayOutputStream buffer = new ByteArrayOutputStream();
+
+        Stream(String name) {
+            this.name = name;
+        }
+
+        @Override
+        public synchronized void write(int b) {
+            buffer.write(b);
+        }
+
+        @Override
+        public synchronized void write(byte[] bytes, int offset, int length) {
+            buffer.write(bytes, offset, length);
+        }
+
+        @Override
+        public synchronized void flush() {
+            if (buffer.size() == 0) {
+                return;
+            }
+            String text = buffer.toString(StandardCharsets.UTF_8);
+            buffer.reset();
+            iopub.publish(frames(List.of(("stream." + name).getBytes(StandardCharsets.UTF_8)), parent, "stream",
+                Map.of("name", name, "text", text)));
+        }
+    }
+}
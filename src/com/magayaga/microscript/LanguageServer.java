@@ -0,0 +1,456 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.concurrent.ConcurrentHashMap;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Language Server Protocol over stdin/stdout, started with `microscript lsp`.
+ * Provides diagnostics from the Checker and TypeChecker, go-to-definition
+ * and hover for functions and macros, and completion of keywords, builtins
+ * and the document's own definitions.
+ */
+public class LanguageServer {
+    private static final Pattern FUNCTION_PATTERN = Pattern.compile("^\\s*function\\s+([\\w:]+)\\s*(?:<[^>]*>)?\\(");
+    private static final Pattern C_STYLE_FUNCTION_PATTERN = Pattern.compile("^\\s*(?:String|Int32|Int64|Float32|Float64|fn)\\s+([\\w:]+)\\s*\\(");
+    private static final Pattern MACRO_PATTERN = Pattern.compile("^\\s*#define\\s+([A-Z_][A-Z0-9_]*)");
+    private static final Pattern LINE_SEPARATOR = Pattern.compile("\\r\\n|\\r|\\n");
+
+    // LSP constants
+    private static final int SYNC_FULL = 1;
+    private static final int SEVERITY_ERROR = 1;
+    private static final int SEVERITY_WARNING = 2;
+    private static final int KIND_FUNCTION = 3;
+    private static final int KIND_MODULE = 9;
+    private static final int KIND_KEYWORD = 14;
+    private static final int KIND_CONSTANT = 21;
+    private static final int METHOD_NOT_FOUND = -32601;
+    private static final int INTERNAL_ERROR = -32603;
+
+    static final List<String> KEYWORDS = Arrays.asList(
+        "function", "fn", "var", "bool", "list", "import", "if", "elif", "else", "while", "for",
+        "switch", "break", "continue", "return", "defer", "with", "using", "struct", "class", "namespace", "spawn", "await");
+
+    static final List<String> MODULES = Arrays.asList("math", "io", "http", "ffi", "dns", "redis", "jwt");
+
+    // Builtin signature -> description, shown in completion and hover
+    static final Map<String, String> BUILTINS = new LinkedHashMap<>();
+
+    static {
+        BUILTINS.put("console.write(value)", "Prints a value followed by a newline.");
+        BUILTINS.put("console.writef(template, args...)", "Prints a template, replacing {expression} placeholders.");
+        BUILTINS.put("console.system(command, options...)", "Runs a command through the platform shell; options are \"shell=\", \"cwd=\", \"env=NAME=VALUE\" and \"timeout=\" (seconds).");
+        BUILTINS.put("runtime.memory()", "Returns environment, interning and heap statistics.");
+        BUILTINS.put("atomic.get(name)", "Reads a variable atomically.");
+        BUILTINS.put("atomic.set(name, value)", "Assigns a variable atomically.");
+        BUILTINS.put("atomic.add(name, delta)", "Adds to a variable atomically and returns the new value.");
+        BUILTINS.put("mutex()", "Creates a lock with lock(), unlock(), tryLock() and isLocked().");
+        for (String name : Arrays.asList("sqrt", "square", "cbrt", "cube", "abs", "log10", "log2", "log",
+                "sin", "cos", "tan", "asin", "acos", "atan", "sinh", "cosh", "tanh", "asinh", "acosh", "atanh")) {
+            BUILTINS.put("math::" + name + "(x)", "Math module.");
+        }
+        BUILTINS.put("math::atan2(y, x)", "Math module.");
+        BUILTINS.put("io::print(value)", "Prints a string or character code without a newline.");
+        BUILTINS.put("io::println(value)", "Prints a string or character code with a newline.");
+        BUILTINS.put("http::createServer(port)", "Creates an HTTP server and returns its handle.");
+        BUILTINS.put("http::addRoute(server, method, path, handler)", "Routes requests to a script function.");
+        BUILTINS.put("http::wait(server)", "Blocks until the server stops.");
+        BUILTINS.put("http::stopServer(server)", "Stops a server.");
+        BUILTINS.put("http::proxy(server, prefix, upstream)", "Forwards requests under the prefix to an upstream server.");
+        BUILTINS.put("http::graphql(server, path, schema, resolvers, graphiql)", "Serves a GraphQL schema resolved by script functions.");
+        BUILTINS.put("http::basicAuth(server, users)", "Requires HTTP Basic credentials from a map of user names to passwords.");
+        BUILTINS.put("http::jwt(server, secret, algorithms)", "Requires a bearer token signed with the secret.");
+        BUILTINS.put("http::requestUser(requestId)", "Returns the verified user of a request, or null.");
+        BUILTINS.put("ffi::open(path)", "Opens a C library.");
+        BUILTINS.put("ffi::call(library, name, [args], returnType)", "Calls a C function; returnType is double, int, long, pointer, string or void.");
+        BUILTINS.put("ffi::close(library)", "Closes a C library.");
+        BUILTINS.put("dns::lookup(host)", "Returns the IPv4 and IPv6 addresses of a host.");
+        BUILTINS.put("dns::reverse(ip)", "Returns the host names an IP address points back to.");
+        BUILTINS.put("dns::mx(domain)", "Returns the mail servers for a domain, most preferred first.");
+        BUILTINS.put("dns::txt(domain)", "Returns the TXT records of a domain.");
+        BUILTINS.put("redis::connect(host, port)", "Connects to a Redis server; both default to localhost:6379.");
+        BUILTINS.put("redis::get(connection, key)", "Returns the value of a key, or null.");
+        BUILTINS.put("redis::set(connection, key, value, seconds)", "Sets a key, expiring after the optional seconds.");
+        BUILTINS.put("redis::del(connection, key, ...)", "Deletes keys and returns how many existed.");
+        BUILTINS.put("redis::expire(connection, key, seconds)", "Sets a key to expire; false when it doesn't exist.");
+        BUILTINS.put("redis::incr(connection, key, amount)", "Adds 1, or the optional amount, to a key and returns it.");
+        BUILTINS.put("redis::publish(connection, channel, message)", "Publishes a message to a channel.");
+        BUILTINS.put("redis::subscribe(connection, channel, handler)", "Calls handler(channel, message) for each message.");
+        BUILTINS.put("redis::wait(connection)", "Blocks until every subscription ends.");
+        BUILTINS.put("jwt::sign(claims, secret, algorithm)", "Signs claims into a token; the algorithm defaults to HS256.");
+        BUILTINS.put("jwt::verify(token, secret, algorithms)", "Returns the claims of a valid token, or null.");
+    }
+
+    /**
+     * A function or macro definition found in a document
+     */
+    private static class Symbol {
+        final String name;
+        final String uri;
+        final int line;
+        final int column;
+        final String signature;
+        final boolean macro;
+
+        Symbol(String name, String uri, int line, int column, String signature, boolean macro) {
+            this.name = name;
+            this.uri = uri;
+            this.line = line;
+            this.column = column;
+            this.signature = signature;
+            this.macro = macro;
+        }
+    }
+
+    private final MessageStream stream;
+    // Open documents by URI, as sent by the editor
+    private final Map<String, List<String>> documents = new ConcurrentHashMap<>();
+    private boolean shutdownRequested;
+
+    public LanguageServer(InputStream in, OutputStream out) {
+        this.stream = new MessageStream(in, out);
+    }
+
+    public static void main(String[] args) {
+        try {
+            System.exit(new LanguageServer(System.in, System.out).serve());
+        } catch (IOException e) {
+            System.err.println("Language server error: " + e.getMessage());
+            System.exit(1);
+        }
+    }
+
+    /**
+     * Handles messages until the client sends exit or closes the stream.
+     * Returns the process exit code the protocol asks for.
+     */
+    public int serve() throws IOException {
+        while (true) {
+            Map<String, Object> message = stream.read();
+            if (message == null) {
+                return 1;
+            }
+            String method = (String) message.get("method");
+            if ("exit".equals(method)) {
+                return shutdownRequested ? 0 : 1;
+            }
+            handle(method, message.get("id"), asMap(message.get("params")));
+        }
+    }
+
+    private void handle(String method, Object id, Map<String, Object> params) throws IOException {
+        if (method == null) {
+            return; // A response to something we never send
+        }
+        try {
+            switch (method) {
+                case "initialize":
+                    respond(id, initializeResult());
+                    break;
+                case "shutdown":
+                    shutdownRequested = true;
+                    respond(id, null);
+                    break;
+                case "textDocument/didOpen": {
+                    Map<String, Object> document = asMap(params.get("textDocument"));
+                    open((String) document.get("uri"), (String) document.get("text"));
+                    break;
+                }
+                case "textDocument/didChange": {
+                    String uri = (String) asMap(params.get("textDocument")).get("uri");
+                    List<?> changes = (List<?>) params.get("contentChanges");
+                    if (changes != null && !changes.isEmpty()) {
+                        // Full sync: the last change holds the whole document
+                        open(uri, (String) asMap(changes.get(changes.size() - 1)).get("text"));
+                    }
+                    break;
+                }
+                case "textDocument/didClose": {
+                    String uri = (String) asMap(params.get("textDocument")).get("uri");
+                    documents.remove(uri);
+                    publishDiagnostics(uri, new ArrayList<>());
+                    break;
+                }
+                case "textDocument/definition":
+                    respond(id, definition(params));
+                    break;
+                case "textDocument/hover":
+                    respond(id, hover(params));
+                    break;
+                case "textDocument/completion":
+                    respond(id, completion(params));
+                    break;
+                default:
+                    if (id != null) {
+                        respondError(id, METHOD_NOT_FOUND, "Method not found: " + method);
+                    }
+            }
+        } catch (RuntimeException e) {
+            if (id != null) {
+                respondError(id, INTERNAL_ERROR, e.getMessage());
+            } else {
+                System.err.println("Error handling " + method + ": " + e.getMessage());
+            }
+        }
+    }
+
+    private Map<String, Object> initializeResult() {
+        Map<String, Object> completion = new LinkedHashMap<>();
+        completion.put("triggerCharacters", Arrays.asList(".", ":"));
+
+        Map<String, Object> capabilities = new LinkedHashMap<>();
+        capabilities.put("textDocumentSync", SYNC_FULL);
+        capabilities.put("definitionProvider", true);
+        capabilities.put("hoverProvider", true);
+        capabilities.put("completionProvider", completion);
+
+        Map<String, Object> serverInfo = new LinkedHashMap<>();
+        serverInfo.put("name", "microscript");
+
+        Map<String, Object> result = new LinkedHashMap<>();
+        result.put("capabilities", capabilities);
+        result.put("serverInfo", serverInfo);
+        return result;
+    }
+
+    private void open(String uri, String text) throws IOException {
+        List<String> lines = Arrays.asList(LINE_SEPARATOR.split(text, -1));
+        documents.put(uri, lines);
+
+        List<Checker.Diagnostic> found = new ArrayList<>(Checker.check(lines));
+        found.addAll(TypeChecker.check(lines));
+        List<Object> diagnostics = new ArrayList<>();
+        for (Checker.Diagnostic diagnostic : found) {
+            Map<String, Object> item = new LinkedHashMap<>();
+            item.put("range", range(diagnostic.getLine(), diagnostic.getColumn(),
+                diagnostic.getColumn() + diagnostic.getLength()));
+            item.put("severity", diagnostic.getSeverity() == Checker.Severity.ERROR ? SEVERITY_ERROR : SEVERITY_WARNING);
+            item.put("source", "microscript");
+            item.put("code", diagnostic.getCode());
+            item.put("message", diagnostic.getMessage());
+            diagnostics.add(item);
+        }
+        publishDiagnostics(uri, diagnostics);
+    }
+
+    private void publishDiagnostics(String uri, List<Object> diagnostics) throws IOException {
+        Map<String, Object> params = new LinkedHashMap<>();
+        params.put("uri", uri);
+        params.put("diagnostics", diagnostics);
+        Map<String, Object> notification = new LinkedHashMap<>();
+        notification.put("jsonrpc", "2.0");
+        notification.put("method", "textDocument/publishDiagnostics");
+        notification.put("params", params);
+        stream.write(notification);
+    }
+
+    private Object definition(Map<String, Object> params) {
+        Symbol symbol = findSymbol(params);
+        if (symbol == null) {
+            return null;
+        }
+        Map<String, Object> location = new LinkedHashMap<>();
+        location.put("uri", symbol.uri);
+        location.put("range", range(symbol.line, symbol.column, symbol.column + symbol.name.length()));
+        return location;
+    }
+
+    private Object hover(Map<String, Object> params) {
+        String word = wordAt(params);
+        if (word == null) {
+            return null;
+        }
+        String text = null;
+        Symbol symbol = findSymbol(params);
+        if (symbol != null) {
+            text = "```microscript\n" + symbol.signature + "\n```";
+        } else {
+            for (Map.Entry<String, String> builtin : BUILTINS.entrySet()) {
+                if (builtin.getKey().startsWith(word + "(")) {
+                    text = "```microscript\n" + builtin.getKey() + "\n```\n" + builtin.getValue();
+                    break;
+                }
+            }
+        }
+        if (text == null) {
+            return null;
+        }
+        Map<String, Object> contents = new LinkedHashMap<>();
+        contents.put("kind", "markdown");
+        contents.put("value", text);
+        Map<String, Object> result = new LinkedHashMap<>();
+        result.put("contents", contents);
+        return result;
+    }
+
+    private Object completion(Map<String, Object> params) {
+        List<Object> items = new ArrayList<>();
+        for (String keyword : KEYWORDS) {
+            items.add(completionItem(keyword, KIND_KEYWORD, null, null));
+        }
+        for (String module : MODULES) {
+            items.add(completionItem(module, KIND_MODULE, "module", null));
+        }
+        for (Map.Entry<String, String> builtin : BUILTINS.entrySet()) {
+            String signature = builtin.getKey();
+            items.add(completionItem(signature.substring(0, signature.indexOf('(')), KIND_FUNCTION,
+                signature, builtin.getValue()));
+        }
+        String uri = (String) asMap(params.get("textDocument")).get("uri");
+        List<String> lines = documents.get(uri);
+        if (lines != null) {
+            for (Symbol symbol : symbols(uri, lines)) {
+                items.add(completionItem(symbol.name, symbol.macro ? KIND_CONSTANT : KIND_FUNCTION,
+                    symbol.signature, null));
+            }
+        }
+        return items;
+    }
+
+    private static Map<String, Object> completionItem(String label, int kind, String detail, String documentation) {
+        Map<String, Object> item = new LinkedHashMap<>();
+        item.put("label", label);
+        item.put("kind", kind);
+        if (detail != null) {
+            item.put("detail", detail);
+        }
+        if (documentation != null) {
+            item.put("documentation", documentation);
+        }
+        return item;
+    }
+
+    /**
+     * Finds the definition of the name under the cursor, preferring the
+     * current document over other open ones
+     */
+    private Symbol findSymbol(Map<String, Object> params) {
+        String word = wordAt(params);
+        if (word == null) {
+            return null;
+        }
+        String uri = (String) asMap(params.get("textDocument")).get("uri");
+        List<String> order = new ArrayList<>();
+        order.add(uri);
+        for (String other : documents.keySet()) {
+            if (!other.equals(uri)) {
+                order.add(other);
+            }
+        }
+        for (String candidate : order) {
+            List<String> lines = documents.get(candidate);
+            if (lines == null) {
+                continue;
+            }
+            for (Symbol symbol : symbols(candidate, lines)) {
+                if (symbol.name.equals(word)) {
+                    return symbol;
+                }
+            }
+        }
+        return null;
+    }
+
+    private static List<Symbol> symbols(String uri, List<String> lines) {
+        List<Symbol> symbols = new ArrayList<>();
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            Matcher matcher = FUNCTION_PATTERN.matcher(line);
+            boolean macro = false;
+            if (!matcher.find()) {
+                matcher = C_STYLE_FUNCTION_PATTERN.matcher(line);
+                if (!matcher.find()) {
+                    matcher = MACRO_PATTERN.matcher(line);
+                    if (!matcher.find()) {
+                        continue;
+                    }
+                    macro = true;
+                }
+            }
+            String signature = line.trim();
+            if (!macro && signature.endsWith("{")) {
+                signature = signature.substring(0, signature.length() - 1).trim();
+            }
+            symbols.add(new Symbol(matcher.group(1), uri, i, matcher.start(1), signature, macro));
+        }
+        return symbols;
+    }
+
+    // The identifier under the cursor, including module (::) and member (.) separators
+    private String wordAt(Map<String, Object> params) {
+        String uri = (String) asMap(params.get("textDocument")).get("uri");
+        Map<String, Object> position = asMap(params.get("position"));
+        List<String> lines = documents.get(uri);
+        int lineNumber = ((Number) position.get("line")).intValue();
+        if (lines == null || lineNumber >= lines.size()) {
+            return null;
+        }
+        String line = lines.get(lineNumber);
+        int character = Math.min(((Number) position.get("character")).intValue(), line.length());
+        int start = character;
+        while (start > 0 && isWordChar(line.charAt(start - 1))) {
+            start--;
+        }
+        int end = character;
+        while (end < line.length() && isWordChar(line.charAt(end))) {
+            end++;
+        }
+        return start < end ? line.substring(start, end) : null;
+    }
+
+    private static boolean isWordChar(char c) {
+        return Character.isLetterOrDigit(c) || c == '_' || c == ':' || c == '.';
+    }
+
+    private static Map<String, Object> range(int line, int startColumn, int endColumn) {
+        Map<String, Object> range = new LinkedHashMap<>();
+        range.put("start", position(line, startColumn));
+        range.put("end", position(line, endColumn));
+        return range;
+    }
+
+    private static Map<String, Object> position(int line, int character) {
+        Map<String, Object> position = new LinkedHashMap<>();
+        position.put("line", line);
+        position.put("character", character);
+        return position;
+    }
+
+    private void respond(Object id, Object result) throws IOException {
+        Map<String, Object> response = new LinkedHashMap<>();
+        response.put("jsonrpc", "2.0");
+        response.put("id", id);
+        response.put("result", result);
+        stream.write(response);
+    }
+
+    private void respondError(Object id, int code, String message) throws IOException {
+        Map<String, Object> error = new LinkedHashMap<>();
+        error.put("code", code);
+        error.put("message", message);
+        Map<String, Object> response = new LinkedHashMap<>();
+        response.put("jsonrpc", "2.0");
+        response.put("id", id);
+        response.put("error", error);
+        stream.write(response);
+    }
+
+    @SuppressWarnings("unchecked")
+    private static Map<String, Object> asMap(Object value) {
+        return value instanceof Map ? (Map<String, Object>) value : new LinkedHashMap<>();
+    }
+}
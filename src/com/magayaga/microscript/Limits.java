@@ -0,0 +1,133 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.Map;
+
+/**
+ * The safety limits a script runs under. Every environment under the same
+ * root shares an instance, so set them on the root before running:
+ *
+ *   callDepth       nested function calls; 0 leaves it to the JVM stack
+ *   loopIterations  iterations of a single for loop
+ *   macroPasses     passes of nested macro expansion per line
+ *
+ * Heavy scripts can raise them and sandboxes can lower them, with run's
+ * --max-depth, --max-iterations and --max-macro-passes options or a
+ * "limits" object in a bundle's bundle.json that uses the names above.
+ */
+public class Limits {
+    public static final String CALL_DEPTH = "callDepth";
+    public static final String LOOP_ITERATIONS = "loopIterations";
+    public static final String MACRO_PASSES = "macroPasses";
+
+    public static final int DEFAULT_CALL_DEPTH = 0;
+    public static final int DEFAULT_LOOP_ITERATIONS = 1_000_000;
+    public static final int DEFAULT_MACRO_PASSES = 10;
+
+    private volatile int callDepth = DEFAULT_CALL_DEPTH;
+    private volatile int loopIterations = DEFAULT_LOOP_ITERATIONS;
+    private volatile int macroPasses = DEFAULT_MACRO_PASSES;
+
+    public int getCallDepth() {
+        return callDepth;
+    }
+
+    public void setCallDepth(int callDepth) {
+        this.callDepth = check(CALL_DEPTH, callDepth);
+    }
+
+    public int getLoopIterations() {
+        return loopIterations;
+    }
+
+    public void setLoopIterations(int loopIterations) {
+        this.loopIterations = check(LOOP_ITERATIONS, loopIterations);
+    }
+
+    public int getMacroPasses() {
+        return macroPasses;
+    }
+
+    public void setMacroPasses(int macroPasses) {
+        this.macroPasses = check(MACRO_PASSES, macroPasses);
+    }
+
+    /**
+     * Sets a limit by its name, as used in bundle.json
+     */
+    public void set(String name, int value) {
+        switch (name) {
+            case CALL_DEPTH:
+                setCallDepth(value);
+                break;
+            case LOOP_ITERATIONS:
+                setLoopIterations(value);
+                break;
+            case MACRO_PASSES:
+                setMacroPasses(value);
+                break;
+            default:
+                throw new IllegalArgumentException("Unknown limit: " + name + " (expected " + CALL_DEPTH + ", "
+                    + LOOP_ITERATIONS + " or " + MACRO_PASSES + ")");
+        }
+    }
+
+    /**
+     * Sets every limit in a map of names to whole numbers, such as the
+     * "limits" object of bundle.json
+     */
+    public void apply(Map<?, ?> settings) {
+        for (Map.Entry<?, ?> setting : settings.entrySet()) {
+            String name = String.valueOf(setting.getKey());
+            Object value = setting.getValue();
+            if (!(value instanceof Number) || ((Number) value).doubleValue() != ((Number) value).intValue()) {
+                throw new IllegalArgumentException(name + " must be a whole number, got " + value);
+            }
+            set(name, ((Number) value).intValue());
+        }
+    }
+
+    /**
+     * The limit a command-line option such as --max-depth sets, or null
+     * when it isn't a limit option
+     */
+    public static String optionName(String option) {
+        switch (option) {
+            case "--max-depth":
+                return CALL_DEPTH;
+            case "--max-iterations":
+                return LOOP_ITERATIONS;
+            case "--max-macro-passes":
+                return MACRO_PASSES;
+            default:
+                return null;
+        }
+    }
+
+    /**
+     * Parses and checks the value given to a limit option
+     */
+    public static int parse(String option, String text) {
+        int value;
+        try {
+            value = Integer.parseInt(text.trim().replace("_", ""));
+        } catch (NumberFormatException e) {
+            throw new IllegalArgumentException(option + " expects a whole number, got: " + text);
+        }
+        return check(optionName(option), value);
+    }
+
+    // Only callDepth can be 0, which turns it off
+    private static int check(String name, int value) {
+        int least = name.equals(CALL_DEPTH) ? 0 : 1;
+        if (value < least) {
+            throw new IllegalArgumentException(name + " must be at least " + least + ", got " + value);
+        }
+        return value;
+    }
+}
@@ -0,0 +1,391 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedReader;
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.InputStreamReader;
+import java.io.PrintStream;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.StandardOpenOption;
+import java.util.ArrayList;
+import java.util.Collection;
+import java.util.List;
+import java.util.TreeSet;
+import java.util.function.Supplier;
+
+/**
+ * A readline-style line editor for the REPL, with history and tab completion.
+ *
+ * On a Unix terminal the editor switches it to raw mode with stty while a
+ * line is being read, so it sees each key: the arrow keys, Home and End
+ * move and recall history, and the usual Emacs keys work (Ctrl-A, Ctrl-E,
+ * Ctrl-K, Ctrl-U, Ctrl-W, Ctrl-L). Tab completes the word before the
+ * cursor. Anywhere else, such as when input is piped, lines are read as
+ * they come and nothing is echoed.
+ *
+ * History is kept in a file, one entry per line, and trimmed to the last
+ * HISTORY_SIZE entries when it is loaded.
+ */
+public class LineEditor {
+    private static final int HISTORY_SIZE = 1000;
+
+    private static final int CTRL_A = 1;
+    private static final int CTRL_B = 2;
+    private static final int CTRL_C = 3;
+    private static final int CTRL_D = 4;
+    private static final int CTRL_E = 5;
+    private static final int CTRL_F = 6;
+    private static final int BACKSPACE = 8;
+    private static final int TAB = 9;
+    private static final int NEWLINE = 10;
+    private static final int CTRL_K = 11;
+    private static final int CTRL_L = 12;
+    private static final int ENTER = 13;
+    private static final int CTRL_N = 14;
+    private static final int CTRL_P = 16;
+    private static final int CTRL_U = 21;
+    private static final int CTRL_W = 23;
+    private static final int ESCAPE = 27;
+    private static final int DELETE = 127;
+
+    /**
+     * Thrown by readLine when Ctrl-C abandons the line being edited
+     */
+    public static class Interrupted extends RuntimeException {
+        Interrupted() {
+            super("Interrupted");
+        }
+    }
+
+    private final InputStream in = System.in;
+    private final PrintStream out = System.out;
+    private final Path historyFile;
+    private final List<String> history = new ArrayList<>();
+    private final Supplier<Collection<String>> completions;
+    private final boolean terminal;
+    private BufferedReader reader;
+
+    // The line being edited and the cursor's position in it
+    private final StringBuilder line = new StringBuilder();
+    private int cursor;
+    private String prompt;
+
+    public LineEditor(Path historyFile, Supplier<Collection<String>> completions) {
+        this.historyFile = historyFile;
+        this.completions = completions;
+        this.terminal = System.console() != null && stty("-g") != null;
+        loadHistory();
+    }
+
+    /**
+     * Whether keys are read one at a time from a terminal, so prompts are shown
+     */
+    public boolean isTerminal() {
+        return terminal;
+    }
+
+    /**
+     * Reads a line, returning null at the end of input (Ctrl-D on an empty line)
+     */
+    public String readLine(String prompt) throws IOException {
+        if (!terminal) {
+            if (reader == null) {
+                reader = new BufferedReader(new InputStreamReader(in, StandardCharsets.UTF_8));
+            }
+            return reader.readLine();
+        }
+        String saved = stty("-g");
+        if (saved == null) {
+            throw new IOException("can't configure the terminal");
+        }
+        stty("-icanon -echo -isig -ixon min 1");
+        try {
+            return edit(prompt);
+        } finally {
+            stty(saved);
+        }
+    }
+
+    /**
+     * Adds an entry to the history and appends it to the history file
+     */
+    public void addHistory(String entry) {
+        if (entry.isBlank() || !history.isEmpty() && history.get(history.size() - 1).equals(entry)) {
+            return;
+        }
+        history.add(entry);
+        if (historyFile == null) {
+            return;
+        }
+        try {
+            Files.writeString(historyFile, entry + System.lineSeparator(), StandardCharsets.UTF_8,
+                StandardOpenOption.CREATE, StandardOpenOption.APPEND);
+        } catch (IOException e) {
+            // History is a convenience; the REPL works without it
+        }
+    }
+
+    private void loadHistory() {
+        if (historyFile == null || !Files.isRegularFile(historyFile)) {
+            return;
+        }
+        try {
+            List<String> lines = Files.readAllLines(historyFile, StandardCharsets.UTF_8);
+            if (lines.size() > HISTORY_SIZE) {
+                lines = new ArrayList<>(lines.subList(lines.size() - HISTORY_SIZE, lines.size()));
+                Files.write(historyFile, lines, StandardCharsets.UTF_8);
+            }
+            history.addAll(lines);
+        } catch (IOException e) {
+            // Start with an empty history
+        }
+    }
+
+    private String edit(String prompt) throws IOException {
+        this.prompt = prompt;
+        line.setLength(0);
+        cursor = 0;
+        // history.size() is the line being typed; lower indexes are recalled entries
+        int recalled = history.size();
+        String typed = "";
+        redraw();
+        while (true) {
+            int key = in.read();
+            switch (key) {
+                case -1:
+                    if (line.length() == 0) {
+                        out.println();
+                        return null;
+                    }
+                    // Fall through and accept what was typed
+                case ENTER:
+                case NEWLINE:
+                    out.print("\r\n");
+                    out.flush();
+                    return line.toString();
+                case CTRL_D:
+                    if (line.length() == 0) {
+                        out.println();
+                        return null;
+                    }
+                    deleteAt(cursor);
+                    break;
+                case CTRL_C:
+                    out.print("^C\r\n");
+                    out.flush();
+                    throw new Interrupted();
+                case CTRL_A:
+                    cursor = 0;
+                    break;
+                case CTRL_E:
+                    cursor = line.length();
+                    break;
+                case CTRL_B:
+                    cursor = Math.max(0, cursor - 1);
+                    break;
+                case CTRL_F:
+                    cursor = Math.min(line.length(), cursor + 1);
+                    break;
+                case BACKSPACE:
+                case DELETE:
+                    if (cursor > 0) {
+                        deleteAt(--cursor);
+                    }
+                    break;
+                case CTRL_K:
+                    line.setLength(cursor);
+                    break;
+                case CTRL_U:
+                    line.delete(0, cursor);
+                    cursor = 0;
+                    break;
+                case CTRL_W: {
+                    int start = cursor;
+                    while (start > 0 && line.charAt(start - 1) == ' ') {
+                        start--;
+                    }
+                    while (start > 0 && line.charAt(start - 1) != ' ') {
+                        start--;
+                    }
+                    line.delete(start, cursor);
+                    cursor = start;
+                    break;
+                }
+                case CTRL_L:
+                    out.print("\u001B[H\u001B[2J");
+                    break;
+                case TAB:
+                    complete();
+                    break;
+                case CTRL_P:
+                case CTRL_N:
+                case ESCAPE: {
+                    int move = key == CTRL_P ? -1 : key == CTRL_N ? 1 : escape();
+                    if (move == 0) {
+                        break;
+                    }
+                    int next = recalled + move;
+                    if (next < 0 || next > history.size()) {
+                        break;
+                    }
+                    if (recalled == history.size()) {
+                        typed = line.toString();
+                    }
+                    recalled = next;
+                    line.setLength(0);
+                    line.append(recalled == history.size() ? typed : history.get(recalled));
+                    cursor = line.length();
+                    break;
+                }
+                default:
+                    if (key >= 32) {
+                        String text = decode(key);
+                        line.insert(cursor, text);
+                        cursor += text.length();
+                    }
+                    break;
+            }
+            redraw();
+        }
+    }
+
+    // Reads the rest of an escape sequence, moving the cursor for the keys that do,
+    // and returns -1 or 1 for the up and down arrows, which move through history
+    private int escape() throws IOException {
+        int kind = in.read();
+        if (kind != '[' && kind != 'O') {
+            return 0;
+        }
+        int key = in.read();
+        if (key >= '0' && key <= '9') {
+            // ESC [ n ~ for Home (1, 7), Delete (3) and End (4, 8)
+            int code = key - '0';
+            while ((key = in.read()) >= '0' && key <= '9') {
+                code = code * 10 + key - '0';
+            }
+            if (key != '~') {
+                return 0;
+            }
+            if (code == 1 || code == 7) {
+                cursor = 0;
+            } else if (code == 4 || code == 8) {
+                cursor = line.length();
+            } else if (code == 3 && cursor < line.length()) {
+                deleteAt(cursor);
+            }
+            return 0;
+        }
+        switch (key) {
+            case 'A':
+                return -1;
+            case 'B':
+                return 1;
+            case 'C':
+                cursor = Math.min(line.length(), cursor + 1);
+                break;
+            case 'D':
+                cursor = Math.max(0, cursor - 1);
+                break;
+            case 'H':
+                cursor = 0;
+                break;
+            case 'F':
+                cursor = line.length();
+                break;
+            default:
+                break;
+        }
+        return 0;
+    }
+
+    // The character a UTF-8 sequence starting with first encodes
+    private String decode(int first) throws IOException {
+        int length = first >= 0xF0 ? 4 : first >= 0xE0 ? 3 : first >= 0xC0 ? 2 : 1;
+        ByteArrayOutputStream bytes = new ByteArrayOutputStream();
+        bytes.write(first);
+        for (int i = 1; i < length; i++) {
+            int next = in.read();
+            if (next == -1) {
+                break;
+            }
+            bytes.write(next);
+        }
+        return bytes.toString(StandardCharsets.UTF_8);
+    }
+
+    private void deleteAt(int position) {
+        if (position < line.length()) {
+            line.deleteCharAt(position);
+        }
+    }
+
+    // Completes the word before the cursor, listing the choices when it can't go further
+    private void complete() {
+        int start = cursor;
+        while (start > 0 && isWordChar(line.charAt(start - 1))) {
+            start--;
+        }
+        String word = line.substring(start, cursor);
+        TreeSet<String> matches = new TreeSet<>();
+        for (String candidate : completions.get()) {
+            if (candidate.startsWith(word) && !candidate.equals(word)) {
+                matches.add(candidate);
+            }
+        }
+        if (matches.isEmpty()) {
+            return;
+        }
+        String common = matches.first();
+        for (String match : matches) {
+            int length = 0;
+            while (length < common.length() && length < match.length() && common.charAt(length) == match.charAt(length)) {
+                length++;
+            }
+            common = common.substring(0, length);
+        }
+        if (common.length() > word.length()) {
+            line.insert(cursor, common.substring(word.length()));
+            cursor += common.length() - word.length();
+            return;
+        }
+        out.print("\r\n" + String.join("  ", matches) + "\r\n");
+    }
+
+    private static boolean isWordChar(char c) {
+        return Character.isLetterOrDigit(c) || c == '_' || c == ':' || c == '.';
+    }
+
+    private void redraw() {
+        out.print("\r" + prompt + line + "\u001B[K");
+        int back = line.codePointCount(cursor, line.length());
+        if (back > 0) {
+            out.print("\u001B[" + back + "D");
+        }
+        out.flush();
+    }
+
+    // Runs stty on the controlling terminal, returning its output or null when it fails
+    private static String stty(String arguments) {
+        try {
+            Process process = new ProcessBuilder("sh", "-c", "stty " + arguments + " < /dev/tty")
+                .redirectErrorStream(true)
+                .start();
+            String output = new String(process.getInputStream().readAllBytes(), StandardCharsets.UTF_8).trim();
+            return process.waitFor() == 0 ? output : null;
+        } catch (IOException e) {
+            return null;
+        } catch (InterruptedException e) {
+            Thread.currentThread().interrupt();
+            return null;
+        }
+    }
+}
@@ -7,8 +7,17 @@
 package com.magayaga.microscript;
 
 import java.util.ArrayList;
+import java.util.Collection;
+import java.util.Comparator;
+import java.util.List;
+import java.util.Objects;
+import java.util.function.Predicate;
+import java.util.function.UnaryOperator;
 
 public class ListVariable extends ArrayList<Object> {
+    // Set by freeze(list); every change after that fails
+    private boolean frozen;
+
     public ListVariable() {
         super();
     }
@@ -28,4 +37,254 @@ public class ListVariable extends ArrayList<Object> {
     public Object get(int index) {
         return super.get(index);
     }
+
+    public boolean isFrozen() {
+        return frozen;
+    }
+
+    public void freeze() {
+        frozen = true;
+    }
+
+    @Override
+    public boolean add(Object element) {
+        checkNotFrozen();
+        return super.add(element);
+    }
+
+    @Override
+    public void add(int index, Object element) {
+        checkNotFrozen();
+        super.add(index, element);
+    }
+
+    @Override
+    public Object set(int index, Object element) {
+        checkNotFrozen();
+        return super.set(index, element);
+    }
+
+    @Override
+    public Object remove(int index) {
+        checkNotFrozen();
+        return super.remove(index);
+    }
+
+    @Override
+    public boolean remove(Object element) {
+        checkNotFrozen();
+        return super.remove(element);
+    }
+
+    @Override
+    public boolean addAll(Collection<?> elements) {
+        checkNotFrozen();
+        return super.addAll(elements);
+    }
+
+    @Override
+    public boolean addAll(int index, Collection<?> elements) {
+        checkNotFrozen();
+        return super.addAll(index, elements);
+    }
+
+    @Override
+    public boolean removeAll(Collection<?> elements) {
+        checkNotFrozen();
+        return super.removeAll(elements);
+    }
+
+    @Override
+    public boolean retainAll(Collection<?> elements) {
+        checkNotFrozen();
+        return super.retainAll(elements);
+    }
+
+    @Override
+    public boolean removeIf(Predicate<? super Object> filter) {
+        checkNotFrozen();
+        return super.removeIf(filter);
+    }
+
+    @Override
+    public void replaceAll(UnaryOperator<Object> operator) {
+        checkNotFrozen();
+        super.replaceAll(operator);
+    }
+
+    @Override
+    public void sort(Comparator<? super Object> comparator) {
+        checkNotFrozen();
+        super.sort(comparator);
+    }
+
+    @Override
+    public void clear() {
+        checkNotFrozen();
+        super.clear();
+    }
+
+    private void checkNotFrozen() {
+        if (frozen) {
+            throw new RuntimeException("Cannot modify a frozen list");
+        }
+    }
+
+    /**
+     * Calls a method by name, as written in a script: xs.join(", "),
+     * xs.contains(v), xs.indexOf(v), xs.reverse() and xs.unique(). reverse
+     * and unique return a new list and leave this one unchanged.
+     */
+    public Object call(String method, List<Object> args) {
+        switch (method) {
+            case "join": {
+                expectArguments(method, args, 1);
+                StringBuilder text = new StringBuilder();
+                for (int i = 0; i < size(); i++) {
+                    if (i > 0) {
+                        text.append(args.get(0));
+                    }
+                    text.append(format(get(i)));
+                }
+                return text.toString();
+            }
+            case "contains":
+                expectArguments(method, args, 1);
+                return indexOf(args.get(0)) >= 0;
+            case "indexOf":
+                expectArguments(method, args, 1);
+                return (double) indexOf(args.get(0));
+            case "reverse": {
+                expectArguments(method, args, 0);
+                ListVariable reversed = new ListVariable();
+                for (int i = size() - 1; i >= 0; i--) {
+                    reversed.add(get(i));
+                }
+                return reversed;
+            }
+            case "unique": {
+                expectArguments(method, args, 0);
+                ListVariable unique = new ListVariable();
+                for (Object element : this) {
+                    if (unique.indexOf(element) < 0) {
+                        unique.add(element);
+                    }
+                }
+                return unique;
+            }
+            default:
+                throw new RuntimeException("Unknown list method: " + method);
+        }
+    }
+
+    // Numbers compare by value, so 2 finds 2.0
+    @Override
+    public int indexOf(Object value) {
+        for (int i = 0; i < size(); i++) {
+            Object element = get(i);
+            if (element instanceof Number && value instanceof Number
+                    ? ((Number) element).doubleValue() == ((Number) value).doubleValue()
+                    : Objects.equals(element, value)) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    private static String format(Object value) {
+        if (value instanceof Double && (Double) value == Math.floor((Double) value) && !Double.isInfinite((Double) value)) {
+            return String.format("%.0f", (Double) value);
+        }
+        return String.valueOf(value);
+    }
+
+    private static void expectArguments(String method, List<Object> args, int expected) {
+        if (args.size() != expected) {
+            throw new RuntimeException("list." + method + " expects " + expected + " argument"
+                + (expected == 1 ? "" : "s") + ", got " + args.size());
+        }
+    }
+
+    /**
+     * Resolves a script index to a position in the list. Negative indices
+     * count from the end, so -1 is the last element.
+     */
+    public static int position(List<?> list, Object index) {
+        if (!(index instanceof Number)) {
+            throw new RuntimeException("Array index must be a number, got: " + index);
+        }
+        int requested = ((Number) index).intValue();
+        int resolved = requested < 0 ? requested + list.size() : requested;
+        if (resolved < 0 || resolved >= list.size()) {
+            throw new RuntimeException("Array index " + requested + " out of range for length " + list.size());
+        }
+        return resolved;
+    }
+
+    /**
+     * The elements from start up to but not including end, as in xs[1:3].
+     * Either bound may be null for the start or end of the list, may be
+     * negative to count from the end, and is clamped to the list's length.
+     * Slicing a tuple gives a tuple.
+     */
+    public static List<Object> slice(List<?> list, Object start, Object end) {
+        int from = bound(list, start, 0);
+        int to = Math.max(from, bound(list, end, list.size()));
+        List<Object> items = new ArrayList<>(list.subList(from, to));
+        return list instanceof Tuple ? new Tuple(items) : new ListVariable(items.toArray());
+    }
+
+    /**
+     * The elements a ...value spread expands to; source is the spread as
+     * written, for the error message
+     */
+    public static List<?> spread(Object value, String source) {
+        if (!(value instanceof List)) {
+            throw new RuntimeException("Type error: cannot spread " + source + "; " + value + " is not a list");
+        }
+        return (List<?>) value;
+    }
+
+    /**
+     * a + b for lists: a new list with b's elements after a's. Two tuples
+     * give a tuple.
+     */
+    public static List<Object> concat(Object left, Object right) {
+        if (!(left instanceof List) || !(right instanceof List)) {
+            throw new RuntimeException("Type error: cannot add " + left + " and " + right + "; both sides of + must be lists");
+        }
+        List<Object> items = new ArrayList<>((List<?>) left);
+        items.addAll((List<?>) right);
+        return left instanceof Tuple && right instanceof Tuple ? new Tuple(items) : new ListVariable(items.toArray());
+    }
+
+    /**
+     * xs * n or n * xs: a new list holding xs's elements n times over
+     */
+    public static List<Object> repeat(Object left, Object right) {
+        List<?> list = (List<?>) (left instanceof List ? left : right);
+        Object count = left instanceof List ? right : left;
+        if (!(count instanceof Number) || ((Number) count).doubleValue() != Math.floor(((Number) count).doubleValue())) {
+            throw new RuntimeException("Type error: a list can only be repeated a whole number of times, got " + count);
+        }
+        List<Object> items = new ArrayList<>();
+        for (int i = 0; i < ((Number) count).intValue(); i++) {
+            items.addAll(list);
+        }
+        return list instanceof Tuple ? new Tuple(items) : new ListVariable(items.toArray());
+    }
+
+    private static int bound(List<?> list, Object value, int fallback) {
+        if (value == null) {
+            return fallback;
+        }
+        if (!(value instanceof Number)) {
+            throw new RuntimeException("Slice bound must be a number, got: " + value);
+        }
+        int bound = ((Number) value).intValue();
+        if (bound < 0) {
+            bound += list.size();
+        }
+        return Math.max(0, Math.min(bound, list.size()));
+    }
 }
\ No newline at end of file
@@ -45,8 +45,9 @@ public class Loop {
      */
     public static boolean executeLoopBlock(List<String> lines, int startIndex, int endIndex, 
                                         Executor executor) {
+        List<Statement> statements = Statement.of(lines);
         for (int i = startIndex; i < endIndex; i++) {
-            String line = lines.get(i).trim();
+            String line = statements.get(i).getText();
             
             // Skip empty lines and closing brace
             if (line.isEmpty() || line.equals("}")) {
@@ -106,68 +107,6 @@ public class Loop {
      * @return The index of the line with the matching closing brace
      */
     public static int findMatchingClosingBrace(List<String> lines, int openingBraceLineIndex) {
-        int braceCount = 0;
-        boolean openingBraceFound = false;
-        
-        // Count opening brace on the first line
-        String firstLine = lines.get(openingBraceLineIndex).trim();
-        
-        // Find the position of the opening brace
-        int openingBracePos = firstLine.indexOf('{');
-        if (openingBracePos != -1) {
-            braceCount = 1;
-            openingBraceFound = true;
-            
-            // Check if there are any closing braces on the same line after the opening brace
-            for (int i = openingBracePos + 1; i < firstLine.length(); i++) {
-                if (firstLine.charAt(i) == '{') {
-                    braceCount++;
-                } else if (firstLine.charAt(i) == '}') {
-                    braceCount--;
-                    if (braceCount == 0) {
-                        return openingBraceLineIndex; // The block starts and ends on the same line
-                    }
-                }
-            }
-        }
-        
-        // If opening brace wasn't found on the first line, return error
-        if (!openingBraceFound) {
-            throw new RuntimeException("No opening brace found at line index: " + openingBraceLineIndex);
-        }
-        
-        // Process subsequent lines
-        for (int i = openingBraceLineIndex + 1; i < lines.size(); i++) {
-            String line = lines.get(i);
-            
-            // Skip comments when counting braces
-            if (line.trim().startsWith("//")) {
-                continue;
-            }
-            
-            // Handle multi-line comments
-            if (line.trim().startsWith("/*")) {
-                while (i < lines.size() && !lines.get(i).contains("*/")) {
-                    i++;
-                }
-                continue;
-            }
-            
-            // Count braces
-            for (int j = 0; j < line.length(); j++) {
-                char c = line.charAt(j);
-                if (c == '{') {
-                    braceCount++;
-                } else if (c == '}') {
-                    braceCount--;
-                    if (braceCount == 0) {
-                        return i;
-                    }
-                }
-            }
-        }
-        
-        // No matching closing brace found
-        return -1;
+        return Statement.of(lines).get(openingBraceLineIndex).closingBrace(lines, openingBraceLineIndex);
     }
 }
\ No newline at end of file
@@ -0,0 +1,62 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.LinkedHashMap;
+import java.util.Map;
+
+/**
+ * A map from string keys to values, such as a parsed JSON object. Keys keep
+ * their insertion order. After freeze(map) every change fails.
+ */
+public class MapVariable extends LinkedHashMap<String, Object> {
+    private boolean frozen;
+
+    public boolean isFrozen() {
+        return frozen;
+    }
+
+    public void freeze() {
+        frozen = true;
+    }
+
+    @Override
+    public Object put(String key, Object value) {
+        checkNotFrozen();
+        return super.put(key, value);
+    }
+
+    @Override
+    public void putAll(Map<? extends String, ?> entries) {
+        checkNotFrozen();
+        super.putAll(entries);
+    }
+
+    @Override
+    public Object putIfAbsent(String key, Object value) {
+        checkNotFrozen();
+        return super.putIfAbsent(key, value);
+    }
+
+    @Override
+    public Object remove(Object key) {
+        checkNotFrozen();
+        return super.remove(key);
+    }
+
+    @Override
+    public void clear() {
+        checkNotFrozen();
+        super.clear();
+    }
+
+    private void checkNotFrozen() {
+        if (frozen) {
+            throw new RuntimeException("Cannot modify a frozen map");
+        }
+    }
+}
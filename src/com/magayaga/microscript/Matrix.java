@@ -0,0 +1,139 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.List;
+
+/**
+ * Matrix helpers for lists of lists of numbers, where each inner list is a
+ * row:
+ *
+ *   matrix.transpose(m)    rows become columns
+ *   matrix.multiply(a, b)  the matrix product of a and b
+ *   matrix.identity(n)     the n x n identity matrix
+ *
+ * Every helper returns a new matrix and leaves its arguments unchanged.
+ */
+public class Matrix {
+    private Matrix() {
+    }
+
+    /**
+     * Calls a helper by name with already evaluated arguments
+     */
+    public static ListVariable call(String operation, List<Object> args) {
+        switch (operation) {
+            case "transpose":
+                expectArguments(operation, args, 1);
+                return transpose(rows(operation, args.get(0)));
+            case "multiply":
+                expectArguments(operation, args, 2);
+                return multiply(rows(operation, args.get(0)), rows(operation, args.get(1)));
+            case "identity": {
+                expectArguments(operation, args, 1);
+                Object size = args.get(0);
+                if (!(size instanceof Number) || ((Number) size).doubleValue() < 0
+                        || ((Number) size).doubleValue() != Math.floor(((Number) size).doubleValue())) {
+                    throw new RuntimeException("matrix.identity expects a whole number size, got " + size);
+                }
+                return identity(((Number) size).intValue());
+            }
+            default:
+                throw new RuntimeException("Unknown matrix operation: " + operation);
+        }
+    }
+
+    public static ListVariable transpose(double[][] m) {
+        int columns = m.length == 0 ? 0 : m[0].length;
+        double[][] result = new double[columns][m.length];
+        for (int i = 0; i < m.length; i++) {
+            for (int j = 0; j < columns; j++) {
+                result[j][i] = m[i][j];
+            }
+        }
+        return toList(result);
+    }
+
+    public static ListVariable multiply(double[][] a, double[][] b) {
+        int shared = a.length == 0 ? 0 : a[0].length;
+        if (shared != b.length) {
+            throw new RuntimeException("matrix.multiply: cannot multiply a " + shape(a) + " matrix by a " + shape(b)
+                + " matrix; the first needs as many columns as the second has rows");
+        }
+        int columns = b.length == 0 ? 0 : b[0].length;
+        double[][] result = new double[a.length][columns];
+        for (int i = 0; i < a.length; i++) {
+            for (int j = 0; j < columns; j++) {
+                double sum = 0;
+                for (int k = 0; k < shared; k++) {
+                    sum += a[i][k] * b[k][j];
+                }
+                result[i][j] = sum;
+            }
+        }
+        return toList(result);
+    }
+
+    public static ListVariable identity(int size) {
+        double[][] result = new double[size][size];
+        for (int i = 0; i < size; i++) {
+            result[i][i] = 1.0;
+        }
+        return toList(result);
+    }
+
+    // Reads a script value as a rectangular grid of numbers
+    private static double[][] rows(String operation, Object value) {
+        if (!(value instanceof List)) {
+            throw new RuntimeException("matrix." + operation + " expects a list of rows, got " + value);
+        }
+        List<?> rows = (List<?>) value;
+        double[][] result = new double[rows.size()][];
+        for (int i = 0; i < rows.size(); i++) {
+            if (!(rows.get(i) instanceof List)) {
+                throw new RuntimeException("matrix." + operation + ": row " + i + " is not a list: " + rows.get(i));
+            }
+            List<?> row = (List<?>) rows.get(i);
+            if (i > 0 && row.size() != result[0].length) {
+                throw new RuntimeException("matrix." + operation + ": row " + i + " has " + row.size()
+                    + " elements but row 0 has " + result[0].length);
+            }
+            result[i] = new double[row.size()];
+            for (int j = 0; j < row.size(); j++) {
+                if (!(row.get(j) instanceof Number)) {
+                    throw new RuntimeException("matrix." + operation + ": element [" + i + "][" + j
+                        + "] is not a number: " + row.get(j));
+                }
+                result[i][j] = ((Number) row.get(j)).doubleValue();
+            }
+        }
+        return result;
+    }
+
+    private static ListVariable toList(double[][] m) {
+        ListVariable rows = new ListVariable();
+        for (double[] row : m) {
+            ListVariable values = new ListVariable();
+            for (double value : row) {
+                values.add(value);
+            }
+            rows.add(values);
+        }
+        return rows;
+    }
+
+    private static String shape(double[][] m) {
+        return m.length + "x" + (m.length == 0 ? 0 : m[0].length);
+    }
+
+    private static void expectArguments(String operation, List<Object> args, int expected) {
+        if (args.size() != expected) {
+            throw new RuntimeException("matrix." + operation + " expects " + expected + " argument"
+                + (expected == 1 ? "" : "s") + ", got " + args.size());
+        }
+    }
+}
@@ -0,0 +1,74 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.LinkedHashMap;
+import java.util.Map;
+import java.util.concurrent.atomic.AtomicLong;
+
+/**
+ * Memory accounting for one interpreter. Every environment created under the
+ * same root shares an instance, so a script can see how many scopes it keeps
+ * alive through runtime.memory().
+ */
+public class Memory {
+    private final AtomicLong environmentsCreated = new AtomicLong();
+    private final AtomicLong environmentsReleased = new AtomicLong();
+    private final AtomicLong peakEnvironments = new AtomicLong();
+    // Plain counters, since interning runs on every assignment; with tasks
+    // running at once they can miss an update, which is fine for statistics
+    private long internHits;
+    private long internedStrings;
+
+    void environmentCreated() {
+        long live = environmentsCreated.incrementAndGet() - environmentsReleased.get();
+        peakEnvironments.accumulateAndGet(live, Math::max);
+    }
+
+    void environmentReleased() {
+        environmentsReleased.incrementAndGet();
+    }
+
+    void internHit() {
+        internHits++;
+    }
+
+    void stringInterned() {
+        internedStrings++;
+    }
+
+    public long liveEnvironments() {
+        return environmentsCreated.get() - environmentsReleased.get();
+    }
+
+    /**
+     * Current usage as nested maps, the value returned by runtime.memory()
+     */
+    public Map<String, Object> snapshot() {
+        Map<String, Object> environments = new LinkedHashMap<>();
+        environments.put("live", liveEnvironments());
+        environments.put("peak", peakEnvironments.get());
+        environments.put("created", environmentsCreated.get());
+        environments.put("released", environmentsReleased.get());
+
+        Map<String, Object> interned = new LinkedHashMap<>();
+        interned.put("strings", internedStrings);
+        interned.put("hits", internHits);
+
+        Runtime runtime = Runtime.getRuntime();
+        Map<String, Object> heap = new LinkedHashMap<>();
+        heap.put("used", runtime.totalMemory() - runtime.freeMemory());
+        heap.put("total", runtime.totalMemory());
+        heap.put("max", runtime.maxMemory());
+
+        Map<String, Object> snapshot = new LinkedHashMap<>();
+        snapshot.put("environments", environments);
+        snapshot.put("interned", interned);
+        snapshot.put("heap", heap);
+        return snapshot;
+    }
+}
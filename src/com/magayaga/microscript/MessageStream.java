@@ -0,0 +1,101 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedInputStream;
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.nio.charset.StandardCharsets;
+import java.util.Map;
+
+/**
+ * JSON messages framed with a Content-Length header, as spoken by the
+ * language server and debug adapter protocols
+ */
+public class MessageStream {
+    private final InputStream in;
+    private final OutputStream out;
+
+    public MessageStream(InputStream in, OutputStream out) {
+        this.in = new BufferedInputStream(in);
+        this.out = out;
+    }
+
+    /**
+     * Reads the next message, or returns null at end of input
+     */
+    @SuppressWarnings("unchecked")
+    public Map<String, Object> read() throws IOException {
+        while (true) {
+            String body = readBody();
+            if (body == null) {
+                return null;
+            }
+            try {
+                Object message = Json.parse(body);
+                if (message instanceof Map) {
+                    return (Map<String, Object>) message;
+                }
+                System.err.println("Ignoring message that isn't an object: " + body);
+            } catch (RuntimeException e) {
+                System.err.println("Ignoring malformed message: " + e.getMessage());
+            }
+        }
+    }
+
+    public synchronized void write(Object message) throws IOException {
+        byte[] body = Json.stringify(message).getBytes(StandardCharsets.UTF_8);
+        out.write(("Content-Length: " + body.length + "\r\n\r\n").getBytes(StandardCharsets.US_ASCII));
+        out.write(body);
+        out.flush();
+    }
+
+    private String readBody() throws IOException {
+        int contentLength = -1;
+        while (true) {
+            String header = readHeaderLine();
+            if (header == null) {
+                return null;
+            }
+            if (header.isEmpty()) {
+                break;
+            }
+            int colon = header.indexOf(':');
+            if (colon > 0 && header.substring(0, colon).trim().equalsIgnoreCase("Content-Length")) {
+                contentLength = Integer.parseInt(header.substring(colon + 1).trim());
+            }
+        }
+        if (contentLength < 0) {
+            throw new IOException("Message without Content-Length header");
+        }
+        byte[] body = new byte[contentLength];
+        int read = 0;
+        while (read < contentLength) {
+            int count = in.read(body, read, contentLength - read);
+            if (count < 0) {
+                return null;
+            }
+            read += count;
+        }
+        return new String(body, StandardCharsets.UTF_8);
+    }
+
+    private String readHeaderLine() throws IOException {
+        ByteArrayOutputStream line = new ByteArrayOutputStream();
+        int c;
+        while ((c = in.read()) != -1) {
+            if (c == '\n') {
+                String text = new String(line.toByteArray(), StandardCharsets.US_ASCII);
+                return text.endsWith("\r") ? text.substring(0, text.length() - 1) : text;
+            }
+            line.write(c);
+        }
+        return null;
+    }
+}
@@ -7,17 +7,50 @@
 package com.magayaga.microscript;
 
 import java.io.IOException;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.LinkedHashMap;
 import java.util.List;
+import java.util.Map;
 import java.util.Set;
 
 public class MicroScript {
     // Use Set for O(1) lookup instead of List with O(n) iteration
     private static final Set<String> VALID_EXTENSIONS = Set.of(
-        ".microscript", ".mus", ".micros"
+        ".microscript", ".mus", ".micros", Bundle.EXTENSION, Compile.EXTENSION
     );
     
     // Constants for better maintainability
     private static final String RUN_COMMAND = "run";
+    private static final String TRACE_JSON_OPTION = "--trace-json";
+    private static final String ERROR_FORMAT_OPTION = "--error-format";
+    
+    private static final String WERROR_OPTION = "--werror";
+    private static final String LENIENT_OPTION = "--lenient";
+    private static final String MAX_TIME_OPTION = "--max-time";
+    private static final String DRY_RUN_OPTION = "--dry-run";
+    private static final String INCLUDE_OPTION = "-I";
+    
+    // ANSI colors for diagnostics
+    private static final String RESET = "\u001B[0m";
+    private static final String RED = "\u001B[31;1m";
+    private static final String YELLOW = "\u001B[33;1m";
+    
+    // Set by --error-format json: errors are printed as one JSON object per line
+    private static boolean jsonErrors = false;
+    // Set by --werror: warnings stop the script from running
+    private static boolean warningsAsErrors = false;
+    // Set by --lenient: failing statements are reported and skipped instead of stopping the script
+    private static boolean lenient = false;
+    // Set by --max-time: the script is cancelled after this many milliseconds
+    private static long maxTimeMillis = 0;
+    // Set by --dry-run: the whole file is checked for syntax errors but nothing runs
+    private static boolean dryRun = false;
+    // Set by -I: directories searched by import after the script's own
+    private static final List<String> includePaths = new ArrayList<>();
+    // Set by --max-depth, --max-iterations and --max-macro-passes; these override a bundle's limits
+    private static final Map<String, Object> limitOptions = new LinkedHashMap<>();
     
     public static void main(String[] args) {
         // Handle CLI commands early return pattern
@@ -39,8 +72,49 @@ public class MicroScript {
             return;
         }
         
+        String tracePath = null;
+        for (int i = 2; i < args.length; i++) {
+            if (TRACE_JSON_OPTION.equals(args[i]) && i + 1 < args.length) {
+                tracePath = args[++i];
+            } else if (ERROR_FORMAT_OPTION.equals(args[i]) && i + 1 < args.length) {
+                String format = args[++i];
+                if (!format.equals("text") && !format.equals("json")) {
+                    System.err.println("Unknown error format: " + format + " (expected text or json)");
+                    return;
+                }
+                jsonErrors = format.equals("json");
+            } else if (WERROR_OPTION.equals(args[i])) {
+                warningsAsErrors = true;
+            } else if (LENIENT_OPTION.equals(args[i])) {
+                lenient = true;
+            } else if (DRY_RUN_OPTION.equals(args[i])) {
+                dryRun = true;
+            } else if (INCLUDE_OPTION.equals(args[i]) && i + 1 < args.length) {
+                includePaths.add(args[++i]);
+            } else if (Limits.optionName(args[i]) != null && i + 1 < args.length) {
+                String option = args[i];
+                try {
+                    limitOptions.put(Limits.optionName(option), Limits.parse(option, args[++i]));
+                } catch (IllegalArgumentException e) {
+                    System.err.println(e.getMessage());
+                    return;
+                }
+            } else if (MAX_TIME_OPTION.equals(args[i]) && i + 1 < args.length) {
+                try {
+                    maxTimeMillis = Math.max(1, Benchmark.parseDuration(args[++i]) / 1_000_000L);
+                } catch (IllegalArgumentException e) {
+                    System.err.println(e.getMessage());
+                    return;
+                }
+            } else {
+                System.err.println("Unknown option: " + args[i]);
+                Cli.printUsage();
+                return;
+            }
+        }
+        
         // Execute MicroScript file
-        executeScript(filePath);
+        executeScript(filePath, tracePath);
     }
     
     /**
@@ -54,7 +128,18 @@ public class MicroScript {
         String firstArg = args[0];
         return "--help".equals(firstArg) || 
                "--version".equals(firstArg) || 
-               "about".equals(firstArg);
+               "about".equals(firstArg) ||
+               "bench".equals(firstArg) ||
+               "check".equals(firstArg) ||
+               "lsp".equals(firstArg) ||
+               "debug".equals(firstArg) ||
+               "fuzz".equals(firstArg) ||
+               "transpile".equals(firstArg) ||
+               "compile".equals(firstArg) ||
+               "repl".equals(firstArg) ||
+               "kernel".equals(firstArg) ||
+               "fmt".equals(firstArg) ||
+               "bundle".equals(firstArg);
     }
     
     /**
@@ -83,33 +168,166 @@ public class MicroScript {
      * Prints formatted error message for invalid file extensions
      */
     private static void printExtensionError(String filePath) {
-        System.err.println("Error: File must have a valid MicroScript extension (.microscript, .mus, .micros)");
+        System.err.println("Error: File must have a valid MicroScript extension (.microscript, .mus, .micros), be a .musx bundle or a compiled .musc file");
         System.err.println("The file '" + filePath + "' does not have a recognized MicroScript extension.");
     }
     
     /**
-     * Executes the MicroScript file with proper error handling
+     * Executes the MicroScript file with proper error handling, writing an
+     * execution trace when tracePath is given
      */
-    private static void executeScript(String filePath) {
+    private static void executeScript(String filePath, String tracePath) {
+        Tracer tracer = null;
+        Interpreter interpreter = new Interpreter();
+        // The lines errors are reported against, once they have been read
+        List<String> source = null;
         try {
-            // Create Scanner object
-            Scanner scanner = new Scanner(filePath);
-            
-            // Read and preprocess lines
-            List<String> lines = scanner.readLines();
-            
-            // Preprocess macros
-            Define define = new Define();
-            List<String> preprocessedLines = define.preprocess(lines);
-            
-            // Parse and execute
-            Parser parser = new Parser(preprocessedLines);
-            parser.parse();
+            interpreter.getEnvironment().setStrict(!lenient);
+            if (maxTimeMillis > 0) {
+                interpreter.setTimeout(maxTimeMillis);
+            }
+            if (tracePath != null) {
+                tracer = new Tracer(tracePath);
+                interpreter.getEnvironment().setTracer(tracer);
+            }
+            String scriptPath = filePath;
+            Limits limits = interpreter.getEnvironment().getLimits();
+            if (filePath.endsWith(Bundle.EXTENSION)) {
+                Bundle bundle = Bundle.open(filePath);
+                bundle.register(interpreter.getEnvironment());
+                limits.apply(bundle.getLimits());
+                scriptPath = bundle.getEntry().toString();
+            }
+            limits.apply(limitOptions);
+            // Imports resolve next to the script first, then in -I directories and MICROSCRIPT_PATH
+            Path scriptDirectory = Paths.get(scriptPath).toAbsolutePath().getParent();
+            Import.addSearchPath(scriptDirectory.toString());
+            for (String includePath : includePaths) {
+                Import.addSearchPath(includePath);
+            }
+            if (scriptPath.endsWith(Compile.EXTENSION)) {
+                // Checked and preprocessed by microscript compile
+                source = Compile.read(Paths.get(scriptPath));
+                interpreter.runCompiled(source);
+                interpreter.runEventLoop();
+                return;
+            }
+            List<String> lines = new Scanner(scriptPath).readLines();
+            source = lines;
+            boolean clean = reportDiagnostics(filePath, lines);
+            if (dryRun && !clean) {
+                System.exit(1);
+            }
+            if (!clean || dryRun) {
+                return;
+            }
+            // Preprocess, optimize, parse and execute
+            interpreter.run(lines);
+            interpreter.runEventLoop();
             
         } catch (IOException e) {
-            System.err.println("Error reading file '" + filePath + "': " + e.getMessage());
+            if (jsonErrors) {
+                printJsonError(filePath, e, "io");
+            } else {
+                System.err.println("Error reading file '" + filePath + "': " + e.getMessage());
+            }
         } catch (Exception e) {
-            System.err.println("Error executing script '" + filePath + "': " + e.getMessage());
+            if (jsonErrors) {
+                printJsonError(filePath, e, null);
+            } else {
+                String where = e instanceof ScriptException && ((ScriptException) e).getLine() >= 0
+                    ? " at line " + (((ScriptException) e).getLine() + 1) : "";
+                if (!where.isEmpty() && ((ScriptException) e).getColumn() >= 0) {
+                    where += ", column " + (((ScriptException) e).getColumn() + 1);
+                }
+                System.err.println("Error executing script '" + filePath + "'" + where + ": " + e.getMessage());
+                if (e instanceof ScriptException && ((ScriptException) e).getExpansion() != null) {
+                    printExpansion(((ScriptException) e).getExpansion());
+                } else if (e instanceof ScriptException && System.console() != null) {
+                    System.err.print(ErrorReport.render(source, (ScriptException) e,
+                        Repl.completions(interpreter.getEnvironment()), true));
+                }
+            }
+        } finally {
+            closeTracer(tracer, tracePath);
+        }
+    }
+    
+    /**
+     * Prints the checker's warnings, as errors under --werror, and every type
+     * error. Under --dry-run the checker's errors and every syntax error are
+     * printed as well. Returns false when the script shouldn't run.
+     */
+    private static boolean reportDiagnostics(String filePath, List<String> lines) {
+        boolean failed = false;
+        for (Checker.Diagnostic diagnostic : Checker.check(lines)) {
+            if (diagnostic.getSeverity() != Checker.Severity.WARNING) {
+                if (dryRun) {
+                    printDiagnostic(filePath, diagnostic);
+                    failed = true;
+                }
+                continue; // Otherwise errors are reported when the script runs
+            }
+            if (warningsAsErrors) {
+                diagnostic = diagnostic.withSeverity(Checker.Severity.ERROR);
+                failed = true;
+            }
+            printDiagnostic(filePath, diagnostic);
+        }
+        for (Checker.Diagnostic diagnostic : TypeChecker.check(lines)) {
+            printDiagnostic(filePath, diagnostic);
+            failed = true;
+        }
+        if (dryRun) {
+            for (Checker.Diagnostic diagnostic : SyntaxChecker.check(lines)) {
+                printDiagnostic(filePath, diagnostic);
+                failed = true;
+            }
+        }
+        return !failed;
+    }
+    
+    private static void printDiagnostic(String filePath, Checker.Diagnostic diagnostic) {
+        if (jsonErrors) {
+            System.err.println(Json.stringify(diagnostic.toMap(filePath)));
+            return;
+        }
+        String severity = diagnostic.getSeverity() == Checker.Severity.ERROR ? RED + "error" : YELLOW + "warning";
+        System.err.println(filePath + ":" + (diagnostic.getLine() + 1) + ":" + (diagnostic.getColumn() + 1)
+            + ": " + severity + RESET + ": " + diagnostic.getMessage() + " [" + diagnostic.getCode() + "]");
+    }
+    
+    /**
+     * Shows the macro invocation an error came from, with what it expanded to
+     */
+    private static void printExpansion(Define.Expansion expansion) {
+        String source = expansion.getSource();
+        String trimmed = source.trim();
+        int indent = source.indexOf(trimmed);
+        StringBuilder marker = new StringBuilder("    ");
+        for (int i = indent; i < expansion.getColumn(); i++) {
+            marker.append(' ');
+        }
+        for (int i = 0; i < expansion.getInvocation().length(); i++) {
+            marker.append(i == 0 ? '^' : '~');
+        }
+        System.err.println("    " + trimmed);
+        System.err.println(marker);
+        System.err.println("note: " + expansion.getNote());
+    }
+    
+    private static void printJsonError(String filePath, Throwable error, String code) {
+        System.err.println(Json.stringify(ScriptException.toDiagnostic(filePath, error, code)));
+    }
+    
+    private static void closeTracer(Tracer tracer, String tracePath) {
+        if (tracer == null) {
+            return;
+        }
+        try {
+            tracer.close();
+        } catch (IOException e) {
+            System.err.println("Error writing trace '" + tracePath + "': " + e.getMessage());
         }
     }
 }
@@ -0,0 +1,83 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.List;
+import java.util.Set;
+
+/**
+ * A module imported under an alias:
+ *
+ *   import math_utils as mu;
+ *   import math as m;
+ *   console.write(mu.clamp(x, 0, 1) + m.sqrt(2));
+ *
+ * The module's names live in its own environment instead of the importing
+ * script's, so two modules can define the same function without one silently
+ * replacing the other. A script module that uses export shows only what it
+ * exports. Native modules name their functions math::sqrt, which the alias
+ * reaches as m.sqrt.
+ */
+public class ModuleNamespace {
+    private final String name;
+    private final Environment environment;
+    private final Set<String> exports;
+
+    public ModuleNamespace(String name, Environment environment, Set<String> exports) {
+        this.name = name;
+        this.environment = environment;
+        this.exports = exports;
+    }
+
+    /**
+     * The value of a global the module defines
+     */
+    public Object get(String member) {
+        String qualified = resolve(member);
+        if (qualified == null || !environment.hasLocalVariable(qualified)) {
+            throw new RuntimeException("Module " + name + " has no variable named " + member);
+        }
+        return environment.getVariable(qualified);
+    }
+
+    /**
+     * Calls a function the module defines, which runs in the module's environment
+     */
+    public Object call(String member, List<Object> args) {
+        String qualified = resolve(member);
+        Object callee = null;
+        if (qualified != null && environment.getFunctionNames().contains(qualified)) {
+            callee = environment.getFunction(qualified);
+        } else if (qualified != null && environment.hasLocalVariable(qualified)) {
+            callee = environment.getVariable(qualified);
+        }
+        if (callee instanceof Function) {
+            return new Executor(environment).call((Function) callee, args.toArray());
+        }
+        if (callee instanceof Import.FunctionInterface) {
+            return ((Import.FunctionInterface) callee).call(args.toArray());
+        }
+        throw new RuntimeException("Module " + name + " has no function named " + member);
+    }
+
+    // The name the member is defined under, or null when the module keeps it private
+    private String resolve(String member) {
+        if (!exports.isEmpty() && !exports.contains(member)) {
+            return null;
+        }
+        String qualified = name + "::" + member;
+        if (environment.getFunctionNames().contains(qualified) || environment.hasLocalVariable(qualified)) {
+            return qualified;
+        }
+        return member;
+    }
+
+    @Override
+    public String toString() {
+        return "module " + name;
+    }
+}
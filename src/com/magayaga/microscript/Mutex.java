@@ -0,0 +1,46 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.concurrent.locks.ReentrantLock;
+
+/**
+ * A lock created with mutex(), used by tasks started with spawn to protect
+ * shared state: m.lock(); ... m.unlock();
+ * The lock is reentrant and must be unlocked by the task that locked it.
+ */
+public class Mutex {
+    private final ReentrantLock lock = new ReentrantLock();
+
+    /**
+     * Calls a method by name, as written in a script
+     */
+    public Object call(String method) {
+        switch (method) {
+            case "lock":
+                lock.lock();
+                return null;
+            case "unlock":
+                if (!lock.isHeldByCurrentThread()) {
+                    throw new RuntimeException("unlock of a mutex not locked by this task");
+                }
+                lock.unlock();
+                return null;
+            case "tryLock":
+                return lock.tryLock();
+            case "isLocked":
+                return lock.isLocked();
+            default:
+                throw new RuntimeException("Unknown mutex method: " + method);
+        }
+    }
+
+    @Override
+    public String toString() {
+        return lock.isLocked() ? "Mutex(locked)" : "Mutex(unlocked)";
+    }
+}
@@ -0,0 +1,167 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.List;
+
+/**
+ * Calls into C libraries for the ffi module. Supported signatures take only
+ * floating-point arguments and return a double, or take only integer, pointer
+ * and string arguments (up to six either way).
+ */
+public class NativeFfi {
+    static {
+        System.loadLibrary("ffibridge"); // Loads ffibridge.dll or libffibridge.so
+    }
+
+    // Return kinds understood by callLongs, mirrored in microscript_ffi.c
+    private static final int RETURN_VOID = 0;
+    private static final int RETURN_INT = 1;
+    private static final int RETURN_LONG = 2;
+    private static final int MAX_ARGS = 6;
+
+    public static native long open(String path);
+    public static native String lastError();
+    public static native long symbol(long library, String name);
+    public static native void close(long library);
+    public static native double callDoubles(long function, double[] args);
+    public static native long callLongs(long function, long[] args, int returnKind);
+    public static native long newString(String value);
+    public static native String readString(long pointer);
+    public static native void freeString(long pointer);
+
+    /**
+     * A library opened with ffi::open
+     */
+    public static class Library implements AutoCloseable {
+        private final String path;
+        private long handle;
+
+        Library(String path, long handle) {
+            this.path = path;
+            this.handle = handle;
+        }
+
+        @Override
+        public void close() {
+            closeLibrary(this);
+        }
+
+        @Override
+        public String toString() {
+            return "Library(" + path + ")";
+        }
+    }
+
+    public static Library openLibrary(String path) {
+        long handle = open(path);
+        if (handle == 0) {
+            throw new RuntimeException("ffi: cannot open " + path + ": " + lastError());
+        }
+        return new Library(path, handle);
+    }
+
+    public static void closeLibrary(Library library) {
+        if (library.handle != 0) {
+            close(library.handle);
+            library.handle = 0;
+        }
+    }
+
+    /**
+     * Calls a C function. returnType is "double", "int", "long", "pointer",
+     * "string" or "void".
+     */
+    public static Object call(Library library, String name, List<Object> args, String returnType) {
+        if (library.handle == 0) {
+            throw new RuntimeException("ffi: " + library + " is closed");
+        }
+        if (args.size() > MAX_ARGS) {
+            throw new RuntimeException("ffi: " + name + " takes more than " + MAX_ARGS + " arguments");
+        }
+        long function = symbol(library.handle, name);
+        if (function == 0) {
+            throw new RuntimeException("ffi: symbol " + name + " not found in " + library + ": " + lastError());
+        }
+
+        if (returnType.equals("double")) {
+            double[] values = new double[args.size()];
+            for (int i = 0; i < values.length; i++) {
+                if (!(args.get(i) instanceof Number)) {
+                    throw new RuntimeException("ffi: " + name + " returns double, so every argument must be a number");
+                }
+                values[i] = ((Number) args.get(i)).doubleValue();
+            }
+            return callDoubles(function, values);
+        }
+
+        int returnKind;
+        switch (returnType) {
+            case "void":
+                returnKind = RETURN_VOID;
+                break;
+            case "int":
+                returnKind = RETURN_INT;
+                break;
+            case "long":
+            case "pointer":
+            case "string":
+                returnKind = RETURN_LONG;
+                break;
+            default:
+                throw new RuntimeException("ffi: unknown return type " + returnType);
+        }
+
+        long[] values = new long[args.size()];
+        long[] strings = new long[args.size()];
+        try {
+            for (int i = 0; i < values.length; i++) {
+                values[i] = toLong(name, args.get(i));
+                if (args.get(i) instanceof String) {
+                    strings[i] = values[i] = newString((String) args.get(i));
+                }
+            }
+            long result = callLongs(function, values, returnKind);
+            switch (returnType) {
+                case "void":
+                    return null;
+                case "int":
+                    return (int) result;
+                case "string":
+                    return result == 0 ? null : readString(result);
+                default:
+                    return result;
+            }
+        } finally {
+            for (long pointer : strings) {
+                if (pointer != 0) {
+                    freeString(pointer);
+                }
+            }
+        }
+    }
+
+    private static long toLong(String name, Object value) {
+        if (value instanceof String) {
+            return 0; // Replaced by a C string pointer
+        }
+        if (value instanceof Boolean) {
+            return (Boolean) value ? 1 : 0;
+        }
+        if (value instanceof Character) {
+            return (Character) value;
+        }
+        if (value instanceof Number) {
+            double number = ((Number) value).doubleValue();
+            if (number != Math.rint(number)) {
+                throw new RuntimeException("ffi: " + name + " takes integer arguments, got " + value);
+            }
+            return ((Number) value).longValue();
+        }
+        throw new RuntimeException("ffi: unsupported argument for " + name + ": " + value);
+    }
+}
@@ -14,6 +14,10 @@ public class NativeHttp {
         try {
             System.loadLibrary("httpserver"); // Loads httpserver.dll or libhttpserver.so
             libraryLoaded = true;
+            // SIGINT/SIGTERM run JVM shutdown hooks, so servers get a chance
+            // to drain in-flight requests and close WebSocket clients
+            Runtime.getRuntime().addShutdownHook(
+                new Thread(NativeHttp::stopAllServers, "microscript-http-shutdown"));
         } catch (UnsatisfiedLinkError e) {
             loadError = e.getMessage();
             System.err.println("Warning: HTTP server library not loaded: " + loadError);
@@ -24,6 +28,33 @@ public class NativeHttp {
         return libraryLoaded;
     }
     
+    // Event kinds delivered by the native library, mirrored in callbacks.go
+    public static final int EVENT_WEBSOCKET_CONNECT = 1;
+    public static final int EVENT_WEBSOCKET_MESSAGE = 2;
+    public static final int EVENT_WEBSOCKET_CLOSE = 3;
+    public static final int EVENT_HTTP_REQUEST = 4;
+    
+    public interface EventListener {
+        void onEvent(int event, int handle, String id, String payload);
+    }
+    
+    private static volatile EventListener eventListener = null;
+    
+    public static void setEventListener(EventListener listener) {
+        eventListener = listener;
+        if (libraryLoaded) {
+            enableCallbacks();
+        }
+    }
+    
+    // Called by the JNI bridge on server threads
+    static void dispatch(int event, int handle, String id, String payload) {
+        EventListener listener = eventListener;
+        if (listener != null) {
+            listener.onEvent(event, handle, id, payload);
+        }
+    }
+    
     public static void checkLibrary() {
         if (!libraryLoaded) {
             throw new RuntimeException("HTTP server library not available: " + loadError);
@@ -32,12 +63,17 @@ public class NativeHttp {
     
     // HTTP Server core functions
     public static native int createServer(int port);
+    public static native int createTLSServer(int port, String certFile, String keyFile);
+    public static native int createAutocertServer(String domains, String cacheDir, String email);
     public static native void stopServer(int serverHandle);
     public static native boolean isRunning(int serverHandle);
+    public static native void stopAllServers();
+    public static native void setShutdownGracePeriod(int milliseconds);
+    public static native void waitServer(int serverHandle);
     
     // Route handling
     public static native void addRoute(int serverHandle, String method, String path, String handlerName);
-    public static native void removeRoute(int serverHandle, String method, String path);
+    public static native boolean removeRoute(int serverHandle, String method, String path);
     
     // Response utilities
     public static native void setResponseHeader(int requestId, String name, String value);
@@ -45,19 +81,35 @@ public class NativeHttp {
     public static native void sendJsonResponse(int requestId, int statusCode, String jsonBody);
     public static native void sendFileResponse(int requestId, String filePath);
     
+    // Static file serving
+    public static native void serveStatic(int serverHandle, String urlPrefix, String directory, boolean allowListing);
+    
+    // Reverse proxying
+    public static native boolean proxy(int serverHandle, String urlPrefix, String upstream);
+    
     // Request information
     public static native String getRequestPath(int requestId);
     public static native String getRequestMethod(int requestId);
     public static native String getRequestHeader(int requestId, String headerName);
     public static native String getRequestBody(int requestId);
+    public static native String getRequestJson(int requestId);
     public static native String getQueryParam(int requestId, String paramName);
     
     // Middleware
     public static native void useMiddleware(int serverHandle, String middlewareName);
+    public static native boolean basicAuth(int serverHandle, String usersJson);
+    public static native boolean jwtAuth(int serverHandle, String secret, String algorithms);
+    public static native String getRequestUser(int requestId);
+    
+    // Access logging
+    public static native boolean setAccessLog(int serverHandle, String format, String destination);
     
     // Utility functions
     public static native String urlEncode(String input);
     public static native String urlDecode(String input);
+    public static native String parseUrl(String input);
+    public static native String buildUrl(String partsJson);
+    public static native String buildQuery(String queryJson);
     public static native String generateUuid();
     
     // WebSocket support
@@ -65,4 +117,8 @@ public class NativeHttp {
     public static native void sendWebSocketMessage(int endpointHandle, String clientId, String message);
     public static native void broadcastWebSocketMessage(int endpointHandle, String message);
     public static native void closeWebSocketConnection(int endpointHandle, String clientId);
+    public static native void enableWebSocketCallbacks(int endpointHandle);
+    
+    // Callbacks
+    private static native void enableCallbacks();
 }
\ No newline at end of file
@@ -0,0 +1,32 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+/**
+ * A module contributed from outside the interpreter. Scripts load it with
+ * `import <name>` like the built-in math, io, http and ffi modules.
+ *
+ * A plugin is a jar that contains an implementation with a public no-argument
+ * constructor, listed in META-INF/services/com.magayaga.microscript.NativeModule:
+ *
+ *   public class MqttModule implements NativeModule {
+ *       public String getName() { return "mqtt"; }
+ *       public void register(Environment env) {
+ *           env.setVariable("mqtt::publish", (Import.FunctionInterface) (args) -> ...);
+ *       }
+ *   }
+ *
+ * Plugins are found on the classpath and in the jars of every directory
+ * listed in MICROSCRIPT_PLUGIN_PATH. Host applications can also call
+ * Import.registerModule directly.
+ */
+public interface NativeModule extends Import.Module {
+    /**
+     * The name scripts import; it can't replace a module that's already registered
+     */
+    String getName();
+}
@@ -17,7 +17,7 @@ public class Operators {
         }
         
         else {
-            throw new RuntimeException("Type error: " + varName + " is not an Integer.");
+            throw new RuntimeException("Type error: " + varName + " expected Int32, got " + Builtins.describe(value) + ".");
         }
     }
 
@@ -31,7 +31,62 @@ public class Operators {
         }
         
         else {
-            throw new RuntimeException("Type error: " + varName + " is not an Integer.");
+            throw new RuntimeException("Type error: " + varName + " expected Int32, got " + Builtins.describe(value) + ".");
         }
     }
+
+    /**
+     * a // b: the quotient rounded down, so -7 // 2 is -4. Whole values give
+     * a whole number, and dividing one by zero is an error; anything else
+     * gives a Float64.
+     */
+    public static Object floorDivide(Object dividend, Object divisor) {
+        double a = number(dividend, "//");
+        double b = number(divisor, "//");
+        if (isWhole(dividend, a) && isWhole(divisor, b)) {
+            if (b == 0) {
+                throw new RuntimeException("Division by zero: " + dividend + " // 0");
+            }
+            return whole(Math.floorDiv(((Number) dividend).longValue(), ((Number) divisor).longValue()));
+        }
+        return Math.floor(a / b);
+    }
+
+    /**
+     * What's left over from a // b, which has the divisor's sign, so that
+     * (a // b) * b + remainder is a
+     */
+    public static Object floorModulo(Object dividend, Object divisor) {
+        double a = number(dividend, "//");
+        double b = number(divisor, "//");
+        if (isWhole(dividend, a) && isWhole(divisor, b)) {
+            if (b == 0) {
+                throw new RuntimeException("Division by zero: " + dividend + " // 0");
+            }
+            return whole(Math.floorMod(((Number) dividend).longValue(), ((Number) divisor).longValue()));
+        }
+        return a - Math.floor(a / b) * b;
+    }
+
+    private static double number(Object value, String operator) {
+        if (!(value instanceof Number)) {
+            throw new RuntimeException("Operator '" + operator + "' expects numbers, got " + Builtins.describe(value));
+        }
+        return ((Number) value).doubleValue();
+    }
+
+    // Int32 and Int64 values, and Float64 values without a fraction, such as the literal 7
+    private static boolean isWhole(Object value, double number) {
+        if (value instanceof Integer || value instanceof Long || value instanceof Short || value instanceof Byte) {
+            return true;
+        }
+        return number == Math.rint(number) && Math.abs(number) <= 1L << 53;
+    }
+
+    private static Object whole(long value) {
+        if (value >= Integer.MIN_VALUE && value <= Integer.MAX_VALUE) {
+            return (int) value;
+        }
+        return value;
+    }
 }
\ No newline at end of file
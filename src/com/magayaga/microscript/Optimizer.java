@@ -0,0 +1,244 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.List;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Source-level optimization pass run on preprocessed lines before parsing.
+ * Folds constant arithmetic (typically left behind by macro expansion),
+ * drops branches under `if (false)` and precomputes constant placeholders
+ * in console.write/console.writef templates. Every rewrite produces the
+ * same result the interpreter would have computed at run time.
+ */
+public class Optimizer {
+    // Pre-compiled regex patterns
+    private static final String NUMBER = "(?:\\d+(?:\\.\\d+)?|\\.\\d+)";
+    private static final Pattern CONSTANT_GROUP_PATTERN = Pattern.compile(
+        "\\(\\s*(" + NUMBER + "(?:\\s*[-+*/%]\\s*" + NUMBER + ")*)\\s*\\)");
+    private static final Pattern CONSTANT_EXPRESSION_PATTERN = Pattern.compile(
+        "\\s*" + NUMBER + "(?:\\s*[-+*/%]\\s*" + NUMBER + ")*\\s*");
+    private static final Pattern TOKEN_PATTERN = Pattern.compile(NUMBER + "|[-+*/%]");
+    private static final Pattern TEMPLATE_PLACEHOLDER_PATTERN = Pattern.compile("\\{([^{}]+)\\}");
+    private static final Pattern CONSOLE_TEMPLATE_PATTERN = Pattern.compile("(console\\.writef?\\(\\s*)\"([^\"]*)\"");
+    private static final Pattern IF_FALSE_PATTERN = Pattern.compile("if\\s*\\(\\s*false\\s*\\)\\s*\\{\\s*");
+    private static final Pattern BRANCH_PATTERN = Pattern.compile("(elif|else)\\b.*");
+
+    public List<String> optimize(List<String> lines) {
+        List<String> output = new ArrayList<>(lines.size());
+        for (String line : lines) {
+            output.add(foldTemplates(foldConstants(line)));
+        }
+        removeDeadBranches(output);
+        return output;
+    }
+
+    /**
+     * Replaces parenthesized constant arithmetic such as (3.14*2*2) with its value,
+     * leaving string literals and call arguments untouched. Nested groups fold
+     * from the inside out.
+     */
+    String foldConstants(String line) {
+        if (line.trim().startsWith("//") || line.indexOf('(') == -1) {
+            return line;
+        }
+        StringBuilder result = new StringBuilder(line.length());
+        int segmentStart = 0;
+        boolean inString = false;
+        for (int i = 0; i <= line.length(); i++) {
+            boolean atEnd = i == line.length();
+            char c = atEnd ? '\0' : line.charAt(i);
+            if (atEnd || (c == '"' && (i == 0 || line.charAt(i - 1) != '\\'))) {
+                String segment = line.substring(segmentStart, i);
+                result.append(inString ? segment : foldSegment(segment));
+                if (!atEnd) {
+                    result.append(c);
+                }
+                segmentStart = i + 1;
+                inString = !inString;
+            }
+        }
+        return result.toString();
+    }
+
+    private String foldSegment(String code) {
+        boolean changed = true;
+        while (changed) {
+            changed = false;
+            Matcher matcher = CONSTANT_GROUP_PATTERN.matcher(code);
+            while (matcher.find()) {
+                // A group right after a name is a call's argument list, not arithmetic
+                int before = matcher.start() - 1;
+                if (before >= 0 && isCallTarget(code.charAt(before))) {
+                    continue;
+                }
+                Double value = evaluateConstant(matcher.group(1));
+                String literal = value == null ? null : toLiteral(value);
+                if (literal == null) {
+                    continue;
+                }
+                // Keywords like if, while and return need their parentheses kept
+                if (value < 0 || endsWithWord(code.substring(0, matcher.start()))) {
+                    literal = "(" + literal + ")";
+                }
+                code = code.substring(0, matcher.start()) + literal + code.substring(matcher.end());
+                changed = true;
+                break;
+            }
+        }
+        return code;
+    }
+
+    private static boolean isCallTarget(char c) {
+        return Character.isLetterOrDigit(c) || c == '_' || c == ')' || c == ']' || c == '.';
+    }
+
+    // Literals are re-read by the evaluator, which doesn't understand exponents
+    private static String toLiteral(double value) {
+        if (Double.isNaN(value) || Double.isInfinite(value)) {
+            return null;
+        }
+        String text = Double.toString(value);
+        if (text.contains("E")) {
+            return null;
+        }
+        return text;
+    }
+
+    private static boolean endsWithWord(String code) {
+        String trimmed = code.trim();
+        return !trimmed.isEmpty() && Character.isLetterOrDigit(trimmed.charAt(trimmed.length() - 1));
+    }
+
+    /**
+     * Precomputes {expression} placeholders made only of constant arithmetic in
+     * the template passed to console.write/console.writef
+     */
+    String foldTemplates(String line) {
+        if (!line.contains("console.write")) {
+            return line;
+        }
+        Matcher matcher = CONSOLE_TEMPLATE_PATTERN.matcher(line);
+        StringBuffer output = new StringBuffer();
+        while (matcher.find()) {
+            String template = matcher.group(2);
+            StringBuffer folded = new StringBuffer();
+            Matcher placeholder = TEMPLATE_PLACEHOLDER_PATTERN.matcher(template);
+            while (placeholder.find()) {
+                String expression = placeholder.group(1);
+                Double value = CONSTANT_EXPRESSION_PATTERN.matcher(expression).matches()
+                    ? evaluateConstant(expression) : null;
+                // Printed the same way the interpreter prints a number
+                String replacement = value != null ? value.toString() : placeholder.group();
+                placeholder.appendReplacement(folded, Matcher.quoteReplacement(replacement));
+            }
+            placeholder.appendTail(folded);
+            matcher.appendReplacement(output,
+                Matcher.quoteReplacement(matcher.group(1) + "\"" + folded + "\""));
+        }
+        matcher.appendTail(output);
+        return output.toString();
+    }
+
+    /**
+     * Evaluates constant arithmetic with the interpreter's rules: every number is
+     * a double, * / % bind tighter than + -, and all operators are left-associative.
+     * Returns null when a divisor is zero, since the Infinity or NaN that
+     * gives can't be written back as a literal.
+     */
+    Double evaluateConstant(String expression) {
+        List<String> tokens = new ArrayList<>();
+        Matcher matcher = TOKEN_PATTERN.matcher(expression);
+        while (matcher.find()) {
+            tokens.add(matcher.group());
+        }
+
+        Double sum = null;
+        char sumOperator = '+';
+        int i = 0;
+        while (i < tokens.size()) {
+            double term = Double.parseDouble(tokens.get(i++));
+            while (i < tokens.size() && "*/%".contains(tokens.get(i))) {
+                char operator = tokens.get(i).charAt(0);
+                double factor = Double.parseDouble(tokens.get(i + 1));
+                i += 2;
+                if (operator != '*' && factor == 0) {
+                    return null; // Division by zero
+                }
+                term = operator == '*' ? term * factor : operator == '/' ? term / factor : term % factor;
+            }
+            if (sum == null) {
+                sum = term;
+            } else {
+                sum = sumOperator == '+' ? sum + term : sum - term;
+            }
+            if (i < tokens.size()) {
+                sumOperator = tokens.get(i++).charAt(0);
+            }
+        }
+        return sum;
+    }
+
+    /**
+     * Removes `if (false) { ... }` blocks. When an elif follows, it becomes the
+     * new if; when an else follows, it runs unconditionally as `if (true)`.
+     * Removed lines are left blank so line numbers still match the source.
+     */
+    void removeDeadBranches(List<String> lines) {
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            String trimmed = line.trim();
+            if (!IF_FALSE_PATTERN.matcher(trimmed).matches()) {
+                continue;
+            }
+            int end = Braces.findClosing(lines, i);
+            if (end == -1) {
+                return; // Unbalanced; leave the error to the parser
+            }
+            String indent = line.substring(0, line.indexOf(trimmed));
+
+            // "} elif (...) {" or "} else {" on the closing line
+            String rest = lines.get(end).trim().substring(1).trim();
+            if (BRANCH_PATTERN.matcher(rest).matches()) {
+                lines.set(end, indent + promote(rest));
+                blank(lines, i, end);
+                continue;
+            }
+
+            // elif/else on its own line after the closing brace
+            int next = end + 1;
+            while (next < lines.size() && lines.get(next).trim().isEmpty()) {
+                next++;
+            }
+            if (next < lines.size()) {
+                String following = lines.get(next).trim();
+                if (BRANCH_PATTERN.matcher(following).matches()) {
+                    lines.set(next, indent + promote(following));
+                    blank(lines, i, next);
+                    continue;
+                }
+            }
+            blank(lines, i, end + 1);
+        }
+    }
+
+    private static void blank(List<String> lines, int from, int to) {
+        for (int i = from; i < to; i++) {
+            lines.set(i, "");
+        }
+    }
+
+    private static String promote(String branch) {
+        if (branch.startsWith("elif")) {
+            return "if" + branch.substring(4);
+        }
+        return "if (true)" + branch.substring(4);
+    }
+}
@@ -0,0 +1,100 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.List;
+import java.util.Set;
+import java.util.concurrent.ConcurrentHashMap;
+import java.util.function.IntConsumer;
+
+/**
+ * Operating system hooks:
+ *
+ *   os.onSignal("SIGINT", shutdown)   calls shutdown on Ctrl-C instead of ending the script
+ *
+ * A trapped signal's handler runs on the event loop, so a server waiting in
+ * http::wait, or a script waiting on its timers, can flush its state and
+ * stop its servers on its own terms. A handler that takes a parameter is
+ * passed the signal's name. If the signal arrives again while its handler
+ * is still waiting for the script to reach the event loop, the script ends
+ * as it would have without the handler, so a busy script can still be
+ * stopped.
+ *
+ * Java has no public API for signals, so traps go through sun.misc.Signal
+ * from the jdk.unsupported module, which javac warns about. OpenJDK-based
+ * JVMs all ship it; on one that doesn't, os.onSignal is an error and
+ * signals keep their default behavior.
+ */
+public class Os {
+    // Signals whose handler is queued but hasn't run yet
+    private static final Set<String> pending = ConcurrentHashMap.newKeySet();
+
+    private Os() {
+    }
+
+    /**
+     * Calls a hook by name with already evaluated arguments
+     */
+    public static Object call(String operation, List<Object> args, Environment environment) {
+        switch (operation) {
+            case "onSignal":
+                expectArguments(operation, args, 2);
+                onSignal(args.get(0), args.get(1), environment);
+                return null;
+            default:
+                throw new RuntimeException("Unknown os operation: " + operation);
+        }
+    }
+
+    private static void onSignal(Object name, Object handler, Environment environment) {
+        if (!(name instanceof String) || !((String) name).matches("SIG[A-Z0-9]+")) {
+            throw new RuntimeException("os.onSignal expects a signal name such as \"SIGINT\", got " + Builtins.describe(name));
+        }
+        if (!(handler instanceof Function || handler instanceof Import.FunctionInterface)) {
+            throw new RuntimeException("os.onSignal expects a function, got " + Builtins.describe(handler));
+        }
+        String signal = (String) name;
+        boolean takesName = handler instanceof Function && !((Function) handler).getParameters().isEmpty();
+        Object[] values = takesName ? new Object[] { signal } : new Object[0];
+        EventLoop loop = environment.getEventLoop();
+        try {
+            Trap.handle(signal, number -> {
+                if (!pending.add(signal)) {
+                    // Exit codes for signals follow the shell's 128 + number
+                    System.exit(128 + number);
+                }
+                loop.post(() -> {
+                    pending.remove(signal);
+                    Builtins.callBack("os.onSignal handler", handler, environment, values);
+                });
+            });
+        } catch (IllegalArgumentException e) {
+            // Unknown signals, and those the JVM keeps for itself such as SIGQUIT
+            throw new RuntimeException("os.onSignal cannot trap " + signal + ": " + e.getMessage());
+        } catch (LinkageError e) {
+            throw new RuntimeException("os.onSignal needs sun.misc.Signal, which this JVM doesn't provide");
+        }
+    }
+
+    /**
+     * The only use of sun.misc.Signal, kept apart so that Os itself loads on
+     * JVMs without it and the missing class surfaces as a LinkageError here
+     */
+    private static class Trap {
+        static void handle(String signal, IntConsumer handler) {
+            sun.misc.Signal.handle(new sun.misc.Signal(signal.substring("SIG".length())),
+                received -> handler.accept(received.getNumber()));
+        }
+    }
+
+    private static void expectArguments(String operation, List<Object> args, int expected) {
+        if (args.size() != expected) {
+            throw new RuntimeException("os." + operation + " expects " + expected + " argument"
+                + (expected == 1 ? "" : "s") + ", got " + args.size());
+        }
+    }
+}
@@ -14,28 +14,168 @@ import java.util.regex.Matcher;
 import java.util.regex.Pattern;
 
 public class Parser {
+    // Pre-compiled regex patterns
+    private static final Pattern IF_PATTERN = Pattern.compile("if\\s*\\((.+?)\\)\\s*\\{");
+    private static final Pattern ARROW_BLOCK_PATTERN = Pattern.compile("var\\s+(\\w+)\\s*=\\s*\\|(.*?)\\|\\s*=>\\s*(\\w+)?\\s*\\{(.*?)\\};");
+    private static final Pattern ARROW_EXPRESSION_PATTERN = Pattern.compile("var\\s+(\\w+)\\s*=\\s*\\|(.*?)\\|\\s*=>\\s*([^{][^;]*);");
+    private static final Pattern ARROW_DECLARATION_PATTERN = Pattern.compile("^var\\s+\\w+\\s*=\\s*\\|");
+    private static final Pattern ARROW_RETURN_TYPE_PATTERN = Pattern.compile("=>\\s*(\\w+)");
+    private static final Pattern C_STYLE_FUNCTION_PATTERN = Pattern.compile("^(String|Int32|Int64|Float32|Float64|fn)\\s+([\\w:]+)\\s*\\(([^)]*)\\)\\s*\\{");
+    // Types may take generic arguments (List<T>, Map<String, T>), and generic functions declare <T, U>
+    private static final Pattern FUNCTION_PATTERN = Pattern.compile("function\\s+([\\w:]+)\\s*(?:<([^>]*)>)?\\(([^)]*)\\)\\s*(->\\s*(\\w+(?:<[^>{]*>+)?\\??(?:\\s*\\|\\s*\\w+(?:<[^>{]*>+)?\\??)*))?\\s*\\{");
+    private static final Pattern CONSOLE_WRITE_PATTERN = Pattern.compile("console.write\\((.*)\\);");
+    private static final Pattern CONSOLE_WRITEF_PATTERN = Pattern.compile("console.writef\\((.*)\\);");
+    private static final Pattern IO_PRINT_PATTERN = Pattern.compile("io::(print|println)\\((.*)\\);");
+    private static final Pattern CALL_PATTERN = Pattern.compile("([\\w:]+)\\((.*)\\);");
+    private static final Pattern CLASS_PATTERN = Pattern.compile("class\\s+(\\w+)\\s*\\{");
+    private static final Pattern METHOD_PATTERN = Pattern.compile("function\\s+(\\w+)\\s*\\(([^)]*)\\)\\s*(->\\s*(\\w+\\??))?\\s*\\{");
+    private static final Pattern PROPERTY_PATTERN = Pattern.compile("(var|bool)\\s+(\\w+)\\s*:\\s*(\\w+)\\s*=\\s*(.+)");
+    private static final Pattern NAMESPACE_PATTERN = Pattern.compile("namespace\\s+([A-Za-z_]\\w*)\\s*\\{");
+    private static final Pattern NAMESPACED_CALL_PATTERN = Pattern.compile("(\\w+)\\((.*)\\);");
+    private static final Pattern MAP_PATTERN = Pattern.compile("@map\\s*=>\\s*(\\([^)]+\\))\\s*\\[([^\\]]+)\\]");
+    private static final Pattern C_STYLE_FUNCTION_HEADER_PATTERN = Pattern.compile("^(String|Int32|Int64|Float32|Float64|fn)\\s+\\w+\\s*\\(.*\\)\\s*\\{");
+    private static final Pattern IMPORT_ALIAS_PATTERN = Pattern.compile("([\\w:]+)\\s+as\\s+([A-Za-z_]\\w*)");
+    private static final Pattern COMMA_SEPARATOR_PATTERN = Pattern.compile("\\s*,\\s*");
+
     private final List<String> lines;
     private final Environment environment;
+    // Executors only hold their environment, so one is shared by every top-level statement
+    private final Executor executor;
+    // The line parse() is currently at
+    private int position;
 
     public Parser(com.magayaga.microscript.Scanner scanner) throws IOException {
-        this.lines = scanner.readLines();
-        this.environment = new Environment();
+        this(scanner.readLines());
     }
 
     public Parser(List<String> lines) {
-        this.lines = lines;
-        this.environment = new Environment();
+        this(lines, new Environment());
     }
     
     public Parser(List<String> lines, Environment environment) {
         this.lines = lines;
         this.environment = environment;
+        this.executor = new Executor(environment);
     }
 
+    /**
+     * Parses and runs the lines; errors carry the line of the top-level
+     * statement being processed unless a function body already set one.
+     * Definitions are read before anything runs, so top-level code can call
+     * a function defined further down the file.
+     */
     public void parse() {
+        try {
+            checkLiterals();
+            stripExports();
+            desugarPipelines();
+            hoistDefinitions();
+            parseLines();
+        } catch (RuntimeException | StackOverflowError e) {
+            throw ScriptException.at(e, position);
+        }
+    }
+
+    /**
+     * Stops before anything runs when a string or character literal is left
+     * open, since the rest of its line would otherwise be read as the string
+     */
+    private void checkLiterals() {
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            // Comment blocks are skipped the way parseLines skips them
+            if (line.trim().startsWith("/*")) {
+                while (i < lines.size() && !lines.get(i).contains("*/")) {
+                    i++;
+                }
+                continue;
+            }
+            int column = Checker.unterminatedLiteral(line);
+            if (column >= 0) {
+                String kind = line.charAt(column) == '"' ? "string" : "character";
+                throw new ScriptException("Unterminated " + kind + " literal: the " + kind + " starting at column "
+                    + (column + 1) + " has no closing quote", i, column, null);
+            }
+        }
+    }
+
+    /**
+     * Drops the export keywords; Import decides what a module shares before
+     * it runs
+     */
+    private void stripExports() {
+        for (int i = 0; i < lines.size(); i++) {
+            if (lines.get(i).contains("export")) {
+                lines.set(i, Exports.strip(lines.get(i)));
+            }
+        }
+    }
+
+    /**
+     * Rewrites value |> f |> g(2) as g(f(value), 2) before anything is read
+     */
+    private void desugarPipelines() {
+        for (int i = 0; i < lines.size(); i++) {
+            position = i;
+            String line = lines.get(i);
+            if (line.contains("|>")) {
+                lines.set(i, Pipeline.desugar(line));
+            }
+        }
+    }
+
+    /**
+     * The first pass: defines the top-level functions, structs and interfaces,
+     * and the functions and structs of namespaces, without running anything.
+     * Interfaces come first so a struct can implement one declared after it.
+     * The second pass defines each of them again where it is written, so a
+     * function defined twice is the first version until the second is reached.
+     */
+    private void hoistDefinitions() {
+        List<Integer> interfaces = new ArrayList<>();
+        List<Integer> definitions = new ArrayList<>();
+        int i = 0;
+        while (i < lines.size()) {
+            position = i;
+            String line = lines.get(i).trim();
+            if (line.startsWith("interface ")) {
+                interfaces.add(i);
+            } else if (line.startsWith("function ") || line.startsWith("struct ") || line.startsWith("namespace ")
+                    || C_STYLE_FUNCTION_HEADER_PATTERN.matcher(line).matches()) {
+                definitions.add(i);
+            } else if (!line.endsWith("{") || line.startsWith("//")) {
+                i++;
+                continue;
+            }
+            // Skip the block, so nothing inside an if or a loop is hoisted
+            i = findClosingBrace(i) + 1;
+        }
+
+        for (int start : interfaces) {
+            position = start;
+            parseInterface(start, findClosingBrace(start));
+        }
+        for (int start : definitions) {
+            position = start;
+            String line = lines.get(start).trim();
+            int end = findClosingBrace(start);
+            if (line.startsWith("struct ")) {
+                parseStruct(start, end);
+            } else if (line.startsWith("namespace ")) {
+                parseNamespace(start, end, true);
+            } else {
+                parseFunction(start, end);
+            }
+        }
+    }
+
+    private void parseLines() {
+        // Compiled once, after the rewrites above, for the blocks that run at the top level
+        Body body = new Body(lines);
         int i = 0;
         boolean hasCStyleMain = false;
         while (i < lines.size()) {
+            position = i;
             String line = lines.get(i).trim();
             
             // Skip comments and empty lines
@@ -78,7 +218,7 @@ public class Parser {
             }
 
             // C-style function
-            if (line.matches("^(String|Int32|Int64|Float32|Float64|fn)\\s+\\w+\\s*\\(.*\\)\\s*\\{")) {
+            if (C_STYLE_FUNCTION_HEADER_PATTERN.matcher(line).matches()) {
                 int closingBraceIndex = findClosingBrace(i);
                 parseFunction(i, closingBraceIndex);
                 if (line.startsWith("fn main")) hasCStyleMain = true;
@@ -93,29 +233,32 @@ public class Parser {
             }
             
             // Arrow function
-            else if (line.contains("=>")) {
+            else if (ARROW_DECLARATION_PATTERN.matcher(line).find()) {
                 parseArrowFunction(line);
                 i++;
             }
             
             // Handle if/elif/else chain as a single block
             else if (line.startsWith("if")) {
-                Executor executor = new Executor(environment);
-                int afterConditional = Statements.processConditionalStatement(lines, i, executor);
+                int afterConditional = Statements.processConditionalStatement(body, i, executor);
                 i = afterConditional; // Skip all lines in the conditional chain
             }
             
             // Handle while loop as a single block
             else if (line.startsWith("while")) {
-                Executor executor = new Executor(environment);
-                int afterLoop = Loop.processLoop(lines, i, executor);
+                int afterLoop = Loop.processLoop(body, i, executor);
                 i = afterLoop; // Skip all lines in the loop
             }
             
+            // Handle with block
+            else if (With.isWith(line)) {
+                i = With.processWithStatement(body, i, executor);
+            }
+
             // Handle namespace block
             else if (line.startsWith("namespace ")) {
                 int closingBraceIndex = findClosingBrace(i);
-                parseNamespace(i, closingBraceIndex);
+                parseNamespace(i, closingBraceIndex, false);
                 i = closingBraceIndex + 1;
             }
 
@@ -126,8 +269,23 @@ public class Parser {
                 i = closingBraceIndex + 1;
             }
 
+            // Handle interface definition
+            else if (line.startsWith("interface ")) {
+                int closingBraceIndex = findClosingBrace(i);
+                parseInterface(i, closingBraceIndex);
+                i = closingBraceIndex + 1;
+            }
+
             else {
                 // Execute top-level commands
+                environment.getCancellation().check();
+                if (environment.getTracer() != null) {
+                    environment.getTracer().statement(i);
+                }
+                Debugger debugger = environment.getDebugger();
+                if (debugger != null) {
+                    debugger.beforeStatement(i);
+                }
                 parseLine(line);
                 i++;
             }
@@ -137,7 +295,6 @@ public class Parser {
         if (hasCStyleMain) {
             Function mainFunc = environment.getFunction("main");
             if (mainFunc != null) {
-                Executor executor = new Executor(environment);
                 executor.executeFunction("main", new String[0]);
             }
         }
@@ -158,9 +315,6 @@ public class Parser {
             if (line.startsWith("elif") || line.equals("else {") || line.equals("else{")) {
                 // Found an elif or else block
                 int nextBlockEnd = findClosingBrace(currentIndex);
-                if (nextBlockEnd == -1) {
-                    throw new RuntimeException("Missing closing brace for elif/else at line: " + (currentIndex + 1));
-                }
                 currentIndex = nextBlockEnd + 1;
             } else {
                 // No more elif or else blocks
@@ -181,8 +335,7 @@ public class Parser {
         String ifLine = lines.get(startIndex).trim();
         
         // Verify if statement syntax
-        Pattern ifPattern = Pattern.compile("if\\s*\\((.+?)\\)\\s*\\{");
-        Matcher ifMatcher = ifPattern.matcher(ifLine);
+        Matcher ifMatcher = IF_PATTERN.matcher(ifLine);
         
         if (!ifMatcher.find()) {
             throw new RuntimeException("Invalid if statement syntax at line: " + (startIndex + 1));
@@ -191,8 +344,7 @@ public class Parser {
 
     private void parseArrowFunction(String line) {
         // Format: var name = |Type: param1, Type: param2| => ReturnType {body} or expression;
-        Pattern pattern = Pattern.compile("var\\s+(\\w+)\\s*=\\s*\\|(.*?)\\|\\s*=>\\s*(\\w+)?\\s*\\{(.*?)\\};");
-        Matcher matcher = pattern.matcher(line);
+        Matcher matcher = ARROW_BLOCK_PATTERN.matcher(line);
         
         if (matcher.find()) {
             String name = matcher.group(1).trim();
@@ -230,8 +382,7 @@ public class Parser {
             environment.setVariable(name, arrowFunction);
         } else {
             // Try to match expression body format: var name = |params| => expression;
-            Pattern exprPattern = Pattern.compile("var\\s+(\\w+)\\s*=\\s*\\|(.*?)\\|\\s*=>\\s*([^{][^;]*);");
-            Matcher exprMatcher = exprPattern.matcher(line);
+            Matcher exprMatcher = ARROW_EXPRESSION_PATTERN.matcher(line);
             
             if (exprMatcher.find()) {
                 String name = exprMatcher.group(1).trim();
@@ -242,11 +393,10 @@ public class Parser {
                 String returnType = "void";
                 
                 // Extract return type if it's specified in the paramString using regex
-                Pattern returnTypePattern = Pattern.compile("=>\\s*(\\w+)");
-                Matcher returnTypeMatcher = returnTypePattern.matcher(line);
+                Matcher returnTypeMatcher = ARROW_RETURN_TYPE_PATTERN.matcher(line);
                 if (returnTypeMatcher.find()) {
                     returnType = returnTypeMatcher.group(1);
-                    paramString = paramString.replaceAll("=>\\s*\\w+", "").trim();
+                    paramString = ARROW_RETURN_TYPE_PATTERN.matcher(paramString).replaceAll("").trim();
                 }
                 
                 List<Parameter> parameters = new ArrayList<>();
@@ -283,28 +433,33 @@ public class Parser {
     }
 
     private void parseFunction(int start, int end, String namePrefix) {
+        environment.defineFunction(readFunction(start, end, namePrefix));
+    }
+
+    // Builds the function whose header is on line start and closing brace on line end
+    private Function readFunction(int start, int end, String namePrefix) {
         String header = lines.get(start).trim();
         
         // C-style function declaration regex
-        Pattern cStylePattern = Pattern.compile("^(String|Int32|Int64|Float32|Float64|fn)\\s+([\\w:]+)\\s*\\(([^)]*)\\)\\s*\\{");
-        Matcher cStyleMatcher = cStylePattern.matcher(header);
+        Matcher cStyleMatcher = C_STYLE_FUNCTION_PATTERN.matcher(header);
     
         // MicroScript-style function declaration regex
-        Pattern microScriptPattern = Pattern.compile("function\\s+([\\w:]+)\\(([^)]*)\\)\\s*(->\\s*(\\w+))?\\s*\\{");
-        Matcher microScriptMatcher = microScriptPattern.matcher(header);
+        Matcher microScriptMatcher = FUNCTION_PATTERN.matcher(header);
     
         if (cStyleMatcher.matches()) {
             String returnType = cStyleMatcher.group(1);
             String name = namePrefix + cStyleMatcher.group(2);
             String params = cStyleMatcher.group(3).trim();
-            parseFunctionBody(name, params, returnType, start, end);
+            return buildFunction(name, params, returnType, new ArrayList<>(), start, end);
         }
         
         else if (microScriptMatcher.matches()) {
             String name = namePrefix + microScriptMatcher.group(1);
-            String params = microScriptMatcher.group(2).trim();
-            String returnType = microScriptMatcher.group(4) != null ? microScriptMatcher.group(4).trim() : "void";
-            parseFunctionBody(name, params, returnType, start, end);
+            String params = microScriptMatcher.group(3).trim();
+            String returnType = microScriptMatcher.group(5) != null ? microScriptMatcher.group(5).trim() : "void";
+            List<String> typeParameters = microScriptMatcher.group(2) != null
+                ? typeParameters(microScriptMatcher.group(2)) : new ArrayList<>();
+            return buildFunction(name, params, returnType, typeParameters, start, end);
         }
         
         else {
@@ -312,10 +467,11 @@ public class Parser {
         }
     }
     
-    private void parseFunctionBody(String name, String params, String returnType, int start, int end) {
+    private Function buildFunction(String name, String params, String returnType, List<String> typeParameters,
+                                   int start, int end) {
         List<Parameter> parameters = new ArrayList<>();
         if (!params.isEmpty()) {
-            for (String param : params.split("\\s*,\\s*")) {
+            for (String param : splitParameters(params)) {
                 String[] parts = param.split(":");
                 if (parts.length != 2) {
                     throw new RuntimeException("Syntax error: Invalid parameter declaration.");
@@ -328,26 +484,91 @@ public class Parser {
             body.add(lines.get(i).trim());
         }
 
-        environment.defineFunction(new Function(name, parameters, returnType, body));
+        Function function = new Function(name, parameters, returnType, body);
+        function.setLine(start);
+        function.setDocs(docComment(lines, start));
+        function.setTypeParameters(typeParameters);
+        return function;
+    }
+
+    /**
+     * Splits a parameter list at the commas outside generic arguments, so
+     * "m: Map<String, T>, n: Int32" gives two parameters. Pieces keep their
+     * surrounding whitespace.
+     */
+    static List<String> splitParameters(String params) {
+        List<String> pieces = new ArrayList<>();
+        int depth = 0;
+        int start = 0;
+        for (int i = 0; i < params.length(); i++) {
+            char c = params.charAt(i);
+            if (c == '<') {
+                depth++;
+            } else if (c == '>') {
+                depth--;
+            } else if (c == ',' && depth == 0) {
+                pieces.add(params.substring(start, i));
+                start = i + 1;
+            }
+        }
+        pieces.add(params.substring(start));
+        return pieces;
+    }
+
+    // The names between <> in function first<T, U>(...)
+    private static List<String> typeParameters(String declared) {
+        List<String> names = new ArrayList<>();
+        for (String name : declared.split(",")) {
+            if (!name.trim().matches("[A-Za-z_]\\w*")) {
+                throw new RuntimeException("Syntax error: Invalid type parameter '" + name.trim() + "'.");
+            }
+            names.add(name.trim());
+        }
+        return names;
+    }
+
+    /**
+     * The /// comment lines directly above the given line, without their
+     * markers; an empty string when there are none
+     */
+    static String docComment(List<String> lines, int line) {
+        int first = line;
+        while (first > 0 && lines.get(first - 1).trim().startsWith("///")) {
+            first--;
+        }
+        StringBuilder docs = new StringBuilder();
+        for (int i = first; i < line; i++) {
+            String text = lines.get(i).trim().substring(3);
+            if (docs.length() > 0) {
+                docs.append('\n');
+            }
+            docs.append(text.startsWith(" ") ? text.substring(1) : text);
+        }
+        return docs.toString();
     }
         
     private int findClosingBrace(int start) {
-        int openBraces = 0;
-        for (int i = start; i < lines.size(); i++) {
-            String line = lines.get(i);
-            openBraces += line.chars().filter(ch -> ch == '{').count();
-            openBraces -= line.chars().filter(ch -> ch == '}').count();
-            if (openBraces == 0) {
-                return i;
-            }
+        int end = Braces.findClosing(lines, start);
+        if (end < 0) {
+            // Returning an index here would send parse() back to an earlier line forever
+            throw Braces.unclosed(lines, start);
         }
-        return -1;
+        return end;
     }
 
     private void parseLine(String line) {
         line = line.trim();
         if (line.startsWith("import ")) {
             String moduleName = line.substring(7).trim();
+            if (moduleName.endsWith(";")) {
+                moduleName = moduleName.substring(0, moduleName.length() - 1).trim();
+            }
+            // import math_utils as mu keeps the module's names behind mu.
+            Matcher alias = IMPORT_ALIAS_PATTERN.matcher(moduleName);
+            if (alias.matches()) {
+                Import.importModule(alias.group(1), alias.group(2), environment);
+                return;
+            }
             Import.importModule(moduleName, environment);
             return;
         }
@@ -359,66 +580,56 @@ public class Parser {
 
         // Handle @map statements
         if (line.startsWith("@map")) {
-            Executor executor = new Executor(environment);
             parseMapOperation(line, executor);
             return;
         }
 
         // Regex to match console.write statements
-        Pattern pattern = Pattern.compile("console.write\\((.*)\\);");
-        Matcher matcher = pattern.matcher(line);
+        Matcher matcher = CONSOLE_WRITE_PATTERN.matcher(line);
         if (matcher.matches()) {
             String expression = matcher.group(1);
-            Executor executor = new Executor(environment);
             executor.execute("console.write(" + expression + ")");
             return;
         }
 
         // Regex to match console.writef statements
-        Pattern writefPattern = Pattern.compile("console.writef\\((.*)\\);");
-        Matcher writefMatcher = writefPattern.matcher(line);
+        Matcher writefMatcher = CONSOLE_WRITEF_PATTERN.matcher(line);
         if (writefMatcher.matches()) {
             String expression = writefMatcher.group(1);
-            Executor executor = new Executor(environment);
             executor.execute("console.writef(" + expression + ")");
             return;
         }
 
         // Regex to match io::print and io::println statements
-        Pattern ioPattern = Pattern.compile("io::(print|println)\\((.*)\\);");
-        Matcher ioMatcher = ioPattern.matcher(line);
+        Matcher ioMatcher = IO_PRINT_PATTERN.matcher(line);
         if (ioMatcher.matches()) {
             String functionName = "io::" + ioMatcher.group(1);
             String args = ioMatcher.group(2).trim();
-            Executor executor = new Executor(environment);
             if (args.isEmpty()) {
                 executor.executeFunction(functionName, new String[0]);
             } else {
-                executor.executeFunction(functionName, args.split("\\s*,\\s*"));
+                executor.executeFunction(functionName, COMMA_SEPARATOR_PATTERN.split(args));
             }
             return;
         }
 
         // Function call
-        Pattern callPattern = Pattern.compile("([\\w:]+)\\((.*)\\);");
-        Matcher callMatcher = callPattern.matcher(line);
+        Matcher callMatcher = CALL_PATTERN.matcher(line);
         if (callMatcher.matches()) {
             String functionName = callMatcher.group(1);
             String args = callMatcher.group(2).trim();
-            Executor executor = new Executor(environment);
             if (args.isEmpty()) {
                 executor.executeFunction(functionName, new String[0]);
             }
             
             else {
-                executor.executeFunction(functionName, args.split("\\s*,\\s*"));
+                executor.executeFunction(functionName, COMMA_SEPARATOR_PATTERN.split(args));
             }
             return;
         }
 
         // Variable or boolean declaration (including letexpr for struct instances)
         if (line.startsWith("var ") || line.startsWith("bool ") || line.startsWith("letexpr ")) {
-            Executor executor = new Executor(environment);
             executor.execute(line);
             return;
         }
@@ -427,15 +638,13 @@ public class Parser {
             int equalsIndex = line.indexOf('=');
             String varName = line.substring(0, equalsIndex).trim();
             String valueExpression = line.substring(equalsIndex + 1).trim().replace(";", "");
-            Executor executor = new Executor(environment);
             executor.execute(varName + " = " + valueExpression);
         }
     }
 
     private void parseClass(int start, int end) {
         String header = lines.get(start).trim();
-        Pattern classPattern = Pattern.compile("class\\s+(\\w+)\\s*\\{");
-        Matcher classMatcher = classPattern.matcher(header);
+        Matcher classMatcher = CLASS_PATTERN.matcher(header);
         
         if (!classMatcher.find()) {
             throw new RuntimeException("Invalid class declaration syntax");
@@ -464,8 +673,7 @@ public class Parser {
         String header = lines.get(start).trim();
         
         // Parse method declaration: function name(params) -> returnType {
-        Pattern methodPattern = Pattern.compile("function\\s+(\\w+)\\s*\\(([^)]*)\\)\\s*(->\\s*(\\w+))?\\s*\\{");
-        Matcher methodMatcher = methodPattern.matcher(header);
+        Matcher methodMatcher = METHOD_PATTERN.matcher(header);
         
         if (!methodMatcher.find()) {
             throw new RuntimeException("Invalid method declaration syntax: " + header);
@@ -497,20 +705,20 @@ public class Parser {
         
         // Create and return the method
         Function method = new Function(methodName, parameters, returnType, body);
+        method.setDocs(docComment(lines, start));
         targetClass.addMethod(method);
     }
 
     private void parseProperty(Class targetClass, String declaration) {
         // Parse property declaration: var name: type = defaultValue
-        Pattern propPattern = Pattern.compile("(var|bool)\\s+(\\w+)\\s*:\\s*(\\w+)\\s*=\\s*(.+)");
-        Matcher propMatcher = propPattern.matcher(declaration);
+        Matcher propMatcher = PROPERTY_PATTERN.matcher(declaration);
         
         if (propMatcher.find()) {
             String name = propMatcher.group(2);
             String type = propMatcher.group(3);
             String defaultValueExpr = propMatcher.group(4).replace(";", "");
             
-            Object defaultValue = new Executor(environment).evaluate(defaultValueExpr);
+            Object defaultValue = executor.evaluate(defaultValueExpr);
             Class.Property property = new Class.Property(name, type, defaultValue);
             targetClass.addProperty(property);
         } else {
@@ -518,10 +726,10 @@ public class Parser {
         }
     }
 
-    private void parseNamespace(int start, int end) {
+    // With definitionsOnly, the namespace's other statements are left for the second pass
+    private void parseNamespace(int start, int end, boolean definitionsOnly) {
         String header = lines.get(start).trim();
-        Pattern namespacePattern = Pattern.compile("namespace\\s+([A-Za-z_]\\w*)\\s*\\{");
-        Matcher namespaceMatcher = namespacePattern.matcher(header);
+        Matcher namespaceMatcher = NAMESPACE_PATTERN.matcher(header);
 
         if (!namespaceMatcher.matches()) {
             throw new RuntimeException("Invalid namespace declaration syntax: " + header);
@@ -537,7 +745,7 @@ public class Parser {
                 continue;
             }
 
-            if (line.matches("^(String|Int32|Int64|Float32|Float64|fn)\\s+\\w+\\s*\\(.*\\)\\s*\\{")) {
+            if (C_STYLE_FUNCTION_HEADER_PATTERN.matcher(line).matches()) {
                 int closingBraceIndex = findClosingBrace(i);
                 parseFunction(i, closingBraceIndex, prefix);
                 i = closingBraceIndex;
@@ -549,7 +757,7 @@ public class Parser {
                 int closingBraceIndex = findClosingBrace(i);
                 parseStruct(i, closingBraceIndex, prefix);
                 i = closingBraceIndex;
-            } else {
+            } else if (!definitionsOnly) {
                 parseLineWithNamespace(line, prefix);
             }
         }
@@ -590,8 +798,7 @@ public class Parser {
             return;
         }
 
-        Pattern namespacedCallPattern = Pattern.compile("(\\w+)\\((.*)\\);");
-        Matcher namespacedCallMatcher = namespacedCallPattern.matcher(trimmed);
+        Matcher namespacedCallMatcher = NAMESPACED_CALL_PATTERN.matcher(trimmed);
         if (namespacedCallMatcher.matches()) {
             String functionName = namespacedCallMatcher.group(1);
             if (!functionName.contains("::")) {
@@ -622,10 +829,18 @@ public class Parser {
     }
 
     private void parseStruct(int start, int end, String namePrefix) {
-        // Collect the struct definition lines
+        // Collect the struct definition lines; methods are read as functions
         List<String> structLines = new ArrayList<>();
-        for (int i = start; i <= end; i++) {
-            structLines.add(lines.get(i));
+        List<Function> methods = new ArrayList<>();
+        structLines.add(lines.get(start));
+        for (int i = start + 1; i <= end; i++) {
+            if (i < end && lines.get(i).trim().startsWith("function ")) {
+                int methodEnd = findClosingBrace(i);
+                methods.add(readFunction(i, methodEnd, ""));
+                i = methodEnd;
+            } else {
+                structLines.add(lines.get(i));
+            }
         }
         
         // Use Struct's parsing method
@@ -633,12 +848,37 @@ public class Parser {
         
         // Register the struct in the environment
         if (!namePrefix.isEmpty()) {
-            structDef = new Struct(namePrefix + structDef.getName(), structDef.getFields());
+            structDef = structDef.withName(namePrefix + structDef.getName());
+        }
+        for (Function method : methods) {
+            structDef.addMethod(method);
+        }
+
+        // A struct that declares an interface must define all of its methods
+        for (String interfaceName : structDef.getInterfaces()) {
+            Interface contract = environment.getInterface(interfaceName);
+            if (contract == null) {
+                throw new RuntimeException("Unknown interface '" + interfaceName + "' in struct " + structDef.getName());
+            }
+            List<String> missing = contract.missingMethods(structDef);
+            if (!missing.isEmpty()) {
+                throw new RuntimeException("Struct " + structDef.getName() + " does not implement " + interfaceName
+                    + ": missing " + String.join(", ", missing));
+            }
         }
 
         environment.defineStruct(structDef);
     }
 
+    /**
+     * Parse an interface definition
+     * @param start The index of the line with the interface keyword
+     * @param end The index of the closing brace
+     */
+    private void parseInterface(int start, int end) {
+        environment.defineInterface(Interface.parseInterfaceDefinition(lines.subList(start, end + 1), 0));
+    }
+
 
     /**
      * Creates a unary lambda function from a string expression
@@ -647,7 +887,11 @@ public class Parser {
         return (arg) -> {
             Environment localEnv = new Environment(executor.getEnvironment());
             localEnv.setVariable("it", arg);  // Use 'it' as default parameter name
-            return new Executor(localEnv).evaluate(lambda);
+            try {
+                return new Executor(localEnv).evaluate(lambda);
+            } finally {
+                localEnv.release();
+            }
         };
     }
 
@@ -658,8 +902,12 @@ public class Parser {
         return (arg) -> {
             Environment localEnv = new Environment(executor.getEnvironment());
             localEnv.setVariable("it", arg);
-            Object result = new Executor(localEnv).evaluate(lambda);
-            return result instanceof Boolean ? (Boolean) result : false;
+            try {
+                Object result = new Executor(localEnv).evaluate(lambda);
+                return result instanceof Boolean ? (Boolean) result : false;
+            } finally {
+                localEnv.release();
+            }
         };
     }
 
@@ -671,7 +919,11 @@ public class Parser {
             Environment localEnv = new Environment(executor.getEnvironment());
             localEnv.setVariable("acc", arg1);  // First argument is accumulator
             localEnv.setVariable("it", arg2);   // Second argument is current item
-            return new Executor(localEnv).evaluate(lambda);
+            try {
+                return new Executor(localEnv).evaluate(lambda);
+            } finally {
+                localEnv.release();
+            }
         };
     }
     
@@ -679,7 +931,6 @@ public class Parser {
      * Parse @__globalfn__ block containing higher-order function operations
      */
     public void parseGlobalFunctionBlock(int start, int end) {
-        Executor executor = new Executor(environment);
         
         for (int i = start + 1; i < end; i++) {
             String line = lines.get(i).trim();
@@ -705,8 +956,7 @@ public class Parser {
      */
     public void parseMapOperation(String line, Executor executor) {
         // Pattern: @map => (operation) [list]
-        Pattern mapPattern = Pattern.compile("@map\\s*=>\\s*(\\([^)]+\\))\\s*\\[([^\\]]+)\\]");
-        Matcher matcher = mapPattern.matcher(line);
+        Matcher matcher = MAP_PATTERN.matcher(line);
         
         if (!matcher.find()) {
             throw new RuntimeException("Invalid @map syntax: " + line);
@@ -717,7 +967,7 @@ public class Parser {
         
         // Parse the list elements
         List<Object> list = new ArrayList<>();
-        String[] elements = listExpression.split("\\s*,\\s*");
+        String[] elements = COMMA_SEPARATOR_PATTERN.split(listExpression);
         
         for (String element : elements) {
             if (!element.trim().isEmpty()) {
@@ -0,0 +1,79 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.lang.management.ManagementFactory;
+import java.util.ArrayList;
+import java.util.List;
+
+/**
+ * Measures time and allocations for parsing and running a generated script.
+ * Usage: java com.magayaga.microscript.ParserBenchmark [lines] [runs]
+ */
+public class ParserBenchmark {
+    private static final int DEFAULT_LINES = 10_000;
+    private static final int DEFAULT_RUNS = 20;
+    private static final int WARMUP_RUNS = 5;
+
+    public static void main(String[] args) {
+        int lineCount = args.length > 0 ? Integer.parseInt(args[0]) : DEFAULT_LINES;
+        int runs = args.length > 1 ? Integer.parseInt(args[1]) : DEFAULT_RUNS;
+        List<String> script = generateScript(lineCount);
+
+        for (int i = 0; i < WARMUP_RUNS; i++) {
+            runOnce(script);
+        }
+
+        com.sun.management.ThreadMXBean threads =
+            (com.sun.management.ThreadMXBean) ManagementFactory.getThreadMXBean();
+        long threadId = Thread.currentThread().getId();
+
+        long startBytes = threads.getThreadAllocatedBytes(threadId);
+        long startTime = System.nanoTime();
+        for (int i = 0; i < runs; i++) {
+            runOnce(script);
+        }
+        long elapsed = System.nanoTime() - startTime;
+        long allocated = threads.getThreadAllocatedBytes(threadId) - startBytes;
+
+        System.out.println("lines:      " + script.size());
+        System.out.println("runs:       " + runs);
+        System.out.printf("ms/run:     %.2f%n", elapsed / 1e6 / runs);
+        System.out.printf("ns/line:    %.0f%n", (double) elapsed / runs / script.size());
+        System.out.printf("bytes/run:  %d%n", allocated / runs);
+        System.out.printf("bytes/line: %d%n", allocated / runs / script.size());
+    }
+
+    private static void runOnce(List<String> script) {
+        new Parser(new Optimizer().optimize(new Define().preprocess(script))).parse();
+    }
+
+    /**
+     * Builds a script mixing declarations, assignments and function calls,
+     * without console output so only the interpreter is measured.
+     */
+    static List<String> generateScript(int lineCount) {
+        List<String> lines = new ArrayList<>();
+        lines.add("function add(a: Int32, b: Int32) -> Int32 {");
+        lines.add("    return a + b;");
+        lines.add("}");
+        for (int i = 0; lines.size() < lineCount; i++) {
+            switch (i % 3) {
+                case 0:
+                    lines.add("var v" + i + ": Int32 = " + i + " + 1;");
+                    break;
+                case 1:
+                    lines.add("v" + (i - 1) + " = v" + (i - 1) + " * 2;");
+                    break;
+                default:
+                    lines.add("add(" + i + ", 1);");
+                    break;
+            }
+        }
+        return lines;
+    }
+}
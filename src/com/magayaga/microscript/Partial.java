@@ -0,0 +1,70 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.List;
+
+/**
+ * A function with its first arguments already given:
+ *
+ *   var add5 = add.bind(5);
+ *   var greet = partial(format, "Hello", "!");
+ *   console.write(add5(10));
+ *
+ * Calling it passes the bound arguments followed by the ones in the call.
+ * Anything callable can be bound: script functions, lambdas, native module
+ * functions and other partials.
+ */
+public class Partial implements Import.FunctionInterface {
+    private final Object target;
+    private final List<Object> bound;
+    // The root, since the scope bind was called in may be gone by the time the partial is
+    private final Environment environment;
+
+    private Partial(Object target, List<Object> bound, Environment environment) {
+        this.target = target;
+        this.bound = bound;
+        this.environment = environment;
+    }
+
+    /**
+     * Binds arguments to a script function or native function, checking there
+     * aren't more than a script function takes
+     */
+    public static Partial of(Object target, List<Object> arguments, Environment environment) {
+        if (target instanceof Function) {
+            Function function = (Function) target;
+            int expected = function.getParameters().size();
+            if (arguments.size() > expected) {
+                throw new RuntimeException("Cannot bind " + arguments.size() + " arguments to " + function.getSignature()
+                    + ", which takes " + expected);
+            }
+        } else if (!(target instanceof Import.FunctionInterface)) {
+            throw new RuntimeException("Only functions can be bound, got " + Builtins.describe(target));
+        }
+        // Lambdas still run in their closure and module functions in their module
+        return new Partial(target, new ArrayList<>(arguments), environment.getRoot());
+    }
+
+    @Override
+    public Object call(Object[] args) {
+        List<Object> values = new ArrayList<>(bound);
+        values.addAll(Arrays.asList(args));
+        if (target instanceof Function) {
+            return new Executor(environment).call((Function) target, values.toArray());
+        }
+        return ((Import.FunctionInterface) target).call(values.toArray());
+    }
+
+    @Override
+    public String toString() {
+        String name = target instanceof Function ? ((Function) target).getName() : target.toString();
+        return "partial(" + name + ", " + bound.size() + (bound.size() == 1 ? " argument)" : " arguments)");
+    }
+}
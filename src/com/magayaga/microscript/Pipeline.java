@@ -0,0 +1,214 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.List;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * The pipeline operator, which passes a value to a function:
+ *
+ *   var total = prices |> discount |> round(2);
+ *
+ * is rewritten before the script runs to
+ *
+ *   var total = round(discount(prices), 2);
+ *
+ * Each stage is a function name, which is called with the value, or a call,
+ * which gets the value as its first argument. The value is everything before
+ * the first |> back to the start of the expression: an opening bracket, a
+ * comma, an assignment, a lambda's => or a return.
+ */
+public class Pipeline {
+    private static final String OPERATOR = "|>";
+    private static final Pattern STAGE_PATTERN = Pattern.compile("[A-Za-z_]\\w*(?:(?:::|\\.)[A-Za-z_]\\w*)*");
+    private static final Pattern KEYWORD_PATTERN = Pattern.compile("^(\\s*(?:return|defer|spawn|await)\\s+)");
+
+    private Pipeline() {
+    }
+
+    /**
+     * Rewrites the pipelines in every line, keeping the line count
+     */
+    public static List<String> desugar(List<String> lines) {
+        List<String> output = new ArrayList<>(lines.size());
+        for (String line : lines) {
+            output.add(desugar(line));
+        }
+        return output;
+    }
+
+    /**
+     * Rewrites the pipelines in a line into nested calls, innermost first
+     */
+    public static String desugar(String line) {
+        int operator;
+        while ((operator = find(line)) >= 0) {
+            line = rewrite(line, operator);
+        }
+        return line;
+    }
+
+    // The first |> outside strings and comments, or -1
+    private static int find(String line) {
+        boolean[] code = code(line);
+        for (int i = 0; i + 1 < line.length(); i++) {
+            if (code[i] && line.startsWith(OPERATOR, i)) {
+                return i;
+            }
+        }
+        return -1;
+    }
+
+    private static String rewrite(String line, int operator) {
+        boolean[] code = code(line);
+
+        // Walk back to where the piped value starts
+        int start = operator;
+        int depth = 0;
+        while (start > 0) {
+            int i = start - 1;
+            char c = line.charAt(i);
+            if (code[i]) {
+                if (c == ')' || c == ']' || c == '}') {
+                    depth++;
+                } else if (c == '(' || c == '[' || c == '{') {
+                    if (depth == 0) {
+                        break;
+                    }
+                    depth--;
+                } else if (depth == 0 && boundary(line, i)) {
+                    break;
+                }
+            }
+            start = i;
+        }
+        Matcher keyword = KEYWORD_PATTERN.matcher(line.substring(start, operator));
+        if (keyword.find()) {
+            start += keyword.end();
+        }
+        while (start < operator && Character.isWhitespace(line.charAt(start))) {
+            start++;
+        }
+        String value = line.substring(start, operator).trim();
+        if (value.isEmpty()) {
+            throw new RuntimeException("Pipeline has no value before |>: " + line.trim());
+        }
+
+        // Apply each stage in turn
+        int pos = operator;
+        while (line.startsWith(OPERATOR, pos)) {
+            pos = skipSpaces(line, pos + OPERATOR.length());
+            Matcher stage = STAGE_PATTERN.matcher(line);
+            stage.region(pos, line.length());
+            if (!stage.lookingAt()) {
+                throw new RuntimeException("A pipeline stage must be a function or a call, as in value |> f or value |> g(2): "
+                    + line.trim());
+            }
+            String callee = stage.group();
+            pos = stage.end();
+            int open = skipSpaces(line, pos);
+            if (open < line.length() && line.charAt(open) == '(') {
+                int close = closing(line, code, open);
+                if (close < 0) {
+                    throw new RuntimeException("Missing closing parenthesis in pipeline stage " + callee + ": " + line.trim());
+                }
+                String arguments = line.substring(open + 1, close).trim();
+                value = callee + "(" + value + (arguments.isEmpty() ? "" : ", " + arguments) + ")";
+                pos = close + 1;
+            } else {
+                value = callee + "(" + value + ")";
+            }
+            int next = skipSpaces(line, pos);
+            if (line.startsWith(OPERATOR, next) && code[next]) {
+                pos = next;
+            }
+        }
+        return line.substring(0, start) + value + line.substring(pos);
+    }
+
+    /**
+     * Whether the character ends the expression a pipeline's value is in:
+     * a comma or semicolon, an assignment such as = or +=, a lambda's =>,
+     * or the ? and : of a conditional or a named argument
+     */
+    private static boolean boundary(String line, int i) {
+        char c = line.charAt(i);
+        char previous = i > 0 ? line.charAt(i - 1) : ' ';
+        char next = i + 1 < line.length() ? line.charAt(i + 1) : ' ';
+        switch (c) {
+            case ',':
+            case ';':
+                return true;
+            case '=':
+                return next != '=' && previous != '=' && previous != '!' && previous != '<' && previous != '>';
+            case '>':
+                return previous == '=';
+            case '?':
+                return next != '?' && previous != '?';
+            case ':':
+                return next != ':' && previous != ':';
+            default:
+                return false;
+        }
+    }
+
+    // The closing parenthesis matching the one at open, or -1
+    private static int closing(String line, boolean[] code, int open) {
+        int depth = 0;
+        for (int i = open; i < line.length(); i++) {
+            if (!code[i]) {
+                continue;
+            }
+            char c = line.charAt(i);
+            if (c == '(' || c == '[' || c == '{') {
+                depth++;
+            } else if (c == ')' || c == ']' || c == '}') {
+                depth--;
+                if (depth == 0) {
+                    return i;
+                }
+            }
+        }
+        return -1;
+    }
+
+    /**
+     * Marks the characters that are code rather than part of a string or
+     * character literal or a // comment
+     */
+    private static boolean[] code(String line) {
+        boolean[] code = new boolean[line.length()];
+        char quote = 0;
+        for (int i = 0; i < line.length(); i++) {
+            char c = line.charAt(i);
+            if (quote != 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == quote) {
+                    quote = 0;
+                }
+            } else if (c == '"' || c == '\'') {
+                quote = c;
+            } else if (line.startsWith("//", i) && Checker.startsComment(line, i)) {
+                break;
+            } else {
+                code[i] = true;
+            }
+        }
+        return code;
+    }
+
+    private static int skipSpaces(String line, int i) {
+        while (i < line.length() && Character.isWhitespace(line.charAt(i))) {
+            i++;
+        }
+        return i;
+    }
+}
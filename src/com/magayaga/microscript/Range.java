@@ -0,0 +1,111 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.AbstractList;
+
+/**
+ * A run of whole numbers:
+ *
+ *   for (var i : 1..10) { ... }      // 1 to 10
+ *   var firstThree = xs[0..<3];      // xs[0:3]
+ *   if (age in 18..65) { ... }
+ *
+ * a..b includes b and a..<b stops before it. The numbers are worked out as
+ * they are needed rather than stored, so 1..1000000 takes no more room than
+ * 1..2. A range whose end is before its start is empty.
+ */
+public class Range extends AbstractList<Object> {
+    private final long start;
+    private final long end; // Exclusive
+    private final String text;
+
+    private Range(long start, long end, String text) {
+        this.start = start;
+        this.end = end;
+        this.text = text;
+    }
+
+    /**
+     * The range from start to end, which must be whole numbers; end is left
+     * out when inclusive is false
+     */
+    public static Range of(Object start, Object end, boolean inclusive) {
+        String operator = inclusive ? ".." : "..<";
+        long first = bound(start, operator);
+        long last = bound(end, operator);
+        String text = first + operator + last;
+        if (inclusive) {
+            if (last == Long.MAX_VALUE) {
+                throw new RuntimeException("Range end " + last + " is too large");
+            }
+            last++;
+        }
+        return new Range(first, Math.max(first, last), text);
+    }
+
+    private static long bound(Object value, String operator) {
+        if (!(value instanceof Number)) {
+            throw new RuntimeException("Type error: " + operator + " needs whole numbers, got " + Builtins.describe(value));
+        }
+        double number = ((Number) value).doubleValue();
+        if (number != Math.rint(number) || Double.isInfinite(number)) {
+            throw new RuntimeException("Type error: " + operator + " needs whole numbers, got " + value);
+        }
+        return ((Number) value).longValue();
+    }
+
+    public long getStart() {
+        return start;
+    }
+
+    /**
+     * The first number after the range
+     */
+    public long getEnd() {
+        return end;
+    }
+
+    /**
+     * Whether the range holds the number, without going through it
+     */
+    public boolean includes(Object item) {
+        if (!(item instanceof Number)) {
+            return false;
+        }
+        double number = ((Number) item).doubleValue();
+        return number == Math.rint(number) && number >= start && number < end;
+    }
+
+    @Override
+    public Object get(int index) {
+        if (index < 0 || index >= size()) {
+            throw new IndexOutOfBoundsException("Range index " + index + " out of range for length " + size());
+        }
+        long value = start + index;
+        return value >= Integer.MIN_VALUE && value <= Integer.MAX_VALUE ? (Object) (int) value : (Object) value;
+    }
+
+    @Override
+    public int size() {
+        long size = end - start;
+        if (size > Integer.MAX_VALUE || size < 0) {
+            throw new RuntimeException("Range " + this + " is too long");
+        }
+        return (int) size;
+    }
+
+    @Override
+    public boolean contains(Object item) {
+        return includes(item);
+    }
+
+    @Override
+    public String toString() {
+        return text;
+    }
+}
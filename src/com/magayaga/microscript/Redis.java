@@ -0,0 +1,307 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedInputStream;
+import java.io.ByteArrayOutputStream;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.net.InetSocketAddress;
+import java.net.Socket;
+import java.nio.charset.StandardCharsets;
+import java.util.List;
+import java.util.Map;
+import java.util.concurrent.ConcurrentHashMap;
+
+/**
+ * A connection to a Redis server for the redis module, speaking the RESP
+ * protocol over a plain socket:
+ *
+ *   import redis
+ *   var cache = redis::connect("localhost", 6379);
+ *   redis::set(cache, "greeting", "hello", 60);
+ *   console.write(redis::get(cache, "greeting"));
+ *   redis::subscribe(cache, "events", "onEvent");
+ *   redis::wait(cache);
+ *
+ * Commands on a connection run one at a time. Subscriptions use a second
+ * connection, opened on the first subscribe; each message calls the named
+ * script function with the channel and the message on the event loop, which
+ * runs while redis::wait blocks.
+ */
+public class Redis implements AutoCloseable {
+    private static final int CONNECT_TIMEOUT = 5000;
+
+    private final String host;
+    private final int port;
+    private final String password;
+    private final Socket socket;
+    private final InputStream input;
+    private final OutputStream output;
+    private Subscriber subscriber;
+
+    private Redis(String host, int port, String password) throws IOException {
+        this.host = host;
+        this.port = port;
+        this.password = password;
+        this.socket = new Socket();
+        socket.connect(new InetSocketAddress(host, port), CONNECT_TIMEOUT);
+        this.input = new BufferedInputStream(socket.getInputStream());
+        this.output = socket.getOutputStream();
+        if (password != null) {
+            command("AUTH", password);
+        }
+    }
+
+    /**
+     * Connects to the server, signing in when a password is given
+     */
+    public static Redis connect(String host, int port, String password) {
+        try {
+            return new Redis(host, port, password);
+        } catch (IOException e) {
+            throw new RuntimeException("redis: cannot connect to " + host + ":" + port + ": " + e.getMessage());
+        }
+    }
+
+    /**
+     * Sends a command and returns its reply: a String, an Int64, a list of
+     * replies, or null for a missing value. An error reply is thrown.
+     */
+    public synchronized Object command(String... arguments) {
+        try {
+            write(output, arguments);
+            return read(input);
+        } catch (IOException e) {
+            throw new RuntimeException("redis: " + arguments[0] + " failed: " + e.getMessage());
+        }
+    }
+
+    /**
+     * Calls the named function with (channel, message) for each message
+     * published to the channel
+     */
+    public void subscribe(String channel, String functionName, Environment environment) {
+        Subscriber current;
+        synchronized (this) {
+            if (subscriber == null || !subscriber.isAlive()) {
+                try {
+                    subscriber = new Subscriber(new Redis(host, port, password), environment);
+                } catch (IOException e) {
+                    throw new RuntimeException("redis: cannot open a subscription connection: " + e.getMessage());
+                }
+                subscriber.start();
+            }
+            current = subscriber;
+        }
+        current.subscribe(channel, functionName);
+    }
+
+    public void unsubscribe(String channel) {
+        Subscriber current;
+        synchronized (this) {
+            current = subscriber;
+        }
+        if (current != null) {
+            current.unsubscribe(channel);
+        }
+    }
+
+    /**
+     * Blocks until every subscription has ended, running callbacks meanwhile
+     */
+    public void await(Environment environment) {
+        Subscriber current;
+        synchronized (this) {
+            current = subscriber;
+        }
+        if (current == null) {
+            return;
+        }
+        environment.getEventLoop().runWhile(current::isAlive, environment.getCancellation());
+    }
+
+    @Override
+    public void close() {
+        Subscriber current;
+        synchronized (this) {
+            current = subscriber;
+            subscriber = null;
+        }
+        if (current != null) {
+            current.connection.closeSocket();
+        }
+        closeSocket();
+    }
+
+    private void closeSocket() {
+        try {
+            socket.close();
+        } catch (IOException e) {
+            // Already closed
+        }
+    }
+
+    @Override
+    public String toString() {
+        return "redis(" + host + ":" + port + ")";
+    }
+
+    // Every command is an array of bulk strings: *2\r\n$3\r\nGET\r\n$3\r\nkey\r\n
+    private static void write(OutputStream output, String... arguments) throws IOException {
+        ByteArrayOutputStream buffer = new ByteArrayOutputStream();
+        buffer.write(("*" + arguments.length + "\r\n").getBytes(StandardCharsets.UTF_8));
+        for (String argument : arguments) {
+            byte[] bytes = argument.getBytes(StandardCharsets.UTF_8);
+            buffer.write(("$" + bytes.length + "\r\n").getBytes(StandardCharsets.UTF_8));
+            buffer.write(bytes);
+            buffer.write('\r');
+            buffer.write('\n');
+        }
+        output.write(buffer.toByteArray());
+        output.flush();
+    }
+
+    private static Object read(InputStream input) throws IOException {
+        int type = input.read();
+        if (type == -1) {
+            throw new IOException("connection closed by the server");
+        }
+        String line = readLine(input);
+        switch (type) {
+            case '+':
+                return line;
+            case '-':
+                throw new RuntimeException("redis: " + line);
+            case ':':
+                return Long.parseLong(line);
+            case '$': {
+                int length = Integer.parseInt(line);
+                if (length < 0) {
+                    return null;
+                }
+                byte[] bytes = new byte[length];
+                int offset = 0;
+                while (offset < length) {
+                    int count = input.read(bytes, offset, length - offset);
+                    if (count == -1) {
+                        throw new IOException("connection closed by the server");
+                    }
+                    offset += count;
+                }
+                readLine(input);
+                return new String(bytes, StandardCharsets.UTF_8);
+            }
+            case '*': {
+                int count = Integer.parseInt(line);
+                if (count < 0) {
+                    return null;
+                }
+                ListVariable items = new ListVariable();
+                for (int i = 0; i < count; i++) {
+                    items.add(read(input));
+                }
+                return items;
+            }
+            default:
+                throw new IOException("unexpected reply type '" + (char) type + "'");
+        }
+    }
+
+    private static String readLine(InputStream input) throws IOException {
+        ByteArrayOutputStream line = new ByteArrayOutputStream();
+        int c;
+        while ((c = input.read()) != -1) {
+            if (c == '\r') {
+                input.read(); // \n
+                break;
+            }
+            line.write(c);
+        }
+        return new String(line.toByteArray(), StandardCharsets.UTF_8);
+    }
+
+    /**
+     * Reads pushed messages from the subscription connection until its last
+     * channel is unsubscribed or the connection closes
+     */
+    private static class Subscriber extends Thread {
+        private final Redis connection;
+        private final Environment environment;
+        // Channel -> script function called for its messages
+        private final Map<String, String> handlers = new ConcurrentHashMap<>();
+
+        Subscriber(Redis connection, Environment environment) {
+            super("redis-subscriber");
+            setDaemon(true);
+            this.connection = connection;
+            this.environment = environment;
+        }
+
+        void subscribe(String channel, String functionName) {
+            handlers.put(channel, functionName);
+            send("SUBSCRIBE", channel);
+        }
+
+        void unsubscribe(String channel) {
+            send("UNSUBSCRIBE", channel);
+        }
+
+        // Replies arrive on this thread, so only the command is written here
+        private void send(String command, String channel) {
+            synchronized (connection) {
+                try {
+                    write(connection.output, command, channel);
+                } catch (IOException e) {
+                    throw new RuntimeException("redis: " + command + " failed: " + e.getMessage());
+                }
+            }
+        }
+
+        @Override
+        public void run() {
+            try {
+                while (true) {
+                    Object reply = read(connection.input);
+                    if (!(reply instanceof List) || ((List<?>) reply).size() < 3) {
+                        continue;
+                    }
+                    List<?> push = (List<?>) reply;
+                    String kind = String.valueOf(push.get(0));
+                    String channel = String.valueOf(push.get(1));
+                    if (kind.equals("message")) {
+                        String functionName = handlers.get(channel);
+                        if (functionName != null) {
+                            call(functionName, channel, push.get(2));
+                        }
+                    } else if (kind.equals("unsubscribe")) {
+                        handlers.remove(channel);
+                        if (push.get(2) instanceof Long && (Long) push.get(2) == 0) {
+                            break;
+                        }
+                    }
+                }
+            } catch (IOException | RuntimeException e) {
+                // The connection was closed
+            } finally {
+                connection.closeSocket();
+            }
+        }
+
+        // Messages keep arriving while the callback waits its turn on the event loop
+        private void call(String functionName, Object... values) {
+            environment.getEventLoop().post(() -> {
+                try {
+                    new Executor(environment).callFunction(functionName, values);
+                } catch (RuntimeException e) {
+                    System.err.println("Error in handler " + functionName + ": " + e.getMessage());
+                }
+            });
+        }
+    }
+}
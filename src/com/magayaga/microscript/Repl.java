@@ -0,0 +1,134 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.Collection;
+import java.util.List;
+import java.util.Set;
+import java.util.TreeSet;
+
+/**
+ * An interactive prompt that runs each entry as it is typed.
+ * Usage:
+ *   microscript repl
+ *
+ * Globals, functions and macros carry over from one entry to the next. An
+ * entry whose braces, parentheses or brackets are still open continues on
+ * the next line, at a "..." prompt, until they are closed. Entries are
+ * saved to ~/.microscript_history; Tab completes keywords, builtins and the
+ * variables and functions defined so far. Ctrl-C discards the entry being
+ * typed and Ctrl-D on an empty line exits.
+ */
+public class Repl {
+    private static final String PROMPT = "> ";
+    private static final String CONTINUATION_PROMPT = "... ";
+    private static final String HISTORY_FILE = ".microscript_history";
+
+    private final Interpreter interpreter = new Interpreter();
+    private final LineEditor editor;
+
+    private Repl() {
+        Path history = Paths.get(System.getProperty("user.home"), HISTORY_FILE);
+        this.editor = new LineEditor(history, () -> completions(interpreter.getEnvironment()));
+    }
+
+    public static void main(String[] args) {
+        new Repl().loop();
+    }
+
+    private void loop() {
+        if (editor.isTerminal()) {
+            System.out.println(Cli.VERSION + " (Ctrl-D to exit)");
+        }
+        StringBuilder entry = new StringBuilder();
+        while (true) {
+            String line;
+            try {
+                line = editor.readLine(entry.length() == 0 ? PROMPT : CONTINUATION_PROMPT);
+            } catch (LineEditor.Interrupted e) {
+                entry.setLength(0);
+                continue;
+            } catch (IOException e) {
+                System.err.println("Error reading input: " + e.getMessage());
+                return;
+            }
+            if (line == null) {
+                if (entry.length() > 0) {
+                    run(entry.toString());
+                }
+                return;
+            }
+            editor.addHistory(line);
+            entry.append(line).append('\n');
+            if (depth(entry) > 0) {
+                continue;
+            }
+            run(entry.toString());
+            entry.setLength(0);
+        }
+    }
+
+    private void run(String source) {
+        try {
+            interpreter.run(source);
+        } catch (ScriptException e) {
+            // Lines only help once an entry spans several
+            boolean multiline = source.indexOf('\n') < source.length() - 1;
+            String where = multiline && e.getLine() >= 0 ? " at line " + (e.getLine() + 1) : "";
+            System.err.println("Error" + where + ": " + e.getMessage());
+            if (editor.isTerminal()) {
+                System.err.print(ErrorReport.render(List.of(source.split("\n", -1)), e,
+                    completions(interpreter.getEnvironment()), true));
+            }
+        } catch (RuntimeException | StackOverflowError e) {
+            System.err.println("Error: " + e.getMessage());
+        }
+        System.out.flush();
+    }
+
+    // How many braces, parentheses and brackets are open, outside strings and comments
+    static int depth(CharSequence source) {
+        int depth = 0;
+        char quote = 0;
+        for (int i = 0; i < source.length(); i++) {
+            char c = source.charAt(i);
+            if (quote != 0) {
+                if (c == '\\') {
+                    i++;
+                } else if (c == quote || c == '\n') {
+                    quote = 0;
+                }
+            } else if (c == '"' || c == '\'') {
+                quote = c;
+            } else if (c == '/' && i + 1 < source.length() && source.charAt(i + 1) == '/') {
+                while (i + 1 < source.length() && source.charAt(i + 1) != '\n') {
+                    i++;
+                }
+            } else if (c == '{' || c == '(' || c == '[') {
+                depth++;
+            } else if (c == '}' || c == ')' || c == ']') {
+                depth--;
+            }
+        }
+        return depth;
+    }
+
+    // Keywords, builtins, modules and what has been defined so far
+    static Collection<String> completions(Environment environment) {
+        Set<String> names = new TreeSet<>(LanguageServer.KEYWORDS);
+        names.addAll(LanguageServer.MODULES);
+        for (String signature : LanguageServer.BUILTINS.keySet()) {
+            names.add(signature.substring(0, signature.indexOf('(')));
+        }
+        names.addAll(environment.getFunctionNames());
+        names.addAll(environment.getLocals().keySet());
+        return names;
+    }
+}
@@ -1,24 +1,161 @@
 /**
  * MicroScript — The programming language
- * Copyright (c) 2024-2025 Cyril John Magayaga
- * 
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
  * It was originally written in Java programming language.
  */
 package com.magayaga.microscript;
 
+import java.io.BufferedInputStream;
+import java.io.BufferedReader;
+import java.io.Closeable;
 import java.io.IOException;
+import java.io.InputStream;
+import java.io.InputStreamReader;
+import java.io.UncheckedIOException;
+import java.nio.charset.CharacterCodingException;
+import java.nio.charset.Charset;
+import java.nio.charset.StandardCharsets;
 import java.nio.file.Files;
 import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.Iterator;
 import java.util.List;
+import java.util.NoSuchElementException;
 
+/**
+ * Reads MicroScript source files. Lines have no length limit; the buffer size
+ * only sets how much is read from disk at a time. Use lines() to stream a
+ * large generated file instead of holding all of it in memory.
+ *
+ * Files are read as UTF-8 unless they start with a UTF-16 byte order mark or
+ * look like UTF-16 text. A leading BOM is dropped, and CRLF and lone CR line
+ * endings split lines just like LF, so neither ends up inside a statement.
+ */
 public class Scanner {
+    public static final int DEFAULT_BUFFER_SIZE = 64 * 1024;
+
     private final String filePath;
+    private final int bufferSize;
 
     public Scanner(String filePath) {
+        this(filePath, DEFAULT_BUFFER_SIZE);
+    }
+
+    public Scanner(String filePath, int bufferSize) {
+        if (bufferSize <= 0) {
+            throw new IllegalArgumentException("Buffer size must be positive: " + bufferSize);
+        }
         this.filePath = filePath;
+        this.bufferSize = bufferSize;
     }
 
     public List<String> readLines() throws IOException {
-        return Files.readAllLines(Paths.get(filePath));
+        List<String> lines = new ArrayList<>();
+        try (Lines reader = lines()) {
+            while (reader.hasNext()) {
+                lines.add(reader.next());
+            }
+        } catch (UncheckedIOException e) {
+            throw e.getCause();
+        }
+        return lines;
+    }
+
+    /**
+     * Opens the file for reading one line at a time. Read errors surface as
+     * UncheckedIOException from hasNext; close the result when done.
+     */
+    public Lines lines() throws IOException {
+        InputStream input = new BufferedInputStream(Files.newInputStream(Paths.get(filePath)), bufferSize);
+        Charset charset;
+        try {
+            charset = detectEncoding(input);
+        } catch (IOException e) {
+            input.close();
+            throw e;
+        }
+        return new Lines(new BufferedReader(new InputStreamReader(input, charset.newDecoder()), bufferSize), charset);
+    }
+
+    /**
+     * Works out the file's encoding from its first bytes, consuming a byte
+     * order mark if there is one
+     */
+    private static Charset detectEncoding(InputStream input) throws IOException {
+        input.mark(3);
+        int first = input.read();
+        int second = input.read();
+        int third = input.read();
+        if (first == 0xEF && second == 0xBB && third == 0xBF) {
+            return StandardCharsets.UTF_8;
+        }
+        input.reset();
+        if (first == 0xFE && second == 0xFF) {
+            input.skip(2);
+            return StandardCharsets.UTF_16BE;
+        }
+        if (first == 0xFF && second == 0xFE) {
+            input.skip(2);
+            return StandardCharsets.UTF_16LE;
+        }
+        // Source text starts with ASCII, so a zero byte beside it means UTF-16 without a BOM
+        if (first == 0 && second > 0) {
+            return StandardCharsets.UTF_16BE;
+        }
+        if (first > 0 && second == 0) {
+            return StandardCharsets.UTF_16LE;
+        }
+        return StandardCharsets.UTF_8;
+    }
+
+    /**
+     * A streaming view of a source file's lines
+     */
+    public static class Lines implements Iterator<String>, Closeable {
+        private final BufferedReader reader;
+        private final Charset charset;
+        private String next;
+        private boolean done;
+        private int lineNumber;
+
+        Lines(BufferedReader reader, Charset charset) {
+            this.reader = reader;
+            this.charset = charset;
+        }
+
+        @Override
+        public boolean hasNext() {
+            if (next == null && !done) {
+                try {
+                    next = reader.readLine();
+                } catch (CharacterCodingException e) {
+                    throw new UncheckedIOException(new IOException("not valid " + charset.name()
+                        + " text near line " + (lineNumber + 1) + "; save it as UTF-8 or UTF-16", e));
+                } catch (IOException e) {
+                    throw new UncheckedIOException(e);
+                }
+                done = next == null;
+                if (!done) {
+                    lineNumber++;
+                }
+            }
+            return next != null;
+        }
+
+        @Override
+        public String next() {
+            if (!hasNext()) {
+                throw new NoSuchElementException();
+            }
+            String line = next;
+            next = null;
+            return line;
+        }
+
+        @Override
+        public void close() throws IOException {
+            reader.close();
+        }
     }
-} 
\ No newline at end of file
+}
@@ -0,0 +1,160 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.LinkedHashMap;
+import java.util.Map;
+
+/**
+ * A script error tied to the source line that raised it. The innermost
+ * statement wraps the original error, so the line points at the code that
+ * failed rather than at the call that led there.
+ */
+public class ScriptException extends RuntimeException {
+    // Message prefix -> code, checked in order
+    private static final String[][] CODES = {
+        {"Syntax error", "syntax"},
+        {"Missing", "syntax"},
+        {"Unexpected", "syntax"},
+        {"Unterminated", "syntax"},
+        {"Type error", "type"},
+        {"Undefined variable", "undefined-variable"},
+        {"Function not found", "undefined-function"},
+        {"Argument count mismatch", "argument-count"},
+        {"Module not found", "undefined-module"},
+        {"Macro expansion", "macro-expansion"},
+        {"Stack overflow", "stack-overflow"},
+        {"Internal error", "internal"},
+        {"Division by zero", "division-by-zero"},
+        {"Modulo by zero", "division-by-zero"},
+    };
+
+    private final int line;
+    // The 0-based column, or -1 when only the line is known
+    private final int column;
+    // Set when the failing line came from a macro expansion
+    private final Define.Expansion expansion;
+
+    /**
+     * @param line The 0-based source line
+     */
+    public ScriptException(String message, int line, Throwable cause) {
+        this(message, line, -1, cause, null);
+    }
+
+    public ScriptException(String message, int line, int column, Throwable cause) {
+        this(message, line, column, cause, null);
+    }
+
+    public ScriptException(String message, int line, Throwable cause, Define.Expansion expansion) {
+        this(message, line, -1, cause, expansion);
+    }
+
+    private ScriptException(String message, int line, int column, Throwable cause, Define.Expansion expansion) {
+        super(message, cause);
+        this.line = line;
+        this.column = column;
+        this.expansion = expansion;
+    }
+
+    /**
+     * Attaches a line to an error unless an inner statement already did.
+     * A failure inside the interpreter itself, such as an index out of bounds
+     * on input it didn't expect or recursion that exhausts the stack, becomes
+     * a script error at that line too, so a host embedding the interpreter
+     * keeps running.
+     */
+    public static ScriptException at(Throwable error, int line) {
+        if (error instanceof ScriptException) {
+            return (ScriptException) error;
+        }
+        return new ScriptException(message(error), line, error);
+    }
+
+    // Script errors are plain RuntimeExceptions; anything else keeps its Java type
+    // in the message, since the message alone is often empty or cryptic
+    private static String message(Throwable error) {
+        if (error.getClass() == RuntimeException.class) {
+            return error.getMessage();
+        }
+        if (error instanceof StackOverflowError) {
+            return "Stack overflow: too many nested calls";
+        }
+        if (error instanceof Statements.BreakException || error instanceof Statements.ContinueException) {
+            return "Break/continue statements are only allowed inside loops";
+        }
+        String name = error.getClass().getSimpleName();
+        return error.getMessage() == null ? "Internal error: " + name : "Internal error: " + name + ": " + error.getMessage();
+    }
+
+    public int getLine() {
+        return line;
+    }
+
+    public int getColumn() {
+        return column;
+    }
+
+    /**
+     * The macro expansion that produced the failing line, or null
+     */
+    public Define.Expansion getExpansion() {
+        return expansion;
+    }
+
+    /**
+     * The same error, pointing at the macro invocation that produced its line
+     */
+    public ScriptException withExpansion(Define.Expansion expansion) {
+        ScriptException error = new ScriptException(getMessage(), line, column, getCause(), expansion);
+        error.setStackTrace(getStackTrace());
+        return error;
+    }
+
+    /**
+     * A short, stable identifier for the kind of error
+     */
+    public String getCode() {
+        return code(getMessage());
+    }
+
+    public static String code(String message) {
+        if (message != null) {
+            for (String[] entry : CODES) {
+                if (message.startsWith(entry[0])) {
+                    return entry[1];
+                }
+            }
+        }
+        return "runtime";
+    }
+
+    /**
+     * The error as a diagnostic object: file, line, column, code, message, severity.
+     * Line and column are 1-based; both are null when the line isn't known.
+     * Errors in a macro expansion point at the invocation and carry a note
+     * with what it expanded to.
+     */
+    public static Map<String, Object> toDiagnostic(String file, Throwable error, String code) {
+        Integer line = error instanceof ScriptException && ((ScriptException) error).line >= 0
+            ? ((ScriptException) error).line + 1 : null;
+        Define.Expansion expansion = error instanceof ScriptException ? ((ScriptException) error).expansion : null;
+        int known = error instanceof ScriptException ? ((ScriptException) error).column : -1;
+        Integer column = line == null ? null : expansion != null ? expansion.getColumn() + 1 : known >= 0 ? known + 1 : 1;
+        Map<String, Object> diagnostic = new LinkedHashMap<>();
+        diagnostic.put("file", file);
+        diagnostic.put("line", line);
+        diagnostic.put("column", column);
+        diagnostic.put("code", code != null ? code : code(error.getMessage()));
+        diagnostic.put("message", error.getMessage());
+        diagnostic.put("severity", "error");
+        if (expansion != null) {
+            diagnostic.put("note", expansion.getNote());
+        }
+        return diagnostic;
+    }
+}
@@ -0,0 +1,131 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.Collections;
+import java.util.List;
+
+/**
+ * A body line, trimmed and classified once so that calls and loop
+ * iterations don't re-inspect the raw source every time they run.
+ * Statements line up one-to-one with the body lines, so block handlers can
+ * keep using body indices.
+ */
+public class Statement {
+    public enum Kind {
+        SKIP,           // empty line or comment
+        BREAK_CONTINUE, // break/continue outside of a loop
+        IF,
+        FOR,
+        WHILE,
+        MAP,
+        GLOBAL_FN,
+        SWITCH,
+        WITH,
+        RETURN,
+        OTHER
+    }
+
+    // closing before the brace has been looked for
+    private static final int UNMATCHED = -2;
+
+    private final Kind kind;
+    private final String text;
+    private final String expression;
+    private volatile int closing = UNMATCHED;
+
+    private Statement(Kind kind, String text, String expression) {
+        this.kind = kind;
+        this.text = text;
+        this.expression = expression;
+    }
+
+    public Kind getKind() {
+        return kind;
+    }
+
+    /**
+     * The trimmed source line
+     */
+    public String getText() {
+        return text;
+    }
+
+    /**
+     * The returned expression for RETURN statements
+     */
+    public String getExpression() {
+        return expression;
+    }
+
+    /**
+     * The index of the line closing the first block opened at or after
+     * index, as Braces.findClosing gives it, found once per statement
+     */
+    public int closingBrace(List<String> lines, int index) {
+        int line = closing;
+        if (line == UNMATCHED) {
+            line = Braces.findClosing(lines, index);
+            closing = line;
+        }
+        return line;
+    }
+
+    /**
+     * The statements of the lines holding a block: those compiled with the
+     * Body of the function or script they belong to, or compiled now for
+     * other lines, such as a single statement run on its own
+     */
+    public static List<Statement> of(List<String> lines) {
+        return lines instanceof Body ? ((Body) lines).getStatements() : compile(lines);
+    }
+
+    static List<Statement> compile(List<String> body) {
+        List<Statement> statements = new ArrayList<>(body.size());
+        for (String rawLine : body) {
+            statements.add(classify(rawLine.trim()));
+        }
+        return Collections.unmodifiableList(statements);
+    }
+
+    private static Statement classify(String line) {
+        if (line.isEmpty() || line.startsWith("//")) {
+            return new Statement(Kind.SKIP, line, null);
+        }
+        if (line.equals("break;") || line.equals("break") ||
+            line.equals("continue;") || line.equals("continue")) {
+            return new Statement(Kind.BREAK_CONTINUE, line, null);
+        }
+        if (line.startsWith("if")) {
+            return new Statement(Kind.IF, line, null);
+        }
+        if (line.startsWith("for")) {
+            return new Statement(Kind.FOR, line, null);
+        }
+        if (line.startsWith("while")) {
+            return new Statement(Kind.WHILE, line, null);
+        }
+        if (line.startsWith("@map")) {
+            return new Statement(Kind.MAP, line, null);
+        }
+        if (line.startsWith("@__globalfn__")) {
+            return new Statement(Kind.GLOBAL_FN, line, null);
+        }
+        if (line.startsWith("switch")) {
+            return new Statement(Kind.SWITCH, line, null);
+        }
+        if (With.isWith(line)) {
+            return new Statement(Kind.WITH, line, null);
+        }
+        if (line.startsWith("return")) {
+            String expression = line.substring(line.indexOf("return") + 6).trim().replace(";", "");
+            return new Statement(Kind.RETURN, line, expression);
+        }
+        return new Statement(Kind.OTHER, line, null);
+    }
+}
@@ -13,6 +13,12 @@ import java.util.regex.Pattern;
 
 public class Statements {
     
+    // Pre-compiled regex patterns
+    private static final Pattern IF_PATTERN = Pattern.compile("if\\s*\\((.+?)\\)\\s*(\\{)?");
+    private static final Pattern ELIF_PATTERN = Pattern.compile("elif\\s*\\((.+?)\\)\\s*(\\{)?");
+    private static final Pattern WHILE_PATTERN = Pattern.compile("while\\s*\\((.+?)\\)");
+    private static final Pattern FOR_PATTERN = Pattern.compile("for\\s*\\((.+?)\\)");
+    
     // Exception classes for loop control
     public static class BreakException extends RuntimeException {
         public BreakException() {
@@ -44,8 +50,7 @@ public class Statements {
         // Process the 'if' statement
         if (line != null && line.startsWith("if")) {
             // Extract condition from if statement with improved regex for complex conditions
-            Pattern ifPattern = Pattern.compile("if\\s*\\((.+?)\\)\\s*(\\{)?");
-            Matcher ifMatcher = ifPattern.matcher(line);
+            Matcher ifMatcher = IF_PATTERN.matcher(line);
             
             if (!ifMatcher.find()) {
                 throw new RuntimeException("Invalid if statement syntax at line: " + line);
@@ -96,8 +101,7 @@ public class Statements {
                 
                 // Handle 'elif' blocks with complex condition support
                 if (line.startsWith("elif")) {
-                    Pattern elifPattern = Pattern.compile("elif\\s*\\((.+?)\\)\\s*(\\{)?");
-                    Matcher elifMatcher = elifPattern.matcher(line);
+                    Matcher elifMatcher = ELIF_PATTERN.matcher(line);
                     
                     if (!elifMatcher.find()) {
                         throw new RuntimeException("Invalid elif statement syntax at line: " + line);
@@ -247,8 +251,7 @@ public class Statements {
         
         if (isWhileLoop) {
             // Extract while condition with support for complex expressions
-            Pattern whilePattern = Pattern.compile("while\\s*\\((.+?)\\)");
-            Matcher whileMatcher = whilePattern.matcher(loopDeclaration);
+            Matcher whileMatcher = WHILE_PATTERN.matcher(loopDeclaration);
             if (!whileMatcher.find()) {
                 throw new RuntimeException("Invalid while loop syntax: " + loopDeclaration);
             }
@@ -268,8 +271,7 @@ public class Statements {
             }
         } else if (isForLoop) {
             // Enhanced for loop implementation
-            Pattern forPattern = Pattern.compile("for\\s*\\((.+?)\\)");
-            Matcher forMatcher = forPattern.matcher(loopDeclaration);
+            Matcher forMatcher = FOR_PATTERN.matcher(loopDeclaration);
             if (!forMatcher.find()) {
                 throw new RuntimeException("Invalid for loop syntax: " + loopDeclaration);
             }
@@ -320,67 +322,7 @@ public class Statements {
      * @return The index of the line with the matching closing brace
      */
     private static int findMatchingClosingBrace(List<String> lines, int openingBraceLineIndex) {
-        int braceCount = 0;
-        boolean openingBraceFound = false;
-        
-        // Count opening brace on the first line
-        String firstLine = lines.get(openingBraceLineIndex).trim();
-        
-        // Check for opening brace and keep track of its position
-        int openingBracePos = firstLine.indexOf('{');
-        if (openingBracePos != -1) {
-            braceCount = 1;
-            openingBraceFound = true;
-            
-            // Check if there are any closing braces on the same line after the opening brace
-            for (int i = openingBracePos + 1; i < firstLine.length(); i++) {
-                if (firstLine.charAt(i) == '{') {
-                braceCount++;
-                } else if (firstLine.charAt(i) == '}') {
-                braceCount--;
-                    if (braceCount == 0) {
-                        return openingBraceLineIndex; // The block starts and ends on the same line
-                    }
-                }
-            }
-        } else {
-        // If opening brace wasn't found on the first line, return error
-            throw new RuntimeException("No opening brace found at line index: " + openingBraceLineIndex);
-        }
-        
-        // Process subsequent lines
-        for (int i = openingBraceLineIndex + 1; i < lines.size(); i++) {
-            String line = lines.get(i);
-            
-            // Skip comments
-            if (line.trim().startsWith("//")) {
-                continue;
-            }
-            
-            // Handle multi-line comments
-            if (line.trim().startsWith("/*")) {
-                while (i < lines.size() && !lines.get(i).contains("*/")) {
-                    i++;
-                }
-                continue;
-            }
-            
-            // Count braces
-            for (int j = 0; j < line.length(); j++) {
-                char c = line.charAt(j);
-                if (c == '{') {
-                    braceCount++;
-                } else if (c == '}') {
-                    braceCount--;
-                    if (braceCount == 0) {
-                        return i;
-                    }
-                }
-            }
-        }
-        
-        // No matching closing brace found
-        return -1;
+        return Statement.of(lines).get(openingBraceLineIndex).closingBrace(lines, openingBraceLineIndex);
     }
     
     /**
@@ -390,9 +332,10 @@ public class Statements {
      * @param endIndex End index of the block (inclusive of the closing brace line)
      * @param executor The executor to execute the lines with
      */
-    private static void executeBlock(List<String> lines, int startIndex, int endIndex, Executor executor) {
+    static void executeBlock(List<String> lines, int startIndex, int endIndex, Executor executor) {
+        List<Statement> statements = Statement.of(lines);
         for (int i = startIndex; i < endIndex; i++) {
-            String line = lines.get(i).trim();
+            String line = statements.get(i).getText();
             
             // Skip empty lines and closing brace
             if (line.isEmpty() || line.equals("}")) {
@@ -436,6 +379,12 @@ public class Statements {
                 i = processLoopStatement(lines, i, executor) - 1; // -1 because the loop will increment i
                 continue;
             }
+
+            // Process nested with blocks
+            if (With.isWith(line)) {
+                i = With.processWithStatement(lines, i, executor) - 1;
+                continue;
+            }
             
             // Handle variable declaration (var ...)
             if (line.startsWith("var ")) {
@@ -553,10 +502,11 @@ public class Statements {
 
     // Helper: Get the next non-empty, non-comment line at or after index, returns [line, index] or [null, -1]
     private static Object[] getNonEmptyNonCommentLineWithIndex(List<String> lines, int index) {
+        List<Statement> statements = Statement.of(lines);
         while (index < lines.size()) {
-            String line = lines.get(index).trim();
-            if (!line.isEmpty() && !line.startsWith("//")) {
-                return new Object[]{line, index};
+            Statement statement = statements.get(index);
+            if (statement.getKind() != Statement.Kind.SKIP) {
+                return new Object[]{statement.getText(), index};
             }
             index++;
         }
@@ -565,8 +515,9 @@ public class Statements {
 
     // Helper: Find the next line with an opening brace '{' (skipping comments/empty lines)
     private static int findNextOpeningBrace(List<String> lines, int startIndex) {
+        List<Statement> statements = Statement.of(lines);
         for (int i = startIndex; i < lines.size(); i++) {
-            String line = lines.get(i).trim();
+            String line = statements.get(i).getText();
             if (!line.isEmpty() && !line.startsWith("//") && line.contains("{")) {
                 return i;
             }
@@ -7,9 +7,11 @@
 package com.magayaga.microscript;
 
 import java.util.LinkedHashMap;
+import java.util.LinkedHashSet;
 import java.util.Map;
 import java.util.List;
 import java.util.ArrayList;
+import java.util.Set;
 import java.util.regex.Pattern;
 import java.util.regex.Matcher;
 
@@ -17,10 +19,17 @@ import java.util.regex.Matcher;
  * Represents a struct definition and instance in MicroScript
  */
 public class Struct {
+    private static final Pattern IMPLEMENTS_PATTERN =
+        Pattern.compile("^(\\w+)\\s+implements\\s+(\\w+(?:\\s*,\\s*\\w+)*)$");
+    
     private final String name;
     private final Map<String, String> fields; // field name -> type
     private final Map<String, Object> values; // field name -> value (for instances)
     private final boolean isDefinition; // true for struct definitions, false for instances
+    private final Map<String, Function> methods = new LinkedHashMap<>(); // shared by a definition's instances
+    private final List<String> interfaces = new ArrayList<>(); // declared with implements
+    private final Set<String> readonlyFields = new LinkedHashSet<>(); // declared with readonly var
+    private boolean frozen; // set by freeze(instance)
     
     /**
      * Constructor for struct definition
@@ -73,7 +82,68 @@ public class Struct {
             instanceValues.put(fieldName, value);
         }
         
-        return new Struct(name, fields, instanceValues);
+        Struct instance = new Struct(name, fields, instanceValues);
+        instance.methods.putAll(methods);
+        instance.interfaces.addAll(interfaces);
+        instance.readonlyFields.addAll(readonlyFields);
+        return instance;
+    }
+    
+    /**
+     * A copy of this definition under another name, such as its name
+     * inside a namespace
+     */
+    public Struct withName(String newName) {
+        if (!isDefinition) {
+            throw new RuntimeException("Cannot rename struct instance " + name);
+        }
+        Struct renamed = new Struct(newName, fields);
+        renamed.methods.putAll(methods);
+        renamed.interfaces.addAll(interfaces);
+        renamed.readonlyFields.addAll(readonlyFields);
+        return renamed;
+    }
+    
+    /**
+     * Add a method, defined as a function inside the struct body
+     */
+    public void addMethod(Function method) {
+        if (!isDefinition) {
+            throw new RuntimeException("Cannot add a method to a struct instance");
+        }
+        if (fields.containsKey(method.getName())) {
+            throw new RuntimeException("Method '" + method.getName() + "' has the same name as a field of struct " + name);
+        }
+        methods.put(method.getName(), method);
+    }
+    
+    /**
+     * Get a method by name, or null if the struct has none by that name
+     */
+    public Function getMethod(String methodName) {
+        return methods.get(methodName);
+    }
+    
+    public void addInterface(String interfaceName) {
+        interfaces.add(interfaceName);
+    }
+    
+    public boolean isReadonly(String fieldName) {
+        return readonlyFields.contains(fieldName);
+    }
+    
+    public boolean isFrozen() {
+        return frozen;
+    }
+    
+    /**
+     * Make every field of this instance readonly
+     */
+    public void freeze() {
+        if (isDefinition) {
+            throw new RuntimeException("Cannot freeze struct definition " + name);
+        }
+        frozen = true;
     }
     
     /**
@@ -103,10 +173,18 @@ public class Struct {
             throw new RuntimeException("Field '" + fieldName + "' does not exist in struct " + name);
         }
         
+        if (frozen) {
+            throw new RuntimeException("Cannot assign to field '" + fieldName + "' of frozen struct " + name);
+        }
+        
+        if (readonlyFields.contains(fieldName)) {
+            throw new RuntimeException("Cannot assign to readonly field '" + fieldName + "' of struct " + name);
+        }
+        
         String expectedType = fields.get(fieldName);
         if (!validateType(value, expectedType)) {
-            throw new RuntimeException("Type error: Field '" + fieldName + "' expects " + 
-                                     expectedType + " but got " + getTypeName(value));
+            throw new RuntimeException("Type error: field '" + fieldName + "' of struct " + name + " expected "
+                + expectedType + ", got " + Builtins.describe(value) + ".");
         }
         
         values.put(fieldName, value);
@@ -122,8 +200,8 @@ public class Struct {
             Object value = values.get(fieldName);
             
             if (!validateType(value, expectedType)) {
-                throw new RuntimeException("Type error: Field '" + fieldName + "' expects " + 
-                                         expectedType + " but got " + getTypeName(value));
+                throw new RuntimeException("Type error: field '" + fieldName + "' of struct " + name + " expected "
+                    + expectedType + ", got " + Builtins.describe(value) + ".");
             }
         }
     }
@@ -153,21 +231,6 @@ public class Struct {
         }
     }
     
-    /**
-     * Get the type name of a value
-     */
-    private String getTypeName(Object value) {
-        if (value instanceof String) return "String";
-        if (value instanceof Integer) return "Int32";
-        if (value instanceof Long) return "Int64";
-        if (value instanceof Float) return "Float32";
-        if (value instanceof Double) return "Float64";
-        if (value instanceof Character) return "Char";
-        if (value instanceof Boolean) return "bool";
-        if (value instanceof Struct) return ((Struct) value).getName();
-        return value.getClass().getSimpleName();
-    }
-    
     /**
      * Parse a struct definition from lines of code
      */
@@ -182,7 +245,18 @@ public class Struct {
             structName = firstLine.substring(6).trim();
         }
         
+        // struct StructName implements Printable, Comparable
+        List<String> interfaceNames = new ArrayList<>();
+        Matcher implementsMatcher = IMPLEMENTS_PATTERN.matcher(structName);
+        if (implementsMatcher.matches()) {
+            structName = implementsMatcher.group(1);
+            for (String interfaceName : implementsMatcher.group(2).split(",")) {
+                interfaceNames.add(interfaceName.trim());
+            }
+        }
+        
         Map<String, String> fields = new LinkedHashMap<>();
+        Set<String> readonlyFields = new LinkedHashSet<>();
         int currentIndex = startIndex + 1;
         
         // If the opening brace is not on the first line, find it
@@ -206,6 +280,12 @@ public class Struct {
                 continue;
             }
             
+            // "readonly var fieldName: Type;" can be set only when the instance is created
+            boolean readonly = line.startsWith("readonly ");
+            if (readonly) {
+                line = line.substring("readonly".length()).trim();
+            }
+            
             // Parse field declaration: "var fieldName: Type;"
             if (line.startsWith("var ") && line.contains(":")) {
                 String fieldDecl = line.substring(4).trim(); // Remove "var "
@@ -218,6 +298,9 @@ public class Struct {
                     String fieldName = parts[0].trim();
                     String fieldType = parts[1].trim();
                     fields.put(fieldName, fieldType);
+                    if (readonly) {
+                        readonlyFields.add(fieldName);
+                    }
                 } else {
                     throw new RuntimeException("Invalid field declaration: " + line);
                 }
@@ -228,7 +311,10 @@ public class Struct {
             currentIndex++;
         }
         
-        return new Struct(structName, fields);
+        Struct definition = new Struct(structName, fields);
+        definition.interfaces.addAll(interfaceNames);
+        definition.readonlyFields.addAll(readonlyFields);
+        return definition;
     }
     
     // Getters
@@ -248,6 +334,14 @@ public class Struct {
         return isDefinition;
     }
     
+    public Map<String, Function> getMethods() {
+        return new LinkedHashMap<>(methods);
+    }
+    
+    public List<String> getInterfaces() {
+        return new ArrayList<>(interfaces);
+    }
+    
     @Override
     public String toString() {
         if (isDefinition) {
@@ -0,0 +1,202 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.List;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Validates a script's syntax without running any of it: macro invocations,
+ * function declarations and their parameters, variable declarations, and the
+ * expressions in declarations, conditions, returns and console output.
+ * Function bodies are checked as well, although the interpreter only reads
+ * them when the function is first called. Bracket and string problems are
+ * left to {@link Checker}.
+ */
+public class SyntaxChecker {
+    private static final Pattern FUNCTION_PATTERN = Pattern.compile("^function\\s+([\\w:]+)\\s*(?:<\\s*\\w+(?:\\s*,\\s*\\w+)*\\s*>)?\\(([^)]*)\\)\\s*(->\\s*(\\w+(?:<[^>{]*>+)?\\??(?:\\s*\\|\\s*\\w+(?:<[^>{]*>+)?\\??)*))?\\s*\\{$");
+    private static final Pattern C_STYLE_HEADER_PATTERN = Pattern.compile("^(String|Int32|Int64|Float32|Float64|fn)\\s+[\\w:]+\\s*\\(");
+    private static final Pattern C_STYLE_FUNCTION_PATTERN = Pattern.compile("^(String|Int32|Int64|Float32|Float64|fn)\\s+([\\w:]+)\\s*\\(([^)]*)\\)\\s*\\{$");
+    private static final Pattern DECLARATION_PATTERN = Pattern.compile("^(var|bool)\\s+([^=]*?)\\s*(=(?![=>])(.*))?$");
+    private static final Pattern CONDITION_PATTERN = Pattern.compile("^(?:\\}\\s*)?(if|elif|while)\\b(.*)$");
+    private static final Pattern CONDITION_HEADER_PATTERN = Pattern.compile("^\\s*\\((.*)\\)\\s*\\{$");
+    private static final Pattern RETURN_PATTERN = Pattern.compile("^return\\b(.*)$");
+    private static final Pattern CONSOLE_PATTERN = Pattern.compile("^console\\.(write|writef)\\s*\\(");
+    private static final Pattern INTERFACE_METHOD_PATTERN = Pattern.compile("^function\\s+(\\w+)\\s*\\(([^)]*)\\)\\s*(->\\s*(\\w+(?:<[^>{]*>+)?\\??(?:\\s*\\|\\s*\\w+(?:<[^>{]*>+)?\\??)*))?\\s*;?$");
+    private static final Pattern MACRO_ERROR_PATTERN = Pattern.compile("/\\*MACRO_ARG_ERROR:(\\w+)\\*/");
+
+    private final List<Checker.Diagnostic> diagnostics = new ArrayList<>();
+    private boolean inBlockComment;
+    private boolean inInterface;
+
+    /**
+     * Returns every syntax error found, in source order
+     */
+    public static List<Checker.Diagnostic> check(List<String> lines) {
+        SyntaxChecker checker = new SyntaxChecker();
+        Define define = new Define();
+        List<String> source;
+        try {
+            source = Exports.strip(define.preprocess(lines));
+        } catch (RuntimeException e) {
+            int line = e instanceof ScriptException ? Math.max(0, ((ScriptException) e).getLine()) : 0;
+            checker.error(line, 0, 0, "Syntax error in macro definitions: " + e.getMessage());
+            source = lines;
+            define = null;
+        }
+
+        List<Checker.Diagnostic> found = new ArrayList<>();
+        for (int i = 0; i < source.size(); i++) {
+            checker.checkLine(source.get(i), i, found);
+        }
+        checker.diagnostics.addAll(define != null ? Checker.atInvocations(found, define) : found);
+        checker.diagnostics.sort((a, b) -> a.getLine() != b.getLine()
+            ? a.getLine() - b.getLine() : a.getColumn() - b.getColumn());
+        return checker.diagnostics;
+    }
+
+    private void checkLine(String line, int index, List<Checker.Diagnostic> found) {
+        String trimmed = line.trim();
+        if (inBlockComment || trimmed.startsWith("/*")) {
+            inBlockComment = !trimmed.contains("*/");
+            return;
+        }
+        String code = Checker.codeOnly(line);
+        // Keep string contents, but drop a trailing line comment
+        String statement = line.substring(0, code.length()).trim();
+        if (statement.isEmpty() || statement.startsWith("#")) {
+            return;
+        }
+        int base = line.indexOf(statement);
+
+        Matcher macroError = MACRO_ERROR_PATTERN.matcher(line);
+        if (macroError.find()) {
+            found.add(syntaxError(index, macroError.start(), macroError.group().length(),
+                "Wrong number of arguments for macro " + macroError.group(1)));
+            return;
+        }
+
+        // An interface body holds method signatures without bodies
+        if (statement.startsWith("interface ")) {
+            inInterface = !statement.endsWith("}");
+            return;
+        }
+        if (inInterface) {
+            if (statement.equals("}")) {
+                inInterface = false;
+                return;
+            }
+            Matcher method = INTERFACE_METHOD_PATTERN.matcher(statement);
+            if (!method.find()) {
+                found.add(syntaxError(index, base, statement.length(),
+                    "Syntax error: an interface holds only method signatures, such as function name(a: Int32) -> String"));
+                return;
+            }
+            checkParameters(method.group(2), index, base + method.start(2), found);
+            return;
+        }
+
+        if (statement.startsWith("function ")) {
+            Matcher function = FUNCTION_PATTERN.matcher(statement);
+            if (!function.find()) {
+                found.add(syntaxError(index, base, statement.length(), "Syntax error: Invalid function declaration."));
+                return;
+            }
+            checkParameters(function.group(2), index, base + function.start(2), found);
+            return;
+        }
+        if (C_STYLE_HEADER_PATTERN.matcher(statement).find()) {
+            Matcher function = C_STYLE_FUNCTION_PATTERN.matcher(statement);
+            if (!function.find()) {
+                found.add(syntaxError(index, base, statement.length(), "Syntax error: Invalid function declaration."));
+                return;
+            }
+            checkParameters(function.group(3), index, base + function.start(3), found);
+            return;
+        }
+
+        if (statement.endsWith(";")) {
+            statement = statement.substring(0, statement.length() - 1).trim();
+        }
+
+        Matcher declaration = DECLARATION_PATTERN.matcher(statement);
+        if (declaration.find()) {
+            if (declaration.group(3) == null) {
+                found.add(syntaxError(index, base, statement.length(),
+                    "Syntax error in variable declaration: " + statement));
+            } else if (!isLambda(declaration.group(4))) {
+                checkExpression(declaration.group(4), index, base + declaration.start(4), found);
+            }
+            return;
+        }
+
+        Matcher condition = CONDITION_PATTERN.matcher(statement);
+        if (condition.find()) {
+            Matcher header = CONDITION_HEADER_PATTERN.matcher(condition.group(2));
+            if (!header.find()) {
+                found.add(syntaxError(index, base, statement.length(),
+                    "Syntax error: expected " + condition.group(1) + " (condition) {"));
+                return;
+            }
+            checkExpression(header.group(1), index, base + condition.start(2) + header.start(1), found);
+            return;
+        }
+
+        Matcher result = RETURN_PATTERN.matcher(statement);
+        if (result.find()) {
+            // return a, b; gives back a tuple, so a comma list is fine here
+            if (TypeChecker.syntaxError("(" + result.group(1) + ")") != null) {
+                checkExpression(result.group(1), index, base + result.start(1), found);
+            }
+            return;
+        }
+
+        if (CONSOLE_PATTERN.matcher(statement).find()) {
+            checkExpression(statement, index, base, found);
+        }
+    }
+
+    private void checkParameters(String parameters, int index, int column, List<Checker.Diagnostic> found) {
+        if (parameters.trim().isEmpty()) {
+            return;
+        }
+        int offset = 0;
+        for (String parameter : Parser.splitParameters(parameters)) {
+            if (parameter.split(":", -1).length != 2 || parameter.split(":")[0].trim().isEmpty()) {
+                found.add(syntaxError(index, column + offset, parameter.length(),
+                    "Syntax error: Invalid parameter declaration '" + parameter.trim() + "', expected name: Type"));
+            }
+            offset += parameter.length() + 1;
+        }
+    }
+
+    private void checkExpression(String expression, int index, int column, List<Checker.Diagnostic> found) {
+        if (expression.trim().isEmpty()) {
+            return;
+        }
+        String message = TypeChecker.syntaxError(expression);
+        if (message != null) {
+            int offset = expression.length() - expression.replaceAll("^\\s+", "").length();
+            found.add(syntaxError(index, column + offset, expression.trim().length(), "Syntax error: " + message));
+        }
+    }
+
+    private static boolean isLambda(String value) {
+        String trimmed = value.trim();
+        return trimmed.startsWith("|") || trimmed.contains("=>") || trimmed.startsWith("{");
+    }
+
+    private static Checker.Diagnostic syntaxError(int line, int column, int length, String message) {
+        return new Checker.Diagnostic(line, column, length, Checker.Severity.ERROR, "syntax", message);
+    }
+
+    private void error(int line, int column, int length, String message) {
+        diagnostics.add(syntaxError(line, column, length, message));
+    }
+}
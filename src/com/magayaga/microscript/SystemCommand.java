@@ -0,0 +1,211 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedReader;
+import java.io.File;
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.InputStreamReader;
+import java.io.PrintStream;
+import java.nio.charset.Charset;
+import java.nio.file.Files;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Locale;
+import java.util.Map;
+import java.util.concurrent.TimeUnit;
+
+/**
+ * Runs the command given to console.system through the platform's shell:
+ *
+ *   console.system("ls -la | wc -l");
+ *   console.system("npm test", "cwd=web", "env=CI=1", "timeout=60");
+ *   console.system("Get-ChildItem", "shell=powershell");
+ *
+ * The shell is /bin/sh on Unix and, on Windows, PowerShell when it is
+ * installed and cmd otherwise. Options are "name=value" strings, or a map
+ * such as one from JSON:
+ *
+ *   shell    bash, sh, cmd, powershell, pwsh, or none to run the command
+ *            directly, split on spaces
+ *   cwd      the working directory
+ *   env      NAME=VALUE to set in the command's environment; may be repeated
+ *   timeout  seconds after which the command is killed and the script fails
+ *
+ * What the command prints goes to the script's standard output and error.
+ */
+public class SystemCommand {
+    private static final boolean WINDOWS = System.getProperty("os.name", "").toLowerCase(Locale.ROOT).startsWith("windows");
+
+    private SystemCommand() {
+    }
+
+    /**
+     * Collects the options given after the command: "name=value" strings or maps
+     */
+    public static Map<String, Object> options(List<Object> values) {
+        Map<String, Object> options = new LinkedHashMap<>();
+        Map<String, String> env = new LinkedHashMap<>();
+        for (Object value : values) {
+            if (value instanceof Map) {
+                for (Map.Entry<?, ?> entry : ((Map<?, ?>) value).entrySet()) {
+                    options.put(String.valueOf(entry.getKey()), entry.getValue());
+                }
+                continue;
+            }
+            String text = String.valueOf(value);
+            int equals = text.indexOf('=');
+            if (equals <= 0) {
+                throw new RuntimeException("console.system: expected an option like \"timeout=10\", got '" + text + "'");
+            }
+            String name = text.substring(0, equals).trim();
+            String setting = text.substring(equals + 1);
+            if (name.equals("env")) {
+                int split = setting.indexOf('=');
+                if (split <= 0) {
+                    throw new RuntimeException("console.system: env must be NAME=VALUE, got '" + setting + "'");
+                }
+                env.put(setting.substring(0, split), setting.substring(split + 1));
+            } else if (name.equals("timeout")) {
+                try {
+                    options.put(name, Double.parseDouble(setting.trim()));
+                } catch (NumberFormatException e) {
+                    throw new RuntimeException("console.system: timeout must be a number of seconds");
+                }
+            } else if (name.equals("shell") || name.equals("cwd")) {
+                options.put(name, setting.trim());
+            } else {
+                throw new RuntimeException("console.system: unknown option '" + name + "'");
+            }
+        }
+        if (!env.isEmpty()) {
+            options.put("env", env);
+        }
+        return options;
+    }
+
+    /**
+     * Runs a command with the given options (null for none) and returns its exit code
+     */
+    public static int run(String command, Map<?, ?> options) throws IOException, InterruptedException {
+        Object shell = option(options, "shell");
+        ProcessBuilder builder = new ProcessBuilder(commandLine(command, shell == null ? defaultShell() : shell.toString()));
+
+        Object cwd = option(options, "cwd");
+        if (cwd != null) {
+            File directory = new File(cwd.toString());
+            if (!directory.isDirectory()) {
+                throw new RuntimeException("console.system: no such directory: " + cwd);
+            }
+            builder.directory(directory);
+        }
+        Object env = option(options, "env");
+        if (env != null) {
+            if (!(env instanceof Map)) {
+                throw new RuntimeException("console.system: env must be a map of names to values");
+            }
+            for (Map.Entry<?, ?> entry : ((Map<?, ?>) env).entrySet()) {
+                builder.environment().put(String.valueOf(entry.getKey()), String.valueOf(entry.getValue()));
+            }
+        }
+        Object timeout = option(options, "timeout");
+        if (timeout != null && !(timeout instanceof Number)) {
+            throw new RuntimeException("console.system: timeout must be a number of seconds");
+        }
+
+        Process process = builder.start();
+        process.getOutputStream().close();
+        // Both streams are drained at once so a chatty command can't block on a full pipe
+        Thread stdout = copy(process.getInputStream(), System.out);
+        Thread stderr = copy(process.getErrorStream(), System.err);
+        if (timeout == null) {
+            process.waitFor();
+        } else if (!process.waitFor((long) (((Number) timeout).doubleValue() * 1000), TimeUnit.MILLISECONDS)) {
+            process.destroyForcibly();
+            throw new RuntimeException("console.system: '" + command + "' timed out after " + timeout + "s");
+        }
+        stdout.join();
+        stderr.join();
+        return process.exitValue();
+    }
+
+    private static Object option(Map<?, ?> options, String name) {
+        return options == null ? null : options.get(name);
+    }
+
+    private static List<String> commandLine(String command, String shell) {
+        List<String> line = new ArrayList<>();
+        switch (shell.toLowerCase(Locale.ROOT)) {
+            case "none":
+                for (String part : command.trim().split("\\s+")) {
+                    line.add(part);
+                }
+                return line;
+            case "cmd":
+                line.add("cmd.exe");
+                line.add("/c");
+                break;
+            case "powershell":
+            case "pwsh":
+                line.add(shell);
+                line.add("-NoProfile");
+                line.add("-NonInteractive");
+                line.add("-Command");
+                break;
+            default:
+                line.add(shell);
+                line.add("-c");
+                break;
+        }
+        line.add(command);
+        return line;
+    }
+
+    private static String defaultShell() {
+        if (!WINDOWS) {
+            return "/bin/sh";
+        }
+        for (String shell : new String[] {"pwsh", "powershell"}) {
+            if (onPath(shell + ".exe")) {
+                return shell;
+            }
+        }
+        return "cmd";
+    }
+
+    private static boolean onPath(String program) {
+        String path = System.getenv("PATH");
+        if (path == null) {
+            return false;
+        }
+        for (String directory : path.split(File.pathSeparator)) {
+            if (!directory.isEmpty() && Files.isExecutable(Paths.get(directory, program))) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    private static Thread copy(InputStream in, PrintStream out) {
+        Thread thread = new Thread(() -> {
+            try (BufferedReader reader = new BufferedReader(new InputStreamReader(in, Charset.defaultCharset()))) {
+                String line;
+                while ((line = reader.readLine()) != null) {
+                    out.println(line);
+                }
+            } catch (IOException e) {
+                // The command was killed
+            }
+        }, "console-system");
+        thread.setDaemon(true);
+        thread.start();
+        return thread;
+    }
+}
@@ -0,0 +1,68 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.concurrent.Callable;
+import java.util.concurrent.ExecutionException;
+import java.util.concurrent.ExecutorService;
+import java.util.concurrent.Executors;
+import java.util.concurrent.Future;
+import java.util.concurrent.atomic.AtomicInteger;
+
+/**
+ * A function call started with `spawn f(args)`; `await task` waits for it and
+ * returns its result. Tasks run on daemon threads, so like goroutines they
+ * don't keep the program alive once the main script has finished.
+ */
+public class Task {
+    private static final AtomicInteger threadCount = new AtomicInteger();
+    private static final ExecutorService pool = Executors.newCachedThreadPool(runnable -> {
+        Thread thread = new Thread(runnable, "microscript-task-" + threadCount.incrementAndGet());
+        thread.setDaemon(true);
+        return thread;
+    });
+
+    private final String functionName;
+    private final Future<Object> future;
+
+    private Task(String functionName, Future<Object> future) {
+        this.functionName = functionName;
+        this.future = future;
+    }
+
+    public static Task start(String functionName, Callable<Object> call) {
+        return new Task(functionName, pool.submit(call));
+    }
+
+    /**
+     * Blocks until the task finishes and returns its result, rethrowing any
+     * error raised by the function
+     */
+    public Object await() {
+        try {
+            return future.get();
+        } catch (InterruptedException e) {
+            Thread.currentThread().interrupt();
+            throw new RuntimeException("Interrupted while awaiting task " + functionName);
+        } catch (ExecutionException e) {
+            Throwable cause = e.getCause();
+            if (cause instanceof RuntimeException) {
+                throw (RuntimeException) cause;
+            }
+            throw new RuntimeException("Task " + functionName + " failed: " + cause.getMessage(), cause);
+        }
+    }
+
+    public boolean isDone() {
+        return future.isDone();
+    }
+
+    @Override
+    public String toString() {
+        return "Task(" + functionName + (future.isDone() ? ", done)" : ", running)");
+    }
+}
@@ -0,0 +1,118 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedWriter;
+import java.io.Closeable;
+import java.io.IOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Paths;
+import java.util.LinkedHashMap;
+import java.util.Map;
+
+/**
+ * Writes an execution trace as JSON Lines, one event per executed statement,
+ * function entry and exit, and variable assignment:
+ *
+ *   {"ts":1520,"thread":"main","event":"enter","function":"fib"}
+ *   {"ts":1794,"thread":"main","event":"statement","function":"fib","line":4}
+ *   {"ts":2311,"thread":"main","event":"set","name":"n","value":9}
+ *   {"ts":9046,"thread":"main","event":"exit","function":"fib","duration":7526}
+ *
+ * ts and duration are nanoseconds since the trace started; lines are 1-based.
+ */
+public class Tracer implements Closeable {
+    private static final String TOP_LEVEL = "<script>";
+
+    private final BufferedWriter writer;
+    private final long start = System.nanoTime();
+    // The function running on each thread, for statement events
+    private final ThreadLocal<String> currentFunction = ThreadLocal.withInitial(() -> TOP_LEVEL);
+    private IOException error;
+
+    public Tracer(String path) throws IOException {
+        this.writer = Files.newBufferedWriter(Paths.get(path), StandardCharsets.UTF_8);
+    }
+
+    /**
+     * Records a function entry and returns the token to pass to exit
+     */
+    public String enter(String function) {
+        String caller = currentFunction.get();
+        currentFunction.set(function);
+        Map<String, Object> event = event("enter");
+        event.put("function", function);
+        write(event);
+        return caller;
+    }
+
+    public void exit(String function, String caller, long enteredAt) {
+        currentFunction.set(caller);
+        Map<String, Object> event = event("exit");
+        event.put("function", function);
+        event.put("duration", System.nanoTime() - enteredAt);
+        write(event);
+    }
+
+    /**
+     * Records the 0-based line about to run
+     */
+    public void statement(int line) {
+        Map<String, Object> event = event("statement");
+        event.put("function", currentFunction.get());
+        event.put("line", line + 1);
+        write(event);
+    }
+
+    public void variable(String name, Object value) {
+        Map<String, Object> event = event("set");
+        event.put("name", name);
+        event.put("value", jsonValue(value));
+        write(event);
+    }
+
+    private Map<String, Object> event(String kind) {
+        Map<String, Object> event = new LinkedHashMap<>();
+        event.put("ts", System.nanoTime() - start);
+        event.put("thread", Thread.currentThread().getName());
+        event.put("event", kind);
+        return event;
+    }
+
+    // Values JSON can't hold are recorded as their printed form
+    private static Object jsonValue(Object value) {
+        if (value == null || value instanceof String || value instanceof Boolean) {
+            return value;
+        }
+        if (value instanceof Number) {
+            double number = ((Number) value).doubleValue();
+            return Double.isNaN(number) || Double.isInfinite(number) ? value.toString() : value;
+        }
+        return String.valueOf(value);
+    }
+
+    private synchronized void write(Map<String, Object> event) {
+        if (error != null) {
+            return;
+        }
+        try {
+            writer.write(Json.stringify(event));
+            writer.newLine();
+        } catch (IOException e) {
+            error = e; // Reported on close instead of failing the script
+        }
+    }
+
+    @Override
+    public synchronized void close() throws IOException {
+        writer.close();
+        if (error != null) {
+            throw error;
+        }
+    }
+}
@@ -0,0 +1,1545 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.IOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.nio.file.Paths;
+import java.util.ArrayList;
+import java.util.HashMap;
+import java.util.HashSet;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+
+/**
+ * Translates a script into the source of another language, so a finished
+ * script can be compiled ahead of time or embedded in a project:
+ *
+ *   microscript transpile --target go fib.mus          (writes fib.go)
+ *   microscript transpile --target go fib.mus -o -     (prints it)
+ *   microscript transpile --target js fib.mus          (writes fib.js)
+ *
+ * Only the statically typed core of the language is translated: functions
+ * with typed parameters, var and list declarations, arithmetic, string
+ * templates in console.write, if/elif/else, while and for loops, lists and
+ * the math module. Anything else is reported with its line rather than
+ * translated into something that behaves differently.
+ *
+ * The script is parsed into a small tree whose expressions carry their
+ * MicroScript types; each target turns that tree into source text.
+ */
+public class Transpiler {
+    // The type of number literals, which take the type of what they meet
+    public static final String NUMBER = "Number";
+    public static final String VOID = "Void";
+
+    private static final Set<String> NUMERIC = Set.of("Int32", "Int64", "Float32", "Float64", NUMBER);
+    private static final Set<String> TYPES = Set.of("Int32", "Int64", "Float32", "Float64", "String", "Char", "Bool");
+
+    // math:: functions a target can translate, by the number of arguments they take
+    public static final Map<String, Integer> MATH_FUNCTIONS = new LinkedHashMap<>();
+    public static final Set<String> MATH_CONSTANTS = Set.of("pi", "e", "eulerNumber", "tau", "phi", "silverRatio",
+        "eulerConstant", "catalan", "apery", "feigenbaumDelta", "feigenbaumAlpha", "plastic", "twinPrime");
+
+    static {
+        for (String name : new String[] {"sqrt", "square", "cbrt", "cube", "abs", "log10", "log2", "log", "sin", "cos",
+                "tan", "asin", "acos", "atan", "sinh", "cosh", "tanh", "asinh", "acosh", "atanh"}) {
+            MATH_FUNCTIONS.put(name, 1);
+        }
+        MATH_FUNCTIONS.put("atan2", 2);
+    }
+
+    /**
+     * A language scripts can be translated into
+     */
+    public interface Target {
+        // The file extension of the output, such as ".go"
+        String extension();
+
+        String emit(Program program);
+    }
+
+    /**
+     * The target with the given --target name
+     */
+    public static Target target(String name) {
+        switch (name) {
+            case "go":
+                return new GoTarget();
+            case "js":
+                return new JsTarget();
+            default:
+                throw new IllegalArgumentException("Unknown transpile target: " + name + " (expected go or js)");
+        }
+    }
+
+    // Program tree
+
+    /**
+     * A whole script: its functions, and the statements outside them, which
+     * run in order as the program's entry point
+     */
+    public static final class Program {
+        public final String name;
+        public final List<Function> functions = new ArrayList<>();
+        // Variables declared outside functions, which every function can see
+        public final Map<String, String> globals = new LinkedHashMap<>();
+        public final Function main;
+
+        Program(String name) {
+            this.name = name;
+            this.main = new Function("main", List.of(), VOID, -1);
+        }
+    }
+
+    public static final class Function {
+        public final String name;
+        public final List<Variable> parameters;
+        public final String returnType;
+        public final List<Node> body = new ArrayList<>();
+        public final int line;
+        // Variables whose value is read somewhere in the body
+        public final Set<String> reads = new HashSet<>();
+
+        Function(String name, List<Variable> parameters, String returnType, int line) {
+            this.name = name;
+            this.parameters = parameters;
+            this.returnType = returnType;
+            this.line = line;
+        }
+    }
+
+    public static final class Variable {
+        public final String name;
+        public final String type;
+
+        Variable(String name, String type) {
+            this.name = name;
+            this.type = type;
+        }
+    }
+
+    public abstract static class Node {
+        public final int line;
+
+        Node(int line) {
+            this.line = line;
+        }
+    }
+
+    // var name: type = value, where value is null for a zero value
+    public static final class Declare extends Node {
+        public final String name;
+        public String type;
+        public final Expr value;
+        public boolean global;
+
+        Declare(int line, String name, String type, Expr value) {
+            super(line);
+            this.name = name;
+            this.type = type;
+            this.value = value;
+        }
+    }
+
+    // target = value, or a compound assignment such as target += value
+    public static final class Assign extends Node {
+        public final Expr target;
+        public final String operator;
+        public final Expr value;
+
+        Assign(int line, Expr target, String operator, Expr value) {
+            super(line);
+            this.target = target;
+            this.operator = operator;
+            this.value = value;
+        }
+    }
+
+    // name++ or name--
+    public static final class Step extends Node {
+        public final Expr target;
+        public final String operator;
+
+        Step(int line, Expr target, String operator) {
+            super(line);
+            this.target = target;
+            this.operator = operator;
+        }
+    }
+
+    // if, any number of elifs, then an optional else (otherwise is null without one)
+    public static final class If extends Node {
+        public final List<Expr> conditions = new ArrayList<>();
+        public final List<List<Node>> branches = new ArrayList<>();
+        public List<Node> otherwise;
+
+        If(int line) {
+            super(line);
+        }
+    }
+
+    public static final class While extends Node {
+        public final Expr condition;
+        public final List<Node> body;
+
+        While(int line, Expr condition, List<Node> body) {
+            super(line);
+            this.condition = condition;
+            this.body = body;
+        }
+    }
+
+    public static final class For extends Node {
+        public final Node init;
+        public final Expr condition;
+        public final Node update;
+        public final List<Node> body;
+
+        For(int line, Node init, Expr condition, Node update, List<Node> body) {
+            super(line);
+            this.init = init;
+            this.condition = condition;
+            this.update = update;
+            this.body = body;
+        }
+    }
+
+    // for (var name : items)
+    public static final class Each extends Node {
+        public final String name;
+        public String type;
+        public final Expr items;
+        public final List<Node> body;
+
+        Each(int line, String name, String type, Expr items, List<Node> body) {
+            super(line);
+            this.name = name;
+            this.type = type;
+            this.items = items;
+            this.body = body;
+        }
+    }
+
+    public static final class Return extends Node {
+        public Expr value;
+
+        Return(int line, Expr value) {
+            super(line);
+            this.value = value;
+        }
+    }
+
+    // break or continue
+    public static final class Jump extends Node {
+        public final String keyword;
+
+        Jump(int line, String keyword) {
+            super(line);
+            this.keyword = keyword;
+        }
+    }
+
+    // console.write or console.writef: the pieces are joined into one line of text
+    public static final class Print extends Node {
+        public final boolean newline;
+        public final List<Expr> arguments;
+        public final List<Expr> pieces = new ArrayList<>();
+
+        Print(int line, boolean newline, List<Expr> arguments) {
+            super(line);
+            this.newline = newline;
+            this.arguments = arguments;
+        }
+    }
+
+    // A function call whose result is discarded
+    public static final class Evaluate extends Node {
+        public final Expr call;
+
+        Evaluate(int line, Expr call) {
+            super(line);
+            this.call = call;
+        }
+    }
+
+    public abstract static class Expr {
+        public final int line;
+        public String type;
+
+        Expr(int line) {
+            this.line = line;
+        }
+    }
+
+    // A number, string ("text" with its escapes), character ('c') or true/false, as written
+    public static final class Literal extends Expr {
+        public final String text;
+
+        Literal(int line, String text, String type) {
+            super(line);
+            this.text = text;
+            this.type = type;
+        }
+
+        // The text of a string literal without its quotes, escapes left in
+        public String content() {
+            return text.substring(1, text.length() - 1);
+        }
+    }
+
+    public static final class Name extends Expr {
+        public final String name;
+
+        Name(int line, String name) {
+            super(line);
+            this.name = name;
+        }
+    }
+
+    // A call to a script function, or to a module function such as math::sqrt
+    public static final class Call extends Expr {
+        public final String name;
+        public final List<Expr> arguments;
+        // The called script function, or null for a module function
+        public Function function;
+
+        Call(int line, String name, List<Expr> arguments) {
+            super(line);
+            this.name = name;
+            this.arguments = arguments;
+        }
+    }
+
+    public static final class Unary extends Expr {
+        public final String operator;
+        public final Expr operand;
+
+        Unary(int line, String operator, Expr operand) {
+            super(line);
+            this.operator = operator;
+            this.operand = operand;
+        }
+    }
+
+    public static final class Binary extends Expr {
+        public final String operator;
+        public final Expr left;
+        public final Expr right;
+        // The type both operands are brought to before the operator applies
+        public String operandType;
+
+        Binary(int line, String operator, Expr left, Expr right) {
+            super(line);
+            this.operator = operator;
+            this.left = left;
+            this.right = right;
+        }
+    }
+
+    public static final class Index extends Expr {
+        public final Expr list;
+        public final Expr index;
+
+        Index(int line, Expr list, Expr index) {
+            super(line);
+            this.list = list;
+            this.index = index;
+        }
+    }
+
+    public static final class ListOf extends Expr {
+        public final List<Expr> items;
+
+        ListOf(int line, List<Expr> items) {
+            super(line);
+            this.items = items;
+        }
+    }
+
+    // Types
+
+    public static boolean isNumeric(String type) {
+        return NUMERIC.contains(type);
+    }
+
+    public static boolean isWhole(String type) {
+        return type.equals("Int32") || type.equals("Int64");
+    }
+
+    // "List<Int32>" -> "Int32"
+    public static String elementType(String type) {
+        return type.startsWith("List<") ? type.substring(5, type.length() - 1) : null;
+    }
+
+    // The type two numbers are brought to before they are combined
+    static String widen(String a, String b) {
+        if (a.equals(NUMBER)) {
+            return b;
+        }
+        if (b.equals(NUMBER) || a.equals(b)) {
+            return a;
+        }
+        if (a.startsWith("Float") || b.startsWith("Float")) {
+            return a.equals("Float32") && b.equals("Float32") ? "Float32" : "Float64";
+        }
+        return "Int64";
+    }
+
+    // Whether a value of type from can be stored in a variable of type to
+    static boolean assignable(String from, String to) {
+        if (from.equals(to) || isNumeric(from) && isNumeric(to)) {
+            return true;
+        }
+        String fromElement = elementType(from);
+        String toElement = elementType(to);
+        return fromElement != null && toElement != null
+            && (fromElement.equals(NUMBER) && isNumeric(toElement) || fromElement.equals(VOID));
+    }
+
+    // Entry point
+
+    private final List<Token> tokens;
+    private int pos = 0;
+
+    private Transpiler(List<Token> tokens) {
+        this.tokens = tokens;
+    }
+
+    /**
+     * Parses and type-checks a script, throwing a ScriptException at the first
+     * construct that can't be translated
+     */
+    public static Program parse(String name, List<String> lines) {
+        List<String> expanded;
+        try {
+            expanded = Exports.strip(Pipeline.desugar(new Define().preprocess(lines)));
+        } catch (RuntimeException e) {
+            throw ScriptException.at(e, -1);
+        }
+        Program program = new Program(name);
+        new Transpiler(lex(expanded)).parseProgram(program);
+        new Resolver(program).resolve();
+        return program;
+    }
+
+    /**
+     * microscript transpile --target <name> <file> [-o <output>|-]
+     */
+    public static void main(String[] args) {
+        String targetName = "go";
+        String filePath = null;
+        String outputPath = null;
+        for (int i = 0; i < args.length; i++) {
+            if (args[i].equals("--target") && i + 1 < args.length) {
+                targetName = args[++i];
+            } else if (args[i].equals("-o") && i + 1 < args.length) {
+                outputPath = args[++i];
+            } else if (filePath == null && !args[i].startsWith("-")) {
+                filePath = args[i];
+            } else {
+                filePath = null;
+                break;
+            }
+        }
+        if (filePath == null) {
+            System.err.println("Usage: microscript transpile [--target go|js] <file> [-o <output>|-]");
+            return;
+        }
+
+        Target target;
+        try {
+            target = target(targetName);
+        } catch (IllegalArgumentException e) {
+            System.err.println(e.getMessage());
+            System.exit(1);
+            return;
+        }
+
+        List<String> lines;
+        try {
+            lines = new Scanner(filePath).readLines();
+        } catch (IOException e) {
+            System.err.println("Error reading file '" + filePath + "': " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+
+        String fileName = Paths.get(filePath).getFileName().toString();
+        int dot = fileName.lastIndexOf('.');
+        String baseName = dot > 0 ? fileName.substring(0, dot) : fileName;
+        String output;
+        try {
+            output = target.emit(parse(fileName, lines));
+        } catch (ScriptException e) {
+            String where = e.getLine() >= 0 ? " at line " + (e.getLine() + 1) : "";
+            System.err.println("Error transpiling script '" + baseName + "'" + where + ": " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+
+        if ("-".equals(outputPath)) {
+            System.out.print(output);
+            return;
+        }
+        Path destination = outputPath != null ? Paths.get(outputPath)
+            : Paths.get(filePath).resolveSibling(baseName + target.extension());
+        try {
+            Files.write(destination, output.getBytes(StandardCharsets.UTF_8));
+        } catch (IOException e) {
+            System.err.println("Error writing file '" + destination + "': " + e.getMessage());
+            System.exit(1);
+            return;
+        }
+        System.out.println("Wrote " + destination);
+    }
+
+    // Lexer
+
+    private enum Kind { NAME, NUMBER, STRING, CHAR, SYMBOL, NEWLINE, END }
+
+    private static final class Token {
+        final Kind kind;
+        final String text;
+        final int line;
+
+        Token(Kind kind, String text, int line) {
+            this.kind = kind;
+            this.text = text;
+            this.line = line;
+        }
+
+        boolean is(String symbol) {
+            return (kind == Kind.SYMBOL || kind == Kind.NAME) && text.equals(symbol);
+        }
+    }
+
+    // Longest first, so "+=" isn't read as "+" then "="
+    private static final String[] SYMBOLS = {"->", "==", "!=", "<=", ">=", "&&", "||", "++", "--", "+=", "-=", "*=",
+        "/=", "%=", "+", "-", "*", "/", "%", "#", "<", ">", "=", "!", "(", ")", "{", "}", "[", "]", ",", ";", ":", "|"};
+
+    // Newlines end statements, except inside brackets, where a statement continues
+    private static List<Token> lex(List<String> lines) {
+        List<Token> tokens = new ArrayList<>();
+        int depth = 0;
+        for (int line = 0; line < lines.size(); line++) {
+            String text = lines.get(line);
+            int i = 0;
+            while (i < text.length()) {
+                char c = text.charAt(i);
+                if (Character.isWhitespace(c)) {
+                    i++;
+                } else if (text.startsWith("//", i) && Checker.startsComment(text, i)) {
+                    break;
+                } else if (text.startsWith("//", i)) {
+                    // a // b, floor division
+                    tokens.add(new Token(Kind.SYMBOL, "//", line));
+                    i += 2;
+                } else if (Character.isLetter(c) || c == '_') {
+                    int start = i;
+                    while (i < text.length()) {
+                        if (Character.isLetterOrDigit(text.charAt(i)) || text.charAt(i) == '_') {
+                            i++;
+                        } else if (text.startsWith("::", i) && i + 2 < text.length() && isNameStart(text.charAt(i + 2))) {
+                            i += 2;
+                        } else if (text.charAt(i) == '.' && i + 1 < text.length() && isNameStart(text.charAt(i + 1))) {
+                            i++;
+                        } else {
+                            break;
+                        }
+                    }
+                    tokens.add(new Token(Kind.NAME, text.substring(start, i), line));
+                } else if (Character.isDigit(c) || c == '.' && i + 1 < text.length() && Character.isDigit(text.charAt(i + 1))) {
+                    int start = i;
+                    while (i < text.length() && (Character.isDigit(text.charAt(i)) || text.charAt(i) == '_')) {
+                        i++;
+                    }
+                    if (i < text.length() && text.charAt(i) == '.' && i + 1 < text.length() && Character.isDigit(text.charAt(i + 1))) {
+                        i++;
+                        while (i < text.length() && Character.isDigit(text.charAt(i))) {
+                            i++;
+                        }
+                    }
+                    if (i < text.length() && (text.charAt(i) == 'e' || text.charAt(i) == 'E')) {
+                        int exponent = i + 1;
+                        if (exponent < text.length() && (text.charAt(exponent) == '+' || text.charAt(exponent) == '-')) {
+                            exponent++;
+                        }
+                        if (exponent < text.length() && Character.isDigit(text.charAt(exponent))) {
+                            i = exponent;
+                            while (i < text.length() && Character.isDigit(text.charAt(i))) {
+                                i++;
+                            }
+                        }
+                    }
+                    tokens.add(new Token(Kind.NUMBER, text.substring(start, i).replace("_", ""), line));
+                } else if (c == '"' || c == '\'') {
+                    int start = i++;
+                    while (i < text.length() && text.charAt(i) != c) {
+                        i += text.charAt(i) == '\\' ? 2 : 1;
+                    }
+                    if (i >= text.length()) {
+                        throw new ScriptException("Unterminated " + (c == '"' ? "string" : "character") + " literal", line, start, null);
+                    }
+                    i++;
+                    tokens.add(new Token(c == '"' ? Kind.STRING : Kind.CHAR, text.substring(start, i), line));
+                } else {
+                    String symbol = null;
+                    for (String candidate : SYMBOLS) {
+                        if (text.startsWith(candidate, i)) {
+                            symbol = candidate;
+                            break;
+                        }
+                    }
+                    if (symbol == null) {
+                        throw new ScriptException("Unexpected character '" + c + "'", line, i, null);
+                    }
+                    if (symbol.equals("(") || symbol.equals("[")) {
+                        depth++;
+                    } else if ((symbol.equals(")") || symbol.equals("]")) && depth > 0) {
+                        depth--;
+                    }
+                    tokens.add(new Token(Kind.SYMBOL, symbol, line));
+                    i += symbol.length();
+                }
+            }
+            if (depth == 0) {
+                tokens.add(new Token(Kind.NEWLINE, "\n", line));
+            }
+        }
+        tokens.add(new Token(Kind.END, "", Math.max(lines.size() - 1, 0)));
+        return tokens;
+    }
+
+    private static boolean isNameStart(char c) {
+        return Character.isLetter(c) || c == '_';
+    }
+
+    // Parser
+
+    private Token peek() {
+        return tokens.get(pos);
+    }
+
+    private Token next() {
+        Token token = tokens.get(pos);
+        if (token.kind != Kind.END) {
+            pos++;
+        }
+        return token;
+    }
+
+    private boolean accept(String symbol) {
+        if (peek().is(symbol)) {
+            pos++;
+            return true;
+        }
+        return false;
+    }
+
+    private Token expect(String symbol, String context) {
+        if (!peek().is(symbol)) {
+            Token found = peek();
+            String what = found.kind == Kind.NEWLINE ? "end of line" : found.kind == Kind.END ? "end of file" : "'" + found.text + "'";
+            throw new ScriptException("Missing '" + symbol + "' " + context + ", found " + what, found.line, null);
+        }
+        return next();
+    }
+
+    private String expectName(String context) {
+        if (peek().kind != Kind.NAME) {
+            throw new ScriptException("Expected a name " + context + ", found '" + peek().text + "'", peek().line, null);
+        }
+        return next().text;
+    }
+
+    private void skipNewlines() {
+        while (peek().kind == Kind.NEWLINE || peek().is(";")) {
+            pos++;
+        }
+    }
+
+    private void endStatement() {
+        if (accept(";") || peek().kind == Kind.NEWLINE || peek().kind == Kind.END || peek().is("}")) {
+            return;
+        }
+        throw new ScriptException("Unexpected '" + peek().text + "' after statement", peek().line, null);
+    }
+
+    private static ScriptException unsupported(String construct, int line) {
+        return new ScriptException(construct + " can't be transpiled", line, null);
+    }
+
+    private void parseProgram(Program program) {
+        Set<String> imports = new HashSet<>();
+        while (true) {
+            skipNewlines();
+            Token token = peek();
+            if (token.kind == Kind.END) {
+                break;
+            }
+            if (token.is("import")) {
+                next();
+                String module = expectName("after import");
+                if (!module.equals("math")) {
+                    throw unsupported("import " + module, token.line);
+                }
+                imports.add(module);
+                endStatement();
+            } else if (token.is("function")) {
+                program.functions.add(parseFunction());
+            } else {
+                Node statement = parseStatement();
+                if (statement instanceof Declare) {
+                    ((Declare) statement).global = true;
+                }
+                program.main.body.add(statement);
+            }
+        }
+        if (!imports.contains("math") && usesMath) {
+            throw new ScriptException("math:: functions need 'import math'", mathLine, null);
+        }
+    }
+
+    // Set when a math:: name is seen, to check the script imports the module
+    private boolean usesMath = false;
+    private int mathLine = -1;
+
+    private Function parseFunction() {
+        int line = next().line;
+        String name = expectName("after function");
+        if (peek().is("<")) {
+            throw unsupported("Generic function " + name, line);
+        }
+        expect("(", "after the function name");
+        List<Variable> parameters = new ArrayList<>();
+        if (!accept(")")) {
+            do {
+                String parameter = expectName("for a parameter of " + name);
+                if (!accept(":")) {
+                    throw new ScriptException("Parameter '" + parameter + "' of " + name + " needs a type to be transpiled", line, null);
+                }
+                parameters.add(new Variable(parameter, parseType()));
+            } while (accept(","));
+            expect(")", "after the parameters of " + name);
+        }
+        String returnType = accept("->") ? parseType() : VOID;
+        Function function = new Function(name, parameters, returnType, line);
+        function.body.addAll(parseBlock());
+        return function;
+    }
+
+    private String parseType() {
+        int line = peek().line;
+        String type = expectName("for a type");
+        if (type.equals("List") && accept("<")) {
+            String element = parseType();
+            expect(">", "after the list's element type");
+            return "List<" + element + ">";
+        }
+        if (!TYPES.contains(type)) {
+            throw unsupported("Type " + type, line);
+        }
+        return type;
+    }
+
+    private List<Node> parseBlock() {
+        skipNewlines();
+        Token open = expect("{", "to start a block");
+        List<Node> body = new ArrayList<>();
+        while (true) {
+            skipNewlines();
+            if (accept("}")) {
+                return body;
+            }
+            if (peek().kind == Kind.END) {
+                throw new ScriptException("Missing closing brace: the '{' at line " + (open.line + 1)
+                    + " is never closed before the end of the file", open.line, null);
+            }
+            body.add(parseStatement());
+        }
+    }
+
+    private Node parseStatement() {
+        Token token = peek();
+        int line = token.line;
+        if (token.kind == Kind.NAME) {
+            switch (token.text) {
+                case "var":
+                case "list": {
+                    next();
+                    Node declaration = parseDeclaration(line, token.text.equals("list"));
+                    endStatement();
+                    return declaration;
+                }
+                case "if":
+                    return parseIf();
+                case "while": {
+                    next();
+                    expect("(", "after while");
+                    Expr condition = parseExpression();
+                    expect(")", "after the while condition");
+                    return new While(line, condition, parseBlock());
+                }
+                case "for":
+                    return parseFor();
+                case "return": {
+                    next();
+                    Expr value = null;
+                    if (!peek().is(";") && peek().kind != Kind.NEWLINE && !peek().is("}") && peek().kind != Kind.END) {
+                        value = parseExpression();
+                    }
+                    endStatement();
+                    return new Return(line, value);
+                }
+                case "break":
+                case "continue":
+                    next();
+                    endStatement();
+                    return new Jump(line, token.text);
+                case "console.write":
+                case "console.writef": {
+                    next();
+                    expect("(", "after " + token.text);
+                    List<Expr> arguments = parseArguments();
+                    if (arguments.isEmpty()) {
+                        throw new ScriptException(token.text + "() requires at least one argument", line, null);
+                    }
+                    endStatement();
+                    return new Print(line, token.text.equals("console.write"), arguments);
+                }
+                case "elif":
+                case "else":
+                    throw new ScriptException("'" + token.text + "' without a matching if", line, null);
+                case "function":
+                    throw unsupported("A function inside a block", line);
+                case "struct":
+                case "interface":
+                case "namespace":
+                case "switch":
+                case "spawn":
+                case "import":
+                case "fn":
+                    throw unsupported("'" + token.text + "'", line);
+                default:
+                    break;
+            }
+        }
+        Node simple = parseSimple();
+        endStatement();
+        return simple;
+    }
+
+    // var name[: type] = value, or list name = [...]
+    private Declare parseDeclaration(int line, boolean list) {
+        String name = expectName("after var");
+        if (peek().is("(") || peek().is("[") || peek().is("{")) {
+            throw unsupported("Destructuring", line);
+        }
+        String type = !list && accept(":") ? parseType() : null;
+        Expr value = null;
+        if (accept("=")) {
+            value = parseExpression();
+        } else if (type == null) {
+            throw new ScriptException("Variable '" + name + "' needs a type or a value", line, null);
+        }
+        return new Declare(line, name, type, value);
+    }
+
+    // An assignment, increment or call: the statements allowed in a for header too
+    private Node parseSimple() {
+        int line = peek().line;
+        if (peek().is("++") || peek().is("--")) {
+            String operator = next().text;
+            return new Step(line, parsePostfix(), operator);
+        }
+        Expr target = parseExpression();
+        Token operator = peek();
+        if (operator.is("=") || operator.is("+=") || operator.is("-=") || operator.is("*=") || operator.is("/=") || operator.is("%=")) {
+            next();
+            checkTarget(target);
+            return new Assign(line, target, operator.text, parseExpression());
+        }
+        if (operator.is("++") || operator.is("--")) {
+            next();
+            checkTarget(target);
+            return new Step(line, target, operator.text);
+        }
+        if (!(target instanceof Call)) {
+            throw new ScriptException("Expression statement has no effect; only calls can stand alone when transpiling", line, null);
+        }
+        return new Evaluate(line, target);
+    }
+
+    private static void checkTarget(Expr target) {
+        if (!(target instanceof Name) && !(target instanceof Index)) {
+            throw new ScriptException("Only variables and list elements can be assigned to", target.line, null);
+        }
+    }
+
+    private If parseIf() {
+        If statement = new If(peek().line);
+        next();
+        parseBranch(statement, "if");
+        while (true) {
+            int save = pos;
+            skipNewlines();
+            if (accept("elif")) {
+                parseBranch(statement, "elif");
+            } else if (peek().is("else")) {
+                next();
+                if (accept("if")) {
+                    parseBranch(statement, "else if");
+                    continue;
+                }
+                statement.otherwise = parseBlock();
+                return statement;
+            } else {
+                pos = save;
+                return statement;
+            }
+        }
+    }
+
+    private void parseBranch(If statement, String keyword) {
+        expect("(", "after " + keyword);
+        statement.conditions.add(parseExpression());
+        expect(")", "after the " + keyword + " condition");
+        statement.branches.add(parseBlock());
+    }
+
+    private Node parseFor() {
+        int line = next().line;
+        expect("(", "after for");
+        // for (var item : items) has no ';' in its header
+        boolean each = true;
+        int depth = 0;
+        for (int i = pos; i < tokens.size() && tokens.get(i).kind != Kind.END; i++) {
+            Token token = tokens.get(i);
+            if (token.is("(") || token.is("[")) {
+                depth++;
+            } else if ((token.is(")") || token.is("]")) && depth-- == 0) {
+                break;
+            } else if (token.is(";") && depth == 0) {
+                each = false;
+                break;
+            }
+        }
+        if (each) {
+            accept("var");
+            String name = expectName("for the loop variable");
+            expect(":", "after the loop variable");
+            // for (var item: String : items) also gives the variable's type
+            String type = null;
+            int save = pos;
+            if (peek().kind == Kind.NAME && (TYPES.contains(peek().text) || peek().is("List"))) {
+                type = parseType();
+                if (!accept(":")) {
+                    type = null;
+                    pos = save;
+                }
+            }
+            Expr items = parseExpression();
+            expect(")", "after the for header");
+            return new Each(line, name, type, items, parseBlock());
+        }
+        Node init = null;
+        if (!peek().is(";")) {
+            init = peek().is("var") ? parseDeclaration(next().line, false) : parseSimple();
+        }
+        expect(";", "after the for loop's initializer");
+        Expr condition = peek().is(";") ? null : parseExpression();
+        expect(";", "after the for loop's condition");
+        Node update = peek().is(")") ? null : parseSimple();
+        expect(")", "after the for header");
+        return new For(line, init, condition, update, parseBlock());
+    }
+
+    private List<Expr> parseArguments() {
+        List<Expr> arguments = new ArrayList<>();
+        if (accept(")")) {
+            return arguments;
+        }
+        do {
+            arguments.add(parseExpression());
+        } while (accept(","));
+        expect(")", "after the arguments");
+        return arguments;
+    }
+
+    // Operators from loosest to tightest binding
+    private static final String[][] LEVELS = {
+        {"||"}, {"&&"}, {"==", "!=", "<", "<=", ">", ">="}, {"+", "-"}, {"*", "/", "//", "#", "%"}
+    };
+
+    private Expr parseExpression() {
+        return parseLevel(0);
+    }
+
+    private Expr parseLevel(int level) {
+        if (level == LEVELS.length) {
+            return parseUnary();
+        }
+        Expr left = parseLevel(level + 1);
+        while (true) {
+            Token token = peek();
+            String operator = null;
+            for (String candidate : LEVELS[level]) {
+                if (token.kind == Kind.SYMBOL && token.text.equals(candidate)) {
+                    operator = candidate;
+                }
+            }
+            if (operator == null) {
+                return left;
+            }
+            next();
+            left = new Binary(token.line, operator, left, parseLevel(level + 1));
+        }
+    }
+
+    private Expr parseUnary() {
+        Token token = peek();
+        if (token.is("!") || token.is("-") || token.is("+")) {
+            next();
+            Expr operand = parseUnary();
+            return token.text.equals("+") ? operand : new Unary(token.line, token.text, operand);
+        }
+        return parsePostfix();
+    }
+
+    private Expr parsePostfix() {
+        Expr expr = parsePrimary();
+        while (peek().is("[")) {
+            int line = next().line;
+            Expr index = parseExpression();
+            if (peek().is(":")) {
+                throw unsupported("A list slice", line);
+            }
+            expect("]", "after the index");
+            expr = new Index(line, expr, index);
+        }
+        return expr;
+    }
+
+    private Expr parsePrimary() {
+        Token token = next();
+        switch (token.kind) {
+            case NUMBER:
+                return new Literal(token.line, token.text, NUMBER);
+            case STRING:
+                return new Literal(token.line, token.text, "String");
+            case CHAR:
+                return new Literal(token.line, token.text, "Char");
+            case NAME:
+                if (token.text.equals("true") || token.text.equals("false")) {
+                    return new Literal(token.line, token.text, "Bool");
+                }
+                if (token.text.startsWith("math::")) {
+                    usesMath = true;
+                    mathLine = mathLine < 0 ? token.line : mathLine;
+                }
+                if (accept("(")) {
+                    return new Call(token.line, token.text, parseArguments());
+                }
+                if (token.text.contains(".")) {
+                    throw unsupported("Member access " + token.text, token.line);
+                }
+                return new Name(token.line, token.text);
+            case SYMBOL:
+                if (token.text.equals("(")) {
+                    Expr inner = parseExpression();
+                    expect(")", "to close the parenthesis");
+                    return inner;
+                }
+                if (token.text.equals("[")) {
+                    List<Expr> items = new ArrayList<>();
+                    if (!accept("]")) {
+                        do {
+                            items.add(parseExpression());
+                        } while (accept(","));
+                        expect("]", "to close the list");
+                    }
+                    return new ListOf(token.line, items);
+                }
+                if (token.text.equals("|")) {
+                    throw unsupported("An arrow function", token.line);
+                }
+                throw new ScriptException("Unexpected '" + token.text + "' in expression", token.line, null);
+            default:
+                throw new ScriptException("Expression expected", token.line, null);
+        }
+    }
+
+    /**
+     * Gives every expression its type, checking the program the way the
+     * interpreter would at run time, and splits console.write templates
+     * into the values they print
+     */
+    private static final class Resolver {
+        private final Program program;
+        private final Map<String, Function> functions = new HashMap<>();
+        private final List<Map<String, String>> scopes = new ArrayList<>();
+        private Function current;
+
+        Resolver(Program program) {
+            this.program = program;
+        }
+
+        void resolve() {
+            for (Function function : program.functions) {
+                if (functions.put(function.name, function) != null) {
+                    throw new ScriptException("Function " + function.name + " is defined twice", function.line, null);
+                }
+            }
+            // Globals are declared as the script's top level runs, but functions see them all
+            for (Node node : program.main.body) {
+                if (node instanceof Declare) {
+                    Declare declaration = (Declare) node;
+                    if (declaration.type != null) {
+                        program.globals.put(declaration.name, declaration.type);
+                    }
+                }
+            }
+            current = program.main;
+            scopes.add(program.globals);
+            block(program.main.body, false);
+            for (Function function : program.functions) {
+                current = function;
+                scopes.clear();
+                scopes.add(program.globals);
+                Map<String, String> parameters = new HashMap<>();
+                for (Variable parameter : function.parameters) {
+                    parameters.put(parameter.name, parameter.type);
+                }
+                scopes.add(parameters);
+                block(function.body, true);
+            }
+        }
+
+        private void block(List<Node> body, boolean scoped) {
+            if (scoped) {
+                scopes.add(new HashMap<>());
+            }
+            for (Node node : body) {
+                statement(node);
+            }
+            if (scoped) {
+                scopes.remove(scopes.size() - 1);
+            }
+        }
+
+        private String lookup(String name) {
+            for (int i = scopes.size() - 1; i >= 0; i--) {
+                String type = scopes.get(i).get(name);
+                if (type != null) {
+                    return type;
+                }
+            }
+            return null;
+        }
+
+        private void declare(String name, String type) {
+            scopes.get(scopes.size() - 1).put(name, type);
+        }
+
+        private void statement(Node node) {
+            if (node instanceof Declare) {
+                Declare declaration = (Declare) node;
+                if (declaration.value != null) {
+                    String type = expression(declaration.value);
+                    if (type.equals(VOID)) {
+                        throw new ScriptException("Type error: variable '" + declaration.name + "' is given the result of a function that returns nothing", node.line, null);
+                    }
+                    if (declaration.type == null) {
+                        if (elementType(type) != null && elementType(type).equals(VOID)) {
+                            throw new ScriptException("The type of the empty list '" + declaration.name + "' must be declared, e.g. List<Int32>", node.line, null);
+                        }
+                        declaration.type = type.equals(NUMBER) ? "Float64" : type.equals("List<" + NUMBER + ">") ? "List<Float64>" : type;
+                    }
+                    check(type, declaration.type, "variable '" + declaration.name + "'", node.line);
+                    retype(declaration.value, declaration.type);
+                }
+                if (declaration.global) {
+                    program.globals.put(declaration.name, declaration.type);
+                } else {
+                    declare(declaration.name, declaration.type);
+                }
+            } else if (node instanceof Assign) {
+                Assign assign = (Assign) node;
+                String target = target(assign.target);
+                String type = expression(assign.value);
+                if (assign.operator.equals("+=") && target.equals("String")) {
+                    return;
+                }
+                if (!assign.operator.equals("=") && !isNumeric(target)) {
+                    throw new ScriptException("Type error: " + assign.operator + " needs a number, got " + target, node.line, null);
+                }
+                check(type, target, "variable '" + describe(assign.target) + "'", node.line);
+                retype(assign.value, target);
+            } else if (node instanceof Step) {
+                Step step = (Step) node;
+                String type = target(step.target);
+                if (!isNumeric(type)) {
+                    throw new ScriptException("Type error: " + step.operator + " needs a number, got " + type, node.line, null);
+                }
+            } else if (node instanceof If) {
+                If statement = (If) node;
+                for (int i = 0; i < statement.conditions.size(); i++) {
+                    condition(statement.conditions.get(i));
+                    block(statement.branches.get(i), true);
+                }
+                if (statement.otherwise != null) {
+                    block(statement.otherwise, true);
+                }
+            } else if (node instanceof While) {
+                condition(((While) node).condition);
+                block(((While) node).body, true);
+            } else if (node instanceof For) {
+                For loop = (For) node;
+                scopes.add(new HashMap<>());
+                if (loop.init != null) {
+                    statement(loop.init);
+                }
+                if (loop.condition != null) {
+                    condition(loop.condition);
+                }
+                if (loop.update != null) {
+                    statement(loop.update);
+                }
+                block(loop.body, true);
+                scopes.remove(scopes.size() - 1);
+            } else if (node instanceof Each) {
+                Each loop = (Each) node;
+                String items = expression(loop.items);
+                String element = elementType(items);
+                if (element == null) {
+                    throw new ScriptException("Type error: a for loop needs a list to go through, got " + items, node.line, null);
+                }
+                if (loop.type == null) {
+                    loop.type = element.equals(NUMBER) ? "Float64" : element;
+                }
+                check(element, loop.type, "loop variable '" + loop.name + "'", node.line);
+                scopes.add(new HashMap<>());
+                declare(loop.name, loop.type);
+                block(loop.body, true);
+                scopes.remove(scopes.size() - 1);
+            } else if (node instanceof Return) {
+                Return statement = (Return) node;
+                if (statement.value == null) {
+                    if (!current.returnType.equals(VOID)) {
+                        throw new ScriptException(current.name + " must return " + current.returnType, node.line, null);
+                    }
+                    return;
+                }
+                if (current == program.main) {
+                    throw new ScriptException("return outside a function", node.line, null);
+                }
+                if (current.returnType.equals(VOID)) {
+                    throw new ScriptException(current.name + " returns a value but declares no return type", node.line, null);
+                }
+                String type = expression(statement.value);
+                check(type, current.returnType, "return value of " + current.name, node.line);
+                retype(statement.value, current.returnType);
+            } else if (node instanceof Print) {
+                print((Print) node);
+            } else if (node instanceof Evaluate) {
+                expression(((Evaluate) node).call);
+            }
+        }
+
+        private String target(Expr target) {
+            if (target instanceof Name) {
+                String type = lookup(((Name) target).name);
+                if (type == null) {
+                    throw new ScriptException("Undefined variable: " + ((Name) target).name, target.line, null);
+                }
+                target.type = type;
+                return type;
+            }
+            return expression(target);
+        }
+
+        private static String describe(Expr target) {
+            return target instanceof Name ? ((Name) target).name : describe(((Index) target).list) + "[...]";
+        }
+
+        private void condition(Expr condition) {
+            checkCondition(expression(condition), condition.line);
+        }
+
+        private static void checkCondition(String type, int line) {
+            if (!type.equals("Bool") && !isNumeric(type) && !type.equals("String")) {
+                throw new ScriptException("Type error: a condition must be a Bool, number or String, got " + type, line, null);
+            }
+        }
+
+        private static void check(String from, String to, String subject, int line) {
+            if (!assignable(from, to)) {
+                throw new ScriptException("Type error: " + subject + " expected " + to + ", got " + from, line, null);
+            }
+        }
+
+        // Number literals in a list take the element type the list is stored as
+        private static void retype(Expr value, String type) {
+            if (value instanceof ListOf && elementType(type) != null) {
+                value.type = type;
+            }
+        }
+
+        private String expression(Expr expr) {
+            expr.type = type(expr);
+            return expr.type;
+        }
+
+        private String type(Expr expr) {
+            if (expr instanceof Literal) {
+                return expr.type;
+            }
+            if (expr instanceof Name) {
+                String name = ((Name) expr).name;
+                if (name.startsWith("math::numbers::") && MATH_CONSTANTS.contains(name.substring(15))) {
+                    return "Float64";
+                }
+                String type = lookup(name);
+                if (type == null) {
+                    throw new ScriptException("Undefined variable: " + name, expr.line, null);
+                }
+                current.reads.add(name);
+                return type;
+            }
+            if (expr instanceof Call) {
+                return call((Call) expr);
+            }
+            if (expr instanceof Unary) {
+                Unary unary = (Unary) expr;
+                String operand = expression(unary.operand);
+                if (unary.operator.equals("!")) {
+                    checkCondition(operand, expr.line);
+                    return "Bool";
+                }
+                if (!isNumeric(operand)) {
+                    throw new ScriptException("Type error: cannot negate " + operand, expr.line, null);
+                }
+                return operand;
+            }
+            if (expr instanceof Binary) {
+                return binary((Binary) expr);
+            }
+            if (expr instanceof Index) {
+                Index index = (Index) expr;
+                String list = expression(index.list);
+                String position = expression(index.index);
+                if (elementType(list) == null) {
+                    throw new ScriptException("Type error: only lists can be indexed, got " + list, expr.line, null);
+                }
+                if (!isNumeric(position)) {
+                    throw new ScriptException("Type error: a list index must be a number, got " + position, expr.line, null);
+                }
+                return elementType(list).equals(NUMBER) ? "Float64" : elementType(list);
+            }
+            ListOf list = (ListOf) expr;
+            String element = VOID;
+            for (Expr item : list.items) {
+                String type = expression(item);
+                if (element.equals(VOID)) {
+                    element = type;
+                } else if (isNumeric(element) && isNumeric(type)) {
+                    element = widen(element, type);
+                } else if (!element.equals(type)) {
+                    throw new ScriptException("Lists must hold values of one type to be transpiled, got " + element + " and " + type, expr.line, null);
+                }
+            }
+            return "List<" + element + ">";
+        }
+
+        private String call(Call call) {
+            for (Expr argument : call.arguments) {
+                expression(argument);
+            }
+            if (call.name.startsWith("math::")) {
+                Integer arity = MATH_FUNCTIONS.get(call.name.substring(6));
+                if (arity == null) {
+                    throw new ScriptException("Undefined function: " + call.name, call.line, null);
+                }
+                if (call.arguments.size() != arity) {
+                    throw new ScriptException(call.name + " expects " + arity + (arity == 1 ? " argument" : " arguments")
+                        + ", got " + call.arguments.size(), call.line, null);
+                }
+                for (Expr argument : call.arguments) {
+                    if (!isNumeric(argument.type)) {
+                        throw new ScriptException("Type error: " + call.name + " expected a number, got " + argument.type, call.line, null);
+                    }
+                }
+                return "Float64";
+            }
+            Function function = functions.get(call.name);
+            if (function == null) {
+                if (call.name.contains("::") || call.name.contains(".")) {
+                    throw unsupported(call.name, call.line);
+                }
+                throw new ScriptException("Undefined function: " + call.name, call.line, null);
+            }
+            if (call.arguments.size() != function.parameters.size()) {
+                throw new ScriptException(call.name + " expects " + function.parameters.size() + " arguments, got "
+                    + call.arguments.size(), call.line, null);
+            }
+            for (int i = 0; i < call.arguments.size(); i++) {
+                Variable parameter = function.parameters.get(i);
+                check(call.arguments.get(i).type, parameter.type, "parameter '" + parameter.name + "' of " + function.name, call.line);
+                retype(call.arguments.get(i), parameter.type);
+            }
+            call.function = function;
+            return function.returnType;
+        }
+
+        private String binary(Binary binary) {
+            String left = expression(binary.left);
+            String right = expression(binary.right);
+            String operator = binary.operator;
+            if (left.equals(VOID) || right.equals(VOID)) {
+                throw new ScriptException("Type error: " + operator + " is given the result of a function that returns nothing", binary.line, null);
+            }
+            switch (operator) {
+                case "&&":
+                case "||":
+                    checkCondition(left, binary.line);
+                    checkCondition(right, binary.line);
+                    return "Bool";
+                case "==":
+                case "!=":
+                case "<":
+                case "<=":
+                case ">":
+                case ">=":
+                    if (isNumeric(left) && isNumeric(right)) {
+                        binary.operandType = widen(left, right);
+                    } else if (left.equals(right) && (left.equals("String") || left.equals("Char")
+                            || left.equals("Bool") && (operator.equals("==") || operator.equals("!=")))) {
+                        binary.operandType = left;
+                    } else {
+                        throw new ScriptException("Type error: cannot compare " + left + " " + operator + " " + right, binary.line, null);
+                    }
+                    return "Bool";
+                case "+":
+                    if (left.equals("String") || right.equals("String")) {
+                        binary.operandType = "String";
+                        return "String";
+                    }
+                    // Fall through to arithmetic
+                default:
+                    if (!isNumeric(left) || !isNumeric(right)) {
+                        throw new ScriptException("Type error: cannot apply " + operator + " to " + left + " and " + right, binary.line, null);
+                    }
+                    String type = widen(left, right);
+                    binary.operandType = type;
+                    // Division always gives a fraction, like the interpreter's
+                    if ((operator.equals("/") || operator.equals("#")) && isWhole(type)) {
+                        binary.operandType = "Float64";
+                        return "Float64";
+                    }
+                    // Floor division stays whole for whole operands, as in the interpreter, and is a Float64 otherwise
+                    if (operator.equals("//") && !isWhole(type)) {
+                        binary.operandType = "Float64";
+                        return "Float64";
+                    }
+                    return type;
+            }
+        }
+
+        // Splits a template such as "{x} is {}" into its text and the values it prints
+        private void print(Print print) {
+            List<Expr> arguments = print.arguments;
+            for (Expr argument : arguments) {
+                if (expression(argument).equals(VOID)) {
+                    throw new ScriptException("console.write is given the result of a function that returns nothing", print.line, null);
+                }
+            }
+            Expr first = arguments.get(0);
+            if (!(first instanceof Literal) || !first.type.equals("String")) {
+                print.pieces.add(first);
+                return;
+            }
+            String template = ((Literal) first).content();
+            StringBuilder text = new StringBuilder();
+            int positional = 1;
+            int i = 0;
+            while (i < template.length()) {
+                char c = template.charAt(i);
+                int close = c == '{' ? template.indexOf('}', i) : -1;
+                if (c == '\\' && i + 1 < template.length()) {
+                    text.append(template, i, i + 2);
+                    i += 2;
+                    continue;
+                }
+                if (close == -1) {
+                    text.append(c);
+                    i++;
+                    continue;
+                }
+                String inner = template.substring(i + 1, close).trim();
+                Expr value = null;
+                if (inner.isEmpty()) {
+                    if (arguments.size() > 1 && positional < arguments.size()) {
+                        value = arguments.get(positional++);
+                    }
+                } else if (FormatSpec.specStart(inner) >= 0) {
+                    throw new ScriptException("The format spec in {" + inner + "} can't be transpiled yet", print.line, null);
+                } else {
+                    value = placeholder(inner, print.line);
+                }
+                if (value == null) {
+                    text.append(template, i, close + 1);
+                } else {
+                    if (text.length() > 0) {
+                        print.pieces.add(new Literal(print.line, "\"" + text + "\"", "String"));
+                        text.setLength(0);
+                    }
+                    print.pieces.add(value);
+                }
+                i = close + 1;
+            }
+            if (text.length() > 0 || print.pieces.isEmpty()) {
+                print.pieces.add(new Literal(print.line, "\"" + text + "\"", "String"));
+            }
+        }
+
+        // The expression in a {placeholder}, or null when it isn't one, which prints as written
+        private Expr placeholder(String text, int line) {
+            List<Token> tokens;
+            try {
+                tokens = lex(List.of(text));
+            } catch (ScriptException e) {
+                return null;
+            }
+            List<Token> relined = new ArrayList<>();
+            for (Token token : tokens) {
+                relined.add(new Token(token.kind, token.text, line));
+            }
+            Transpiler parser = new Transpiler(relined);
+            Expr value;
+            try {
+                value = parser.parseExpression();
+            } catch (ScriptException e) {
+                return null;
+            }
+            parser.skipNewlines();
+            if (parser.peek().kind != Kind.END) {
+                return null;
+            }
+            if (value instanceof Name && lookup(((Name) value).name) == null
+                    && !((Name) value).name.startsWith("math::")) {
+                throw new ScriptException("Undefined variable: " + ((Name) value).name, line, null);
+            }
+            expression(value);
+            return value;
+        }
+    }
+}
@@ -0,0 +1,56 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.AbstractList;
+import java.util.List;
+
+/**
+ * An immutable, fixed-size group of values written (1, "a", true). A single
+ * element tuple needs a trailing comma, (x,), to tell it apart from a
+ * parenthesized expression.
+ *
+ * Functions return several values as a tuple (return a, b;), and a for loop
+ * over a map yields each entry as a (key, value) tuple. Tuples are indexed
+ * like lists and unpacked with var (a, b) = t;
+ */
+public class Tuple extends AbstractList<Object> {
+    private final Object[] items;
+
+    public Tuple(Object... items) {
+        this.items = items.clone();
+    }
+
+    public Tuple(List<?> items) {
+        this.items = items.toArray();
+    }
+
+    @Override
+    public Object get(int index) {
+        if (index < 0 || index >= items.length) {
+            throw new IndexOutOfBoundsException("Index " + index + " out of bounds for tuple of size " + items.length);
+        }
+        return items[index];
+    }
+
+    @Override
+    public int size() {
+        return items.length;
+    }
+
+    @Override
+    public String toString() {
+        StringBuilder text = new StringBuilder("(");
+        for (int i = 0; i < items.length; i++) {
+            if (i > 0) {
+                text.append(", ");
+            }
+            text.append(items[i]);
+        }
+        return text.append(items.length == 1 ? ",)" : ")").toString();
+    }
+}
@@ -0,0 +1,1017 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.HashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Checks types before a script runs, using the annotations on declarations,
+ * parameters and return types. Reports values that don't fit a declaration
+ * or assignment, calls with the wrong number or types of arguments, returns
+ * that don't match the function's return type, and arithmetic or comparison
+ * on values that aren't numbers. Expressions whose type is only known at
+ * run time (module calls, struct fields, list elements, macros) are accepted.
+ */
+public class TypeChecker {
+    // Inferred value types; null means unknown
+    private static final String INT = "Int";
+    private static final String FLOAT = "Float";
+    private static final String NUMBER = "Number"; // A number that may or may not be whole
+    private static final String STRING = "String";
+    private static final String CHAR = "Char";
+    private static final String BOOL = "Bool";
+    private static final String LIST = "List";
+
+    private static final Pattern FUNCTION_PATTERN = Pattern.compile("^function\\s+([\\w:]+)\\s*(?:<([^>]*)>)?\\(([^)]*)\\)\\s*(?:->\\s*(\\w+(?:<[^>{]*>+)?\\??(?:\\s*\\|\\s*\\w+(?:<[^>{]*>+)?\\??)*))?\\s*\\{");
+    private static final Pattern C_STYLE_FUNCTION_PATTERN = Pattern.compile("^(String|Int32|Int64|Float32|Float64|fn)\\s+([\\w:]+)\\s*\\(([^)]*)\\)\\s*\\{");
+    private static final Pattern VAR_PATTERN = Pattern.compile("^var\\s+([A-Za-z_]\\w*)\\s*(?::\\s*(\\w+(?:<[^>=]*>+)?\\??(?:\\s*\\|\\s*\\w+(?:<[^>=]*>+)?\\??)*))?\\s*=(?![=>])(.*)$");
+    private static final Pattern BOOL_PATTERN = Pattern.compile("^bool\\s+([A-Za-z_]\\w*)\\s*=(.*)$");
+    private static final Pattern ASSIGN_PATTERN = Pattern.compile("^([A-Za-z_]\\w*)\\s*([-+*/]?)=(?![=>])(.*)$");
+    private static final Pattern RETURN_PATTERN = Pattern.compile("^return\\b(.*)$");
+    private static final Pattern NARROWING_PATTERN = Pattern.compile("^if\\s*\\(\\s*([A-Za-z_]\\w*)\\s+is\\s+(\\w+\\??)\\s*\\)\\s*\\{$");
+    private static final Pattern CONDITION_PATTERN = Pattern.compile("^(?:\\}\\s*)?(?:if|elif|while)\\s*\\((.*)\\)\\s*\\{?$");
+
+    private static final List<String> SYMBOLS = Arrays.asList(
+        "<=>", "==", "!=", "<=", ">=", "&&", "||", "??",
+        "**", "//", "..<", "..", "+", "-", "*", "/", "#", "%", "<", ">", "!", "(", ")", "[", "]", ",", ".", "?", ":");
+    private static final List<String> COMPARISONS = Arrays.asList("==", "!=", "<", ">", "<=", ">=", "<=>");
+
+    private enum Kind {
+        NUMBER, STRING, CHAR, NAME, SYMBOL
+    }
+
+    private static class Token {
+        final Kind kind;
+        final String text;
+        final int position;
+
+        Token(Kind kind, String text, int position) {
+            this.kind = kind;
+            this.text = text;
+            this.position = position;
+        }
+    }
+
+    private static class Signature {
+        final List<String> parameters;
+        final String returnType;
+        // The T in function first<T>(xs: List<T>) -> T, bound afresh at each call
+        final List<String> typeParameters;
+
+        Signature(List<String> parameters, String returnType) {
+            this(parameters, returnType, new ArrayList<>());
+        }
+
+        Signature(List<String> parameters, String returnType, List<String> typeParameters) {
+            this.parameters = parameters;
+            this.returnType = returnType;
+            this.typeParameters = typeParameters;
+        }
+
+        // The type parameter an annotation names, or null: T and T? both name T
+        String typeParameter(String annotation) {
+            String name = annotation.endsWith("?") ? annotation.substring(0, annotation.length() - 1) : annotation;
+            return typeParameters.contains(name) ? name : null;
+        }
+    }
+
+    // The names visible to a statement: their value types and declared annotations
+    private static class Scope {
+        final Map<String, String> types = new HashMap<>();
+        final Map<String, String> annotations = new HashMap<>();
+
+        Scope() {
+        }
+
+        Scope(Scope parent) {
+            types.putAll(parent.types);
+            annotations.putAll(parent.annotations);
+        }
+
+        void declare(String name, String annotation, String type) {
+            if (annotation != null) {
+                annotations.put(name, annotation);
+                type = valueType(annotation);
+            } else {
+                annotations.remove(name);
+            }
+            types.put(name, type);
+        }
+    }
+
+    // Inside if (id is String) { ... } the checker treats id as a String
+    private static class Narrowing {
+        final int end;
+        final String name;
+        final boolean known;
+        final String previous;
+
+        Narrowing(int end, String name, Scope scope) {
+            this.end = end;
+            this.name = name;
+            this.known = scope.types.containsKey(name);
+            this.previous = scope.types.get(name);
+        }
+    }
+
+    private final Map<String, Signature> signatures = new HashMap<>();
+    private final List<Checker.Diagnostic> diagnostics = new ArrayList<>();
+    private boolean inBlockComment;
+
+    /**
+     * Type-checks the source and returns every error found, in source order
+     */
+    public static List<Checker.Diagnostic> check(List<String> lines) {
+        Define define = new Define();
+        List<String> source;
+        try {
+            // Macros expand in place, so line numbers still match the file
+            source = define.preprocess(lines);
+        } catch (RuntimeException e) {
+            // A broken macro is reported when the script runs; check the raw text
+            source = lines;
+            define = null;
+        }
+        try {
+            source = Pipeline.desugar(source);
+        } catch (RuntimeException e) {
+            // A malformed pipeline is reported when the script runs
+        }
+        source = Exports.strip(source);
+        TypeChecker checker = new TypeChecker();
+        Scope globals = checker.collect(source);
+        checker.checkLines(source, globals);
+
+        List<Checker.Diagnostic> found = define != null
+            ? Checker.atInvocations(checker.diagnostics, define) : checker.diagnostics;
+        found.sort((a, b) -> a.getLine() != b.getLine() ? a.getLine() - b.getLine() : a.getColumn() - b.getColumn());
+        return found;
+    }
+
+    /**
+     * First pass: function signatures and the annotated globals, so bodies can
+     * use functions and globals declared further down
+     */
+    private Scope collect(List<String> lines) {
+        Scope globals = new Scope();
+        int depth = 0;
+        for (String line : lines) {
+            String code = Checker.codeOnly(line);
+            String trimmed = code.trim();
+            Matcher function = FUNCTION_PATTERN.matcher(trimmed);
+            Matcher cStyle = C_STYLE_FUNCTION_PATTERN.matcher(trimmed);
+            // Functions inside classes and namespaces are called by qualified names
+            if (depth == 0 && function.find()) {
+                List<String> typeParameters = new ArrayList<>();
+                if (function.group(2) != null) {
+                    for (String name : function.group(2).split(",")) {
+                        typeParameters.add(name.trim());
+                    }
+                }
+                signatures.put(function.group(1), new Signature(parameterTypes(function.group(3)),
+                    function.group(4) != null ? function.group(4) : "void", typeParameters));
+            } else if (depth == 0 && cStyle.find()) {
+                signatures.put(cStyle.group(2), new Signature(parameterTypes(cStyle.group(3)), cStyle.group(1)));
+            } else if (depth == 0) {
+                Matcher declaration = VAR_PATTERN.matcher(trimmed);
+                if (declaration.find() && declaration.group(2) != null) {
+                    globals.declare(declaration.group(1), declaration.group(2), null);
+                }
+            }
+            depth += Checker.braceDelta(code);
+        }
+        return globals;
+    }
+
+    private static List<String> parameterTypes(String parameters) {
+        List<String> types = new ArrayList<>();
+        if (!parameters.trim().isEmpty()) {
+            for (String parameter : Parser.splitParameters(parameters)) {
+                String[] parts = parameter.split(":");
+                types.add(parts.length == 2 ? parts[1].trim() : null);
+            }
+        }
+        return types;
+    }
+
+    private static List<String> parameterNames(String parameters) {
+        List<String> names = new ArrayList<>();
+        if (!parameters.trim().isEmpty()) {
+            for (String parameter : Parser.splitParameters(parameters)) {
+                names.add(parameter.split(":")[0].trim());
+            }
+        }
+        return names;
+    }
+
+    private void checkLines(List<String> lines, Scope globals) {
+        List<Narrowing> narrowed = new ArrayList<>();
+        for (int i = 0; i < lines.size(); i++) {
+            String line = lines.get(i);
+            String trimmed = line.trim();
+            if (skipComment(trimmed)) {
+                continue;
+            }
+
+            Matcher function = FUNCTION_PATTERN.matcher(trimmed);
+            Matcher cStyle = C_STYLE_FUNCTION_PATTERN.matcher(trimmed);
+            boolean isFunction = function.find();
+            if (isFunction || cStyle.find()) {
+                int end = Checker.blockEnd(lines, i);
+                if (end < 0) {
+                    continue; // Reported by the bracket check
+                }
+                String name = isFunction ? function.group(1) : cStyle.group(2);
+                String parameters = isFunction ? function.group(3) : cStyle.group(3);
+                String returnType = isFunction
+                    ? (function.group(4) != null ? function.group(4) : "void") : cStyle.group(1);
+                Scope locals = new Scope(globals);
+                List<String> names = parameterNames(parameters);
+                List<String> types = parameterTypes(parameters);
+                for (int p = 0; p < names.size(); p++) {
+                    locals.declare(names.get(p), types.get(p), null);
+                }
+                List<Narrowing> narrowedLocals = new ArrayList<>();
+                for (int j = i + 1; j < end; j++) {
+                    if (!skipComment(lines.get(j).trim())) {
+                        narrow(lines, j, locals, narrowedLocals);
+                        checkStatement(lines.get(j), j, locals, name, returnType);
+                    }
+                }
+                i = end;
+                continue;
+            }
+            narrow(lines, i, globals, narrowed);
+            checkStatement(line, i, globals, null, null);
+        }
+    }
+
+    // Ends the narrowings whose blocks closed before this line, then starts
+    // one if the line opens an if (name is Type) block
+    private void narrow(List<String> lines, int index, Scope scope, List<Narrowing> narrowed) {
+        for (int k = narrowed.size() - 1; k >= 0; k--) {
+            Narrowing narrowing = narrowed.get(k);
+            if (narrowing.end >= index) {
+                continue;
+            }
+            if (narrowing.known) {
+                scope.types.put(narrowing.name, narrowing.previous);
+            } else {
+                scope.types.remove(narrowing.name);
+            }
+            narrowed.remove(k);
+        }
+        Matcher matcher = NARROWING_PATTERN.matcher(Checker.codeOnly(lines.get(index)).trim());
+        if (matcher.find()) {
+            int end = Checker.blockEnd(lines, index);
+            if (end > index) {
+                narrowed.add(new Narrowing(end, matcher.group(1), scope));
+                scope.types.put(matcher.group(1), valueType(matcher.group(2)));
+            }
+        }
+    }
+
+    // Tracks /* */ comments across lines; true when the line is part of one
+    private boolean skipComment(String trimmed) {
+        if (inBlockComment || trimmed.startsWith("/*")) {
+            inBlockComment = !trimmed.contains("*/");
+            return true;
+        }
+        return false;
+    }
+
+    private void checkStatement(String line, int index, Scope scope, String function, String returnType) {
+        String code = Checker.codeOnly(line);
+        // Keep string contents, but drop a trailing line comment
+        String statement = line.substring(0, code.length()).trim();
+        if (statement.isEmpty() || statement.startsWith("#")) {
+            return;
+        }
+        if (statement.endsWith(";")) {
+            statement = statement.substring(0, statement.length() - 1).trim();
+        }
+        // A deferred statement is checked as if it ran where it's written
+        if (statement.matches("defer\\s+[^=].*")) {
+            statement = statement.substring(5).trim();
+        }
+        // A static variable is checked like any other declaration
+        if (statement.matches("static\\s+var\\s.*")) {
+            statement = statement.substring(6).trim();
+        }
+        int base = line.indexOf(statement);
+
+        Matcher declaration = VAR_PATTERN.matcher(statement);
+        if (declaration.find()) {
+            String name = declaration.group(1);
+            String annotation = declaration.group(2);
+            String value = declaration.group(3);
+            String type = isLambda(value) ? null : checkValue(value, annotation, scope, index,
+                base + declaration.start(3), "Cannot assign %s to '" + name + "' of type %s");
+            scope.declare(name, annotation, type);
+            return;
+        }
+
+        Matcher bool = BOOL_PATTERN.matcher(statement);
+        if (bool.find()) {
+            checkValue(bool.group(2), "Bool", scope, index, base + bool.start(2),
+                "Cannot assign %s to '" + bool.group(1) + "' of type %s");
+            scope.declare(bool.group(1), "Bool", null);
+            return;
+        }
+
+        Matcher result = RETURN_PATTERN.matcher(statement);
+        if (result.find()) {
+            String value = result.group(1);
+            if (function == null || value.trim().isEmpty()) {
+                return;
+            }
+            if (returnType.equals("void") || returnType.equals("fn")) {
+                diagnostics.add(new Checker.Diagnostic(index, base, statement.length(), Checker.Severity.ERROR,
+                    "type-mismatch", "Function '" + function + "' has no return type but returns a value"));
+                return;
+            }
+            checkValue(value, returnType, scope, index, base + result.start(1),
+                "Function '" + function + "' returns %2$s, got %1$s");
+            return;
+        }
+
+        Matcher condition = CONDITION_PATTERN.matcher(statement);
+        if (condition.find()) {
+            infer(condition.group(1), scope, index, base + condition.start(1));
+            return;
+        }
+
+        Matcher assignment = ASSIGN_PATTERN.matcher(statement);
+        if (assignment.find() && !isLambda(assignment.group(3))) {
+            String name = assignment.group(1);
+            String operator = assignment.group(2);
+            int column = base + assignment.start(3);
+            if (operator.isEmpty()) {
+                checkValue(assignment.group(3), scope.annotations.get(name), scope, index, column,
+                    "Cannot assign %s to '" + name + "' of type %s");
+                return;
+            }
+            String type = infer(assignment.group(3), scope, index, column);
+            for (String operand : Arrays.asList(scope.types.get(name), type)) {
+                if (operand != null && !isNumeric(operand)) {
+                    diagnostics.add(new Checker.Diagnostic(index, base + assignment.start(2), 2, Checker.Severity.ERROR,
+                        "operand-type", "Operator '" + operator + "=' expects numbers, got " + operand));
+                    return;
+                }
+            }
+            return;
+        }
+
+        if (!statement.startsWith("for") && !statement.startsWith("switch") && !statement.startsWith("case")) {
+            infer(statement, scope, index, base);
+        }
+    }
+
+    /**
+     * Infers the value's type and reports it when it doesn't fit the
+     * annotation. The message takes the value's type, then the annotation.
+     */
+    private String checkValue(String value, String annotation, Scope scope, int line, int column, String message) {
+        int offset = value.length() - value.replaceAll("^\\s+", "").length();
+        // Only nullable types such as String? or Int32 | null take null
+        if (value.trim().equals("null") && annotation != null && !nullable(annotation)
+                && !scope.types.containsKey("null")) {
+            diagnostics.add(new Checker.Diagnostic(line, column + offset, 4, Checker.Severity.ERROR,
+                "type-mismatch", String.format(message, "null", annotation)));
+            return null;
+        }
+        String type = infer(value, scope, line, column);
+        if (annotation != null && type != null && !compatible(annotation, type)) {
+            diagnostics.add(new Checker.Diagnostic(line, column + offset, value.trim().length(), Checker.Severity.ERROR,
+                "type-mismatch", String.format(message, type, annotation)));
+        }
+        return type;
+    }
+
+    /**
+     * Infers an expression's type, reporting operator and call errors inside
+     * it. Syntax this pass doesn't model gives an unknown type and no errors.
+     */
+    private String infer(String text, Scope scope, int line, int column) {
+        try {
+            Expression expression = new Expression(tokenize(text), scope, line, column);
+            String type = expression.parse();
+            diagnostics.addAll(expression.found);
+            return type;
+        } catch (IllegalArgumentException e) {
+            return null;
+        }
+    }
+
+    /**
+     * The syntax error in an expression built only from tokens this pass
+     * knows, or null when it parses or uses syntax the pass doesn't model
+     */
+    static String syntaxError(String text) {
+        List<Token> tokens;
+        try {
+            tokens = tokenize(text);
+        } catch (IllegalArgumentException e) {
+            return null;
+        }
+        // spawn and await prefix a call rather than starting an expression
+        if (!tokens.isEmpty() && tokens.get(0).kind == Kind.NAME
+            && (tokens.get(0).text.equals("spawn") || tokens.get(0).text.equals("await"))) {
+            return null;
+        }
+        try {
+            new TypeChecker().new Expression(tokens, new Scope(), 0, 0).parse();
+            return null;
+        } catch (IllegalArgumentException e) {
+            return e.getMessage();
+        }
+    }
+
+    private static List<Token> tokenize(String text) {
+        List<Token> tokens = new ArrayList<>();
+        int i = 0;
+        while (i < text.length()) {
+            char c = text.charAt(i);
+            int start = i;
+            if (Character.isWhitespace(c)) {
+                i++;
+            } else if (Character.isDigit(c)) {
+                while (i < text.length() && (Character.isDigit(text.charAt(i))
+                        || text.charAt(i) == '.' && !text.startsWith("..", i))) {
+                    i++;
+                }
+                tokens.add(new Token(Kind.NUMBER, text.substring(start, i), start));
+            } else if (c == '"') {
+                i++;
+                while (i < text.length() && text.charAt(i) != '"') {
+                    i += text.charAt(i) == '\\' ? 2 : 1;
+                }
+                if (i >= text.length()) {
+                    throw new IllegalArgumentException("Unterminated string");
+                }
+                i++;
+                tokens.add(new Token(Kind.STRING, text.substring(start, i), start));
+            } else if (c == '\'') {
+                if (i + 2 >= text.length() || text.charAt(i + 2) != '\'') {
+                    throw new IllegalArgumentException("Invalid character literal");
+                }
+                i += 3;
+                tokens.add(new Token(Kind.CHAR, text.substring(start, i), start));
+            } else if (Character.isLetter(c) || c == '_') {
+                while (i < text.length()) {
+                    if (Character.isLetterOrDigit(text.charAt(i)) || text.charAt(i) == '_') {
+                        i++;
+                    } else if (text.startsWith("::", i)) {
+                        i += 2;
+                    } else {
+                        break;
+                    }
+                }
+                tokens.add(new Token(Kind.NAME, text.substring(start, i), start));
+            } else {
+                String symbol = null;
+                for (String candidate : SYMBOLS) {
+                    if (text.startsWith(candidate, i)) {
+                        symbol = candidate;
+                        break;
+                    }
+                }
+                if (symbol == null) {
+                    throw new IllegalArgumentException("Unexpected '" + c + "'");
+                }
+                i += symbol.length();
+                tokens.add(new Token(Kind.SYMBOL, symbol, start));
+            }
+        }
+        return tokens;
+    }
+
+    /**
+     * A recursive descent pass over one expression, with the evaluator's
+     * precedence: ternary, ||, &&, comparison, + -, * / # %, unary, postfix
+     */
+    private class Expression {
+        private final List<Token> tokens;
+        private final Scope scope;
+        private final int line;
+        private final int column;
+        private final List<Checker.Diagnostic> found = new ArrayList<>();
+        private int pos;
+
+        Expression(List<Token> tokens, Scope scope, int line, int column) {
+            this.tokens = tokens;
+            this.scope = scope;
+            this.line = line;
+            this.column = column;
+        }
+
+        String parse() {
+            String type = ternary();
+            if (pos != tokens.size()) {
+                throw new IllegalArgumentException("Unexpected " + tokens.get(pos).text);
+            }
+            return type;
+        }
+
+        private String ternary() {
+            String condition = coalesce();
+            if (!accept("?")) {
+                return condition;
+            }
+            String whenTrue = ternary();
+            expect(":");
+            String whenFalse = ternary();
+            if (whenTrue != null && whenTrue.equals(whenFalse)) {
+                return whenTrue;
+            }
+            return whenTrue != null && whenFalse != null && isNumeric(whenTrue) && isNumeric(whenFalse) ? NUMBER : null;
+        }
+
+        // a ?? b has a's type when b has the same one
+        private String coalesce() {
+            String type = or();
+            while (accept("??")) {
+                String fallback = or();
+                type = type != null && type.equals(fallback) ? type : null;
+            }
+            return type;
+        }
+
+        private String or() {
+            String type = and();
+            while (accept("||")) {
+                and();
+                type = BOOL;
+            }
+            return type;
+        }
+
+        private String and() {
+            String type = comparison();
+            while (accept("&&")) {
+                comparison();
+                type = BOOL;
+            }
+            return type;
+        }
+
+        // The evaluator compares numbers only, so strings can't be compared with ==
+        private String comparison() {
+            String left = range();
+            // Chained comparisons check each pair: in 0 <= x < 10, 0 with x and x with 10
+            String operand = left;
+            while (peekSymbol(COMPARISONS)) {
+                Token operator = tokens.get(pos++);
+                String right = range();
+                left = numbers(operator, operand, right) ? (operator.text.equals("<=>") ? INT : BOOL) : null;
+                operand = right;
+            }
+            // A membership test: x in xs, "key" in config
+            if (acceptName("in")) {
+                range();
+                return BOOL;
+            }
+            // A type test: id is String, name is String?
+            if (acceptName("is")) {
+                Token type = next();
+                if (type.kind != Kind.NAME) {
+                    throw new IllegalArgumentException("Expected a type after is");
+                }
+                if (pos < tokens.size() && tokens.get(pos).text.equals("?")
+                        && tokens.get(pos).position == type.position + type.text.length()) {
+                    pos++;
+                }
+                return BOOL;
+            }
+            return left;
+        }
+
+        // A range of whole numbers: 1..10, 0..<n
+        private String range() {
+            String left = additive();
+            if (peekSymbol(Arrays.asList("..", "..<"))) {
+                Token operator = tokens.get(pos++);
+                String right = additive();
+                return numbers(operator, left, right) ? LIST : null;
+            }
+            return left;
+        }
+
+        private String additive() {
+            String left = term();
+            while (peekSymbol(Arrays.asList("+", "-"))) {
+                Token operator = tokens.get(pos++);
+                left = arithmetic(operator, left, term());
+            }
+            return left;
+        }
+
+        private String term() {
+            String left = unary();
+            while (peekSymbol(Arrays.asList("*", "/", "//", "#", "%"))) {
+                Token operator = tokens.get(pos++);
+                left = arithmetic(operator, left, unary());
+            }
+            return left;
+        }
+
+        private String unary() {
+            if (accept("!") || acceptName("not")) {
+                unary();
+                return BOOL;
+            }
+            if (peekSymbol(Arrays.asList("-"))) {
+                Token operator = tokens.get(pos++);
+                String type = unary();
+                if (!numbers(operator, type)) {
+                    return null;
+                }
+                return type == null || type.equals(BOOL) ? NUMBER : type;
+            }
+            return power();
+        }
+
+        // ** binds tighter than unary minus and groups from the right
+        private String power() {
+            String base = postfix();
+            if (peekSymbol(Arrays.asList("**"))) {
+                Token operator = tokens.get(pos++);
+                return arithmetic(operator, base, unary());
+            }
+            return base;
+        }
+
+        private String postfix() {
+            String type = primary();
+            while (true) {
+                if (accept(".")) {
+                    if (next().kind != Kind.NAME) {
+                        throw new IllegalArgumentException("Expected a field name");
+                    }
+                    if (accept("(")) {
+                        arguments(new ArrayList<>());
+                    }
+                    type = null; // Fields and methods are resolved at run time
+                } else if (accept("[")) {
+                    // An index, or a slice with either bound left out: xs[1:3], xs[:2]
+                    if (!peekSymbol(Arrays.asList(":"))) {
+                        ternary();
+                    }
+                    if (accept(":") && !peekSymbol(Arrays.asList("]"))) {
+                        ternary();
+                    }
+                    expect("]");
+                    type = null;
+                } else {
+                    return type;
+                }
+            }
+        }
+
+        private String primary() {
+            Token token = next();
+            switch (token.kind) {
+                case NUMBER:
+                    return token.text.contains(".") ? FLOAT : INT;
+                case STRING:
+                    return STRING;
+                case CHAR:
+                    return CHAR;
+                case NAME:
+                    return name(token);
+                default:
+                    break;
+            }
+            if (token.text.equals("(")) {
+                String type = ternary();
+                // A tuple: (1, "a") or (x,)
+                if (accept(",")) {
+                    type = null;
+                    while (!peekSymbol(Arrays.asList(")"))) {
+                        ternary();
+                        if (!accept(",")) {
+                            break;
+                        }
+                    }
+                }
+                expect(")");
+                return type;
+            }
+            if (token.text.equals("[")) {
+                if (!accept("]")) {
+                    acceptSpread();
+                    ternary();
+                    // A comprehension binds its own variable, so skip to the closing bracket
+                    if (acceptName("for")) {
+                        for (int depth = 0; depth > 0 || !peekSymbol(Arrays.asList("]")); pos++) {
+                            if (pos >= tokens.size()) {
+                                throw new IllegalArgumentException("Expected ]");
+                            }
+                            if (peekSymbol(Arrays.asList("(", "["))) {
+                                depth++;
+                            } else if (peekSymbol(Arrays.asList(")", "]"))) {
+                                depth--;
+                            }
+                        }
+                        expect("]");
+                        return LIST;
+                    }
+                    while (accept(",")) {
+                        acceptSpread();
+                        ternary();
+                    }
+                    expect("]");
+                }
+                return LIST;
+            }
+            throw new IllegalArgumentException("Unexpected " + token.text);
+        }
+
+        private String name(Token token) {
+            if (token.text.equals("true") || token.text.equals("false")) {
+                return BOOL;
+            }
+            if (!accept("(")) {
+                return scope.types.get(token.text);
+            }
+            List<int[]> spans = new ArrayList<>();
+            List<String> types = arguments(spans);
+            Signature signature = signatures.get(token.text);
+            // A variable holding a lambda can share a function's name
+            if (signature == null || scope.types.containsKey(token.text)) {
+                return null;
+            }
+            // Spread and named arguments aren't checked until they run
+            if (types == null) {
+                return valueType(signature.returnType);
+            }
+            return checkCall(token, signature, types, spans);
+        }
+
+        // Parses arguments up to the closing parenthesis, recording each one's
+        // token span; null when an argument is spread with ... or passed by name
+        private List<String> arguments(List<int[]> spans) {
+            List<String> types = new ArrayList<>();
+            if (accept(")")) {
+                return types;
+            }
+            boolean unchecked = false;
+            do {
+                unchecked |= acceptSpread() || acceptArgumentName();
+                int first = pos;
+                types.add(ternary());
+                spans.add(new int[]{first, pos});
+            } while (accept(","));
+            expect(")");
+            return unchecked ? null : types;
+        }
+
+        // Skips the width: of an argument passed by name
+        private boolean acceptArgumentName() {
+            if (pos + 1 >= tokens.size() || tokens.get(pos).kind != Kind.NAME
+                    || tokens.get(pos + 1).kind != Kind.SYMBOL || !tokens.get(pos + 1).text.equals(":")) {
+                return false;
+            }
+            pos += 2;
+            return true;
+        }
+
+        // Skips the ... of a spread element or argument
+        private boolean acceptSpread() {
+            for (int i = 0; i < 3; i++) {
+                if (pos + i >= tokens.size() || !tokens.get(pos + i).text.equals(".")
+                        || tokens.get(pos + i).kind != Kind.SYMBOL) {
+                    return false;
+                }
+            }
+            pos += 3;
+            return true;
+        }
+
+        // Checks the arguments and returns the call's type. A type parameter
+        // takes the type of the first argument passed for it, and later
+        // arguments for it must match.
+        private String checkCall(Token name, Signature signature, List<String> types, List<int[]> spans) {
+            int expected = signature.parameters.size();
+            if (types.size() != expected) {
+                found.add(new Checker.Diagnostic(line, column + name.position, name.text.length(), Checker.Severity.ERROR,
+                    "argument-count", "Function '" + name.text + "' expects " + expected
+                    + (expected == 1 ? " argument" : " arguments") + ", got " + types.size()));
+                return valueType(signature.returnType);
+            }
+            Map<String, String> bound = new HashMap<>();
+            Map<String, Integer> boundBy = new HashMap<>();
+            for (int i = 0; i < expected; i++) {
+                String parameter = signature.parameters.get(i);
+                String type = types.get(i);
+                String typeParameter = parameter == null ? null : signature.typeParameter(parameter);
+                String message = null;
+                if (typeParameter != null && type != null) {
+                    String previous = bound.get(typeParameter);
+                    String unified = previous == null ? type : unify(previous, type);
+                    if (unified == null) {
+                        message = "Argument " + (i + 1) + " of '" + name.text + "' expects " + typeParameter + ", which is "
+                            + previous + " from argument " + boundBy.get(typeParameter) + ", got " + type;
+                    } else {
+                        bound.put(typeParameter, unified);
+                        boundBy.putIfAbsent(typeParameter, i + 1);
+                    }
+                } else if (typeParameter == null && parameter != null && !compatible(parameter, type)) {
+                    message = "Argument " + (i + 1) + " of '" + name.text + "' expects " + parameter + ", got " + type;
+                }
+                if (message != null) {
+                    Token first = tokens.get(spans.get(i)[0]);
+                    Token last = tokens.get(spans.get(i)[1] - 1);
+                    found.add(new Checker.Diagnostic(line, column + first.position,
+                        last.position + last.text.length() - first.position, Checker.Severity.ERROR, "type-mismatch",
+                        message));
+                }
+            }
+            String returned = signature.typeParameter(signature.returnType);
+            return returned != null ? bound.get(returned) : valueType(signature.returnType);
+        }
+
+        private String arithmetic(Token operator, String left, String right) {
+            // Lists concatenate with + and repeat with *
+            if (operator.text.equals("+") && (LIST.equals(left) || LIST.equals(right))) {
+                if (left != null && right != null && !(LIST.equals(left) && LIST.equals(right))) {
+                    found.add(new Checker.Diagnostic(line, column + operator.position, 1, Checker.Severity.ERROR,
+                        "operand-type", "Operator '+' expects two lists or two numbers, got " + left + " and " + right));
+                }
+                return LIST;
+            }
+            if (operator.text.equals("*") && (LIST.equals(left) || LIST.equals(right))) {
+                String count = LIST.equals(left) ? right : left;
+                return numbers(operator, count) ? LIST : null;
+            }
+            // Strings repeat with *: "-" * 40
+            if (operator.text.equals("*") && (STRING.equals(left) || STRING.equals(right))) {
+                String count = STRING.equals(left) ? right : left;
+                return numbers(operator, count) ? STRING : null;
+            }
+            if (!numbers(operator, left, right)) {
+                return null;
+            }
+            if (operator.text.equals("#")) {
+                return INT;
+            }
+            if (FLOAT.equals(left) || FLOAT.equals(right)) {
+                return FLOAT;
+            }
+            // Floor division too gives a whole number only for whole operands; 7.5 // 2 is 3.0
+            if (!operator.text.equals("/") && isWhole(left) && isWhole(right)) {
+                return INT;
+            }
+            return NUMBER;
+        }
+
+        // Reports the first operand that is known not to be a number
+        private boolean numbers(Token operator, String... operands) {
+            for (String operand : operands) {
+                if (operand != null && !isNumeric(operand)) {
+                    found.add(new Checker.Diagnostic(line, column + operator.position, operator.text.length(),
+                        Checker.Severity.ERROR, "operand-type",
+                        "Operator '" + operator.text + "' expects numbers, got " + operand));
+                    return false;
+                }
+            }
+            return true;
+        }
+
+        private Token next() {
+            if (pos >= tokens.size()) {
+                throw new IllegalArgumentException("Unexpected end of expression");
+            }
+            return tokens.get(pos++);
+        }
+
+        private boolean accept(String symbol) {
+            if (peekSymbol(Arrays.asList(symbol))) {
+                pos++;
+                return true;
+            }
+            return false;
+        }
+
+        private boolean acceptName(String name) {
+            if (pos < tokens.size() && tokens.get(pos).kind == Kind.NAME && tokens.get(pos).text.equals(name)) {
+                pos++;
+                return true;
+            }
+            return false;
+        }
+
+        private void expect(String symbol) {
+            if (!accept(symbol)) {
+                throw new IllegalArgumentException("Expected " + symbol);
+            }
+        }
+
+        private boolean peekSymbol(List<String> symbols) {
+            return pos < tokens.size() && tokens.get(pos).kind == Kind.SYMBOL && symbols.contains(tokens.get(pos).text);
+        }
+    }
+
+    private static boolean isLambda(String value) {
+        String trimmed = value.trim();
+        return trimmed.startsWith("|") || trimmed.contains("=>") || trimmed.startsWith("{");
+    }
+
+    /**
+     * The value type an annotation holds, or null for types this pass doesn't
+     * track (structs, maps, tasks, mutexes and type parameters)
+     */
+    private static String valueType(String annotation) {
+        if (annotation == null || annotation.contains("|")) {
+            return null; // A union's members are checked one by one
+        }
+        // A nullable String? holds strings too, and List<T> is a list whatever T is
+        String type = annotation.endsWith("?") ? annotation.substring(0, annotation.length() - 1) : annotation;
+        if (type.contains("<")) {
+            type = type.substring(0, type.indexOf('<')).trim();
+        }
+        switch (type) {
+            case "Int32":
+            case "Int64":
+                return INT;
+            case "Float32":
+            case "Float64":
+                return FLOAT;
+            case "String":
+                return STRING;
+            case "Char":
+                return CHAR;
+            case "Bool":
+                return BOOL;
+            case "List":
+                return LIST;
+            default:
+                return null;
+        }
+    }
+
+    // The type two values bound to the same type parameter share, or null
+    // when they don't fit together; whole and fractional numbers share Float
+    private static String unify(String left, String right) {
+        if (left.equals(right)) {
+            return left;
+        }
+        if (left.equals(BOOL) || right.equals(BOOL) || !isNumeric(left) || !isNumeric(right)) {
+            return null;
+        }
+        return left.equals(FLOAT) || right.equals(FLOAT) ? FLOAT : NUMBER;
+    }
+
+    private static boolean compatible(String annotation, String type) {
+        // A union takes a value that fits any of its members
+        if (annotation.contains("|")) {
+            for (String member : annotation.split("\\|")) {
+                if (compatible(member.trim(), type)) {
+                    return true;
+                }
+            }
+            return false;
+        }
+        String expected = valueType(annotation);
+        if (expected == null || type == null) {
+            return true;
+        }
+        switch (expected) {
+            case INT:
+                return type.equals(INT) || type.equals(NUMBER);
+            case FLOAT:
+                return isNumeric(type) && !type.equals(BOOL);
+            default:
+                return expected.equals(type);
+        }
+    }
+
+    // String?, Int32? | String and Int32 | null take null
+    private static boolean nullable(String annotation) {
+        for (String member : annotation.split("\\|")) {
+            if (member.trim().endsWith("?") || member.trim().equals("null")) {
+                return true;
+            }
+        }
+        return false;
+    }
+
+    // Booleans count as 1 and 0 in arithmetic
+    private static boolean isNumeric(String type) {
+        return type.equals(INT) || type.equals(FLOAT) || type.equals(NUMBER) || type.equals(BOOL);
+    }
+
+    private static boolean isWhole(String type) {
+        return INT.equals(type) || BOOL.equals(type);
+    }
+}
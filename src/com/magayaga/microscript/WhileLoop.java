@@ -11,6 +11,8 @@ import java.util.regex.Matcher;
 import java.util.regex.Pattern;
 
 public class WhileLoop {
+    // Pre-compiled regex patterns
+    private static final Pattern WHILE_PATTERN = Pattern.compile("while\\s*\\((.+?)\\)\\s*(\\{)?");
     
     /**
      * Process a while loop statement in the code
@@ -23,8 +25,7 @@ public class WhileLoop {
         String line = lines.get(startIndex).trim();
         
         // Extract condition from while statement
-        Pattern whilePattern = Pattern.compile("while\\s*\\((.+?)\\)\\s*(\\{)?");
-        Matcher whileMatcher = whilePattern.matcher(line);
+        Matcher whileMatcher = WHILE_PATTERN.matcher(line);
         
         if (!whileMatcher.find()) {
             throw new RuntimeException("Invalid while loop syntax at line: " + line);
@@ -71,6 +72,7 @@ public class WhileLoop {
         
         // While loop execution
         while (iterations < MAX_ITERATIONS) {
+            executor.getEnvironment().getCancellation().check();
             // Evaluate the condition
             Object conditionResult = executor.evaluate(condition);
             boolean conditionValue = isTruthyValue(conditionResult);
@@ -110,8 +112,9 @@ public class WhileLoop {
      */
     private static void executeLoopBlock(List<String> lines, int startIndex, int endIndex, 
                                         Executor executor) throws Statements.BreakException, Statements.ContinueException {
+        List<Statement> statements = Statement.of(lines);
         for (int i = startIndex; i < endIndex; i++) {
-            String line = lines.get(i).trim();
+            String line = statements.get(i).getText();
             
             // Skip empty lines and comments
             if (line.isEmpty() || line.startsWith("//")) {
@@ -222,40 +225,7 @@ public class WhileLoop {
      * @return Index of the line with the matching closing brace, or -1 if not found
      */
     private static int findMatchingClosingBrace(List<String> lines, int openBraceIndex) {
-        int braceCount = 0;
-        boolean foundOpenBrace = false;
-        
-        for (int i = openBraceIndex; i < lines.size(); i++) {
-            String line = lines.get(i).trim();
-            
-            // Skip empty lines and comments
-            if (line.isEmpty() || line.startsWith("//")) {
-                continue;
-            }
-            
-            // Handle multi-line comments
-            if (line.startsWith("/*")) {
-                while (i < lines.size() && !lines.get(i).contains("*/")) {
-                    i++;
-                }
-                continue;
-            }
-            
-            // Count braces in the line
-            for (char c : line.toCharArray()) {
-                if (c == '{') {
-                    braceCount++;
-                    foundOpenBrace = true;
-                } else if (c == '}') {
-                    braceCount--;
-                    if (foundOpenBrace && braceCount == 0) {
-                        return i;
-                    }
-                }
-            }
-        }
-        
-        return -1;
+        return Statement.of(lines).get(openBraceIndex).closingBrace(lines, openBraceIndex);
     }
     
     private static boolean isTruthyValue(Object value) {
@@ -0,0 +1,109 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.util.List;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Blocks that close what they open, however they are left:
+ *
+ *   with (var cache = redis::connect()) {
+ *       redis::set(cache, "greeting", "Hello");
+ *   }
+ *
+ * using is another name for with. The value is closed when the block ends,
+ * even when a statement in it fails, in which case that error is the one
+ * reported. Closable values are the handles native modules return, such as
+ * Redis connections and ffi libraries, and structs with a close() method.
+ * A null value is skipped, so a block can work with something optional.
+ */
+public class With {
+    private static final Pattern WITH_PATTERN = Pattern.compile("^(?:with|using)\\s*\\((.+)\\)\\s*(\\{)?\\s*$");
+    private static final Pattern BINDING_PATTERN = Pattern.compile("^var\\s+([A-Za-z_]\\w*)\\s*(?::[^=]+)?=(?!=)(.+)$");
+
+    private With() {
+    }
+
+    public static boolean isWith(String line) {
+        return WITH_PATTERN.matcher(line.trim()).matches();
+    }
+
+    /**
+     * Runs a with block starting at the given line
+     * @return The index after the block
+     */
+    public static int processWithStatement(List<String> lines, int startIndex, Executor executor) {
+        String line = lines.get(startIndex).trim();
+        Matcher matcher = WITH_PATTERN.matcher(line);
+        if (!matcher.matches()) {
+            throw new RuntimeException("Invalid with statement syntax at line: " + line);
+        }
+        int braceIndex = startIndex;
+        if (matcher.group(2) == null) {
+            braceIndex = startIndex + 1;
+            while (braceIndex < lines.size() && lines.get(braceIndex).trim().isEmpty()) {
+                braceIndex++;
+            }
+            if (braceIndex >= lines.size() || !lines.get(braceIndex).trim().equals("{")) {
+                throw new RuntimeException("Missing opening brace for with statement at line: " + line);
+            }
+        }
+        int endIndex = Braces.findClosing(lines, braceIndex);
+        if (endIndex == -1) {
+            throw new RuntimeException("Missing closing brace for with statement starting at line: " + line);
+        }
+
+        String resource = matcher.group(1).trim();
+        Matcher binding = BINDING_PATTERN.matcher(resource);
+        String name;
+        Object value;
+        if (binding.matches()) {
+            name = binding.group(1);
+            executor.execute(resource + ";");
+            value = executor.getEnvironment().getVariable(name);
+        } else {
+            name = resource;
+            value = executor.evaluate(resource);
+        }
+        if (value != null && !closable(value)) {
+            throw new RuntimeException("with: " + name + " can't be closed: " + value);
+        }
+
+        try {
+            Statements.executeBlock(lines, braceIndex + 1, endIndex, executor);
+        } catch (RuntimeException e) {
+            try {
+                close(value, executor);
+            } catch (RuntimeException closeError) {
+                e.addSuppressed(closeError);
+            }
+            throw e;
+        }
+        close(value, executor);
+        return endIndex + 1;
+    }
+
+    private static boolean closable(Object value) {
+        return value instanceof AutoCloseable || value instanceof Struct && ((Struct) value).getMethod("close") != null;
+    }
+
+    private static void close(Object value, Executor executor) {
+        if (value instanceof Struct) {
+            executor.callMethod((Struct) value, "close");
+        } else if (value instanceof AutoCloseable) {
+            try {
+                ((AutoCloseable) value).close();
+            } catch (RuntimeException e) {
+                throw e;
+            } catch (Exception e) {
+                throw new RuntimeException("with: closing " + value + " failed: " + e.getMessage(), e);
+            }
+        }
+    }
+}
@@ -0,0 +1,295 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2024-2026 Cyril John Magayaga
+ *
+ * It was originally written in Java programming language.
+ */
+package com.magayaga.microscript;
+
+import java.io.BufferedInputStream;
+import java.io.BufferedOutputStream;
+import java.io.DataInputStream;
+import java.io.DataOutputStream;
+import java.io.EOFException;
+import java.io.IOException;
+import java.net.InetAddress;
+import java.net.ServerSocket;
+import java.net.Socket;
+import java.nio.charset.StandardCharsets;
+import java.util.ArrayList;
+import java.util.List;
+import java.util.concurrent.CopyOnWriteArrayList;
+
+/**
+ * Just enough of ZeroMQ's wire protocol (ZMTP 3.0 with the NULL security
+ * mechanism) for the Jupyter kernel to talk to notebook frontends, which
+ * connect with libzmq. Each socket binds a TCP port and speaks to every peer
+ * that connects. A reply goes back over the connection its request came in
+ * on, which is how ROUTER and REP sockets route; PUB sends to all peers.
+ */
+public class Zmq {
+    private static final int GREETING_SIZE = 64;
+    private static final int MORE = 0x01;
+    private static final int LONG = 0x02;
+    private static final int COMMAND = 0x04;
+
+    private Zmq() {
+    }
+
+    /**
+     * Called with each multipart message a peer sends
+     */
+    @FunctionalInterface
+    public interface Handler {
+        void handle(Connection connection, List<byte[]> frames) throws IOException;
+    }
+
+    /**
+     * A bound socket of one type, such as "ROUTER", "PUB" or "REP"
+     */
+    public static class Listener implements AutoCloseable {
+        private final String type;
+        private final ServerSocket server;
+        private final Handler handler;
+        private final List<Connection> connections = new CopyOnWriteArrayList<>();
+
+        private Listener(String type, ServerSocket server, Handler handler) {
+            this.type = type;
+            this.server = server;
+            this.handler = handler;
+        }
+
+        /**
+         * Sends a message to every connected peer, dropping peers that have gone
+         */
+        public void publish(List<byte[]> frames) {
+            for (Connection connection : connections) {
+                try {
+                    connection.send(frames);
+                } catch (IOException e) {
+                    connections.remove(connection);
+                    connection.close();
+                }
+            }
+        }
+
+        @Override
+        public void close() {
+            try {
+                server.close();
+            } catch (IOException e) {
+                // Already closed
+            }
+            for (Connection connection : connections) {
+                connection.close();
+            }
+        }
+
+        private void accept() {
+            while (!server.isClosed()) {
+                Socket socket;
+                try {
+                    socket = server.accept();
+                } catch (IOException e) {
+                    return; // Closed
+                }
+                Thread thread = new Thread(() -> serve(socket), "zmq-" + type.toLowerCase());
+                thread.setDaemon(true);
+                thread.start();
+            }
+        }
+
+        private void serve(Socket socket) {
+            Connection connection = null;
+            try {
+                socket.setTcpNoDelay(true);
+                connection = new Connection(socket);
+                connection.handshake(type);
+                connections.add(connection);
+                List<byte[]> frames;
+                while ((frames = connection.receive()) != null) {
+                    if (handler != null) {
+                        handler.handle(connection, frames);
+                    }
+                }
+            } catch (IOException e) {
+                // The peer went away or doesn't speak ZMTP 3
+            } finally {
+                if (connection != null) {
+                    connections.remove(connection);
+                    connection.close();
+                }
+            }
+        }
+    }
+
+    /**
+     * One peer's connection to a Listener
+     */
+    public static class Connection {
+        private final Socket socket;
+        private final DataInputStream in;
+        private final DataOutputStream out;
+
+        private Connection(Socket socket) throws IOException {
+            this.socket = socket;
+            this.in = new DataInputStream(new BufferedInputStream(socket.getInputStream()));
+            this.out = new DataOutputStream(new BufferedOutputStream(socket.getOutputStream()));
+        }
+
+        /**
+         * Sends a multipart message to this peer
+         */
+        public synchronized void send(List<byte[]> frames) throws IOException {
+            for (int i = 0; i < frames.size(); i++) {
+                writeFrame(frames.get(i), i < frames.size() - 1 ? MORE : 0);
+            }
+            out.flush();
+        }
+
+        private void handshake(String type) throws IOException {
+            byte[] greeting = new byte[GREETING_SIZE];
+            greeting[0] = (byte) 0xFF;
+            greeting[9] = 0x7F;
+            greeting[10] = 3; // Version 3.0
+            byte[] mechanism = "NULL".getBytes(StandardCharsets.US_ASCII);
+            System.arraycopy(mechanism, 0, greeting, 12, mechanism.length);
+            synchronized (this) {
+                out.write(greeting);
+                out.flush();
+            }
+
+            byte[] peer = new byte[GREETING_SIZE];
+            in.readFully(peer);
+            if ((peer[0] & 0xFF) != 0xFF || (peer[9] & 0x01) == 0 || peer[10] < 3) {
+                throw new IOException("peer doesn't speak ZMTP 3");
+            }
+            String peerMechanism = new String(peer, 12, 20, StandardCharsets.US_ASCII).trim();
+            if (!peerMechanism.equals("NULL")) {
+                throw new IOException("unsupported security mechanism " + peerMechanism);
+            }
+
+            synchronized (this) {
+                writeFrame(command("READY", property("Socket-Type", type)), COMMAND);
+                out.flush();
+            }
+            // The peer's READY comes first; nothing in it changes how we talk to it
+            readFrame();
+        }
+
+        /**
+         * The next message from the peer, or null when it has disconnected.
+         * Commands between messages are answered or skipped.
+         */
+        private List<byte[]> receive() throws IOException {
+            List<byte[]> frames = new ArrayList<>();
+            while (true) {
+                Frame frame;
+                try {
+                    frame = readFrame();
+                } catch (EOFException e) {
+                    return null;
+                }
+                if ((frame.flags & COMMAND) != 0) {
+                    pong(frame.body);
+                    continue;
+                }
+                frames.add(frame.body);
+                if ((frame.flags & MORE) == 0) {
+                    return frames;
+                }
+            }
+        }
+
+        // ZMTP 3.1 peers may send PING when heartbeats are on
+        private void pong(byte[] body) throws IOException {
+            if (body.length < 7 || body[0] != 4 || !new String(body, 1, 4, StandardCharsets.US_ASCII).equals("PING")) {
+                return; // SUBSCRIBE and CANCEL, which PUB may ignore
+            }
+            byte[] context = new byte[body.length - 7];
+            System.arraycopy(body, 7, context, 0, context.length);
+            synchronized (this) {
+                writeFrame(command("PONG", context), COMMAND);
+                out.flush();
+            }
+        }
+
+        private Frame readFrame() throws IOException {
+            int flags = in.readUnsignedByte();
+            long size = (flags & LONG) != 0 ? in.readLong() : in.readUnsignedByte();
+            if (size < 0 || size > Integer.MAX_VALUE - 8) {
+                throw new IOException("frame too large");
+            }
+            byte[] body = new byte[(int) size];
+            in.readFully(body);
+            return new Frame(flags, body);
+        }
+
+        private void writeFrame(byte[] body, int flags) throws IOException {
+            if (body.length > 255) {
+                out.writeByte(flags | LONG);
+                out.writeLong(body.length);
+            } else {
+                out.writeByte(flags);
+                out.writeByte(body.length);
+            }
+            out.write(body);
+        }
+
+        void close() {
+            try {
+                socket.close();
+            } catch (IOException e) {
+                // Already closed
+            }
+        }
+    }
+
+    private static final class Frame {
+        final int flags;
+        final byte[] body;
+
+        Frame(int flags, byte[] body) {
+            this.flags = flags;
+            this.body = body;
+        }
+    }
+
+    /**
+     * Binds a socket of the given type and serves its peers on background
+     * threads, passing each message they send to the handler (null to ignore
+     * what they send)
+     */
+    public static Listener bind(String type, String ip, int port, Handler handler) throws IOException {
+        ServerSocket server = new ServerSocket(port, 50, InetAddress.getByName(ip));
+        Listener listener = new Listener(type, server, handler);
+        Thread thread = new Thread(listener::accept, "zmq-" + type.toLowerCase() + "-accept");
+        thread.setDaemon(true);
+        thread.start();
+        return listener;
+    }
+
+    private static byte[] command(String name, byte[] data) {
+        byte[] nameBytes = name.getBytes(StandardCharsets.US_ASCII);
+        byte[] body = new byte[1 + nameBytes.length + data.length];
+        body[0] = (byte) nameBytes.length;
+        System.arraycopy(nameBytes, 0, body, 1, nameBytes.length);
+        System.arraycopy(data, 0, body, 1 + nameBytes.length, data.length);
+        return body;
+    }
+
+    private static byte[] property(String name, String value) {
+        byte[] nameBytes = name.getBytes(StandardCharsets.US_ASCII);
+        byte[] valueBytes = value.getBytes(StandardCharsets.UTF_8);
+        byte[] body = new byte[1 + nameBytes.length + 4 + valueBytes.length];
+        body[0] = (byte) nameBytes.length;
+        System.arraycopy(nameBytes, 0, body, 1, nameBytes.length);
+        int at = 1 + nameBytes.length;
+        body[at] = (byte) (valueBytes.length >>> 24);
+        body[at + 1] = (byte) (valueBytes.length >>> 16);
+        body[at + 2] = (byte) (valueBytes.length >>> 8);
+        body[at + 3] = (byte) valueBytes.length;
+        System.arraycopy(valueBytes, 0, body, at + 4, valueBytes.length);
+        return body;
+    }
+}
@@ -75,13 +75,18 @@ extern "C" {
 #endif
 
 extern __declspec(dllexport) GoInt createServer(GoInt port);
+extern __declspec(dllexport) GoInt createTLSServer(GoInt port, char* certFile, char* keyFile);
+extern __declspec(dllexport) GoInt createAutocertServer(char* domains, char* cacheDir, char* email);
 extern __declspec(dllexport) void stopServer(GoInt serverHandle);
 extern __declspec(dllexport) GoUint8 isRunning(GoInt serverHandle);
+extern __declspec(dllexport) void stopAllServers();
+extern __declspec(dllexport) void waitServer(GoInt serverHandle);
+extern __declspec(dllexport) void setShutdownGracePeriod(GoInt milliseconds);
 
 // Route handling
 //
 extern __declspec(dllexport) void addRoute(GoInt serverHandle, char* method, char* path, char* handlerName);
-extern __declspec(dllexport) void removeRoute(GoInt serverHandle, char* method, char* path);
+extern __declspec(dllexport) GoUint8 removeRoute(GoInt serverHandle, char* method, char* path);
 
 // Response utilities
 //
@@ -90,27 +95,51 @@ extern __declspec(dllexport) void sendResponse(GoInt requestId, GoInt statusCode
 extern __declspec(dllexport) void sendJsonResponse(GoInt requestId, GoInt statusCode, char* jsonBody);
 extern __declspec(dllexport) void sendFileResponse(GoInt requestId, char* filePath);
 
+// Static file serving
+//
+extern __declspec(dllexport) void serveStatic(GoInt serverHandle, char* urlPrefix, char* directory, GoUint8 allowListing);
+
+// Reverse proxying
+//
+extern __declspec(dllexport) GoUint8 proxy(GoInt serverHandle, char* urlPrefix, char* upstream);
+
 // Request information
 //
 extern __declspec(dllexport) char* getRequestPath(GoInt requestId);
 extern __declspec(dllexport) char* getRequestMethod(GoInt requestId);
 extern __declspec(dllexport) char* getRequestHeader(GoInt requestId, char* headerName);
 extern __declspec(dllexport) char* getRequestBody(GoInt requestId);
+extern __declspec(dllexport) char* getRequestJson(GoInt requestId);
 extern __declspec(dllexport) char* getQueryParam(GoInt requestId, char* paramName);
 
 // Middleware
 //
 extern __declspec(dllexport) void useMiddleware(GoInt serverHandle, char* middlewareName);
+extern __declspec(dllexport) GoUint8 basicAuth(GoInt serverHandle, char* usersJson);
+extern __declspec(dllexport) GoUint8 jwtAuth(GoInt serverHandle, char* secret, char* algorithms);
+extern __declspec(dllexport) char* getRequestUser(GoInt requestId);
+
+// Access logging
+//
+extern __declspec(dllexport) GoUint8 setAccessLog(GoInt serverHandle, char* format, char* destination);
 
 // Utility functions
 //
 extern __declspec(dllexport) char* urlEncode(char* input);
 extern __declspec(dllexport) char* urlDecode(char* input);
+extern __declspec(dllexport) char* parseUrl(char* input);
+extern __declspec(dllexport) char* buildUrl(char* partsJson);
+extern __declspec(dllexport) char* buildQuery(char* queryJson);
 extern __declspec(dllexport) char* generateUuid();
 extern __declspec(dllexport) GoInt createWebSocketEndpoint(GoInt serverHandle, char* path);
 extern __declspec(dllexport) void sendWebSocketMessage(GoInt endpointHandle, char* clientId, char* message);
 extern __declspec(dllexport) void broadcastWebSocketMessage(GoInt endpointHandle, char* message);
 extern __declspec(dllexport) void closeWebSocketConnection(GoInt endpointHandle, char* clientId);
+extern __declspec(dllexport) void enableWebSocketCallbacks(GoInt endpointHandle);
+
+// Callbacks into the host
+//
+extern __declspec(dllexport) void setCallbackDispatcher(void* dispatcher);
 
 #ifdef __cplusplus
 }
@@ -0,0 +1,232 @@
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Access logging for the HTTP server.
+ * Each server can log requests in Common Log Format, Combined Log Format
+ * or JSON lines, to stderr, stdout or a file.
+ */
+package main
+
+import (
+	"C"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+type accessLogger struct {
+	format string
+	mu     sync.Mutex
+	out    io.Writer
+	file   *os.File // set when the logger owns the destination
+
+	// Requests still logging here; a replaced logger is closed once they finish
+	inFlight sync.WaitGroup
+}
+
+func newAccessLogger(format, destination string) (*accessLogger, error) {
+	switch format {
+	case "common", "combined", "json":
+	default:
+		return nil, fmt.Errorf("unknown access log format %q", format)
+	}
+
+	logger := &accessLogger{format: format}
+	switch destination {
+	case "", "stderr":
+		logger.out = os.Stderr
+	case "stdout":
+		logger.out = os.Stdout
+	default:
+		file, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		logger.out = file
+		logger.file = file
+	}
+	return logger, nil
+}
+
+func (l *accessLogger) close() {
+	if l.file != nil {
+		l.file.Close()
+	}
+}
+
+// acquireAccessLog returns the server's logger, or nil when logging is off.
+// The caller must call inFlight.Done on a logger it gets once it has logged.
+func (server *HttpServer) acquireAccessLog() *accessLogger {
+	server.accessLogMu.RLock()
+	defer server.accessLogMu.RUnlock()
+	logger := server.accessLog.Load()
+	if logger != nil {
+		logger.inFlight.Add(1)
+	}
+	return logger
+}
+
+// replaceAccessLog installs logger, which may be nil, and closes the previous
+// logger once the requests still writing to it have finished
+func (server *HttpServer) replaceAccessLog(logger *accessLogger) {
+	server.accessLogMu.Lock()
+	previous := server.accessLog.Swap(logger)
+	server.accessLogMu.Unlock()
+	if previous != nil {
+		// No request can acquire previous any more, so waiting here is safe
+		go func() {
+			previous.inFlight.Wait()
+			previous.close()
+		}()
+	}
+}
+
+func (l *accessLogger) log(r *http.Request, status int, bytes int64, latency time.Duration, now time.Time) {
+	remoteAddr := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	user, _, _ := r.BasicAuth()
+
+	var line string
+	if l.format == "json" {
+		entry := map[string]interface{}{
+			"time":        now.Format(time.RFC3339),
+			"remote_addr": remoteAddr,
+			"method":      r.Method,
+			"path":        r.URL.RequestURI(),
+			"proto":       r.Proto,
+			"status":      status,
+			"bytes":       bytes,
+			"latency_ms":  float64(latency.Microseconds()) / 1000,
+			"referer":     r.Referer(),
+			"user_agent":  r.UserAgent(),
+		}
+		if user != "" {
+			entry["user"] = user
+		}
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return
+		}
+		line = string(encoded) + "\n"
+	} else {
+		size := "-"
+		if bytes > 0 {
+			size = fmt.Sprint(bytes)
+		}
+		line = fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+			remoteAddr, orDash(user), now.Format(clfTimeFormat),
+			r.Method, r.URL.RequestURI(), r.Proto, status, size)
+		if l.format == "combined" {
+			line += fmt.Sprintf(" %q %q", orDash(r.Referer()), orDash(r.UserAgent()))
+		}
+		// Latency is appended after the standard fields so log parsers
+		// that stop at the known columns still work
+		line += fmt.Sprintf(" %.3fms\n", float64(latency.Microseconds())/1000)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.out, line)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// statusRecorder captures the status code and body size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(data []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(data)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack keeps WebSocket upgrades working through the recorder
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rec.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// ServeHTTP authenticates and routes the request and writes an access log entry when logging
+// is enabled for the server.
+func (server *HttpServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := server.acquireAccessLog()
+	if logger == nil {
+		server.serveAuthorized(w, r)
+		return
+	}
+	defer logger.inFlight.Done()
+
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	server.serveAuthorized(recorder, r)
+	logger.log(r, recorder.status, recorder.bytes, time.Since(start), start)
+}
+
+// setAccessLog enables access logging for a server. format is "common",
+// "combined" or "json"; "off" or "" disables logging. destination is
+// "stderr" (the default), "stdout" or a file path opened for appending.
+//
+//export setAccessLog
+func setAccessLog(serverHandle int, format, destination *C.char) bool {
+	globalMu.Lock()
+	server, exists := servers[serverHandle]
+	globalMu.Unlock()
+	if !exists {
+		return false
+	}
+
+	formatStr := strings.ToLower(strings.TrimSpace(C.GoString(format)))
+
+	var logger *accessLogger
+	if formatStr != "" && formatStr != "off" {
+		var err error
+		logger, err = newAccessLogger(formatStr, C.GoString(destination))
+		if err != nil {
+			log.Printf("Access log error: %v", err)
+			return false
+		}
+	}
+
+	server.replaceAccessLog(logger)
+	return true
+}
@@ -0,0 +1,241 @@
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Authentication middleware for the HTTP server.
+ * Every request to a server is checked before it is routed: unauthorized
+ * requests are answered with 401 here, and the verified user is kept with
+ * the request so handlers can read it with getRequestUser.
+ */
+package main
+
+import (
+	"C"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// authenticator verifies a request and returns the user it belongs to.
+// When verification fails, challenge gives the WWW-Authenticate header.
+type authenticator interface {
+	authenticate(r *http.Request) (user map[string]any, err error)
+	challenge(err error) string
+}
+
+type userContextKey struct{}
+
+// serveAuthorized answers 401 for requests the server's authenticator
+// rejects and routes the rest with their user attached.
+func (server *HttpServer) serveAuthorized(w http.ResponseWriter, r *http.Request) {
+	auth := server.auth.Load()
+	if auth == nil {
+		server.routes.ServeHTTP(w, r)
+		return
+	}
+	user, err := (*auth).authenticate(r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", (*auth).challenge(err))
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	server.routes.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey{}, user)))
+}
+
+// basicAuth requires HTTP Basic credentials on every request to the server.
+// usersJson maps user names to passwords, e.g. {"alice": "s3cret"}; the
+// verified user is {"name": "alice"}. An empty map turns auth off.
+//
+//export basicAuth
+func basicAuth(serverHandle int, usersJson *C.char) bool {
+	var users map[string]string
+	if err := json.Unmarshal([]byte(C.GoString(usersJson)), &users); err != nil {
+		log.Printf("Basic auth error: users must be a JSON object of names to passwords: %v", err)
+		return false
+	}
+	if len(users) == 0 {
+		return setAuth(serverHandle, nil)
+	}
+	return setAuth(serverHandle, &basicAuthenticator{users: users})
+}
+
+// jwtAuth requires a bearer token signed with secret on every request to the
+// server. algorithms is a comma-separated list of the HMAC algorithms
+// accepted ("HS256,HS512"), HS256 when empty. The verified user is the
+// token's claims. An empty secret turns auth off.
+//
+//export jwtAuth
+func jwtAuth(serverHandle int, secret, algorithms *C.char) bool {
+	secretStr := C.GoString(secret)
+	if secretStr == "" {
+		return setAuth(serverHandle, nil)
+	}
+	allowed := make(map[string]bool)
+	for _, name := range strings.Split(C.GoString(algorithms), ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if jwtHash(name) == nil {
+			log.Printf("JWT auth error: unsupported algorithm %q (expected HS256, HS384 or HS512)", name)
+			return false
+		}
+		allowed[name] = true
+	}
+	if len(allowed) == 0 {
+		allowed["HS256"] = true
+	}
+	return setAuth(serverHandle, &jwtAuthenticator{secret: []byte(secretStr), algorithms: allowed})
+}
+
+func setAuth(serverHandle int, auth authenticator) bool {
+	globalMu.Lock()
+	server, exists := servers[serverHandle]
+	globalMu.Unlock()
+	if !exists {
+		return false
+	}
+
+	if auth == nil {
+		server.auth.Store(nil)
+	} else {
+		server.auth.Store(&auth)
+	}
+	return true
+}
+
+// getRequestUser returns the verified user of a request as JSON, or "null"
+// when the server has no auth.
+//
+//export getRequestUser
+func getRequestUser(requestId int) *C.char {
+	globalMu.Lock()
+	request, exists := requests[requestId]
+	globalMu.Unlock()
+	if !exists {
+		return C.CString("null")
+	}
+
+	user, _ := request.r.Context().Value(userContextKey{}).(map[string]any)
+	if user == nil {
+		return C.CString("null")
+	}
+	encoded, err := json.Marshal(user)
+	if err != nil {
+		return C.CString("null")
+	}
+	return C.CString(string(encoded))
+}
+
+type basicAuthenticator struct {
+	users map[string]string
+}
+
+func (a *basicAuthenticator) authenticate(r *http.Request) (map[string]any, error) {
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, fmt.Errorf("missing credentials")
+	}
+	expected, exists := a.users[name]
+	// Compare anyway so unknown users take as long as wrong passwords
+	if subtle.ConstantTimeCompare([]byte(password), []byte(expected)) != 1 || !exists {
+		return nil, fmt.Errorf("invalid credentials")
+	}
+	return map[string]any{"name": name}, nil
+}
+
+func (a *basicAuthenticator) challenge(err error) string {
+	return `Basic realm="MicroScript", charset="UTF-8"`
+}
+
+type jwtAuthenticator struct {
+	secret     []byte
+	algorithms map[string]bool
+}
+
+func (a *jwtAuthenticator) authenticate(r *http.Request) (map[string]any, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, fmt.Errorf("missing bearer token")
+	}
+	return verifyJwt(strings.TrimSpace(token), a.secret, a.algorithms, time.Now())
+}
+
+func (a *jwtAuthenticator) challenge(err error) string {
+	if err.Error() == "missing bearer token" {
+		return `Bearer realm="MicroScript"`
+	}
+	return fmt.Sprintf(`Bearer realm="MicroScript", error="invalid_token", error_description=%q`, err.Error())
+}
+
+func jwtHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "HS256":
+		return sha256.New
+	case "HS384":
+		return sha512.New384
+	case "HS512":
+		return sha512.New
+	}
+	return nil
+}
+
+// verifyJwt checks a compact JWT's signature and its exp and nbf claims,
+// returning the claims.
+func verifyJwt(token string, secret []byte, algorithms map[string]bool, now time.Time) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJwtPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed header")
+	}
+	// Only the configured algorithms, so "none" and key confusion are refused
+	if !algorithms[header.Alg] {
+		return nil, fmt.Errorf("algorithm %s is not accepted", header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature")
+	}
+	mac := hmac.New(jwtHash(header.Alg), secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	var claims map[string]any
+	if err := decodeJwtPart(parts[1], &claims); err != nil || claims == nil {
+		return nil, fmt.Errorf("malformed claims")
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return nil, fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return nil, fmt.Errorf("token not valid yet")
+	}
+	return claims, nil
+}
+
+func decodeJwtPart(part string, value any) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, value)
+}
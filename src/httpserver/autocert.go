@@ -0,0 +1,71 @@
+//go:build autocert
+
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Let's Encrypt certificates for the HTTP server.
+ * Only compiled with `go build -tags autocert` so the default build does not
+ * depend on golang.org/x/crypto.
+ */
+package main
+
+import (
+	"C"
+	"log"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+//export createAutocertServer
+func createAutocertServer(domains, cacheDir, email *C.char) int {
+	var hosts []string
+	for _, host := range strings.Split(C.GoString(domains), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		log.Printf("HTTPS server error: autocert needs at least one domain")
+		return -1
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(C.GoString(cacheDir)),
+		Email:      C.GoString(email),
+	}
+
+	configure := func(srv *http.Server) {
+		srv.TLSConfig = manager.TLSConfig()
+	}
+
+	serverID := registerServer(443, configure, func(srv *http.Server) error {
+		return srv.ListenAndServeTLS("", "")
+	})
+
+	// HTTP-01 challenges are answered on port 80, everything else is
+	// redirected to HTTPS
+	challenge := &http.Server{
+		Addr:    ":80",
+		Handler: manager.HTTPHandler(nil),
+	}
+
+	globalMu.Lock()
+	if server, exists := servers[serverID]; exists {
+		server.mu.Lock()
+		server.challengeServer = challenge
+		server.mu.Unlock()
+	}
+	globalMu.Unlock()
+
+	go func() {
+		if err := challenge.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("ACME challenge server error: %v", err)
+		}
+	}()
+
+	return serverID
+}
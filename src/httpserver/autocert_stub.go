@@ -0,0 +1,19 @@
+//go:build !autocert
+
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Placeholder used when the library is built without Let's Encrypt support.
+ */
+package main
+
+import (
+	"C"
+	"log"
+)
+
+//export createAutocertServer
+func createAutocertServer(domains, cacheDir, email *C.char) int {
+	log.Printf("HTTPS server error: autocert support is not compiled in (rebuild with -tags autocert)")
+	return -1
+}
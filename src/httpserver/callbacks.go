@@ -0,0 +1,53 @@
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Callbacks from the HTTP server into the host.
+ * The JNI bridge registers a C function pointer with setCallbackDispatcher;
+ * server events are delivered through it so scripts can react to them.
+ * This file must not contain //export functions because its preamble
+ * defines a C function.
+ */
+package main
+
+/*
+#include <stdlib.h>
+
+typedef void (*microscript_dispatcher)(int event, int handle, char* id, char* payload);
+
+static inline void invokeDispatcher(void* dispatcher, int event, int handle, char* id, char* payload) {
+	((microscript_dispatcher)dispatcher)(event, handle, id, payload);
+}
+*/
+import "C"
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Event kinds passed to the dispatcher, mirrored in NativeHttp.java
+const (
+	eventWebSocketConnect = 1
+	eventWebSocketMessage = 2
+	eventWebSocketClose   = 3
+	eventHttpRequest      = 4
+)
+
+var callbackDispatcher unsafe.Pointer
+
+// dispatchEvent hands an event to the host. Reports false when no
+// dispatcher has been registered.
+func dispatchEvent(event, handle int, id, payload string) bool {
+	dispatcher := atomic.LoadPointer(&callbackDispatcher)
+	if dispatcher == nil {
+		return false
+	}
+
+	cID := C.CString(id)
+	defer C.free(unsafe.Pointer(cID))
+	cPayload := C.CString(payload)
+	defer C.free(unsafe.Pointer(cPayload))
+
+	C.invokeDispatcher(dispatcher, C.int(event), C.int(handle), cID, cPayload)
+	return true
+}
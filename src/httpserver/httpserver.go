@@ -8,36 +8,63 @@ package main
 
 import (
 	"C"
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/google/uuid"
-	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
 // Server management
 type HttpServer struct {
 	server      *http.Server
-	router      *mux.Router
+	routes      *routeTable
 	isRunning   bool
 	mu          sync.Mutex
 	wsEndpoints map[int]*WebSocketEndpoint
 	handlers    map[string]func(int)
+
+	// Closed once the listener has returned
+	done chan struct{}
+
+	// Access log configuration, nil when logging is off. Replacing it takes
+	// accessLogMu for writing, so no request picks up a logger being retired.
+	accessLog   atomic.Pointer[accessLogger]
+	accessLogMu sync.RWMutex
+
+	// Checks every request before routing, nil when auth is off
+	auth atomic.Pointer[authenticator]
+
+	// Plain HTTP listener answering ACME challenges for autocert servers
+	challengeServer *http.Server
 }
 
 type WebSocketEndpoint struct {
+	id        int
 	path      string
 	clients   map[string]*websocket.Conn
 	clientsMu sync.Mutex
+
+	// When set, connection events are dispatched to the host instead of
+	// messages being echoed back
+	callbacks bool
 }
 
 type RequestContext struct {
@@ -45,6 +72,18 @@ type RequestContext struct {
 	w           http.ResponseWriter
 	r           *http.Request
 	headersSent bool
+
+	// The body can only be read once, so it is cached for later calls
+	bodyOnce sync.Once
+	body     []byte
+	bodyErr  error
+}
+
+func (request *RequestContext) readBody() ([]byte, error) {
+	request.bodyOnce.Do(func() {
+		request.body, request.bodyErr = io.ReadAll(request.r.Body)
+	})
+	return request.body, request.bodyErr
 }
 
 var (
@@ -54,29 +93,68 @@ var (
 	requestCounter  = 0
 	endpointCounter = 0
 	globalMu        sync.Mutex
+
+	// How long stopServer waits for in-flight requests before closing them
+	shutdownGracePeriod = 5 * time.Second
 )
 
 //export createServer
 func createServer(port int) int {
+	return registerServer(port, nil, func(srv *http.Server) error {
+		return srv.ListenAndServe()
+	})
+}
+
+//export createTLSServer
+func createTLSServer(port int, certFile, keyFile *C.char) int {
+	certFileStr := C.GoString(certFile)
+	keyFileStr := C.GoString(keyFile)
+
+	// Load the key pair up front so a bad certificate is reported to the
+	// caller instead of silently killing the listener goroutine
+	certificate, err := tls.LoadX509KeyPair(certFileStr, keyFileStr)
+	if err != nil {
+		log.Printf("HTTPS server certificate error: %v", err)
+		return -1
+	}
+
+	configure := func(srv *http.Server) {
+		srv.TLSConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{certificate},
+		}
+	}
+
+	return registerServer(port, configure, func(srv *http.Server) error {
+		return srv.ListenAndServeTLS("", "")
+	})
+}
+
+// registerServer creates a server entry, applies the optional configure hook
+// and starts listening in a goroutine using the given listen function.
+func registerServer(port int, configure func(*http.Server), listen func(*http.Server) error) int {
 	globalMu.Lock()
 	defer globalMu.Unlock()
 
 	serverID := serverCounter
 	serverCounter++
 
-	router := mux.NewRouter()
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: router,
+		Addr: fmt.Sprintf(":%d", port),
+	}
+	if configure != nil {
+		configure(srv)
 	}
 
 	server := &HttpServer{
 		server:      srv,
-		router:      router,
+		routes:      newRouteTable(),
 		isRunning:   false,
 		wsEndpoints: make(map[int]*WebSocketEndpoint),
 		handlers:    make(map[string]func(int)),
+		done:        make(chan struct{}),
 	}
+	srv.Handler = server
 
 	servers[serverID] = server
 
@@ -86,7 +164,7 @@ func createServer(port int) int {
 		server.isRunning = true
 		server.mu.Unlock()
 
-		err := srv.ListenAndServe()
+		err := listen(srv)
 		if err != nil && err != http.ErrServerClosed {
 			log.Printf("HTTP server error: %v", err)
 		}
@@ -94,6 +172,7 @@ func createServer(port int) int {
 		server.mu.Lock()
 		server.isRunning = false
 		server.mu.Unlock()
+		close(server.done)
 	}()
 
 	// Wait a bit to ensure the server starts
@@ -109,26 +188,104 @@ func stopServer(serverHandle int) {
 		globalMu.Unlock()
 		return
 	}
+	gracePeriod := shutdownGracePeriod
+	endpoints := make([]*WebSocketEndpoint, 0, len(server.wsEndpoints))
+	for _, endpoint := range server.wsEndpoints {
+		endpoints = append(endpoints, endpoint)
+	}
 	globalMu.Unlock()
 
 	server.mu.Lock()
 	defer server.mu.Unlock()
 
+	// WebSocket connections are hijacked, so Shutdown does not know about
+	// them; send each client a close frame before stopping the listener
+	closeWebSocketClients(endpoints)
+
 	if server.isRunning {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
 		defer cancel()
 
+		// Shutdown stops accepting new connections and waits for in-flight
+		// requests to finish; whatever is left after the grace period is cut off
 		if err := server.server.Shutdown(ctx); err != nil {
 			log.Printf("HTTP server shutdown error: %v", err)
+			server.server.Close()
 		}
 		server.isRunning = false
 	}
 
+	if server.challengeServer != nil {
+		server.challengeServer.Close()
+		server.challengeServer = nil
+	}
+
+	server.replaceAccessLog(nil)
+
 	globalMu.Lock()
 	delete(servers, serverHandle)
 	globalMu.Unlock()
 }
 
+//export waitServer
+func waitServer(serverHandle int) {
+	globalMu.Lock()
+	server, exists := servers[serverHandle]
+	globalMu.Unlock()
+	if !exists {
+		return
+	}
+
+	<-server.done
+}
+
+//export stopAllServers
+func stopAllServers() {
+	globalMu.Lock()
+	handles := make([]int, 0, len(servers))
+	for handle := range servers {
+		handles = append(handles, handle)
+	}
+	globalMu.Unlock()
+
+	// Stop servers in parallel so the total wait is one grace period
+	var wg sync.WaitGroup
+	for _, handle := range handles {
+		wg.Add(1)
+		go func(handle int) {
+			defer wg.Done()
+			stopServer(handle)
+		}(handle)
+	}
+	wg.Wait()
+}
+
+//export setShutdownGracePeriod
+func setShutdownGracePeriod(milliseconds int) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	if milliseconds < 0 {
+		milliseconds = 0
+	}
+	shutdownGracePeriod = time.Duration(milliseconds) * time.Millisecond
+}
+
+func closeWebSocketClients(endpoints []*WebSocketEndpoint) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	deadline := time.Now().Add(time.Second)
+
+	for _, endpoint := range endpoints {
+		endpoint.clientsMu.Lock()
+		for clientID, conn := range endpoint.clients {
+			conn.WriteControl(websocket.CloseMessage, message, deadline)
+			conn.Close()
+			delete(endpoint.clients, clientID)
+		}
+		endpoint.clientsMu.Unlock()
+	}
+}
+
 //export isRunning
 func isRunning(serverHandle int) bool {
 	globalMu.Lock()
@@ -175,9 +332,12 @@ func addRoute(serverHandle int, method, path, handlerName *C.char) {
 		}
 		globalMu.Unlock()
 
-		// Call the registered handler by name
+		// Call the registered handler by name, falling back to the host,
+		// which runs the script function and writes the response
 		if handlerFunc, exists := server.handlers[handlerNameStr]; exists {
 			handlerFunc(reqID)
+		} else {
+			dispatchEvent(eventHttpRequest, reqID, handlerNameStr, "")
 		}
 
 		// Clean up request context after a delay to ensure all processing is done
@@ -189,14 +349,20 @@ func addRoute(serverHandle int, method, path, handlerName *C.char) {
 		}()
 	}
 
-	server.router.HandleFunc(pathStr, handler).Methods(methodStr)
+	// Registering the same method and path again replaces the old handler
+	server.routes.handle(methodStr, pathStr, false, http.HandlerFunc(handler))
 }
 
 //export removeRoute
-func removeRoute(serverHandle int, method, path *C.char) {
-	// Note: mux doesn't directly support route removal
-	// In a real implementation, you might need to recreate the router
-	// For simplicity, this is a placeholder
+func removeRoute(serverHandle int, method, path *C.char) bool {
+	globalMu.Lock()
+	server, exists := servers[serverHandle]
+	globalMu.Unlock()
+	if !exists {
+		return false
+	}
+
+	return server.routes.remove(C.GoString(method), C.GoString(path))
 }
 
 // Response utilities
@@ -259,14 +425,21 @@ func sendJsonResponse(requestId int, statusCode int, jsonBody *C.char) {
 		return
 	}
 
-	bodyStr := C.GoString(jsonBody)
+	bodyBytes := []byte(C.GoString(jsonBody))
 
 	request.headersSent = true
 	globalMu.Unlock()
 
+	// Never send a malformed document with a JSON content type
+	if !json.Valid(bodyBytes) {
+		log.Printf("Invalid JSON response body for request %d", requestId)
+		statusCode = http.StatusInternalServerError
+		bodyBytes = []byte(`{"error":"invalid JSON response"}`)
+	}
+
 	request.w.Header().Set("Content-Type", "application/json")
 	request.w.WriteHeader(statusCode)
-	request.w.Write([]byte(bodyStr))
+	request.w.Write(bodyBytes)
 }
 
 //export sendFileResponse
@@ -309,15 +482,23 @@ func sendFileResponse(requestId int, filePath *C.char) {
 
 // Get content type based on file extension
 func getContentTypeFromExtension(ext string) string {
-	switch ext {
+	switch strings.ToLower(ext) {
 	case ".html", ".htm":
 		return "text/html"
 	case ".css":
 		return "text/css"
-	case ".js":
+	case ".js", ".mjs":
 		return "application/javascript"
-	case ".json":
+	case ".json", ".map":
 		return "application/json"
+	case ".txt":
+		return "text/plain"
+	case ".csv":
+		return "text/csv"
+	case ".md":
+		return "text/markdown"
+	case ".xml":
+		return "application/xml"
 	case ".png":
 		return "image/png"
 	case ".jpg", ".jpeg":
@@ -326,13 +507,203 @@ func getContentTypeFromExtension(ext string) string {
 		return "image/gif"
 	case ".svg":
 		return "image/svg+xml"
+	case ".webp":
+		return "image/webp"
+	case ".ico":
+		return "image/x-icon"
+	case ".woff":
+		return "font/woff"
+	case ".woff2":
+		return "font/woff2"
+	case ".ttf":
+		return "font/ttf"
+	case ".otf":
+		return "font/otf"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".wav":
+		return "audio/wav"
+	case ".mp4":
+		return "video/mp4"
+	case ".webm":
+		return "video/webm"
+	case ".wasm":
+		return "application/wasm"
 	case ".pdf":
 		return "application/pdf"
+	case ".zip":
+		return "application/zip"
 	default:
 		return "application/octet-stream"
 	}
 }
 
+// Static file serving
+//
+//export serveStatic
+func serveStatic(serverHandle int, urlPrefix, directory *C.char, allowListing bool) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	server, exists := servers[serverHandle]
+	if !exists {
+		return
+	}
+
+	prefixStr := "/" + strings.Trim(C.GoString(urlPrefix), "/")
+	root, err := filepath.Abs(C.GoString(directory))
+	if err != nil {
+		log.Printf("Static directory error: %v", err)
+		return
+	}
+
+	handler := &staticHandler{
+		prefix:       prefixStr,
+		root:         root,
+		allowListing: allowListing,
+	}
+
+	if prefixStr == "/" {
+		server.routes.handle("", "/", true, handler)
+	} else {
+		// Redirect "/assets" to "/assets/" so relative links in index pages resolve
+		server.routes.handle("", prefixStr, false, http.RedirectHandler(prefixStr+"/", http.StatusMovedPermanently))
+		server.routes.handle("", prefixStr+"/", true, handler)
+	}
+}
+
+type staticHandler struct {
+	prefix       string
+	root         string
+	allowListing bool
+}
+
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fullPath, ok := h.resolve(r.URL.Path)
+	if !ok {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	if info.IsDir() {
+		// Directories are always addressed with a trailing slash
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+			return
+		}
+
+		indexPath := filepath.Join(fullPath, "index.html")
+		if indexInfo, err := os.Stat(indexPath); err == nil && !indexInfo.IsDir() {
+			serveStaticFile(w, r, indexPath, indexInfo)
+			return
+		}
+
+		if !h.allowListing {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		writeDirectoryListing(w, r, fullPath)
+		return
+	}
+
+	serveStaticFile(w, r, fullPath, info)
+}
+
+// resolve maps a request path below the prefix onto the static root and
+// rejects anything that would escape it (e.g. "/assets/../../etc/passwd").
+func (h *staticHandler) resolve(requestPath string) (string, bool) {
+	relative := strings.TrimPrefix(requestPath, h.prefix)
+	if strings.Contains(relative, "\x00") {
+		return "", false
+	}
+
+	cleaned := path.Clean("/" + relative)
+	fullPath := filepath.Join(h.root, filepath.FromSlash(cleaned))
+
+	rel, err := filepath.Rel(h.root, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	// Follow symlinks and make sure the target is still inside the root
+	if resolved, err := filepath.EvalSymlinks(fullPath); err == nil {
+		resolvedRoot, err := filepath.EvalSymlinks(h.root)
+		if err != nil {
+			return "", false
+		}
+		rel, err = filepath.Rel(resolvedRoot, resolved)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+	}
+
+	return fullPath, true
+}
+
+func serveStaticFile(w http.ResponseWriter, r *http.Request, filePath string, info os.FileInfo) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", getContentTypeFromExtension(filepath.Ext(filePath)))
+
+	// ServeContent handles Range, HEAD and If-Modified-Since for us
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+func writeDirectoryListing(w http.ResponseWriter, r *http.Request, dirPath string) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		http.Error(w, "Cannot read directory", http.StatusInternalServerError)
+		return
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	title := html.EscapeString(r.URL.Path)
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head><title>Index of %s</title></head>\n<body>\n", title)
+	fmt.Fprintf(w, "<h1>Index of %s</h1>\n<ul>\n", title)
+	if r.URL.Path != "/" {
+		fmt.Fprint(w, "<li><a href=\"../\">../</a></li>\n")
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		link := url.URL{Path: name}
+		fmt.Fprintf(w, "<li><a href=\"%s\">%s</a></li>\n", link.String(), html.EscapeString(name))
+	}
+	fmt.Fprint(w, "</ul>\n</body>\n</html>\n")
+}
+
 // Request information
 //
 //export getRequestPath
@@ -385,8 +756,7 @@ func getRequestBody(requestId int) *C.char {
 		return C.CString("")
 	}
 
-	// Read body
-	bodyBytes, err := io.ReadAll(request.r.Body)
+	bodyBytes, err := request.readBody()
 	if err != nil {
 		return C.CString("")
 	}
@@ -394,6 +764,38 @@ func getRequestBody(requestId int) *C.char {
 	return C.CString(string(bodyBytes))
 }
 
+// getRequestJson validates the request body with encoding/json and returns
+// it compacted, "null" for an empty body, or NULL when it is not valid JSON.
+//
+//export getRequestJson
+func getRequestJson(requestId int) *C.char {
+	globalMu.Lock()
+	request, exists := requests[requestId]
+	globalMu.Unlock()
+
+	if !exists {
+		return nil
+	}
+
+	bodyBytes, err := request.readBody()
+	if err != nil {
+		log.Printf("Request body error: %v", err)
+		return nil
+	}
+
+	if len(bytes.TrimSpace(bodyBytes)) == 0 {
+		return C.CString("null")
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, bodyBytes); err != nil {
+		log.Printf("Invalid JSON request body: %v", err)
+		return nil
+	}
+
+	return C.CString(compacted.String())
+}
+
 //export getQueryParam
 func getQueryParam(requestId int, paramName *C.char) *C.char {
 	globalMu.Lock()
@@ -435,6 +837,124 @@ func urlDecode(input *C.char) *C.char {
 	return C.CString(decoded)
 }
 
+// urlParts is the JSON shape exchanged with the host for parseUrl/buildUrl.
+// Query values are strings, or lists of strings for repeated keys.
+type urlParts struct {
+	Scheme   string                 `json:"scheme"`
+	User     string                 `json:"user,omitempty"`
+	Host     string                 `json:"host"`
+	Hostname string                 `json:"hostname,omitempty"`
+	Port     string                 `json:"port,omitempty"`
+	Path     string                 `json:"path"`
+	Query    map[string]interface{} `json:"query"`
+	Fragment string                 `json:"fragment"`
+}
+
+//export parseUrl
+func parseUrl(input *C.char) *C.char {
+	parsed, err := url.Parse(C.GoString(input))
+	if err != nil {
+		log.Printf("URL parse error: %v", err)
+		return nil
+	}
+
+	query := make(map[string]interface{})
+	for key, values := range parsed.Query() {
+		if len(values) == 1 {
+			query[key] = values[0]
+		} else {
+			query[key] = values
+		}
+	}
+
+	parts := urlParts{
+		Scheme:   parsed.Scheme,
+		Host:     parsed.Host,
+		Hostname: parsed.Hostname(),
+		Port:     parsed.Port(),
+		Path:     parsed.Path,
+		Query:    query,
+		Fragment: parsed.Fragment,
+	}
+	if parsed.User != nil {
+		parts.User = parsed.User.String()
+	}
+
+	encoded, err := json.Marshal(parts)
+	if err != nil {
+		return nil
+	}
+	return C.CString(string(encoded))
+}
+
+//export buildUrl
+func buildUrl(partsJson *C.char) *C.char {
+	var parts urlParts
+	decoder := json.NewDecoder(strings.NewReader(C.GoString(partsJson)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parts); err != nil {
+		log.Printf("URL build error: %v", err)
+		return nil
+	}
+
+	host := parts.Host
+	if host == "" && parts.Hostname != "" {
+		host = parts.Hostname
+		if parts.Port != "" {
+			host += ":" + parts.Port
+		}
+	}
+
+	built := url.URL{
+		Scheme:   parts.Scheme,
+		Host:     host,
+		Path:     parts.Path,
+		RawQuery: toQueryValues(parts.Query).Encode(),
+		Fragment: parts.Fragment,
+	}
+	if parts.User != "" {
+		if username, password, hasPassword := strings.Cut(parts.User, ":"); hasPassword {
+			built.User = url.UserPassword(username, password)
+		} else {
+			built.User = url.User(username)
+		}
+	}
+
+	return C.CString(built.String())
+}
+
+//export buildQuery
+func buildQuery(queryJson *C.char) *C.char {
+	var query map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(C.GoString(queryJson)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&query); err != nil {
+		log.Printf("Query build error: %v", err)
+		return nil
+	}
+
+	return C.CString(toQueryValues(query).Encode())
+}
+
+// toQueryValues converts decoded JSON into url.Values; lists become
+// repeated keys and other scalars are formatted as text.
+func toQueryValues(query map[string]interface{}) url.Values {
+	values := url.Values{}
+	for key, value := range query {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case []interface{}:
+			for _, item := range v {
+				values.Add(key, fmt.Sprint(item))
+			}
+		default:
+			values.Add(key, fmt.Sprint(v))
+		}
+	}
+	return values
+}
+
 //export generateUuid
 func generateUuid() *C.char {
 	return C.CString(uuid.New().String())
@@ -465,6 +985,7 @@ func createWebSocketEndpoint(serverHandle int, path *C.char) int {
 	endpointCounter++
 
 	wsEndpoint := &WebSocketEndpoint{
+		id:      endpointID,
 		path:    pathStr,
 		clients: make(map[string]*websocket.Conn),
 	}
@@ -472,7 +993,7 @@ func createWebSocketEndpoint(serverHandle int, path *C.char) int {
 	server.wsEndpoints[endpointID] = wsEndpoint
 
 	// Handle WebSocket connections
-	server.router.HandleFunc(pathStr, func(w http.ResponseWriter, r *http.Request) {
+	server.routes.handle("", pathStr, false, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Printf("WebSocket upgrade error: %v", err)
@@ -487,12 +1008,20 @@ func createWebSocketEndpoint(serverHandle int, path *C.char) int {
 		wsEndpoint.clients[clientID] = conn
 		wsEndpoint.clientsMu.Unlock()
 
+		if wsEndpoint.hasCallbacks() {
+			dispatchEvent(eventWebSocketConnect, wsEndpoint.id, clientID, "")
+		}
+
 		// Handle disconnect
 		defer func() {
 			conn.Close()
 			wsEndpoint.clientsMu.Lock()
 			delete(wsEndpoint.clients, clientID)
 			wsEndpoint.clientsMu.Unlock()
+
+			if wsEndpoint.hasCallbacks() {
+				dispatchEvent(eventWebSocketClose, wsEndpoint.id, clientID, "")
+			}
 		}()
 
 		// Message handling loop
@@ -502,17 +1031,52 @@ func createWebSocketEndpoint(serverHandle int, path *C.char) int {
 				break
 			}
 
-			if messageType == websocket.TextMessage {
-				// Handle message (callbacks would be implemented here)
-				// For now, we'll just echo it back
-				conn.WriteMessage(websocket.TextMessage, message)
+			if messageType != websocket.TextMessage {
+				continue
+			}
+
+			if wsEndpoint.hasCallbacks() {
+				dispatchEvent(eventWebSocketMessage, wsEndpoint.id, clientID, string(message))
+				continue
 			}
+
+			// Without callbacks the endpoint echoes messages back; writes
+			// share the lock with sendWebSocketMessage and broadcasts
+			wsEndpoint.clientsMu.Lock()
+			conn.WriteMessage(websocket.TextMessage, message)
+			wsEndpoint.clientsMu.Unlock()
 		}
-	})
+	}))
 
 	return endpointID
 }
 
+func (endpoint *WebSocketEndpoint) hasCallbacks() bool {
+	endpoint.clientsMu.Lock()
+	defer endpoint.clientsMu.Unlock()
+	return endpoint.callbacks
+}
+
+//export enableWebSocketCallbacks
+func enableWebSocketCallbacks(endpointHandle int) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	for _, server := range servers {
+		if endpoint, exists := server.wsEndpoints[endpointHandle]; exists {
+			endpoint.clientsMu.Lock()
+			endpoint.callbacks = true
+			endpoint.clientsMu.Unlock()
+			return
+		}
+	}
+}
+
+//export setCallbackDispatcher
+func setCallbackDispatcher(dispatcher unsafe.Pointer) {
+	atomic.StorePointer(&callbackDispatcher, dispatcher)
+}
+
 //export sendWebSocketMessage
 func sendWebSocketMessage(endpointHandle int, clientId, message *C.char) {
 	globalMu.Lock()
@@ -0,0 +1,74 @@
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Reverse proxying for the HTTP server.
+ * Requests under a URL prefix are forwarded to an upstream server with the
+ * prefix replaced by the upstream's own path, so a MicroScript server can
+ * front existing services during development.
+ */
+package main
+
+import (
+	"C"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// proxy forwards every request under urlPrefix to upstream, e.g. "/api/"
+// to "http://localhost:9000": GET /api/users becomes GET /users upstream.
+// Headers are passed through (with X-Forwarded-For, -Host and -Proto added)
+// and bodies are streamed both ways. Returns false for an invalid upstream.
+//
+//export proxy
+func proxy(serverHandle int, urlPrefix, upstream *C.char) bool {
+	target, err := url.Parse(C.GoString(upstream))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		log.Printf("Proxy error: invalid upstream URL %q", C.GoString(upstream))
+		return false
+	}
+
+	globalMu.Lock()
+	defer globalMu.Unlock()
+
+	server, exists := servers[serverHandle]
+	if !exists {
+		return false
+	}
+
+	prefixStr := "/" + strings.Trim(C.GoString(urlPrefix), "/")
+	handler := newProxyHandler(prefixStr, target)
+	if prefixStr == "/" {
+		server.routes.handle("", "/", true, handler)
+	} else {
+		// "/api" is proxied as well as everything under "/api/"
+		server.routes.handle("", prefixStr, false, handler)
+		server.routes.handle("", prefixStr+"/", true, handler)
+	}
+	return true
+}
+
+func newProxyHandler(prefix string, target *url.URL) http.Handler {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			relative := strings.TrimPrefix(r.In.URL.Path, prefix)
+			if prefix == "/" {
+				relative = r.In.URL.Path
+			}
+			r.Out.URL.Path = "/" + strings.TrimPrefix(relative, "/")
+			r.Out.URL.RawPath = ""
+			// Joins the upstream's path and points the Host header at it
+			r.SetURL(target)
+			r.SetXForwarded()
+		},
+		// Flush as soon as upstream writes, so server-sent events and
+		// other streamed responses arrive without buffering
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("Proxy error: %s %s -> %s: %v", r.Method, r.URL.Path, target, err)
+			http.Error(w, "Bad gateway", http.StatusBadGateway)
+		},
+	}
+}
@@ -0,0 +1,117 @@
+/* MicroScript — The programming language
+ * Copyright (c) 2025-2026 Cyril John Magayaga
+ *
+ * Rebuildable router for the HTTP server.
+ * mux cannot remove routes, so every registration is recorded in a route
+ * table and a fresh mux.Router is built and swapped in whenever routes are
+ * added, replaced or removed. Requests already in flight keep using the
+ * router they started with.
+ */
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/mux"
+)
+
+type routeEntry struct {
+	method  string // empty matches any method
+	path    string
+	prefix  bool
+	handler http.Handler
+}
+
+type routeTable struct {
+	mu      sync.Mutex
+	entries []*routeEntry
+	current atomic.Pointer[mux.Router]
+}
+
+func newRouteTable() *routeTable {
+	table := &routeTable{}
+	table.current.Store(mux.NewRouter())
+	return table
+}
+
+func (t *routeTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	t.current.Load().ServeHTTP(w, r)
+}
+
+// handle registers a route, replacing an existing one with the same method
+// and path in place so its matching priority is kept.
+func (t *routeTable) handle(method, path string, prefix bool, handler http.Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := &routeEntry{
+		method:  strings.ToUpper(method),
+		path:    path,
+		prefix:  prefix,
+		handler: handler,
+	}
+
+	replaced := false
+	for i, existing := range t.entries {
+		if existing.method == entry.method && existing.path == entry.path && existing.prefix == entry.prefix {
+			t.entries[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		t.entries = append(t.entries, entry)
+	}
+
+	t.rebuild()
+}
+
+// remove drops every route registered for the method and path. A method of
+// "" or "*" removes the path for all methods. Reports whether anything was
+// removed.
+func (t *routeTable) remove(method, path string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	method = strings.ToUpper(method)
+	anyMethod := method == "" || method == "*"
+
+	kept := t.entries[:0]
+	removed := false
+	for _, entry := range t.entries {
+		if entry.path == path && (anyMethod || entry.method == method) {
+			removed = true
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	// Clear the tail so removed handlers can be collected
+	for i := len(kept); i < len(t.entries); i++ {
+		t.entries[i] = nil
+	}
+	t.entries = kept
+
+	if removed {
+		t.rebuild()
+	}
+	return removed
+}
+
+func (t *routeTable) rebuild() {
+	router := mux.NewRouter()
+	for _, entry := range t.entries {
+		var route *mux.Route
+		if entry.prefix {
+			route = router.PathPrefix(entry.path).Handler(entry.handler)
+		} else {
+			route = router.Handle(entry.path, entry.handler)
+		}
+		if entry.method != "" {
+			route.Methods(entry.method)
+		}
+	}
+	t.current.Store(router)
+}
@@ -0,0 +1,186 @@
+/**
+ * MicroScript — The programming language
+ * Copyright (c) 2026 Cyril John Magayaga
+ * 
+ * It was originally written in C programming language.
+ */
+#include <jni.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#ifdef _WIN32
+#include <windows.h>
+#else
+#include <dlfcn.h>
+#endif
+
+// Return kinds, mirrored in NativeFfi.java
+#define RETURN_VOID 0
+#define RETURN_INT  1
+#define RETURN_LONG 2
+
+#define MAX_ARGS 6
+
+typedef double (*double_fn0)(void);
+typedef double (*double_fn1)(double);
+typedef double (*double_fn2)(double, double);
+typedef double (*double_fn3)(double, double, double);
+typedef double (*double_fn4)(double, double, double, double);
+typedef double (*double_fn5)(double, double, double, double, double);
+typedef double (*double_fn6)(double, double, double, double, double, double);
+
+typedef intptr_t (*long_fn0)(void);
+typedef intptr_t (*long_fn1)(intptr_t);
+typedef intptr_t (*long_fn2)(intptr_t, intptr_t);
+typedef intptr_t (*long_fn3)(intptr_t, intptr_t, intptr_t);
+typedef intptr_t (*long_fn4)(intptr_t, intptr_t, intptr_t, intptr_t);
+typedef intptr_t (*long_fn5)(intptr_t, intptr_t, intptr_t, intptr_t, intptr_t);
+typedef intptr_t (*long_fn6)(intptr_t, intptr_t, intptr_t, intptr_t, intptr_t, intptr_t);
+
+typedef int (*int_fn0)(void);
+typedef int (*int_fn1)(intptr_t);
+typedef int (*int_fn2)(intptr_t, intptr_t);
+typedef int (*int_fn3)(intptr_t, intptr_t, intptr_t);
+typedef int (*int_fn4)(intptr_t, intptr_t, intptr_t, intptr_t);
+typedef int (*int_fn5)(intptr_t, intptr_t, intptr_t, intptr_t, intptr_t);
+typedef int (*int_fn6)(intptr_t, intptr_t, intptr_t, intptr_t, intptr_t, intptr_t);
+
+static void throw_error(JNIEnv *env, const char *message) {
+    jclass cls = (*env)->FindClass(env, "java/lang/RuntimeException");
+    if (cls != NULL) {
+        (*env)->ThrowNew(env, cls, message);
+    }
+}
+
+// Open a shared library, returning 0 on failure
+JNIEXPORT jlong JNICALL Java_com_magayaga_microscript_NativeFfi_open(JNIEnv *env, jclass cls, jstring path) {
+    const char *cpath = (*env)->GetStringUTFChars(env, path, NULL);
+#ifdef _WIN32
+    void *handle = (void *) LoadLibraryA(cpath);
+#else
+    void *handle = dlopen(cpath, RTLD_NOW | RTLD_LOCAL);
+#endif
+    (*env)->ReleaseStringUTFChars(env, path, cpath);
+    return (jlong) (intptr_t) handle;
+}
+
+// Describe the last open or symbol failure
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeFfi_lastError(JNIEnv *env, jclass cls) {
+#ifdef _WIN32
+    char message[64];
+    snprintf(message, sizeof(message), "error code %lu", (unsigned long) GetLastError());
+    return (*env)->NewStringUTF(env, message);
+#else
+    const char *message = dlerror();
+    return (*env)->NewStringUTF(env, message != NULL ? message : "unknown error");
+#endif
+}
+
+// Look up an exported function, returning 0 when it doesn't exist
+JNIEXPORT jlong JNICALL Java_com_magayaga_microscript_NativeFfi_symbol(JNIEnv *env, jclass cls, jlong library, jstring name) {
+    const char *cname = (*env)->GetStringUTFChars(env, name, NULL);
+#ifdef _WIN32
+    void *symbol = (void *) GetProcAddress((HMODULE) (intptr_t) library, cname);
+#else
+    void *symbol = dlsym((void *) (intptr_t) library, cname);
+#endif
+    (*env)->ReleaseStringUTFChars(env, name, cname);
+    return (jlong) (intptr_t) symbol;
+}
+
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeFfi_close(JNIEnv *env, jclass cls, jlong library) {
+#ifdef _WIN32
+    FreeLibrary((HMODULE) (intptr_t) library);
+#else
+    dlclose((void *) (intptr_t) library);
+#endif
+}
+
+// Call a function taking and returning doubles
+JNIEXPORT jdouble JNICALL Java_com_magayaga_microscript_NativeFfi_callDoubles(JNIEnv *env, jclass cls, jlong function, jdoubleArray args) {
+    double a[MAX_ARGS] = {0};
+    jsize count = (*env)->GetArrayLength(env, args);
+    if (count > MAX_ARGS) {
+        throw_error(env, "ffi: too many arguments");
+        return 0;
+    }
+    (*env)->GetDoubleArrayRegion(env, args, 0, count, a);
+
+    void *fn = (void *) (intptr_t) function;
+    switch (count) {
+        case 0: return ((double_fn0) fn)();
+        case 1: return ((double_fn1) fn)(a[0]);
+        case 2: return ((double_fn2) fn)(a[0], a[1]);
+        case 3: return ((double_fn3) fn)(a[0], a[1], a[2]);
+        case 4: return ((double_fn4) fn)(a[0], a[1], a[2], a[3]);
+        case 5: return ((double_fn5) fn)(a[0], a[1], a[2], a[3], a[4]);
+        default: return ((double_fn6) fn)(a[0], a[1], a[2], a[3], a[4], a[5]);
+    }
+}
+
+static intptr_t call_int(void *fn, jsize count, const intptr_t *a) {
+    switch (count) {
+        case 0: return ((int_fn0) fn)();
+        case 1: return ((int_fn1) fn)(a[0]);
+        case 2: return ((int_fn2) fn)(a[0], a[1]);
+        case 3: return ((int_fn3) fn)(a[0], a[1], a[2]);
+        case 4: return ((int_fn4) fn)(a[0], a[1], a[2], a[3]);
+        case 5: return ((int_fn5) fn)(a[0], a[1], a[2], a[3], a[4]);
+        default: return ((int_fn6) fn)(a[0], a[1], a[2], a[3], a[4], a[5]);
+    }
+}
+
+static intptr_t call_long(void *fn, jsize count, const intptr_t *a) {
+    switch (count) {
+        case 0: return ((long_fn0) fn)();
+        case 1: return ((long_fn1) fn)(a[0]);
+        case 2: return ((long_fn2) fn)(a[0], a[1]);
+        case 3: return ((long_fn3) fn)(a[0], a[1], a[2]);
+        case 4: return ((long_fn4) fn)(a[0], a[1], a[2], a[3]);
+        case 5: return ((long_fn5) fn)(a[0], a[1], a[2], a[3], a[4]);
+        default: return ((long_fn6) fn)(a[0], a[1], a[2], a[3], a[4], a[5]);
+    }
+}
+
+// Call a function taking integers or pointers; void functions are called as if they returned a long
+JNIEXPORT jlong JNICALL Java_com_magayaga_microscript_NativeFfi_callLongs(JNIEnv *env, jclass cls, jlong function, jlongArray args, jint returnKind) {
+    jlong values[MAX_ARGS] = {0};
+    intptr_t a[MAX_ARGS] = {0};
+    jsize count = (*env)->GetArrayLength(env, args);
+    if (count > MAX_ARGS) {
+        throw_error(env, "ffi: too many arguments");
+        return 0;
+    }
+    (*env)->GetLongArrayRegion(env, args, 0, count, values);
+    for (jsize i = 0; i < count; i++) {
+        a[i] = (intptr_t) values[i];
+    }
+
+    void *fn = (void *) (intptr_t) function;
+    if (returnKind == RETURN_INT) {
+        return (jlong) call_int(fn, count, a);
+    }
+    intptr_t result = call_long(fn, count, a);
+    return returnKind == RETURN_VOID ? 0 : (jlong) result;
+}
+
+// Copy a Java string into a C string owned by the caller
+JNIEXPORT jlong JNICALL Java_com_magayaga_microscript_NativeFfi_newString(JNIEnv *env, jclass cls, jstring value) {
+    const char *cvalue = (*env)->GetStringUTFChars(env, value, NULL);
+    char *copy = (char *) malloc(strlen(cvalue) + 1);
+    if (copy != NULL) {
+        strcpy(copy, cvalue);
+    }
+    (*env)->ReleaseStringUTFChars(env, value, cvalue);
+    return (jlong) (intptr_t) copy;
+}
+
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeFfi_readString(JNIEnv *env, jclass cls, jlong pointer) {
+    return (*env)->NewStringUTF(env, (const char *) (intptr_t) pointer);
+}
+
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeFfi_freeString(JNIEnv *env, jclass cls, jlong pointer) {
+    free((void *) (intptr_t) pointer);
+}
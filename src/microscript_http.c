@@ -12,11 +12,79 @@
 // JNI function naming convention: Java_packagename_classname_methodname
 // For com.magayaga.microscript.NativeHttp
 
+// Server events arrive on Go threads, so the VM is cached to attach them
+static JavaVM *cachedVM = NULL;
+static jclass nativeHttpClass = NULL;
+static jmethodID dispatchMethod = NULL;
+
+JNIEXPORT jint JNICALL JNI_OnLoad(JavaVM *vm, void *reserved) {
+    cachedVM = vm;
+    return JNI_VERSION_1_8;
+}
+
+// Called by the Go library for every server event; forwards to NativeHttp.dispatch
+static void dispatchToJava(int event, int handle, char *id, char *payload) {
+    JNIEnv *env;
+    if ((*cachedVM)->GetEnv(cachedVM, (void **)&env, JNI_VERSION_1_8) != JNI_OK) {
+        // Go reuses its threads, so attach once as a daemon and stay attached
+        if ((*cachedVM)->AttachCurrentThreadAsDaemon(cachedVM, (void **)&env, NULL) != JNI_OK) {
+            return;
+        }
+    }
+    
+    jstring idStr = (*env)->NewStringUTF(env, id);
+    jstring payloadStr = (*env)->NewStringUTF(env, payload);
+    
+    (*env)->CallStaticVoidMethod(env, nativeHttpClass, dispatchMethod, (jint)event, (jint)handle, idStr, payloadStr);
+    if ((*env)->ExceptionCheck(env)) {
+        (*env)->ExceptionDescribe(env);
+        (*env)->ExceptionClear(env);
+    }
+    
+    (*env)->DeleteLocalRef(env, idStr);
+    (*env)->DeleteLocalRef(env, payloadStr);
+}
+
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_enableCallbacks
+  (JNIEnv *env, jclass cls) {
+    if (nativeHttpClass == NULL) {
+        nativeHttpClass = (jclass)(*env)->NewGlobalRef(env, cls);
+        dispatchMethod = (*env)->GetStaticMethodID(env, cls, "dispatch", "(IILjava/lang/String;Ljava/lang/String;)V");
+    }
+    setCallbackDispatcher((void *)dispatchToJava);
+}
+
 JNIEXPORT jint JNICALL Java_com_magayaga_microscript_NativeHttp_createServer
   (JNIEnv *env, jclass cls, jint port) {
     return createServer((int)port);
 }
 
+JNIEXPORT jint JNICALL Java_com_magayaga_microscript_NativeHttp_createTLSServer
+  (JNIEnv *env, jclass cls, jint port, jstring certFile, jstring keyFile) {
+    const char *certFileStr = (*env)->GetStringUTFChars(env, certFile, NULL);
+    const char *keyFileStr = (*env)->GetStringUTFChars(env, keyFile, NULL);
+    
+    int result = createTLSServer((int)port, (char*)certFileStr, (char*)keyFileStr);
+    
+    (*env)->ReleaseStringUTFChars(env, certFile, certFileStr);
+    (*env)->ReleaseStringUTFChars(env, keyFile, keyFileStr);
+    return result;
+}
+
+JNIEXPORT jint JNICALL Java_com_magayaga_microscript_NativeHttp_createAutocertServer
+  (JNIEnv *env, jclass cls, jstring domains, jstring cacheDir, jstring email) {
+    const char *domainsStr = (*env)->GetStringUTFChars(env, domains, NULL);
+    const char *cacheDirStr = (*env)->GetStringUTFChars(env, cacheDir, NULL);
+    const char *emailStr = (*env)->GetStringUTFChars(env, email, NULL);
+    
+    int result = createAutocertServer((char*)domainsStr, (char*)cacheDirStr, (char*)emailStr);
+    
+    (*env)->ReleaseStringUTFChars(env, domains, domainsStr);
+    (*env)->ReleaseStringUTFChars(env, cacheDir, cacheDirStr);
+    (*env)->ReleaseStringUTFChars(env, email, emailStr);
+    return result;
+}
+
 JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_stopServer
   (JNIEnv *env, jclass cls, jint serverHandle) {
     stopServer((int)serverHandle);
@@ -27,6 +95,21 @@ JNIEXPORT jboolean JNICALL Java_com_magayaga_microscript_NativeHttp_isRunning
     return isRunning((int)serverHandle) ? JNI_TRUE : JNI_FALSE;
 }
 
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_waitServer
+  (JNIEnv *env, jclass cls, jint serverHandle) {
+    waitServer((int)serverHandle);
+}
+
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_stopAllServers
+  (JNIEnv *env, jclass cls) {
+    stopAllServers();
+}
+
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_setShutdownGracePeriod
+  (JNIEnv *env, jclass cls, jint milliseconds) {
+    setShutdownGracePeriod((int)milliseconds);
+}
+
 JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_addRoute
   (JNIEnv *env, jclass cls, jint serverHandle, jstring method, jstring path, jstring handlerName) {
     const char *methodStr = (*env)->GetStringUTFChars(env, method, NULL);
@@ -40,15 +123,17 @@ JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_addRoute
     (*env)->ReleaseStringUTFChars(env, handlerName, handlerNameStr);
 }
 
-JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_removeRoute
+JNIEXPORT jboolean JNICALL Java_com_magayaga_microscript_NativeHttp_removeRoute
   (JNIEnv *env, jclass cls, jint serverHandle, jstring method, jstring path) {
     const char *methodStr = (*env)->GetStringUTFChars(env, method, NULL);
     const char *pathStr = (*env)->GetStringUTFChars(env, path, NULL);
     
-    removeRoute((int)serverHandle, (char*)methodStr, (char*)pathStr);
+    GoUint8 removed = removeRoute((int)serverHandle, (char*)methodStr, (char*)pathStr);
     
     (*env)->ReleaseStringUTFChars(env, method, methodStr);
     (*env)->ReleaseStringUTFChars(env, path, pathStr);
+    
+    return removed ? JNI_TRUE : JNI_FALSE;
 }
 
 JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_setResponseHeader
@@ -91,6 +176,30 @@ JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_sendFileResponse
     (*env)->ReleaseStringUTFChars(env, filePath, filePathStr);
 }
 
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_serveStatic
+  (JNIEnv *env, jclass cls, jint serverHandle, jstring urlPrefix, jstring directory, jboolean allowListing) {
+    const char *urlPrefixStr = (*env)->GetStringUTFChars(env, urlPrefix, NULL);
+    const char *directoryStr = (*env)->GetStringUTFChars(env, directory, NULL);
+    
+    serveStatic((int)serverHandle, (char*)urlPrefixStr, (char*)directoryStr, allowListing == JNI_TRUE ? 1 : 0);
+    
+    (*env)->ReleaseStringUTFChars(env, urlPrefix, urlPrefixStr);
+    (*env)->ReleaseStringUTFChars(env, directory, directoryStr);
+}
+
+JNIEXPORT jboolean JNICALL Java_com_magayaga_microscript_NativeHttp_proxy
+  (JNIEnv *env, jclass cls, jint serverHandle, jstring urlPrefix, jstring upstream) {
+    const char *urlPrefixStr = (*env)->GetStringUTFChars(env, urlPrefix, NULL);
+    const char *upstreamStr = (*env)->GetStringUTFChars(env, upstream, NULL);
+    
+    GoUint8 added = proxy((int)serverHandle, (char*)urlPrefixStr, (char*)upstreamStr);
+    
+    (*env)->ReleaseStringUTFChars(env, urlPrefix, urlPrefixStr);
+    (*env)->ReleaseStringUTFChars(env, upstream, upstreamStr);
+    
+    return added ? JNI_TRUE : JNI_FALSE;
+}
+
 JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_getRequestPath
   (JNIEnv *env, jclass cls, jint requestId) {
     char *path = getRequestPath((int)requestId);
@@ -127,6 +236,17 @@ JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_getRequestBod
     return result;
 }
 
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_getRequestJson
+  (JNIEnv *env, jclass cls, jint requestId) {
+    char *json = getRequestJson((int)requestId);
+    if (json == NULL) {
+        return NULL; // Body is not valid JSON
+    }
+    jstring result = (*env)->NewStringUTF(env, json);
+    free(json);
+    return result;
+}
+
 JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_getQueryParam
   (JNIEnv *env, jclass cls, jint requestId, jstring paramName) {
     const char *paramNameStr = (*env)->GetStringUTFChars(env, paramName, NULL);
@@ -148,6 +268,51 @@ JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_useMiddleware
     (*env)->ReleaseStringUTFChars(env, middlewareName, middlewareNameStr);
 }
 
+JNIEXPORT jboolean JNICALL Java_com_magayaga_microscript_NativeHttp_basicAuth
+  (JNIEnv *env, jclass cls, jint serverHandle, jstring usersJson) {
+    const char *usersJsonStr = (*env)->GetStringUTFChars(env, usersJson, NULL);
+    
+    GoUint8 enabled = basicAuth((int)serverHandle, (char*)usersJsonStr);
+    
+    (*env)->ReleaseStringUTFChars(env, usersJson, usersJsonStr);
+    
+    return enabled ? JNI_TRUE : JNI_FALSE;
+}
+
+JNIEXPORT jboolean JNICALL Java_com_magayaga_microscript_NativeHttp_jwtAuth
+  (JNIEnv *env, jclass cls, jint serverHandle, jstring secret, jstring algorithms) {
+    const char *secretStr = (*env)->GetStringUTFChars(env, secret, NULL);
+    const char *algorithmsStr = (*env)->GetStringUTFChars(env, algorithms, NULL);
+    
+    GoUint8 enabled = jwtAuth((int)serverHandle, (char*)secretStr, (char*)algorithmsStr);
+    
+    (*env)->ReleaseStringUTFChars(env, secret, secretStr);
+    (*env)->ReleaseStringUTFChars(env, algorithms, algorithmsStr);
+    
+    return enabled ? JNI_TRUE : JNI_FALSE;
+}
+
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_getRequestUser
+  (JNIEnv *env, jclass cls, jint requestId) {
+    char *user = getRequestUser((int)requestId);
+    jstring result = (*env)->NewStringUTF(env, user);
+    free(user);
+    return result;
+}
+
+JNIEXPORT jboolean JNICALL Java_com_magayaga_microscript_NativeHttp_setAccessLog
+  (JNIEnv *env, jclass cls, jint serverHandle, jstring format, jstring destination) {
+    const char *formatStr = (*env)->GetStringUTFChars(env, format, NULL);
+    const char *destinationStr = (*env)->GetStringUTFChars(env, destination, NULL);
+    
+    GoUint8 enabled = setAccessLog((int)serverHandle, (char*)formatStr, (char*)destinationStr);
+    
+    (*env)->ReleaseStringUTFChars(env, format, formatStr);
+    (*env)->ReleaseStringUTFChars(env, destination, destinationStr);
+    
+    return enabled ? JNI_TRUE : JNI_FALSE;
+}
+
 JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_urlEncode
   (JNIEnv *env, jclass cls, jstring input) {
     const char *inputStr = (*env)->GetStringUTFChars(env, input, NULL);
@@ -172,6 +337,51 @@ JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_urlDecode
     return result;
 }
 
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_parseUrl
+  (JNIEnv *env, jclass cls, jstring input) {
+    const char *inputStr = (*env)->GetStringUTFChars(env, input, NULL);
+    
+    char *output = parseUrl((char*)inputStr);
+    
+    (*env)->ReleaseStringUTFChars(env, input, inputStr);
+    if (output == NULL) {
+        return NULL; // Not a valid URL
+    }
+    jstring result = (*env)->NewStringUTF(env, output);
+    free(output);
+    return result;
+}
+
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_buildUrl
+  (JNIEnv *env, jclass cls, jstring input) {
+    const char *inputStr = (*env)->GetStringUTFChars(env, input, NULL);
+    
+    char *output = buildUrl((char*)inputStr);
+    
+    (*env)->ReleaseStringUTFChars(env, input, inputStr);
+    if (output == NULL) {
+        return NULL; // Malformed URL parts
+    }
+    jstring result = (*env)->NewStringUTF(env, output);
+    free(output);
+    return result;
+}
+
+JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_buildQuery
+  (JNIEnv *env, jclass cls, jstring input) {
+    const char *inputStr = (*env)->GetStringUTFChars(env, input, NULL);
+    
+    char *output = buildQuery((char*)inputStr);
+    
+    (*env)->ReleaseStringUTFChars(env, input, inputStr);
+    if (output == NULL) {
+        return NULL; // Malformed query
+    }
+    jstring result = (*env)->NewStringUTF(env, output);
+    free(output);
+    return result;
+}
+
 JNIEXPORT jstring JNICALL Java_com_magayaga_microscript_NativeHttp_generateUuid
   (JNIEnv *env, jclass cls) {
     char *uuid = generateUuid();
@@ -218,3 +428,8 @@ JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_closeWebSocketCo
     
     (*env)->ReleaseStringUTFChars(env, clientId, clientIdStr);
 }
+
+JNIEXPORT void JNICALL Java_com_magayaga_microscript_NativeHttp_enableWebSocketCallbacks
+  (JNIEnv *env, jclass cls, jint endpointHandle) {
+    enableWebSocketCallbacks((int)endpointHandle);
+}